@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"embed"
 	"fmt"
+	"io/fs"
 	"time"
 
 	"github.com/go-faster/errors"
@@ -12,8 +13,12 @@ import (
 	"github.com/pressly/goose/v3"
 )
 
+//go:embed migrations/*.sql
 var migrations embed.FS
 
+//go:embed migrations_sqlite/*.sql
+var sqliteMigrations embed.FS
+
 func Migrate(connStr string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -42,3 +47,21 @@ func Migrate(connStr string) error {
 	}
 	return nil
 }
+
+// MigrateSQLite применяет миграции SQLite к уже открытому соединению.
+// Используется goose.Provider, чтобы не трогать глобальное состояние goose,
+// которое настраивает Migrate для Postgres.
+func MigrateSQLite(ctx context.Context, db *sql.DB) error {
+	fsys, err := fs.Sub(sqliteMigrations, "migrations_sqlite")
+	if err != nil {
+		return errors.Wrap(err, "sqlite migrations fs")
+	}
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, fsys)
+	if err != nil {
+		return errors.Wrap(err, "create goose provider")
+	}
+	if _, err := provider.Up(ctx); err != nil {
+		return errors.Wrap(err, "apply sqlite migrations")
+	}
+	return nil
+}
@@ -1,3 +1,4 @@
+-- +goose Up
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
 CREATE TABLE IF NOT EXISTS cars (
@@ -7,4 +8,6 @@ CREATE TABLE IF NOT EXISTS cars (
     year INT NOT NULL CHECK (year >= 1886 AND year <= EXTRACT(YEAR FROM CURRENT_DATE) + 1),
     created_at TIMESTAMP NOT NULL DEFAULT NOW()
 );
-DROP TABLE IF EXISTS cars;
\ No newline at end of file
+
+-- +goose Down
+DROP TABLE IF EXISTS cars;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE cars DROP COLUMN IF EXISTS version;
@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS cars_deleted_at_idx ON cars (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS cars_deleted_at_idx;
+ALTER TABLE cars DROP COLUMN IF EXISTS deleted_at;
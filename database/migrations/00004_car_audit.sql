@@ -0,0 +1,20 @@
+-- +goose Up
+-- Без внешнего ключа на cars: история переживает окончательное удаление машины.
+CREATE TABLE IF NOT EXISTS car_audit (
+    id BIGSERIAL PRIMARY KEY,
+    car_id UUID NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor VARCHAR(100) NOT NULL DEFAULT '',
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    before JSONB NULL,
+    after JSONB NULL,
+    diff JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS car_audit_car_id_idx ON car_audit (car_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS car_audit_actor_idx ON car_audit (actor, created_at DESC);
+CREATE INDEX IF NOT EXISTS car_audit_created_at_idx ON car_audit (created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS car_audit;
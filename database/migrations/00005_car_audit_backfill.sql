@@ -0,0 +1,25 @@
+-- +goose Up
+-- Чтения "as of" восстанавливают состояние по car_audit, поэтому машинам,
+-- созданным до появления аудита, нужна начальная запись create (и delete для корзины).
+-- Начальное состояние берётся из before самой ранней записи, иначе из текущей строки.
+INSERT INTO car_audit (car_id, action, actor, before, after, diff, created_at)
+SELECT c.id, 'create', 'migration', NULL,
+       COALESCE(
+           (SELECT a.before FROM car_audit a WHERE a.car_id = c.id ORDER BY a.created_at, a.id LIMIT 1),
+           jsonb_build_object('brand', c.brand, 'model', c.model, 'year', c.year, 'version', c.version)
+       ),
+       '{}', c.created_at::timestamptz
+FROM cars c
+WHERE NOT EXISTS (SELECT 1 FROM car_audit a WHERE a.car_id = c.id AND a.action = 'create');
+
+INSERT INTO car_audit (car_id, action, actor, before, after, diff, created_at)
+SELECT c.id, 'delete', 'migration', NULL,
+       jsonb_build_object('brand', c.brand, 'model', c.model, 'year', c.year, 'version', c.version,
+                          'deleted_at', c.deleted_at),
+       '{}', c.deleted_at
+FROM cars c
+WHERE c.deleted_at IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM car_audit a WHERE a.car_id = c.id AND a.action = 'delete');
+
+-- +goose Down
+DELETE FROM car_audit WHERE actor = 'migration';
@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS car_imports (
+    id UUID PRIMARY KEY,
+    format VARCHAR(16) NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    total INT NOT NULL DEFAULT 0,
+    accepted INT NOT NULL DEFAULT 0,
+    rejected INT NOT NULL DEFAULT 0,
+    actor VARCHAR(100) NOT NULL DEFAULT '',
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Отчёт по строкам импорта; значения хранятся как пришли, поэтому TEXT.
+CREATE TABLE IF NOT EXISTS car_import_rows (
+    import_id UUID NOT NULL REFERENCES car_imports (id) ON DELETE CASCADE,
+    line INT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    car_id UUID NULL,
+    brand TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    year INT NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (import_id, line)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS car_import_rows;
+DROP TABLE IF EXISTS car_imports;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Очередь фоновых задач. Воркер захватывает задачу через FOR UPDATE SKIP LOCKED и держит
+-- аренду до locked_until; задача упавшего воркера снова становится доступной после её истечения.
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'queued',
+    params JSONB NOT NULL DEFAULT '{}',
+    result JSONB NULL,
+    error TEXT NOT NULL DEFAULT '',
+    processed BIGINT NOT NULL DEFAULT 0,
+    total BIGINT NOT NULL DEFAULT 0,
+    attempts INT NOT NULL DEFAULT 0,
+    cancel_requested BOOLEAN NOT NULL DEFAULT FALSE,
+    locked_until TIMESTAMPTZ NULL,
+    actor VARCHAR(100) NOT NULL DEFAULT '',
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ NULL,
+    finished_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS jobs_active_idx ON jobs (created_at) WHERE status IN ('queued', 'running');
+CREATE INDEX IF NOT EXISTS jobs_finished_idx ON jobs (finished_at) WHERE finished_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS vin VARCHAR(17) NULL CHECK (char_length(vin) = 17);
+
+-- VIN уникален среди машин вне корзины: удалённая машина не мешает завести её заново,
+-- а восстановить её можно, только если VIN свободен.
+CREATE UNIQUE INDEX IF NOT EXISTS cars_vin_key ON cars (vin) WHERE vin IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS cars_vin_key;
+ALTER TABLE cars DROP COLUMN IF EXISTS vin;
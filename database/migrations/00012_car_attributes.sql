@@ -0,0 +1,35 @@
+-- +goose Up
+-- Перечисления (кузов, топливо, коробка, состояние, статус, валюта) проверяет приложение:
+-- расширение списка не требует миграции.
+ALTER TABLE cars
+    ADD COLUMN IF NOT EXISTS mileage INTEGER NULL CHECK (mileage >= 0),
+    ADD COLUMN IF NOT EXISTS color VARCHAR(30) NULL,
+    ADD COLUMN IF NOT EXISTS body_type VARCHAR(20) NULL,
+    ADD COLUMN IF NOT EXISTS fuel_type VARCHAR(20) NULL,
+    ADD COLUMN IF NOT EXISTS transmission VARCHAR(20) NULL,
+    ADD COLUMN IF NOT EXISTS engine_volume INTEGER NULL CHECK (engine_volume > 0),
+    ADD COLUMN IF NOT EXISTS engine_power INTEGER NULL CHECK (engine_power > 0),
+    ADD COLUMN IF NOT EXISTS price BIGINT NULL CHECK (price >= 0),
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NULL,
+    ADD COLUMN IF NOT EXISTS condition VARCHAR(20) NULL,
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'available';
+
+ALTER TABLE cars ADD CONSTRAINT cars_price_currency_check CHECK ((price IS NULL) = (currency IS NULL));
+
+CREATE INDEX IF NOT EXISTS cars_status_idx ON cars (status) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS cars_status_idx;
+ALTER TABLE cars DROP CONSTRAINT IF EXISTS cars_price_currency_check;
+ALTER TABLE cars
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS condition,
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS price,
+    DROP COLUMN IF EXISTS engine_power,
+    DROP COLUMN IF EXISTS engine_volume,
+    DROP COLUMN IF EXISTS transmission,
+    DROP COLUMN IF EXISTS fuel_type,
+    DROP COLUMN IF EXISTS body_type,
+    DROP COLUMN IF EXISTS color,
+    DROP COLUMN IF EXISTS mileage;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- Справочник марок и моделей. name_key и keys — models.CatalogKey названия и всех
+-- написаний (название и синонимы): по keys ищутся входящие марка и модель машины.
+-- Машины хранят марку и модель строкой, поэтому удаление из справочника их не затрагивает.
+CREATE TABLE IF NOT EXISTS brands (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) NOT NULL,
+    name_key VARCHAR(50) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    keys TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS brands_name_key ON brands (name_key);
+CREATE INDEX IF NOT EXISTS brands_keys_idx ON brands USING GIN (keys);
+
+CREATE TABLE IF NOT EXISTS models (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    brand_id UUID NOT NULL REFERENCES brands (id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    name_key VARCHAR(50) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    keys TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS models_brand_name_key ON models (brand_id, name_key);
+CREATE INDEX IF NOT EXISTS models_keys_idx ON models USING GIN (keys);
+
+-- +goose Down
+DROP TABLE IF EXISTS models;
+DROP TABLE IF EXISTS brands;
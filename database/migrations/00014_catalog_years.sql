@@ -0,0 +1,17 @@
+-- +goose Up
+-- Годы выпуска марки и модели, по которым проверяется год машины; NULL — граница не задана.
+ALTER TABLE brands
+    ADD COLUMN IF NOT EXISTS year_from INTEGER NULL,
+    ADD COLUMN IF NOT EXISTS year_to INTEGER NULL;
+ALTER TABLE brands ADD CONSTRAINT brands_years_check CHECK (year_from <= year_to);
+
+ALTER TABLE models
+    ADD COLUMN IF NOT EXISTS year_from INTEGER NULL,
+    ADD COLUMN IF NOT EXISTS year_to INTEGER NULL;
+ALTER TABLE models ADD CONSTRAINT models_years_check CHECK (year_from <= year_to);
+
+-- +goose Down
+ALTER TABLE models DROP CONSTRAINT IF EXISTS models_years_check;
+ALTER TABLE models DROP COLUMN IF EXISTS year_to, DROP COLUMN IF EXISTS year_from;
+ALTER TABLE brands DROP CONSTRAINT IF EXISTS brands_years_check;
+ALTER TABLE brands DROP COLUMN IF EXISTS year_to, DROP COLUMN IF EXISTS year_from;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Как и car_audit, без внешнего ключа на cars: история цен переживает окончательное удаление.
+CREATE TABLE IF NOT EXISTS car_prices (
+    id BIGSERIAL PRIMARY KEY,
+    car_id UUID NOT NULL,
+    price BIGINT NULL CHECK (price >= 0),
+    currency CHAR(3) NULL,
+    actor VARCHAR(100) NOT NULL DEFAULT '',
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((price IS NULL) = (currency IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS car_prices_car_id_idx ON car_prices (car_id, id DESC);
+
+-- Текущие цены становятся первой точкой истории.
+INSERT INTO car_prices (car_id, price, currency, changed_at)
+SELECT id, price, currency, created_at
+FROM cars
+WHERE price IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS car_prices;
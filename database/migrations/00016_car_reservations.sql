@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS car_reservations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    car_id UUID NOT NULL REFERENCES cars (id) ON DELETE CASCADE,
+    customer VARCHAR(100) NOT NULL,
+    note VARCHAR(500) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL,
+    actor VARCHAR(100) NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMPTZ NULL
+);
+
+-- Не больше одной активной брони на машину, даже если проверку в приложении обошли.
+CREATE UNIQUE INDEX IF NOT EXISTS car_reservations_active_idx ON car_reservations (car_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS car_reservations_expires_at_idx ON car_reservations (expires_at) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS car_reservations_car_id_idx ON car_reservations (car_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS car_reservations;
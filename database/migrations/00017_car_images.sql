@@ -0,0 +1,19 @@
+-- +goose Up
+-- Внешнего ключа на cars нет намеренно: после окончательного удаления машины строки
+-- остаются, пока очистка не удалит файлы фотографий из хранилища объектов.
+CREATE TABLE IF NOT EXISTS car_images (
+    id UUID PRIMARY KEY,
+    car_id UUID NOT NULL,
+    position INT NOT NULL,
+    content_type VARCHAR(32) NOT NULL,
+    size BIGINT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    thumbnails TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS car_images_car_id_idx ON car_images (car_id, position);
+
+-- +goose Down
+DROP TABLE IF EXISTS car_images;
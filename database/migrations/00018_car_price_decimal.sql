@@ -0,0 +1,9 @@
+-- +goose Up
+-- Цена с копейками: NUMERIC(14,2) вместо целых единиц валюты, значения не меняются.
+-- В SQLite та же миграция объявляет NUMERIC(14,2) (database/migrations_sqlite).
+ALTER TABLE cars ALTER COLUMN price TYPE NUMERIC(14,2);
+ALTER TABLE car_prices ALTER COLUMN price TYPE NUMERIC(14,2);
+
+-- +goose Down
+ALTER TABLE car_prices ALTER COLUMN price TYPE BIGINT USING round(price);
+ALTER TABLE cars ALTER COLUMN price TYPE BIGINT USING round(price);
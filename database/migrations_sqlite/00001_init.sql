@@ -0,0 +1,15 @@
+-- +goose Up
+-- Верхняя граница года (текущий год + 1) проверяется в приложении:
+-- SQLite не допускает недетерминированные функции в CHECK.
+CREATE TABLE IF NOT EXISTS cars (
+    id TEXT PRIMARY KEY,
+    brand TEXT NOT NULL CHECK (length(brand) <= 50),
+    model TEXT NOT NULL CHECK (length(model) <= 50),
+    year INTEGER NOT NULL CHECK (year >= 1886),
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS cars_created_at_idx ON cars (created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS cars;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS car_audit (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    car_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    before TEXT NULL,
+    after TEXT NULL,
+    diff TEXT NOT NULL DEFAULT '{}',
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS car_audit_car_id_idx ON car_audit (car_id, created_at);
+CREATE INDEX IF NOT EXISTS car_audit_actor_idx ON car_audit (actor, created_at);
+CREATE INDEX IF NOT EXISTS car_audit_created_at_idx ON car_audit (created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS car_audit;
@@ -0,0 +1,22 @@
+-- +goose Up
+INSERT INTO car_audit (car_id, action, actor, before, after, diff, created_at)
+SELECT c.id, 'create', 'migration', NULL,
+       COALESCE(
+           (SELECT a.before FROM car_audit a WHERE a.car_id = c.id ORDER BY a.created_at, a.id LIMIT 1),
+           json_object('brand', c.brand, 'model', c.model, 'year', c.year, 'version', c.version)
+       ),
+       '{}', c.created_at
+FROM cars c
+WHERE NOT EXISTS (SELECT 1 FROM car_audit a WHERE a.car_id = c.id AND a.action = 'create');
+
+INSERT INTO car_audit (car_id, action, actor, before, after, diff, created_at)
+SELECT c.id, 'delete', 'migration', NULL,
+       json_object('brand', c.brand, 'model', c.model, 'year', c.year, 'version', c.version,
+                   'deleted_at', c.deleted_at),
+       '{}', c.deleted_at
+FROM cars c
+WHERE c.deleted_at IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM car_audit a WHERE a.car_id = c.id AND a.action = 'delete');
+
+-- +goose Down
+DELETE FROM car_audit WHERE actor = 'migration';
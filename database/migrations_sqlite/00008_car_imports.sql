@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS car_imports (
+    id TEXT PRIMARY KEY,
+    format TEXT NOT NULL,
+    dry_run INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    accepted INTEGER NOT NULL DEFAULT 0,
+    rejected INTEGER NOT NULL DEFAULT 0,
+    actor TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS car_import_rows (
+    import_id TEXT NOT NULL REFERENCES car_imports (id) ON DELETE CASCADE,
+    line INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    car_id TEXT NULL,
+    brand TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    year INTEGER NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (import_id, line)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS car_import_rows;
+DROP TABLE IF EXISTS car_imports;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- params и result хранятся JSON-текстом. SKIP LOCKED в SQLite нет: захват задачи —
+-- один UPDATE, а запись в базу и так сериализована.
+CREATE TABLE IF NOT EXISTS jobs (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'queued',
+    params TEXT NOT NULL DEFAULT '{}',
+    result TEXT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    processed INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    cancel_requested INTEGER NOT NULL DEFAULT 0,
+    locked_until TEXT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    started_at TEXT NULL,
+    finished_at TEXT NULL
+);
+
+CREATE INDEX IF NOT EXISTS jobs_active_idx ON jobs (created_at) WHERE status IN ('queued', 'running');
+CREATE INDEX IF NOT EXISTS jobs_finished_idx ON jobs (finished_at) WHERE finished_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS jobs;
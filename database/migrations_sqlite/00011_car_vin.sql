@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE cars ADD COLUMN vin TEXT NULL CHECK (length(vin) = 17);
+
+CREATE UNIQUE INDEX IF NOT EXISTS cars_vin_key ON cars (vin) WHERE vin IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS cars_vin_key;
+ALTER TABLE cars DROP COLUMN vin;
@@ -0,0 +1,28 @@
+-- +goose Up
+ALTER TABLE cars ADD COLUMN mileage INTEGER NULL CHECK (mileage >= 0);
+ALTER TABLE cars ADD COLUMN color TEXT NULL;
+ALTER TABLE cars ADD COLUMN body_type TEXT NULL;
+ALTER TABLE cars ADD COLUMN fuel_type TEXT NULL;
+ALTER TABLE cars ADD COLUMN transmission TEXT NULL;
+ALTER TABLE cars ADD COLUMN engine_volume INTEGER NULL CHECK (engine_volume > 0);
+ALTER TABLE cars ADD COLUMN engine_power INTEGER NULL CHECK (engine_power > 0);
+ALTER TABLE cars ADD COLUMN price INTEGER NULL CHECK (price >= 0);
+ALTER TABLE cars ADD COLUMN currency TEXT NULL CHECK (length(currency) = 3);
+ALTER TABLE cars ADD COLUMN condition TEXT NULL;
+ALTER TABLE cars ADD COLUMN status TEXT NOT NULL DEFAULT 'available';
+
+CREATE INDEX IF NOT EXISTS cars_status_idx ON cars (status) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS cars_status_idx;
+ALTER TABLE cars DROP COLUMN status;
+ALTER TABLE cars DROP COLUMN condition;
+ALTER TABLE cars DROP COLUMN currency;
+ALTER TABLE cars DROP COLUMN price;
+ALTER TABLE cars DROP COLUMN engine_power;
+ALTER TABLE cars DROP COLUMN engine_volume;
+ALTER TABLE cars DROP COLUMN transmission;
+ALTER TABLE cars DROP COLUMN fuel_type;
+ALTER TABLE cars DROP COLUMN body_type;
+ALTER TABLE cars DROP COLUMN color;
+ALTER TABLE cars DROP COLUMN mileage;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE brands ADD COLUMN year_from INTEGER NULL;
+ALTER TABLE brands ADD COLUMN year_to INTEGER NULL;
+ALTER TABLE models ADD COLUMN year_from INTEGER NULL;
+ALTER TABLE models ADD COLUMN year_to INTEGER NULL;
+
+-- +goose Down
+ALTER TABLE models DROP COLUMN year_to;
+ALTER TABLE models DROP COLUMN year_from;
+ALTER TABLE brands DROP COLUMN year_to;
+ALTER TABLE brands DROP COLUMN year_from;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS car_prices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    car_id TEXT NOT NULL,
+    price INTEGER NULL CHECK (price >= 0),
+    currency TEXT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    changed_at TEXT NOT NULL,
+    CHECK ((price IS NULL) = (currency IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS car_prices_car_id_idx ON car_prices (car_id, id);
+
+INSERT INTO car_prices (car_id, price, currency, changed_at)
+SELECT id, price, currency, created_at
+FROM cars
+WHERE price IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS car_prices;
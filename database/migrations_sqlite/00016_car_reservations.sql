@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS car_reservations (
+    id TEXT PRIMARY KEY,
+    car_id TEXT NOT NULL REFERENCES cars (id) ON DELETE CASCADE,
+    customer TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    expires_at TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    closed_at TEXT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS car_reservations_active_idx ON car_reservations (car_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS car_reservations_expires_at_idx ON car_reservations (expires_at) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS car_reservations_car_id_idx ON car_reservations (car_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS car_reservations;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Без внешнего ключа на cars: строки фотографий окончательно удалённой машины нужны,
+-- чтобы очистка удалила их файлы. thumbnails хранится JSON-массивом.
+CREATE TABLE IF NOT EXISTS car_images (
+    id TEXT PRIMARY KEY,
+    car_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    thumbnails TEXT NOT NULL DEFAULT '[]',
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS car_images_car_id_idx ON car_images (car_id, position);
+
+-- +goose Down
+DROP TABLE IF EXISTS car_images;
@@ -0,0 +1,47 @@
+-- +goose Up
+-- Цена с копейками, как NUMERIC(14,2) в PostgreSQL. Десятичного типа в SQLite нет: колонка
+-- с NUMERIC-аффинностью хранит дробную цену как REAL, а models.Money при чтении округляет её
+-- до сотых, что точно для любых 15 значащих цифр NUMERIC(14,2). Тип колонки в SQLite не меняется
+-- через ALTER, поэтому в cars колонка пересоздаётся, а car_prices (с табличным CHECK) — целиком.
+ALTER TABLE cars ADD COLUMN price_decimal NUMERIC(14,2) NULL CHECK (price_decimal >= 0);
+UPDATE cars SET price_decimal = price;
+ALTER TABLE cars DROP COLUMN price;
+ALTER TABLE cars RENAME COLUMN price_decimal TO price;
+
+CREATE TABLE car_prices_decimal (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    car_id TEXT NOT NULL,
+    price NUMERIC(14,2) NULL CHECK (price >= 0),
+    currency TEXT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    changed_at TEXT NOT NULL,
+    CHECK ((price IS NULL) = (currency IS NULL))
+);
+INSERT INTO car_prices_decimal (id, car_id, price, currency, actor, request_id, changed_at)
+SELECT id, car_id, price, currency, actor, request_id, changed_at FROM car_prices;
+DROP TABLE car_prices;
+ALTER TABLE car_prices_decimal RENAME TO car_prices;
+CREATE INDEX IF NOT EXISTS car_prices_car_id_idx ON car_prices (car_id, id);
+
+-- +goose Down
+ALTER TABLE cars ADD COLUMN price_integer INTEGER NULL CHECK (price_integer >= 0);
+UPDATE cars SET price_integer = CAST(round(price) AS INTEGER);
+ALTER TABLE cars DROP COLUMN price;
+ALTER TABLE cars RENAME COLUMN price_integer TO price;
+
+CREATE TABLE car_prices_integer (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    car_id TEXT NOT NULL,
+    price INTEGER NULL CHECK (price >= 0),
+    currency TEXT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    changed_at TEXT NOT NULL,
+    CHECK ((price IS NULL) = (currency IS NULL))
+);
+INSERT INTO car_prices_integer (id, car_id, price, currency, actor, request_id, changed_at)
+SELECT id, car_id, CAST(round(price) AS INTEGER), currency, actor, request_id, changed_at FROM car_prices;
+DROP TABLE car_prices;
+ALTER TABLE car_prices_integer RENAME TO car_prices;
+CREATE INDEX IF NOT EXISTS car_prices_car_id_idx ON car_prices (car_id, id);
@@ -0,0 +1,38 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/pavel97go/service-cars/internal/config"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/repository/repotest"
+	"github.com/pavel97go/service-cars/internal/storage"
+)
+
+func connect(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool, err := storage.GetConnect(ctx, config.Init().GetConnStr())
+	if err != nil {
+		t.Skipf("postgres is not available: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestCarRepo_Contract(t *testing.T) {
+	pool := connect(t)
+
+	repotest.RunCarProviderSuite(t, func(t *testing.T) repository.CarProvider {
+		if _, err := pool.Exec(context.Background(), `TRUNCATE cars, car_audit, car_outbox, car_prices, car_reservations, car_images, webhook_subscriptions, car_imports, jobs, idempotency_keys, brands, models CASCADE;`); err != nil {
+			t.Fatalf("truncate cars: %v", err)
+		}
+		return repository.NewCarRepo(pool)
+	})
+}
@@ -2,19 +2,31 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/http"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/pavel97go/service-cars/database"
+	"github.com/pavel97go/service-cars/internal/blob"
+	"github.com/pavel97go/service-cars/internal/cache"
 	"github.com/pavel97go/service-cars/internal/config"
+	"github.com/pavel97go/service-cars/internal/events"
 	"github.com/pavel97go/service-cars/internal/handler"
+	"github.com/pavel97go/service-cars/internal/imaging"
+	"github.com/pavel97go/service-cars/internal/jobs"
 	"github.com/pavel97go/service-cars/internal/metrics"
 	"github.com/pavel97go/service-cars/internal/models"
 	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/reqctx"
 	"github.com/pavel97go/service-cars/internal/router"
 	"github.com/pavel97go/service-cars/internal/storage"
+	"github.com/pavel97go/service-cars/internal/stream"
 	"github.com/pavel97go/service-cars/internal/usecase"
+	"github.com/pavel97go/service-cars/internal/webhook"
+	"github.com/pavel97go/service-cars/internal/worker"
 )
 
 func Run(ctx context.Context) error {
@@ -23,26 +35,240 @@ func Run(ctx context.Context) error {
 
 	cfg := config.Init()
 	addr := ":" + cfg.App.Port
-	dsn := cfg.GetConnStr()
 
-	pctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+	repo, closeRepo, err := newCarProvider(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	// SSE и WebSocket живут в памяти процесса и получают события прямо из usecase.
+	broker := stream.NewBroker(cfg.Stream.LogSize, cfg.Stream.BufferSize)
+	defer broker.Close()
+	uc := usecase.NewCarUsecase(repo,
+		usecase.WithNotifier(broker),
+		usecase.WithBatchLimit(cfg.Batch.MaxOperations),
+		usecase.WithCatalog(repo, cfg.Catalog.Strict),
+		usecase.WithImages(repo),
+	)
+	auditUC := usecase.NewAuditUsecase(repo)
+	reservationUC := usecase.NewReservationUsecase(repo,
+		time.Duration(cfg.Reservations.TTLMinutes)*time.Minute,
+		time.Duration(cfg.Reservations.MaxTTLHours)*time.Hour,
+		usecase.WithReservationNotifier(broker),
+	)
 
-	pool, err := storage.GetConnect(pctx, dsn)
+	if cfg.Trash.RetentionDays > 0 && cfg.Trash.PurgeIntervalMinutes > 0 {
+		retention := time.Duration(cfg.Trash.RetentionDays) * 24 * time.Hour
+		interval := time.Duration(cfg.Trash.PurgeIntervalMinutes) * time.Minute
+		go worker.PurgeTrash(ctx, uc, retention, interval)
+	}
+	if cfg.Reservations.SweepIntervalSeconds > 0 {
+		go worker.ExpireReservations(ctx, reservationUC, time.Duration(cfg.Reservations.SweepIntervalSeconds)*time.Second)
+	}
+	blobs, err := newBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+	sizes, err := imaging.ParseSizes(cfg.Images.ThumbnailSizes)
 	if err != nil {
 		return err
 	}
-	defer pool.Close()
+	imageUC := usecase.NewImageUsecase(repo, blobs, usecase.ImageConfig{
+		MaxBytes:  cfg.Images.MaxBytes,
+		MaxPerCar: cfg.Images.MaxPerCar,
+		Sizes:     sizes,
+	})
+	if cfg.Images.CleanupIntervalMinutes > 0 {
+		go worker.PurgeImages(ctx, imageUC, time.Duration(cfg.Images.CleanupIntervalMinutes)*time.Minute)
+	}
+	pub, closePub, err := newOutboxPublisher(cfg, repo)
+	if err != nil {
+		return err
+	}
+	defer closePub()
+	go worker.RelayOutbox(ctx, repo, pub, worker.RelayConfig{
+		Interval:     time.Duration(cfg.Outbox.PollIntervalMs) * time.Millisecond,
+		BatchSize:    cfg.Outbox.BatchSize,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   time.Duration(cfg.Outbox.MaxBackoffSeconds) * time.Second,
+		PublishLimit: 10 * time.Second,
+	})
+
+	go worker.DeliverWebhooks(ctx, repo, webhook.NewSender(time.Duration(cfg.Webhooks.TimeoutSeconds)*time.Second),
+		worker.DeliveryConfig{
+			Interval:    time.Duration(cfg.Webhooks.PollIntervalMs) * time.Millisecond,
+			BatchSize:   50,
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			BaseBackoff: 5 * time.Second,
+			MaxBackoff:  time.Duration(cfg.Webhooks.MaxBackoffSeconds) * time.Second,
+		})
 
-	repo := repository.NewCarRepo(pool)
-	uc := usecase.NewCarUsecase(repo)
-	h := handler.NewCarHandler(uc)
+	idemUC := usecase.NewIdempotencyUsecase(repo, time.Duration(cfg.Idempotency.TTLHours)*time.Hour)
+	if cfg.Idempotency.PurgeIntervalMinutes > 0 {
+		go worker.PurgeIdempotencyKeys(ctx, idemUC, time.Duration(cfg.Idempotency.PurgeIntervalMinutes)*time.Minute)
+	}
+
+	files, err := jobs.NewFiles(cfg.Jobs.Dir)
+	if err != nil {
+		return err
+	}
+	importUC := usecase.NewImportUsecase(repo, cfg.Import.MaxRows,
+		usecase.WithImportCatalog(repo, cfg.Catalog.Strict), usecase.WithImportNotifier(broker))
+	exportUC := usecase.NewExportUsecase(repo)
+	var stats repository.StatsProvider = repo
+	if cfg.Stats.CacheTTLSeconds > 0 {
+		stats = cache.NewStatsCache(repo, time.Duration(cfg.Stats.CacheTTLSeconds)*time.Second)
+	}
+	jobUC := usecase.NewJobUsecase(repo, files)
+	go jobs.NewPool(repo, files, map[string]jobs.Handler{
+		models.JobKindImport: usecase.ImportJob(importUC, files),
+		models.JobKindExport: usecase.ExportJob(exportUC, files),
+	}, jobs.Config{
+		Workers:      cfg.Jobs.Workers,
+		PollInterval: time.Duration(cfg.Jobs.PollIntervalMs) * time.Millisecond,
+		Lease:        time.Duration(cfg.Jobs.LeaseSeconds) * time.Second,
+		MaxAttempts:  cfg.Jobs.MaxAttempts,
+		Retention:    time.Duration(cfg.Jobs.RetentionHours) * time.Hour,
+	}).Run(ctx)
 
-	app := fiber.New()
+	handlers := router.Handlers{
+		Cars:         handler.NewCarHandler(uc),
+		Audit:        handler.NewAuditHandler(auditUC),
+		Webhooks:     handler.NewWebhookHandler(usecase.NewWebhookUsecase(repo)),
+		Stream:       handler.NewStreamHandler(broker, time.Duration(cfg.Stream.HeartbeatSeconds)*time.Second),
+		Export:       handler.NewExportHandler(exportUC, jobUC),
+		Imports:      handler.NewImportHandler(importUC, jobUC, cfg.Import.MaxBytes),
+		Jobs:         handler.NewJobHandler(jobUC),
+		VIN:          handler.NewVINHandler(),
+		Catalog:      handler.NewCatalogHandler(usecase.NewCatalogUsecase(repo)),
+		Stats:        handler.NewStatsHandler(usecase.NewStatsUsecase(stats)),
+		Prices:       handler.NewPriceHandler(usecase.NewPriceUsecase(repo)),
+		Reservations: handler.NewReservationHandler(reservationUC),
+		Images:       handler.NewImageHandler(imageUC, cfg.Images.MaxBytes),
+		Idempotency:  handler.Idempotency(idemUC),
+		Live: handler.NewLiveHandler(broker, handler.LiveConfig{
+			MaxSubscriptions: cfg.WS.MaxSubscriptions,
+			PingInterval:     time.Duration(cfg.WS.PingIntervalSeconds) * time.Second,
+			WriteTimeout:     10 * time.Second,
+		}),
+	}
+
+	// тела импорта и загрузки фотографий читаются потоком; для остальных маршрутов лимит проверяет handler.BodyLimit
+	app := fiber.New(fiber.Config{StreamRequestBody: true})
 	app.Use(metrics.Middleware())
+	app.Use(reqctx.Middleware())
+	app.Use(handler.BodyLimit(fiber.DefaultBodyLimit, "/api/v1/cars/import", "/api/v1/cars/:id/images"))
 	app.Get("/metrics", metrics.Handler())
-	router.Register(app, h)
+	router.Register(app, handlers)
 
 	log.Printf("Server is running on %s", addr)
 	return app.Listen(addr)
 }
+
+// store — возможности, которые должен поддерживать любой драйвер хранилища.
+type store interface {
+	repository.CarProvider
+	repository.AuditProvider
+	repository.OutboxProvider
+	repository.WebhookProvider
+	repository.ImportProvider
+	repository.ExportProvider
+	repository.JobProvider
+	repository.IdempotencyProvider
+	repository.CatalogProvider
+	repository.StatsProvider
+	repository.PriceProvider
+	repository.ReservationProvider
+	repository.ImageProvider
+}
+
+// newCarProvider выбирает хранилище по cfg.StorageDriver().
+func newCarProvider(ctx context.Context, cfg *config.Config) (store, func(), error) {
+	pctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	switch driver := cfg.StorageDriver(); driver {
+	case "memory":
+		log.Println("using in-memory storage, data will be lost on restart")
+		return repository.NewMemoryCarRepo(), func() {}, nil
+	case "sqlite":
+		db, err := storage.OpenSQLite(pctx, cfg.GetConnStr())
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := database.MigrateSQLite(pctx, db); err != nil {
+			_ = db.Close()
+			return nil, nil, err
+		}
+		return repository.NewSQLiteCarRepo(db), func() { _ = db.Close() }, nil
+	case "postgres":
+		pool, err := storage.GetConnect(pctx, cfg.GetConnStr())
+		if err != nil {
+			return nil, nil, err
+		}
+		return repository.NewCarRepo(pool), pool.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
+
+// newBlobStore выбирает хранилище файлов фотографий по cfg.Images.Storage.
+func newBlobStore(cfg *config.Config) (blob.Store, error) {
+	switch kind := cfg.Images.Storage; kind {
+	case "fs":
+		return blob.NewFS(cfg.Images.Dir)
+	case "s3":
+		s3 := cfg.Images.S3
+		return blob.NewS3(blob.S3Config{
+			Endpoint:  s3.Endpoint,
+			Region:    s3.Region,
+			Bucket:    s3.Bucket,
+			AccessKey: s3.AccessKey,
+			SecretKey: s3.SecretKey,
+		}, &http.Client{Timeout: time.Minute})
+	default:
+		return nil, fmt.Errorf("unknown images storage %q", kind)
+	}
+}
+
+// newEventPublisher выбирает транспорт доменных событий по cfg.Outbox.Publisher.
+// newOutboxPublisher собирает получателей relay: webhook-подписки получают события всегда,
+// внешний транспорт добавляется, только если OUTBOX_PUBLISHER не none.
+func newOutboxPublisher(cfg *config.Config, queue webhook.DeliveryQueue) (events.EventPublisher, func(), error) {
+	enqueuer := webhook.NewEnqueuer(queue)
+	if cfg.Outbox.Publisher == "none" {
+		return events.Fanout{enqueuer}, func() {}, nil
+	}
+	pub, closePub, err := newEventPublisher(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return events.Fanout{pub, enqueuer}, closePub, nil
+}
+
+func newEventPublisher(cfg *config.Config) (events.EventPublisher, func(), error) {
+	switch kind := cfg.Outbox.Publisher; kind {
+	case "log":
+		return events.NewLogPublisher(nil), func() {}, nil
+	case "file":
+		p, err := events.NewFilePublisher(cfg.Outbox.FilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return p, func() { _ = p.Close() }, nil
+	case "webhook":
+		if cfg.Outbox.WebhookURL == "" {
+			return nil, nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required for webhook publisher")
+		}
+		return events.NewWebhookPublisher(cfg.Outbox.WebhookURL, 10*time.Second), func() {}, nil
+	case "nats":
+		p, err := events.NewNATSPublisher(cfg.Outbox.NATSURL, cfg.Outbox.NATSSubject, 5*time.Second)
+		if err != nil {
+			return nil, nil, err
+		}
+		return p, func() { _ = p.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown outbox publisher %q", kind)
+	}
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/config"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// OUTBOX_PUBLISHER=none отключает только внешний транспорт: события из outbox
+// по-прежнему раскладываются по webhook-подпискам.
+func TestOutboxPublisher_NoneStillEnqueuesWebhooks(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryCarRepo()
+	sub := models.WebhookSubscription{URL: "https://example.com/hook", Secret: "whsec_0123456789abcdef", Active: true}
+	require.NoError(t, repo.CreateWebhook(ctx, &sub))
+	require.NoError(t, repo.InsertCar(ctx, &models.Car{Brand: "Kia", Model: "Rio", Year: 2021}))
+
+	cfg := &config.Config{}
+	cfg.Outbox.Publisher = "none"
+	pub, closePub, err := newOutboxPublisher(cfg, repo)
+	require.NoError(t, err)
+	defer closePub()
+
+	pending, err := repo.PendingEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.NoError(t, pub.Publish(ctx, pending[0]))
+
+	deliveries, err := repo.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, sub.ID, deliveries[0].SubscriptionID)
+	assert.Equal(t, models.EventCarCreated, deliveries[0].EventType)
+}
+
+func TestOutboxPublisher_UnknownPublisher(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Outbox.Publisher = "kafka"
+	_, _, err := newOutboxPublisher(cfg, repository.NewMemoryCarRepo())
+	assert.Error(t, err)
+}
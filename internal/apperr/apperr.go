@@ -3,7 +3,17 @@ package apperr
 import "errors"
 
 var (
-	ErrNotFound     = errors.New("not found")
-	ErrInvalidInput = errors.New("invalid input")
-	ErrInternal     = errors.New("internal server error")
+	ErrNotFound           = errors.New("not found")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrInternal           = errors.New("internal server error")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrAborted — операция не применена, потому что атомарный пакет отменён из-за другой.
+	ErrAborted = errors.New("aborted")
+	// ErrTooLarge — запрос превышает допустимый размер.
+	ErrTooLarge = errors.New("payload too large")
+	// ErrUnsupportedMedia — содержимое запроса не того типа, что ожидается.
+	ErrUnsupportedMedia = errors.New("unsupported media type")
+	// ErrMismatch — повтор запроса с тем же Idempotency-Key отличается от первого.
+	ErrMismatch = errors.New("request does not match")
 )
@@ -0,0 +1,37 @@
+// Package blob хранит двоичные объекты (файлы изображений машин) по ключу: в локальном
+// каталоге или в S3-совместимом хранилище.
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+)
+
+// Store — хранилище объектов. Ключ — сегменты из букв, цифр, '.', '_' и '-' через '/'.
+type Store interface {
+	// Put записывает объект целиком, заменяя существующий.
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get открывает объект на чтение; apperr.ErrNotFound, если его нет.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект; отсутствие объекта ошибкой не считается.
+	Delete(ctx context.Context, key string) error
+}
+
+// checkKey не пропускает ключи, которые вышли бы за пределы каталога или бакета.
+func checkKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("%w: empty blob key", apperr.ErrInvalidInput)
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." || strings.TrimLeft(seg, keyChars) != "" {
+			return fmt.Errorf("%w: invalid blob key %q", apperr.ErrInvalidInput, key)
+		}
+	}
+	return nil
+}
+
+const keyChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789._-"
@@ -125,6 +125,11 @@ func (c *CarCache) GetCarByID(ctx context.Context, id string) (*models.Car, erro
 	c.setByID(*car)
 	return car, nil
 }
+
+// GetCarByVIN не кэшируется: VIN может перейти к другой машине при удалении и восстановлении.
+func (c *CarCache) GetCarByVIN(ctx context.Context, vin string) (*models.Car, error) {
+	return c.next.GetCarByVIN(ctx, vin)
+}
 func (c *CarCache) InsertCar(ctx context.Context, newCar *models.Car) error {
 	if err := c.next.InsertCar(ctx, newCar); err != nil {
 		return err
@@ -138,6 +143,9 @@ func (c *CarCache) UpdateCar(ctx context.Context, updatedCar *models.Car) error
 		if errors.Is(err, apperr.ErrNotFound) {
 			return apperr.ErrNotFound
 		}
+		if errors.Is(err, apperr.ErrConflict) { // в кэше устаревшая версия
+			c.delByID(updatedCar.ID)
+		}
 		return err
 	}
 	c.delByID(updatedCar.ID)
@@ -156,3 +164,38 @@ func (c *CarCache) DeleteByID(ctx context.Context, id string) error {
 	c.invalidateList()
 	return nil
 }
+func (c *CarCache) ListDeletedCars(ctx context.Context) ([]models.Car, error) {
+	return c.next.ListDeletedCars(ctx)
+}
+func (c *CarCache) RestoreByID(ctx context.Context, id string) (*models.Car, error) {
+	car, err := c.next.RestoreByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidateList()
+	c.setByID(*car)
+	return car, nil
+}
+func (c *CarCache) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return c.next.PurgeDeleted(ctx, before)
+}
+
+// GetCarAsOf и ListCarsAsOf не кэшируются: исторические чтения идут напрямую в хранилище.
+func (c *CarCache) GetCarAsOf(ctx context.Context, id string, at time.Time) (*models.Car, error) {
+	return c.next.GetCarAsOf(ctx, id, at)
+}
+func (c *CarCache) ListCarsAsOf(ctx context.Context, at time.Time) ([]models.Car, error) {
+	return c.next.ListCarsAsOf(ctx, at)
+}
+
+// ApplyBatch сбрасывает закэшированные записи всех затронутых машин, даже если пакет отменён.
+func (c *CarCache) ApplyBatch(ctx context.Context, ops []models.CarMutation, atomic bool) ([]models.MutationResult, error) {
+	results, err := c.next.ApplyBatch(ctx, ops, atomic)
+	for _, op := range ops {
+		if op.Car.ID != "" {
+			c.delByID(op.Car.ID)
+		}
+	}
+	c.invalidateList()
+	return results, err
+}
@@ -21,7 +21,7 @@ type fakeRepo struct {
 	list  []models.Car
 	err   error
 	calls struct {
-		list, get, ins, upd, del int
+		list, get, ins, upd, del, asOf int
 	}
 }
 
@@ -58,6 +58,10 @@ func (f *fakeRepo) GetCarByID(ctx context.Context, id string) (*models.Car, erro
 	return &cc, nil
 }
 
+func (f *fakeRepo) GetCarByVIN(ctx context.Context, vin string) (*models.Car, error) {
+	return nil, apperr.ErrNotFound
+}
+
 func (f *fakeRepo) InsertCar(ctx context.Context, c *models.Car) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -111,6 +115,62 @@ func (f *fakeRepo) DeleteByID(ctx context.Context, id string) error {
 	return nil
 }
 
+func (f *fakeRepo) ListDeletedCars(ctx context.Context) ([]models.Car, error) {
+	return []models.Car{}, nil
+}
+
+func (f *fakeRepo) RestoreByID(ctx context.Context, id string) (*models.Car, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	c, ok := f.cars[id]
+	if !ok {
+		return nil, apperr.ErrNotFound
+	}
+	return &c, nil
+}
+
+func (f *fakeRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeRepo) GetCarAsOf(ctx context.Context, id string, at time.Time) (*models.Car, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls.asOf++
+	c, ok := f.cars[id]
+	if !ok {
+		return nil, apperr.ErrNotFound
+	}
+	c.Brand = "historical"
+	return &c, nil
+}
+
+func (f *fakeRepo) ListCarsAsOf(ctx context.Context, at time.Time) ([]models.Car, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls.asOf++
+	return []models.Car{}, nil
+}
+
+func (f *fakeRepo) ApplyBatch(ctx context.Context, ops []models.CarMutation, atomic bool) ([]models.MutationResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	results := make([]models.MutationResult, len(ops))
+	for i, op := range ops {
+		c := op.Car
+		if op.Op == models.BatchOpCreate {
+			c.ID = "batch-id"
+			f.list = append(f.list, c)
+		}
+		f.cars[c.ID] = c
+		results[i].Car = &c
+	}
+	return results, nil
+}
+
 func TestCarCache_GetCarByID_MissThenHit(t *testing.T) {
 	t.Parallel()
 
@@ -227,3 +287,85 @@ func TestCarCache_ErrorsAreNotCached(t *testing.T) {
 	require.Error(t, err)
 	assert.Equal(t, getCalls+1, repo.calls.get, "errors must not be cached")
 }
+
+func TestCarCache_Restore_InvalidatesList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newFakeRepo()
+	car := models.Car{ID: "id3", Brand: "Kia", Model: "Rio", Year: 2018}
+	repo.cars[car.ID] = car
+
+	c := cache.NewCarCache(repo, time.Minute)
+	_, err := c.ListCars(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.calls.list)
+
+	restored, err := c.RestoreByID(ctx, car.ID)
+	require.NoError(t, err)
+	assert.Equal(t, car.ID, restored.ID)
+
+	_, err = c.ListCars(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.calls.list, "list invalidated after restore")
+
+	_, err = c.GetCarByID(ctx, car.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, repo.calls.get, "restored car should be cached")
+}
+
+func TestCarCache_AsOf_BypassesCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newFakeRepo()
+	repo.cars["id1"] = models.Car{ID: "id1", Brand: "BMW", Model: "X5", Year: 2022}
+
+	c := cache.NewCarCache(repo, time.Minute)
+	_, err := c.GetCarByID(ctx, "id1")
+	require.NoError(t, err)
+
+	at := time.Now().Add(-time.Hour)
+	got, err := c.GetCarAsOf(ctx, "id1", at)
+	require.NoError(t, err)
+	assert.Equal(t, "historical", got.Brand)
+	_, err = c.GetCarAsOf(ctx, "id1", at)
+	require.NoError(t, err)
+	_, err = c.ListCarsAsOf(ctx, at)
+	require.NoError(t, err)
+	assert.Equal(t, 3, repo.calls.asOf, "as-of reads must always hit repo")
+
+	cur, err := c.GetCarByID(ctx, "id1")
+	require.NoError(t, err)
+	assert.Equal(t, "BMW", cur.Brand, "as-of read must not overwrite cached current state")
+	assert.Equal(t, 1, repo.calls.get)
+}
+
+func TestCarCache_ApplyBatch_Invalidates(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newFakeRepo()
+	repo.cars["id1"] = models.Car{ID: "id1", Brand: "BMW", Model: "X5", Year: 2022}
+
+	c := cache.NewCarCache(repo, time.Minute)
+	_, err := c.GetCarByID(ctx, "id1")
+	require.NoError(t, err)
+	_, err = c.ListCars(ctx)
+	require.NoError(t, err)
+
+	_, err = c.ApplyBatch(ctx, []models.CarMutation{
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "Kia", Model: "Rio", Year: 2020}},
+		{Op: models.BatchOpUpdate, Car: models.Car{ID: "id1", Brand: "Audi", Model: "A6", Year: 2022}},
+	}, true)
+	require.NoError(t, err)
+
+	got, err := c.GetCarByID(ctx, "id1")
+	require.NoError(t, err)
+	assert.Equal(t, "Audi", got.Brand)
+	assert.Equal(t, 2, repo.calls.get, "updated car must be refetched")
+
+	_, err = c.ListCars(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.calls.list, "list invalidated after batch")
+}
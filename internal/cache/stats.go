@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+type statsItem struct {
+	stats models.CarStats
+	exp   time.Time
+}
+
+// StatsCache кэширует статистику по запросу на ttl. Изменения машин кэш не сбрасывают:
+// агрегаты по всей таблице дороги, а отчёту достаточно данных не старше ttl.
+type StatsCache struct {
+	next  repository.StatsProvider
+	ttl   time.Duration
+	mu    sync.Mutex
+	items map[models.StatsQuery]statsItem
+}
+
+var _ repository.StatsProvider = (*StatsCache)(nil)
+
+func NewStatsCache(next repository.StatsProvider, ttl time.Duration) *StatsCache {
+	return &StatsCache{next: next, ttl: ttl, items: make(map[models.StatsQuery]statsItem)}
+}
+
+func cloneStats(s models.CarStats) *models.CarStats {
+	s.Groups = slices.Clone(s.Groups)
+	s.Created = slices.Clone(s.Created)
+	return &s
+}
+
+func (c *StatsCache) CarStats(ctx context.Context, q models.StatsQuery) (*models.CarStats, error) {
+	// одинаковые моменты в разных зонах — один ключ
+	q.From, q.To = q.From.UTC(), q.To.UTC()
+	now := time.Now()
+
+	c.mu.Lock()
+	item, ok := c.items[q]
+	c.mu.Unlock()
+	if ok && now.Before(item.exp) {
+		return cloneStats(item.stats), nil
+	}
+
+	stats, err := c.next.CarStats(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	for k, it := range c.items { // протухшие записи удаляются при записи, чтобы кэш не рос
+		if !now.Before(it.exp) {
+			delete(c.items, k)
+		}
+	}
+	c.items[q] = statsItem{stats: *cloneStats(*stats), exp: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return stats, nil
+}
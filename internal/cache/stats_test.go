@@ -0,0 +1,72 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/cache"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+type fakeStats struct {
+	calls int
+	err   error
+}
+
+func (f *fakeStats) CarStats(ctx context.Context, q models.StatsQuery) (*models.CarStats, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.CarStats{Total: int64(f.calls), Groups: []models.StatsGroup{{Key: q.Filter.Brand, Count: 1}}}, nil
+}
+
+func TestStatsCache_PerQueryWithTTL(t *testing.T) {
+	repo := &fakeStats{}
+	c := cache.NewStatsCache(repo, 50*time.Millisecond)
+	ctx := context.Background()
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	q := models.StatsQuery{GroupBy: models.StatsGroupBrand, Period: models.StatsPeriodDay, From: day, To: day.AddDate(0, 0, 1)}
+
+	first, err := c.CarStats(ctx, q)
+	require.NoError(t, err)
+	first.Groups[0].Key = "changed" // вызывающий не может испортить кэш
+
+	local := q
+	local.From = q.From.In(time.FixedZone("MSK", 3*60*60))
+	second, err := c.CarStats(ctx, local)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.calls)
+	assert.Equal(t, int64(1), second.Total)
+	assert.Equal(t, "", second.Groups[0].Key)
+
+	other := q
+	other.Filter.Brand = "BMW"
+	_, err = c.CarStats(ctx, other)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.calls, "another filter is another entry")
+
+	time.Sleep(80 * time.Millisecond)
+	third, err := c.CarStats(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, 3, repo.calls)
+	assert.Equal(t, int64(3), third.Total)
+}
+
+func TestStatsCache_ErrorsAreNotCached(t *testing.T) {
+	repo := &fakeStats{err: errors.New("db down")}
+	c := cache.NewStatsCache(repo, time.Minute)
+	ctx := context.Background()
+
+	_, err := c.CarStats(ctx, models.StatsQuery{})
+	require.Error(t, err)
+	repo.err = nil
+	_, err = c.CarStats(ctx, models.StatsQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.calls)
+}
@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -14,6 +16,8 @@ type Config struct {
 		Port string
 	}
 	DB struct {
+		Driver   string
+		DSN      string
 		Port     string
 		Host     string
 		User     string
@@ -24,6 +28,80 @@ type Config struct {
 	Cache struct {
 		TTLSeconds int
 	}
+	Stats struct {
+		CacheTTLSeconds int // 0 — считать статистику на каждый запрос
+	}
+	Batch struct {
+		MaxOperations int
+	}
+	Catalog struct {
+		Strict bool // отклонять машины с маркой не из справочника
+	}
+	Import struct {
+		MaxRows  int
+		MaxBytes int64
+	}
+	Jobs struct {
+		Workers        int
+		Dir            string // входные и выходные файлы задач; общий для всех экземпляров
+		LeaseSeconds   int
+		PollIntervalMs int
+		MaxAttempts    int
+		RetentionHours int
+	}
+	Idempotency struct {
+		TTLHours             int // сколько хранится ответ для повторов с тем же Idempotency-Key
+		PurgeIntervalMinutes int
+	}
+	Trash struct {
+		RetentionDays        int
+		PurgeIntervalMinutes int
+	}
+	Reservations struct {
+		TTLMinutes           int // срок брони, если клиент его не задал
+		MaxTTLHours          int
+		SweepIntervalSeconds int // как часто снимаются истёкшие брони
+	}
+	Images struct {
+		Storage                string // fs или s3
+		Dir                    string // каталог файлов для fs; общий для всех экземпляров
+		MaxBytes               int64
+		MaxPerCar              int
+		ThumbnailSizes         string // имя:пиксели через запятую, например small:160,medium:480
+		CleanupIntervalMinutes int    // как часто удаляются файлы машин, удалённых из корзины
+		S3                     struct {
+			Endpoint  string
+			Region    string
+			Bucket    string
+			AccessKey string
+			SecretKey string
+		}
+	}
+	Outbox struct {
+		Publisher         string // log, file, webhook, nats или none
+		FilePath          string
+		WebhookURL        string
+		NATSURL           string
+		NATSSubject       string
+		PollIntervalMs    int
+		BatchSize         int
+		MaxBackoffSeconds int
+	}
+	Webhooks struct {
+		MaxAttempts       int
+		TimeoutSeconds    int
+		PollIntervalMs    int
+		MaxBackoffSeconds int
+	}
+	Stream struct {
+		LogSize          int // сколько последних событий хранится для Last-Event-ID
+		BufferSize       int // очередь подписчика; при переполнении он отключается
+		HeartbeatSeconds int
+	}
+	WS struct {
+		MaxSubscriptions    int
+		PingIntervalSeconds int
+	}
 }
 
 func env(key, def string) string {
@@ -40,10 +118,20 @@ func envInt(key string, def int) int {
 	}
 	return def
 }
+func envBool(key string, def bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
 func Init() *Config {
 	var c Config
 	c.App.Port = env("APP_PORT", "8080")
 
+	c.DB.Driver = env("DB_DRIVER", "")
+	c.DB.DSN = env("DB_DSN", "")
 	c.DB.Host = env("DB_HOST", "localhost")
 	c.DB.Port = env("DB_PORT", "5432")
 	c.DB.User = env("DB_USER", "cars")
@@ -53,12 +141,88 @@ func Init() *Config {
 
 	c.Metrics.Port = env("METRICS_PORT", "9100")
 	c.Cache.TTLSeconds = envInt("CACHE_TTL_SECONDS", 60)
+	c.Stats.CacheTTLSeconds = envInt("STATS_CACHE_TTL_SECONDS", 300)
+	c.Batch.MaxOperations = envInt("BATCH_MAX_OPERATIONS", 1000)
+	c.Catalog.Strict = envBool("CATALOG_STRICT", false)
+	c.Import.MaxRows = envInt("IMPORT_MAX_ROWS", 100000)
+	c.Import.MaxBytes = int64(envInt("IMPORT_MAX_BYTES", 100<<20))
+
+	c.Jobs.Workers = envInt("JOBS_WORKERS", 2)
+	c.Jobs.Dir = env("JOBS_DIR", filepath.Join(os.TempDir(), "service-cars-jobs"))
+	c.Jobs.LeaseSeconds = envInt("JOBS_LEASE_SECONDS", 30)
+	c.Jobs.PollIntervalMs = envInt("JOBS_POLL_INTERVAL_MS", 1000)
+	c.Jobs.MaxAttempts = envInt("JOBS_MAX_ATTEMPTS", 3)
+	c.Jobs.RetentionHours = envInt("JOBS_RETENTION_HOURS", 24)
+
+	c.Idempotency.TTLHours = envInt("IDEMPOTENCY_TTL_HOURS", 24)
+	c.Idempotency.PurgeIntervalMinutes = envInt("IDEMPOTENCY_PURGE_INTERVAL_MINUTES", 60)
+
+	c.Trash.RetentionDays = envInt("TRASH_RETENTION_DAYS", 30)
+	c.Trash.PurgeIntervalMinutes = envInt("TRASH_PURGE_INTERVAL_MINUTES", 60)
+
+	c.Reservations.TTLMinutes = envInt("RESERVATION_TTL_MINUTES", 60)
+	c.Reservations.MaxTTLHours = envInt("RESERVATION_MAX_TTL_HOURS", 168)
+	c.Reservations.SweepIntervalSeconds = envInt("RESERVATION_SWEEP_INTERVAL_SECONDS", 60)
+
+	c.Images.Storage = env("IMAGES_STORAGE", "fs")
+	c.Images.Dir = env("IMAGES_DIR", filepath.Join(os.TempDir(), "service-cars-images"))
+	c.Images.MaxBytes = int64(envInt("IMAGES_MAX_BYTES", 10<<20))
+	c.Images.MaxPerCar = envInt("IMAGES_MAX_PER_CAR", 20)
+	c.Images.ThumbnailSizes = env("IMAGES_THUMBNAIL_SIZES", "small:160,medium:480,large:1280")
+	c.Images.CleanupIntervalMinutes = envInt("IMAGES_CLEANUP_INTERVAL_MINUTES", 60)
+	c.Images.S3.Endpoint = env("IMAGES_S3_ENDPOINT", "")
+	c.Images.S3.Region = env("IMAGES_S3_REGION", "us-east-1")
+	c.Images.S3.Bucket = env("IMAGES_S3_BUCKET", "")
+	c.Images.S3.AccessKey = env("IMAGES_S3_ACCESS_KEY", "")
+	c.Images.S3.SecretKey = env("IMAGES_S3_SECRET_KEY", "")
+
+	c.Outbox.Publisher = env("OUTBOX_PUBLISHER", "log")
+	c.Outbox.FilePath = env("OUTBOX_FILE_PATH", "events.ndjson")
+	c.Outbox.WebhookURL = env("OUTBOX_WEBHOOK_URL", "")
+	c.Outbox.NATSURL = env("OUTBOX_NATS_URL", "nats://localhost:4222")
+	c.Outbox.NATSSubject = env("OUTBOX_NATS_SUBJECT", "cars")
+	c.Outbox.PollIntervalMs = envInt("OUTBOX_POLL_INTERVAL_MS", 1000)
+	c.Outbox.BatchSize = envInt("OUTBOX_BATCH_SIZE", 100)
+	c.Outbox.MaxBackoffSeconds = envInt("OUTBOX_MAX_BACKOFF_SECONDS", 300)
+
+	c.Webhooks.MaxAttempts = envInt("WEBHOOK_MAX_ATTEMPTS", 8)
+	c.Webhooks.TimeoutSeconds = envInt("WEBHOOK_TIMEOUT_SECONDS", 10)
+	c.Webhooks.PollIntervalMs = envInt("WEBHOOK_POLL_INTERVAL_MS", 1000)
+	c.Webhooks.MaxBackoffSeconds = envInt("WEBHOOK_MAX_BACKOFF_SECONDS", 3600)
+
+	c.Stream.LogSize = envInt("STREAM_LOG_SIZE", 1000)
+	c.Stream.BufferSize = envInt("STREAM_BUFFER_SIZE", 64)
+	c.Stream.HeartbeatSeconds = envInt("STREAM_HEARTBEAT_SECONDS", 15)
+
+	c.WS.MaxSubscriptions = envInt("WS_MAX_SUBSCRIPTIONS", 20)
+	c.WS.PingIntervalSeconds = envInt("WS_PING_INTERVAL_SECONDS", 30)
 
 	return &c
 }
 func (c *Config) GetConnStr() string {
+	if c.DB.DSN != "" {
+		return c.DB.DSN
+	}
 	return fmt.Sprintf(
 		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
 		c.DB.User, c.DB.Password, c.DB.Host, c.DB.Port, c.DB.Name, c.DB.SSLMode,
 	)
 }
+
+// StorageDriver возвращает драйвер хранилища: явно заданный DB_DRIVER
+// либо выведенный из схемы DB_DSN (postgres://, sqlite://, memory://).
+func (c *Config) StorageDriver() string {
+	if c.DB.Driver != "" {
+		return c.DB.Driver
+	}
+	scheme, _, ok := strings.Cut(c.DB.DSN, "://")
+	if !ok {
+		return "postgres"
+	}
+	switch scheme {
+	case "postgres", "postgresql":
+		return "postgres"
+	default:
+		return scheme
+	}
+}
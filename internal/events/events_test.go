@@ -0,0 +1,167 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+func testEvent() models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:         42,
+		Type:       models.EventCarCreated,
+		CarID:      "7b1f4a7e-0000-4000-8000-000000000001",
+		Payload:    json.RawMessage(`{"brand":"Toyota"}`),
+		OccurredAt: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	var got models.OutboxEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "42", r.Header.Get(HeaderEventID))
+		assert.Equal(t, models.EventCarCreated, r.Header.Get(HeaderEventType))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p := NewWebhookPublisher(srv.URL, time.Second)
+	require.NoError(t, p.Publish(context.Background(), testEvent()))
+	assert.Equal(t, int64(42), got.ID)
+	assert.JSONEq(t, `{"brand":"Toyota"}`, string(got.Payload))
+}
+
+func TestWebhookPublisher_Non2xxIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookPublisher(srv.URL, time.Second).Publish(context.Background(), testEvent())
+	assert.ErrorContains(t, err, "503")
+}
+
+func TestFilePublisher_AppendsNDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	p, err := NewFilePublisher(path)
+	require.NoError(t, err)
+
+	evt := testEvent()
+	require.NoError(t, p.Publish(context.Background(), evt))
+	evt.ID = 43
+	require.NoError(t, p.Publish(context.Background(), evt))
+	require.NoError(t, p.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var second models.OutboxEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, int64(43), second.ID)
+}
+
+// fakeNATS — минимальный сервер NATS: приветствие INFO, PONG на PING,
+// -ERR на PUB в subject, начинающийся с "deny".
+func fakeNATS(t *testing.T) (addr string, published <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	ch := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveNATS(conn, ch)
+		}
+	}()
+	return ln.Addr().String(), ch
+}
+
+func serveNATS(conn net.Conn, published chan<- string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	_, _ = io.WriteString(conn, "INFO {\"server_id\":\"fake\"}\r\n")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "PING":
+			_, _ = io.WriteString(conn, "PONG\r\n")
+		case strings.HasPrefix(line, "PUB "):
+			var (
+				subject string
+				size    int
+			)
+			if _, err := fmt.Sscanf(line, "PUB %s %d", &subject, &size); err != nil {
+				return
+			}
+			body := make([]byte, size+2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return
+			}
+			if strings.HasPrefix(subject, "deny") {
+				_, _ = io.WriteString(conn, "-ERR 'Permissions Violation'\r\n")
+				continue
+			}
+			published <- subject + " " + string(body[:size])
+		}
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	addr, published := fakeNATS(t)
+	p, err := NewNATSPublisher("nats://"+addr, "cars", time.Second)
+	require.NoError(t, err)
+	defer p.Close()
+
+	require.NoError(t, p.Publish(context.Background(), testEvent()))
+	msg := <-published
+	subject, body, _ := strings.Cut(msg, " ")
+	assert.Equal(t, "cars.CarCreated", subject)
+	var got models.OutboxEvent
+	require.NoError(t, json.Unmarshal([]byte(body), &got))
+	assert.Equal(t, int64(42), got.ID)
+
+	// соединение переиспользуется
+	require.NoError(t, p.Publish(context.Background(), testEvent()))
+	<-published
+}
+
+func TestNATSPublisher_ServerErrorIsReported(t *testing.T) {
+	addr, _ := fakeNATS(t)
+	p, err := NewNATSPublisher("nats://"+addr, "deny", time.Second)
+	require.NoError(t, err)
+	defer p.Close()
+
+	err = p.Publish(context.Background(), testEvent())
+	assert.ErrorContains(t, err, "Permissions Violation")
+}
+
+func TestNewNATSPublisher_InvalidURL(t *testing.T) {
+	_, err := NewNATSPublisher("http://localhost:4222", "cars", time.Second)
+	assert.Error(t, err)
+}
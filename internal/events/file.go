@@ -0,0 +1,43 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// FilePublisher дописывает события в файл в формате NDJSON (одно событие на строку).
+type FilePublisher struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FilePublisher{f: f}, nil
+}
+
+func (p *FilePublisher) Publish(ctx context.Context, evt models.OutboxEvent) error {
+	line, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.f.Write(line); err != nil {
+		return err
+	}
+	return p.f.Sync() // событие считается опубликованным только после записи на диск
+}
+
+func (p *FilePublisher) Close() error {
+	return p.f.Close()
+}
@@ -0,0 +1,177 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+const defaultNATSPort = "4222"
+
+// NATSPublisher публикует события по текстовому протоколу NATS (совместим с
+// nats-server и другими брокерами, понимающими PUB). Событие уходит в subject
+// "<prefix>.<type>"; после PUB отправляется PING, и доставка подтверждается
+// только получением PONG — так ошибка сервера (-ERR) не теряется.
+type NATSPublisher struct {
+	addr    string
+	user    *url.Userinfo
+	prefix  string
+	timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewNATSPublisher принимает адрес вида nats://[user:pass@]host[:port].
+// Соединение устанавливается при первой публикации и переоткрывается после ошибки.
+func NewNATSPublisher(rawURL, subjectPrefix string, timeout time.Duration) (*NATSPublisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "nats" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid NATS url %q", rawURL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = defaultNATSPort
+	}
+	return &NATSPublisher{
+		addr:    net.JoinHostPort(u.Hostname(), port),
+		user:    u.User,
+		prefix:  subjectPrefix,
+		timeout: timeout,
+	}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, evt models.OutboxEvent) error {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	subject := evt.Type
+	if p.prefix != "" {
+		subject = p.prefix + "." + evt.Type
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.publish(ctx, subject, body); err != nil {
+		p.reset()
+		return err
+	}
+	return nil
+}
+
+func (p *NATSPublisher) publish(ctx context.Context, subject string, body []byte) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.conn.SetDeadline(p.deadline(ctx)); err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(body), body)
+	if _, err := p.conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return p.awaitPong()
+}
+
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	d := net.Dialer{Timeout: p.timeout}
+	conn, err := d.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return err
+	}
+	p.conn, p.r = conn, bufio.NewReader(conn)
+	if err := conn.SetDeadline(p.deadline(ctx)); err != nil {
+		return err
+	}
+
+	line, err := p.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO") {
+		return fmt.Errorf("nats: unexpected greeting %q", line)
+	}
+
+	opts := map[string]any{"verbose": false, "pedantic": false, "name": "service-cars"}
+	if p.user != nil {
+		opts["user"] = p.user.Username()
+		if pass, ok := p.user.Password(); ok {
+			opts["pass"] = pass
+		}
+	}
+	connect, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		return err
+	}
+	return p.awaitPong()
+}
+
+// awaitPong читает ответы сервера до PONG, отвечая на его PING.
+func (p *NATSPublisher) awaitPong() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("nats: " + strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (p *NATSPublisher) readLine() (string, error) {
+	line, err := p.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p *NATSPublisher) deadline(ctx context.Context) time.Time {
+	d := time.Now().Add(p.timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(d) {
+		return dl
+	}
+	return d
+}
+
+func (p *NATSPublisher) reset() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+	p.conn, p.r = nil, nil
+}
+
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reset()
+	return nil
+}
@@ -0,0 +1,50 @@
+// Package events содержит транспорты для доменных событий из outbox.
+package events
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// EventPublisher доставляет событие подписчикам. Ошибка означает, что событие
+// не доставлено и будет отправлено повторно; доставка — at-least-once,
+// получатели должны дедуплицировать по ID.
+type EventPublisher interface {
+	Publish(ctx context.Context, evt models.OutboxEvent) error
+}
+
+// LogPublisher пишет события в лог; полезен для локальной разработки.
+type LogPublisher struct {
+	logger *slog.Logger
+}
+
+func NewLogPublisher(logger *slog.Logger) *LogPublisher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LogPublisher{logger: logger}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, evt models.OutboxEvent) error {
+	p.logger.InfoContext(ctx, "domain event",
+		"id", evt.ID, "type", evt.Type, "car_id", evt.CarID, "payload", string(evt.Payload))
+	return nil
+}
+
+// Fanout публикует событие во все транспорты. Если хотя бы один вернул ошибку,
+// событие будет отправлено повторно во все, поэтому транспорты должны быть
+// идемпотентны или допускать дубликаты.
+type Fanout []EventPublisher
+
+func (f Fanout) Publish(ctx context.Context, evt models.OutboxEvent) error {
+	var errs []error
+	for _, p := range f {
+		if err := p.Publish(ctx, evt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+// Package export пишет выгрузку машин в CSV, NDJSON и XLSX построчно, не накапливая данные в памяти.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+)
+
+var contentTypes = map[string]string{
+	FormatCSV:    "text/csv",
+	FormatNDJSON: "application/x-ndjson",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// columns — заголовок табличных форматов, порядок совпадает с record.
+var columns = []string{"id", "brand", "model", "year", "version", "vin", "mileage", "color", "body_type",
+	"fuel_type", "transmission", "engine_volume", "engine_power", "price", "currency", "condition", "status"}
+
+// Writer пишет машины по одной. Flush отдаёт накопленное дальше (чтобы заметить
+// отключение клиента), Close дописывает окончание формата; после Close писать нельзя.
+type Writer interface {
+	Write(car models.CarResponse) error
+	Flush() error
+	Close() error
+}
+
+// ContentType возвращает MIME-тип формата; ok=false для неизвестного формата.
+func ContentType(format string) (string, bool) {
+	ct, ok := contentTypes[format]
+	return ct, ok
+}
+
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(columns); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw}, nil
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return &ndjsonWriter{enc: enc}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+func record(car models.CarResponse) []string {
+	a := car.CarAttributes
+	return []string{car.ID, car.Brand, car.Model, strconv.Itoa(car.Year), strconv.Itoa(car.Version), car.VIN,
+		optionalInt(a.Mileage), a.Color, a.BodyType, a.FuelType, a.Transmission, optionalInt(a.EngineVolume),
+		optionalInt(a.EnginePower), optionalMoney(a.Price), a.Currency, a.Condition, a.Status}
+}
+
+// optionalInt — пустая ячейка для неуказанного значения.
+func optionalInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+// optionalMoney — сумма десятичным числом, пустая ячейка для неуказанной.
+func optionalMoney(v *models.Money) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(car models.CarResponse) error {
+	return c.w.Write(record(car))
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(car models.CarResponse) error {
+	return n.enc.Encode(car)
+}
+
+func (n *ndjsonWriter) Flush() error { return nil }
+func (n *ndjsonWriter) Close() error { return nil }
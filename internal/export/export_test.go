@@ -0,0 +1,119 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var sample = []models.CarResponse{
+	{ID: "id-1", Brand: "BMW", Model: "Xfive", Year: 2020, VIN: "1M8GDM9AXKP042788", Version: 1,
+		CarAttributes: models.CarAttributes{Mileage: ptr(42000), Color: "black", FuelType: "diesel", Price: money(349999999),
+			Currency: "RUB", Status: "available"}},
+	{ID: "id-2", Brand: `Rolls <"&"> Royce`, Model: "Ghost, Black", Year: 2019, Version: 3},
+}
+
+func ptr(v int) *int { return &v }
+
+func money(v models.Money) *models.Money { return &v }
+
+func writeAll(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+	for _, car := range sample {
+		require.NoError(t, w.Write(car))
+		require.NoError(t, w.Flush())
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	want := "id,brand,model,year,version,vin,mileage,color,body_type,fuel_type,transmission,engine_volume," +
+		"engine_power,price,currency,condition,status\n" +
+		"id-1,BMW,Xfive,2020,1,1M8GDM9AXKP042788,42000,black,,diesel,,,,3499999.99,RUB,,available\n" +
+		"id-2,\"Rolls <\"\"&\"\"> Royce\",\"Ghost, Black\",2019,3,,,,,,,,,,,,\n"
+	assert.Equal(t, want, string(writeAll(t, FormatCSV)))
+}
+
+func TestNDJSON(t *testing.T) {
+	want := `{"id":"id-1","brand":"BMW","model":"Xfive","year":2020,"vin":"1M8GDM9AXKP042788","mileage":42000,` +
+		`"color":"black","fuel_type":"diesel","price":3499999.99,"currency":"RUB","status":"available","version":1}` + "\n" +
+		`{"id":"id-2","brand":"Rolls <\"&\"> Royce","model":"Ghost, Black","year":2019,"version":3}` + "\n"
+	assert.Equal(t, want, string(writeAll(t, FormatNDJSON)))
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSX(t *testing.T) {
+	data := writeAll(t, FormatXLSX)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = b
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		require.Contains(t, files, name)
+		require.NoError(t, xml.Unmarshal(files[name], new(struct{})), name)
+	}
+
+	var sheet xlsxSheet
+	require.NoError(t, xml.Unmarshal(files["xl/worksheets/sheet1.xml"], &sheet))
+	require.Len(t, sheet.Rows, 3)
+	assert.Equal(t, "id", sheet.Rows[0].Cells[0].Inline)
+	row := sheet.Rows[2]
+	assert.Equal(t, 3, row.R)
+	assert.Equal(t, "B3", row.Cells[1].Ref)
+	assert.Equal(t, `Rolls <"&"> Royce`, row.Cells[1].Inline)
+	assert.Equal(t, "", row.Cells[3].Type)
+	assert.Equal(t, "2019", row.Cells[3].Value)
+
+	// пустые числовые ячейки не пишутся: ссылки остаются по колонкам
+	first := sheet.Rows[1]
+	refs := make([]string, len(first.Cells))
+	for i, c := range first.Cells {
+		refs[i] = c.Ref
+	}
+	assert.Contains(t, refs, "G2")
+	assert.NotContains(t, refs, "L2")
+	assert.Equal(t, "42000", first.Cells[6].Value)
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 4: "E", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, want, xlsxColumn(i))
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := NewWriter("xml", io.Discard)
+	assert.Error(t, err)
+	_, ok := ContentType("xml")
+	assert.False(t, ok)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+type AuditHandler struct {
+	uc usecase.AuditUsecase
+}
+
+func NewAuditHandler(uc usecase.AuditUsecase) *AuditHandler {
+	return &AuditHandler{uc: uc}
+}
+
+// CarHistory — GET /cars/:id/history?limit=&offset=, новые записи первыми.
+func (h *AuditHandler) CarHistory(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing id"})
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	var q models.AuditQuery
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.History(ctx, id, q.Limit, q.Offset)
+	if err != nil {
+		return writeAuditError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// Query — GET /audit?actor=&action=&car_id=&from=&to=&limit=&offset=.
+func (h *AuditHandler) Query(c *fiber.Ctx) error {
+	var q models.AuditQuery
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.Query(ctx, q)
+	if err != nil {
+		return writeAuditError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func writeAuditError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, apperr.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
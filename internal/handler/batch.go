@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+type batchItemResponse struct {
+	Index  int                 `json:"index"`
+	Op     string              `json:"op"`
+	Status int                 `json:"status"`
+	Car    *models.CarResponse `json:"car,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Atomic    bool                `json:"atomic"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Results   []batchItemResponse `json:"results"`
+}
+
+// Batch — POST /cars/batch: 200, если применены все операции, иначе 207 Multi-Status
+// со статусом каждой операции.
+func (h *CarHandler) Batch(c *fiber.Ctx) error {
+	var req models.BatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 30*time.Second)
+	defer cancel()
+
+	res, err := h.uc.Batch(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperr.ErrInvalidInput):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, apperr.ErrConflict): // VIN заняли параллельно, пакет не применён
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	resp := batchResponse{Atomic: res.Atomic, Results: make([]batchItemResponse, len(res.Items))}
+	for i, item := range res.Items {
+		r := batchItemResponse{Index: item.Index, Op: item.Op, Car: item.Car}
+		if item.Err != nil {
+			r.Status = batchErrorStatus(item.Err)
+			r.Error = item.Err.Error()
+			resp.Failed++
+		} else {
+			r.Status = fiber.StatusOK
+			if item.Op == models.BatchOpCreate {
+				r.Status = fiber.StatusCreated
+			}
+			resp.Succeeded++
+		}
+		resp.Results[i] = r
+	}
+
+	status := fiber.StatusOK
+	if resp.Failed > 0 {
+		status = fiber.StatusMultiStatus
+	}
+	return c.Status(status).JSON(resp)
+}
+
+func batchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, apperr.ErrInvalidInput):
+		return fiber.StatusBadRequest
+	case errors.Is(err, apperr.ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, apperr.ErrConflict):
+		return fiber.StatusConflict
+	case errors.Is(err, apperr.ErrAborted):
+		return fiber.StatusFailedDependency
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
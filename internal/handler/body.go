@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit ограничивает тело запроса, когда сервер запущен со StreamRequestBody:
+// fasthttp тогда не отклоняет большие тела сам, а c.Body() прочитал бы их целиком.
+// Пути из streamed (сегмент вида :id совпадает с любым) читают тело потоком
+// и сами отвечают за его размер.
+func BodyLimit(limit int, streamed ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		path := strings.TrimSuffix(c.Path(), "/")
+		if slices.ContainsFunc(streamed, func(pattern string) bool { return matchPath(pattern, path) }) {
+			return c.Next()
+		}
+		size := c.Request().Header.ContentLength()
+		stream := c.Context().RequestBodyStream()
+		if size == 0 || stream == nil {
+			return c.Next()
+		}
+		if size > limit {
+			return bodyTooLarge(c)
+		}
+		// размер заранее неизвестен (chunked): читаем не больше limit+1 байт
+		body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if len(body) > limit {
+			return bodyTooLarge(c)
+		}
+		c.Request().SetBody(body)
+		return c.Next()
+	}
+}
+
+// matchPath сравнивает путь с шаблоном по сегментам; сегмент шаблона с ':' совпадает с любым непустым.
+func matchPath(pattern, path string) bool {
+	ps, segs := strings.Split(pattern, "/"), strings.Split(path, "/")
+	if len(ps) != len(segs) {
+		return false
+	}
+	for i, p := range ps {
+		if p != segs[i] && (!strings.HasPrefix(p, ":") || segs[i] == "") {
+			return false
+		}
+	}
+	return true
+}
+
+// bodyTooLarge отвечает 413 и закрывает соединение: непрочитанный остаток тела не даст обработать следующий запрос.
+func bodyTooLarge(c *fiber.Ctx) error {
+	c.Context().SetConnectionClose()
+	return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "request body too large"})
+}
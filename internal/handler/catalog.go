@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+// CatalogHandler — администрирование справочника: /catalog/brands и /catalog/brands/:id/models.
+type CatalogHandler struct {
+	uc usecase.CatalogUsecase
+}
+
+func NewCatalogHandler(uc usecase.CatalogUsecase) *CatalogHandler {
+	return &CatalogHandler{uc: uc}
+}
+
+func (h *CatalogHandler) CreateBrand(c *fiber.Ctx) error {
+	var req models.BrandRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.CreateBrand(ctx, req)
+	if err != nil {
+		return writeCatalogError(c, err, "brand not found")
+	}
+	c.Location("/api/v1/catalog/brands/" + resp.ID)
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *CatalogHandler) ListBrands(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.ListBrands(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *CatalogHandler) GetBrand(c *fiber.Ctx) error {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.GetBrand(ctx, id)
+	if err != nil {
+		return writeCatalogError(c, err, "brand not found")
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *CatalogHandler) ReplaceBrand(c *fiber.Ctx) error {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	var req models.BrandRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	req.ID = id
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.ReplaceBrand(ctx, req)
+	if err != nil {
+		return writeCatalogError(c, err, "brand not found")
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *CatalogHandler) SetBrandYears(c *fiber.Ctx) error {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	var req models.YearRangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	req.ID = id
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.SetBrandYears(ctx, req)
+	if err != nil {
+		return writeCatalogError(c, err, "brand not found")
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *CatalogHandler) DeleteBrand(c *fiber.Ctx) error {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	if err := h.uc.DeleteBrand(ctx, id); err != nil {
+		return writeCatalogError(c, err, "brand not found")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *CatalogHandler) CreateModel(c *fiber.Ctx) error {
+	brandID, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	var req models.CarModelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	req.BrandID = brandID
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.CreateModel(ctx, req)
+	if err != nil {
+		return writeCatalogError(c, err, "brand not found")
+	}
+	c.Location("/api/v1/catalog/brands/" + brandID + "/models/" + resp.ID)
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *CatalogHandler) ListModels(c *fiber.Ctx) error {
+	brandID, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.ListModels(ctx, brandID)
+	if err != nil {
+		return writeCatalogError(c, err, "brand not found")
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *CatalogHandler) GetModel(c *fiber.Ctx) error {
+	brandID, ok := uuidParam(c, "id")
+	modelID, modelOK := uuidParam(c, "modelId")
+	if !ok || !modelOK {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.GetModel(ctx, brandID, modelID)
+	if err != nil {
+		return writeCatalogError(c, err, "model not found")
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *CatalogHandler) ReplaceModel(c *fiber.Ctx) error {
+	brandID, ok := uuidParam(c, "id")
+	modelID, modelOK := uuidParam(c, "modelId")
+	if !ok || !modelOK {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	var req models.CarModelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	req.ID = modelID
+	req.BrandID = brandID
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.ReplaceModel(ctx, req)
+	if err != nil {
+		return writeCatalogError(c, err, "model not found")
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *CatalogHandler) SetModelYears(c *fiber.Ctx) error {
+	brandID, ok := uuidParam(c, "id")
+	modelID, modelOK := uuidParam(c, "modelId")
+	if !ok || !modelOK {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	var req models.YearRangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	req.ID = modelID
+	req.BrandID = brandID
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.SetModelYears(ctx, req)
+	if err != nil {
+		return writeCatalogError(c, err, "model not found")
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *CatalogHandler) DeleteModel(c *fiber.Ctx) error {
+	brandID, ok := uuidParam(c, "id")
+	modelID, modelOK := uuidParam(c, "modelId")
+	if !ok || !modelOK {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	if err := h.uc.DeleteModel(ctx, brandID, modelID); err != nil {
+		return writeCatalogError(c, err, "model not found")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// uuidParam возвращает параметр пути, если это UUID.
+func uuidParam(c *fiber.Ctx, name string) (string, bool) {
+	id := c.Params(name)
+	if _, err := uuid.Parse(id); err != nil {
+		return "", false
+	}
+	return id, true
+}
+
+func writeCatalogError(c *fiber.Ctx, err error, notFound string) error {
+	switch {
+	case errors.Is(err, apperr.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": notFound})
+	case errors.Is(err, apperr.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, apperr.ErrConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// etag строит сильный ETag из версии записи: "3".
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseETag разбирает одиночный ETag (сильный или W/"...") в версию.
+func parseETag(tag string) (int, bool) {
+	tag = strings.TrimSpace(tag)
+	tag = strings.TrimPrefix(tag, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	v, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	return v, true
+}
+
+// ifMatchVersions возвращает ожидаемые версии из If-Match (список через запятую).
+// nil означает «без условия» (заголовка нет или указан *),
+// ok=false — заголовок есть, но ни с одной версией совпасть не может.
+// If-Match сравнивается строго (RFC 9110), поэтому слабые W/"..." не совпадают никогда.
+func ifMatchVersions(c *fiber.Ctx) (versions []int, ok bool) {
+	h := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if h == "" || h == "*" {
+		return nil, true
+	}
+	for _, tag := range strings.Split(h, ",") {
+		if strings.HasPrefix(strings.TrimSpace(tag), "W/") {
+			continue
+		}
+		if v, ok := parseETag(tag); ok {
+			versions = append(versions, v)
+		}
+	}
+	return versions, len(versions) > 0
+}
+
+// noneMatch проверяет If-None-Match против текущей версии.
+func noneMatch(c *fiber.Ctx, version int) bool {
+	h := strings.TrimSpace(c.Get(fiber.HeaderIfNoneMatch))
+	if h == "" {
+		return false
+	}
+	if h == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(h, ",") {
+		if v, ok := parseETag(tag); ok && v == version {
+			return true
+		}
+	}
+	return false
+}
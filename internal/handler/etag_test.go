@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/handler"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+func TestMain(m *testing.M) {
+	models.Validate()
+	os.Exit(m.Run())
+}
+
+// carApp — GET и PATCH /cars/:id поверх хранилища в памяти с одной машиной версии 2.
+func carApp(t *testing.T) (*fiber.App, string) {
+	t.Helper()
+	uc := usecase.NewCarUsecase(repository.NewMemoryCarRepo())
+	car, err := uc.Create(context.Background(), models.CreateCarRequest{Brand: "Toyota", Model: "Camry", Year: 2020})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := uc.Update(context.Background(), models.UpdateCarRequest{ID: car.ID, Model: models.Some("Corolla")}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	h := handler.NewCarHandler(uc)
+	app := fiber.New()
+	app.Get("/cars/:id", h.Get)
+	app.Patch("/cars/:id", h.Update)
+	return app, car.ID
+}
+
+func TestGet_IfNoneMatch(t *testing.T) {
+	app, id := carApp(t)
+	for header, want := range map[string]int{
+		`"2"`:            fiber.StatusNotModified,
+		`W/"2"`:          fiber.StatusNotModified,
+		`"1", "2"`:       fiber.StatusNotModified,
+		`*`:              fiber.StatusNotModified,
+		`"1"`:            fiber.StatusOK,
+		`"1", W/"3"`:     fiber.StatusOK,
+		`"not-a-number"`: fiber.StatusOK,
+	} {
+		req := httptest.NewRequest(fiber.MethodGet, "/cars/"+id, nil)
+		req.Header.Set(fiber.HeaderIfNoneMatch, header)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		if resp.StatusCode != want || resp.Header.Get(fiber.HeaderETag) != `"2"` {
+			t.Fatalf("If-None-Match %s: %d, ETag %q; want %d", header, resp.StatusCode, resp.Header.Get(fiber.HeaderETag), want)
+		}
+	}
+}
+
+func TestUpdate_IfMatch(t *testing.T) {
+	for header, want := range map[string]int{
+		`"1"`:          fiber.StatusPreconditionFailed,
+		`W/"2"`:        fiber.StatusPreconditionFailed,
+		`W/"1", W/"2"`: fiber.StatusPreconditionFailed,
+		`garbage`:      fiber.StatusPreconditionFailed,
+		`"2"`:          fiber.StatusOK,
+		`"1", "2"`:     fiber.StatusOK,
+		`W/"2", "2"`:   fiber.StatusOK,
+		`*`:            fiber.StatusOK,
+	} {
+		app, id := carApp(t)
+		req := httptest.NewRequest(fiber.MethodPatch, "/cars/"+id, strings.NewReader(`{"model":"Prius"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set(fiber.HeaderIfMatch, header)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		if resp.StatusCode != want {
+			t.Fatalf("If-Match %s: %d, want %d", header, resp.StatusCode, want)
+		}
+		if want == fiber.StatusOK && resp.Header.Get(fiber.HeaderETag) != `"3"` {
+			t.Fatalf("If-Match %s: ETag %q, want \"3\"", header, resp.Header.Get(fiber.HeaderETag))
+		}
+	}
+}
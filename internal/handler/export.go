@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/export"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+const (
+	exportTimeout    = 30 * time.Minute
+	exportFlushEvery = 500 // строк между сбросами буфера клиенту
+)
+
+type ExportHandler struct {
+	uc   usecase.ExportUsecase
+	jobs usecase.JobUsecase
+}
+
+func NewExportHandler(uc usecase.ExportUsecase, jobs usecase.JobUsecase) *ExportHandler {
+	return &ExportHandler{uc: uc, jobs: jobs}
+}
+
+// Submit — POST /cars/export с теми же параметрами, что и GET: выгрузка ставится
+// в очередь фоновых задач, файл забирается по ссылке из задачи.
+func (h *ExportHandler) Submit(c *fiber.Ctx) error {
+	format := c.Query("format", export.FormatCSV)
+	if _, ok := export.ContentType(format); !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "format must be csv, ndjson or xlsx"})
+	}
+	filter, err := carFilterParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	job, err := h.jobs.SubmitExport(ctx, models.ExportJobParams{Format: format, Filter: filter})
+	if err != nil {
+		return writeJobError(c, err)
+	}
+	return accepted(c, job)
+}
+
+// Export — GET /cars/export?format=csv|ndjson|xlsx с фильтрами списка: строки пишутся
+// в ответ по мере чтения из хранилища.
+func (h *ExportHandler) Export(c *fiber.Ctx) error {
+	format := c.Query("format", export.FormatCSV)
+	contentType, ok := export.ContentType(format)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "format must be csv, ndjson or xlsx"})
+	}
+	filter, err := carFilterParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	filename := fmt.Sprintf("cars-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	uc := h.uc
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// обработчик уже вернулся: контекст запроса здесь не действует
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		defer cancel()
+
+		out, err := export.NewWriter(format, w)
+		if err != nil {
+			slog.Error("export", "format", format, "err", err)
+			return
+		}
+		n := 0
+		err = uc.Export(ctx, filter, func(car models.CarResponse) error {
+			if err := out.Write(car); err != nil {
+				return err
+			}
+			if n++; n%exportFlushEvery != 0 {
+				return nil
+			}
+			if err := out.Flush(); err != nil {
+				return err
+			}
+			return w.Flush() // ошибка — клиент отключился, курсор закрывается
+		})
+		if err != nil {
+			// заголовки уже отправлены, статус не поменять: файл обрывается без окончания формата
+			slog.Error("export aborted", "rows", n, "err", err)
+			return
+		}
+		if err := out.Close(); err != nil {
+			return
+		}
+		_ = w.Flush()
+	})
+	return nil
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -26,8 +28,13 @@ func (h *CarHandler) Create(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
+	prefill, err := boolQuery(c, "prefill_from_vin")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	req.PrefillFromVIN = prefill
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
 	defer cancel()
 
 	resp, err := h.uc.Create(ctx, req)
@@ -35,20 +42,37 @@ func (h *CarHandler) Create(c *fiber.Ctx) error {
 		switch {
 		case errors.Is(err, apperr.ErrInvalidInput):
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, apperr.ErrConflict):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
 		default:
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 		}
 	}
 
 	c.Location("/api/v1/cars/" + resp.ID)
+	c.Set(fiber.HeaderETag, etag(resp.Version))
 	return c.Status(fiber.StatusCreated).JSON(resp)
 }
 
 func (h *CarHandler) List(c *fiber.Ctx) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	asOf, ok, err := asOfParam(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	filter, err := carFilterParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
 	defer cancel()
 
-	resp, err := h.uc.List(ctx)
+	var resp []models.CarResponse
+	if ok {
+		resp, err = h.uc.ListAsOf(ctx, asOf, filter)
+	} else {
+		resp, err = h.uc.List(ctx, filter)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -64,10 +88,20 @@ func (h *CarHandler) Get(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	asOf, historical, err := asOfParam(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
 	defer cancel()
 
-	resp, err := h.uc.Get(ctx, id)
+	var resp models.CarResponse
+	if historical {
+		resp, err = h.uc.GetAsOf(ctx, id, asOf)
+	} else {
+		resp, err = h.uc.Get(ctx, id)
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, apperr.ErrNotFound):
@@ -76,9 +110,46 @@ func (h *CarHandler) Get(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 		}
 	}
+	if historical { // ETag описывает текущую версию, для исторического снимка он не выставляется
+		return c.Status(fiber.StatusOK).JSON(resp)
+	}
+	c.Set(fiber.HeaderETag, etag(resp.Version))
+	if noneMatch(c, resp.Version) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
+// GetByVIN ищет машину по VIN; регистр не важен.
+func (h *CarHandler) GetByVIN(c *fiber.Ctx) error {
+	vin := c.Params("vin")
+	if vin == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing vin"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.GetByVIN(ctx, vin)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperr.ErrInvalidInput):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, apperr.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "car not found"})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	c.Set(fiber.HeaderETag, etag(resp.Version))
+	if noneMatch(c, resp.Version) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// Update — PATCH: application/json и application/merge-patch+json обрабатываются
+// по RFC 7396, application/json-patch+json — по RFC 6902.
 func (h *CarHandler) Update(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
@@ -88,30 +159,201 @@ func (h *CarHandler) Update(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
 	}
 
-	var req models.UpdateCarRequest
+	ifMatch, ok := ifMatchVersions(c)
+	if !ok {
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{"error": "invalid If-Match"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	var (
+		resp models.CarResponse
+		err  error
+	)
+	switch mediaType(c) {
+	case mimeJSONPatch:
+		var ops []models.PatchOperation
+		if err := c.BodyParser(&ops); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		resp, err = h.uc.Patch(ctx, models.JSONPatchRequest{ID: id, Operations: ops, IfMatch: ifMatch})
+	case fiber.MIMEApplicationJSON, mimeMergePatch:
+		var req models.UpdateCarRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		req.ID = id
+		req.IfMatch = ifMatch
+		resp, err = h.uc.Update(ctx, req)
+	default:
+		c.Set(fiber.HeaderAcceptPatch, acceptPatch)
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": "unsupported patch format"})
+	}
+	if err != nil {
+		return writeUpdateError(c, err)
+	}
+	c.Set(fiber.HeaderETag, etag(resp.Version))
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// Replace — PUT: полная замена записи, все поля обязательны.
+func (h *CarHandler) Replace(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing id"})
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ifMatch, ok := ifMatchVersions(c)
+	if !ok {
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{"error": "invalid If-Match"})
+	}
+
+	var req models.ReplaceCarRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 	req.ID = id
+	req.IfMatch = ifMatch
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
 	defer cancel()
 
-	resp, err := h.uc.Update(ctx, req)
+	resp, err := h.uc.Replace(ctx, req)
+	if err != nil {
+		return writeUpdateError(c, err)
+	}
+	c.Set(fiber.HeaderETag, etag(resp.Version))
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// asOfParam разбирает ?as_of=<RFC3339>; ok=false, если параметр не передан.
+func asOfParam(c *fiber.Ctx) (at time.Time, ok bool, err error) {
+	raw := c.Query("as_of")
+	if raw == "" {
+		return time.Time{}, false, nil
+	}
+	at, err = time.Parse(time.RFC3339, raw)
 	if err != nil {
+		return time.Time{}, false, errors.New("invalid as_of, must be RFC 3339")
+	}
+	return at, true, nil
+}
+
+// carFilterParams разбирает фильтры списка: ?brand=&model=&year_from=&year_to=, атрибуты
+// (color, body_type, fuel_type, transmission, condition, status, currency) и границы
+// price_from, price_to, mileage_from, mileage_to.
+func carFilterParams(c *fiber.Ctx) (models.CarFilter, error) {
+	f := models.CarFilter{
+		Brand:        c.Query("brand"),
+		Model:        c.Query("model"),
+		Color:        c.Query("color"),
+		BodyType:     c.Query("body_type"),
+		FuelType:     c.Query("fuel_type"),
+		Transmission: c.Query("transmission"),
+		Condition:    c.Query("condition"),
+		Status:       c.Query("status"),
+		Currency:     c.Query("currency"),
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"year_from", &f.YearFrom}, {"year_to", &f.YearTo},
+		{"mileage_from", &f.MileageFrom}, {"mileage_to", &f.MileageTo},
+	} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			return models.CarFilter{}, fmt.Errorf("invalid %s, must be a positive integer", p.name)
+		}
+		*p.dst = v
+	}
+	for _, p := range []struct {
+		name string
+		dst  *models.Money
+	}{{"price_from", &f.PriceFrom}, {"price_to", &f.PriceTo}} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		v, err := models.ParseMoney(raw)
+		if err != nil || v <= 0 {
+			return models.CarFilter{}, fmt.Errorf("invalid %s, must be a positive number with at most 2 decimal places", p.name)
+		}
+		*p.dst = v
+	}
+	for _, r := range []struct {
+		name     string
+		from, to int64
+	}{
+		{"year", int64(f.YearFrom), int64(f.YearTo)},
+		{"price", int64(f.PriceFrom), int64(f.PriceTo)},
+		{"mileage", int64(f.MileageFrom), int64(f.MileageTo)},
+	} {
+		if r.from != 0 && r.to != 0 && r.from > r.to {
+			return models.CarFilter{}, fmt.Errorf("%s_from must be <= %s_to", r.name, r.name)
+		}
+	}
+	return f, nil
+}
+
+func writeUpdateError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, apperr.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "car not found"})
+	case errors.Is(err, apperr.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, apperr.ErrPreconditionFailed):
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{"error": "car was modified, refetch and retry"})
+	case errors.Is(err, apperr.ErrConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
+
+func (h *CarHandler) Delete(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing id"})
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	if err := h.uc.Delete(ctx, id); err != nil {
 		switch {
 		case errors.Is(err, apperr.ErrNotFound):
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "car not found"})
-		case errors.Is(err, apperr.ErrInvalidInput):
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		default:
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 		}
 	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *CarHandler) ListTrash(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.ListTrash(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
-func (h *CarHandler) Delete(c *fiber.Ctx) error {
+func (h *CarHandler) Restore(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing id"})
@@ -120,16 +362,20 @@ func (h *CarHandler) Delete(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
 	defer cancel()
 
-	if err := h.uc.Delete(ctx, id); err != nil {
+	resp, err := h.uc.Restore(ctx, id)
+	if err != nil {
 		switch {
 		case errors.Is(err, apperr.ErrNotFound):
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "car not found"})
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "car not found in trash"})
+		case errors.Is(err, apperr.ErrConflict):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
 		default:
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 		}
 	}
-	return c.SendStatus(fiber.StatusNoContent)
+	c.Set(fiber.HeaderETag, etag(resp.Version))
+	return c.Status(fiber.StatusOK).JSON(resp)
 }
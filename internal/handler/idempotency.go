@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/reqctx"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLen     = 255
+	idempotencyStoreTimeout  = 5 * time.Second
+)
+
+// replayedHeaders — заголовки ответа, которые сохраняются вместе с телом.
+var replayedHeaders = []string{fiber.HeaderContentType, fiber.HeaderLocation, fiber.HeaderETag}
+
+// Idempotency повторяет сохранённый ответ на запрос с тем же Idempotency-Key.
+// Ключ действует в пределах автора (X-Actor) и маршрута. Ответы 5xx и ошибки
+// не сохраняются: ключ освобождается, и клиент может повторить запрос.
+func Idempotency(uc usecase.IdempotencyUsecase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Idempotency-Key is too long"})
+		}
+		req := models.IdempotentRequest{
+			Scope: reqctx.Actor(c.UserContext()) + " " + c.Method() + " " + c.Route().Path,
+			Key:   strings.Clone(key),
+			Query: string(c.Request().URI().QueryString()),
+			Body:  c.Body(),
+		}
+
+		ctx, cancel := context.WithTimeout(c.UserContext(), idempotencyStoreTimeout)
+		stored, err := uc.Begin(ctx, req)
+		cancel()
+		if err != nil {
+			switch {
+			case errors.Is(err, apperr.ErrMismatch):
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+			case errors.Is(err, apperr.ErrConflict):
+				c.Set(fiber.HeaderRetryAfter, "1")
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+			default:
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+			}
+		}
+		if stored != nil {
+			for name, value := range stored.Headers {
+				c.Set(name, value)
+			}
+			c.Set(HeaderIdempotentReplayed, "true")
+			return c.Status(stored.Status).Send(stored.Body)
+		}
+
+		// ответ сохраняется, даже если клиент уже отключился
+		req.Body = nil
+		err = c.Next()
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(c.UserContext()), idempotencyStoreTimeout)
+		defer cancel()
+		status := c.Response().StatusCode()
+		if err != nil || status >= fiber.StatusInternalServerError {
+			if rerr := uc.Release(ctx, req); rerr != nil {
+				slog.Error("idempotency key release failed", "key", req.Key, "err", rerr)
+			}
+			return err
+		}
+		resp := models.StoredResponse{
+			Status:  status,
+			Headers: map[string]string{},
+			Body:    slices.Clone(c.Response().Body()),
+		}
+		for _, name := range replayedHeaders {
+			if v := c.GetRespHeader(name); v != "" {
+				resp.Headers[name] = strings.Clone(v)
+			}
+		}
+		// если ответ не сохранился, ключ считается брошенным через минуту и освобождается
+		if cerr := uc.Complete(ctx, req, resp); cerr != nil {
+			slog.Error("idempotency key complete failed", "key", req.Key, "err", cerr)
+		}
+		return nil
+	}
+}
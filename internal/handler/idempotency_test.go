@@ -0,0 +1,141 @@
+package handler_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/handler"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+// idempotentApp — POST /cars за middleware Idempotency с хранилищем в памяти; create отвечает на запрос.
+func idempotentApp(create fiber.Handler) *fiber.App {
+	uc := usecase.NewIdempotencyUsecase(repository.NewMemoryCarRepo(), time.Hour)
+	app := fiber.New()
+	app.Post("/cars", handler.Idempotency(uc), create)
+	return app
+}
+
+func newPost(key, body string) *http.Request {
+	req := httptest.NewRequest(fiber.MethodPost, "/cars", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(handler.HeaderIdempotencyKey, key)
+	return req
+}
+
+func postCar(t *testing.T, app *fiber.App, key, body string) *http.Response {
+	t.Helper()
+	resp, err := app.Test(newPost(key, body), -1)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return string(b)
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	var calls atomic.Int32
+	app := idempotentApp(func(c *fiber.Ctx) error {
+		calls.Add(1)
+		c.Set(fiber.HeaderLocation, "/api/v1/cars/42")
+		c.Set(fiber.HeaderETag, `"1"`)
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": "42"})
+	})
+
+	first := postCar(t, app, "k1", `{"brand":"BMW"}`)
+	firstBody := readBody(t, first)
+	if first.StatusCode != fiber.StatusCreated || first.Header.Get(handler.HeaderIdempotentReplayed) != "" {
+		t.Fatalf("first response: %d %v", first.StatusCode, first.Header)
+	}
+
+	again := postCar(t, app, "k1", `{"brand":"BMW"}`)
+	if again.StatusCode != fiber.StatusCreated || again.Header.Get(handler.HeaderIdempotentReplayed) != "true" {
+		t.Fatalf("replay: %d %v", again.StatusCode, again.Header)
+	}
+	if again.Header.Get(fiber.HeaderLocation) != "/api/v1/cars/42" || again.Header.Get(fiber.HeaderETag) != `"1"` {
+		t.Fatalf("replay must keep Location and ETag: %v", again.Header)
+	}
+	if body := readBody(t, again); body != firstBody {
+		t.Fatalf("replay body = %s, want %s", body, firstBody)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", calls.Load())
+	}
+}
+
+func TestIdempotency_InFlightConflict(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	app := idempotentApp(func(c *fiber.Ctx) error {
+		close(entered)
+		<-release
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	done := make(chan int)
+	go func() {
+		resp, err := app.Test(newPost("k1", `{}`), -1)
+		if err != nil {
+			done <- 0
+			return
+		}
+		done <- resp.StatusCode
+	}()
+	<-entered
+
+	resp := postCar(t, app, "k1", `{}`)
+	if resp.StatusCode != fiber.StatusConflict || resp.Header.Get(fiber.HeaderRetryAfter) != "1" {
+		t.Fatalf("in-flight duplicate: %d %v", resp.StatusCode, resp.Header)
+	}
+	close(release)
+	if status := <-done; status != fiber.StatusCreated {
+		t.Fatalf("first request: %d", status)
+	}
+}
+
+func TestIdempotency_BodyMismatch(t *testing.T) {
+	app := idempotentApp(func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) })
+
+	if resp := postCar(t, app, "k1", `{"brand":"BMW"}`); resp.StatusCode != fiber.StatusCreated {
+		t.Fatalf("first request: %d", resp.StatusCode)
+	}
+	if resp := postCar(t, app, "k1", `{"brand":"Audi"}`); resp.StatusCode != fiber.StatusUnprocessableEntity {
+		t.Fatalf("other body with the same key: %d, want 422", resp.StatusCode)
+	}
+}
+
+func TestIdempotency_ReleasesKeyOnServerError(t *testing.T) {
+	var calls atomic.Int32
+	app := idempotentApp(func(c *fiber.Ctx) error {
+		if calls.Add(1) == 1 {
+			return c.SendStatus(fiber.StatusServiceUnavailable)
+		}
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	if resp := postCar(t, app, "k1", `{}`); resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Fatalf("first request: %d", resp.StatusCode)
+	}
+	resp := postCar(t, app, "k1", `{}`)
+	if resp.StatusCode != fiber.StatusCreated || resp.Header.Get(handler.HeaderIdempotentReplayed) != "" {
+		t.Fatalf("retry after 5xx must run again: %d %v", resp.StatusCode, resp.Header)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("handler called %d times, want 2", calls.Load())
+	}
+}
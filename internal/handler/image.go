@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+const (
+	// imageFormField — поле multipart-формы с файлом фотографии.
+	imageFormField = "file"
+	// multipartOverhead — запас на заголовки частей и остальные поля формы сверх размера файла.
+	multipartOverhead = 64 << 10
+
+	imageUploadTimeout   = time.Minute
+	imageDownloadTimeout = time.Minute
+)
+
+type ImageHandler struct {
+	uc       usecase.ImageUsecase
+	maxBytes int64
+}
+
+// NewImageHandler — maxBytes ограничивает размер файла фотографии.
+func NewImageHandler(uc usecase.ImageUsecase, maxBytes int64) *ImageHandler {
+	return &ImageHandler{uc: uc, maxBytes: maxBytes}
+}
+
+// Upload — POST /cars/:id/images, multipart/form-data с файлом в поле file. Тип файла
+// определяется по содержимому; тело читается потоком и не больше лимита.
+func (h *ImageHandler) Upload(c *fiber.Ctx) error {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+	_, params, err := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	if mediaType(c) != fiber.MIMEMultipartForm || err != nil || params["boundary"] == "" {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error": "upload the image as multipart/form-data with a file field",
+		})
+	}
+	maxBody := h.maxBytes + multipartOverhead
+	if size := c.Request().Header.ContentLength(); int64(size) > maxBody {
+		return bodyTooLarge(c)
+	}
+
+	var body io.Reader = c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+	data, err := h.readFile(multipart.NewReader(&uploadBody{r: body, max: maxBody}, params["boundary"]))
+	if err != nil {
+		return writeImageError(c, err)
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), imageUploadTimeout)
+	defer cancel()
+
+	img, err := h.uc.Upload(ctx, id, data)
+	if err != nil {
+		return writeImageError(c, err)
+	}
+	c.Location(img.URL)
+	return c.Status(fiber.StatusCreated).JSON(img)
+}
+
+// readFile возвращает содержимое поля file, пропуская остальные поля формы.
+func (h *ImageHandler) readFile(form *multipart.Reader) ([]byte, error) {
+	for {
+		part, err := form.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: multipart form has no %s field", apperr.ErrInvalidInput, imageFormField)
+		}
+		if err != nil {
+			return nil, uploadError(err)
+		}
+		if part.FormName() != imageFormField {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(part, h.maxBytes+1))
+		if err != nil {
+			return nil, uploadError(err)
+		}
+		if int64(len(data)) > h.maxBytes {
+			return nil, fmt.Errorf("%w: image is limited to %d bytes", apperr.ErrTooLarge, h.maxBytes)
+		}
+		return data, nil
+	}
+}
+
+// uploadError оставляет ErrTooLarge от uploadBody, остальные ошибки разбора — неверная форма.
+func uploadError(err error) error {
+	if errors.Is(err, apperr.ErrTooLarge) {
+		return err
+	}
+	return fmt.Errorf("%w: malformed multipart form: %v", apperr.ErrInvalidInput, err)
+}
+
+// List — GET /cars/:id/images, фотографии по порядку галереи.
+func (h *ImageHandler) List(c *fiber.Ctx) error {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	images, err := h.uc.List(ctx, id)
+	if err != nil {
+		return writeImageError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(images)
+}
+
+// Get — GET /cars/:id/images/:imageId?size=: файл оригинала или миниатюры. Файлы фотографии
+// не меняются, поэтому их можно кэшировать без срока.
+func (h *ImageHandler) Get(c *fiber.Ctx) error {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+	imageID, ok := uuidParam(c, "imageId")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid image id format, must be UUID"})
+	}
+
+	// контекст живёт, пока fasthttp не дочитает и не закроет файл
+	ctx, cancel := context.WithTimeout(c.UserContext(), imageDownloadTimeout)
+	file, err := h.uc.Open(ctx, id, imageID, c.Query("size"))
+	if err != nil {
+		cancel()
+		return writeImageError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=31536000, immutable")
+	return c.Status(fiber.StatusOK).SendStream(&cancelOnClose{ReadCloser: file.Body, cancel: cancel})
+}
+
+// Delete — DELETE /cars/:id/images/:imageId.
+func (h *ImageHandler) Delete(c *fiber.Ctx) error {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+	imageID, ok := uuidParam(c, "imageId")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid image id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	if err := h.uc.Delete(ctx, id, imageID); err != nil {
+		return writeImageError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Reorder — PUT /cars/:id/images/order с {"ids": [...]}: все фотографии в новом порядке.
+func (h *ImageHandler) Reorder(c *fiber.Ctx) error {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+	var req models.ImageOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid JSON"})
+	}
+	req.CarID = id
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	images, err := h.uc.Reorder(ctx, req)
+	if err != nil {
+		return writeImageError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(images)
+}
+
+// cancelOnClose отменяет контекст чтения файла, когда его закрывают.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnClose) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
+
+func writeImageError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, apperr.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, apperr.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, apperr.ErrConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, apperr.ErrUnsupportedMedia):
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, apperr.ErrTooLarge):
+		c.Context().SetConnectionClose()
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+const (
+	mimeCSV    = "text/csv"
+	mimeNDJSON = "application/x-ndjson"
+
+	importTimeout  = 10 * time.Minute
+	reportPageSize = 500
+)
+
+var importMediaTypes = map[string]string{
+	mimeCSV:                 models.ImportFormatCSV,
+	mimeNDJSON:              models.ImportFormatNDJSON,
+	"application/ndjson":    models.ImportFormatNDJSON,
+	"application/jsonlines": models.ImportFormatNDJSON,
+}
+
+type ImportHandler struct {
+	uc       usecase.ImportUsecase
+	jobs     usecase.JobUsecase
+	maxBytes int64
+}
+
+// NewImportHandler — maxBytes ограничивает размер загружаемого файла, 0 — без ограничения.
+func NewImportHandler(uc usecase.ImportUsecase, jobs usecase.JobUsecase, maxBytes int64) *ImportHandler {
+	return &ImportHandler{uc: uc, jobs: jobs, maxBytes: maxBytes}
+}
+
+// Import — POST /cars/import?format=csv|ndjson&dry_run=true&async=true. Формат берётся
+// из format или Content-Type; тело читается потоком, не целиком. С async=true файл
+// сохраняется, импорт ставится в очередь фоновых задач и ответ — 202 со ссылкой на задачу.
+func (h *ImportHandler) Import(c *fiber.Ctx) error {
+	format := c.Query("format")
+	if format == "" {
+		format = importMediaTypes[mediaType(c)]
+	}
+	if format != models.ImportFormatCSV && format != models.ImportFormatNDJSON {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error": "unsupported import format, use text/csv or application/x-ndjson",
+		})
+	}
+	dryRun, err := boolQuery(c, "dry_run")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	async, err := boolQuery(c, "async")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if size := c.Request().Header.ContentLength(); h.maxBytes > 0 && int64(size) > h.maxBytes {
+		return bodyTooLarge(c)
+	}
+
+	var body io.Reader = c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+	body = &uploadBody{r: body, max: h.maxBytes}
+	req := models.ImportRequest{Format: format, DryRun: dryRun}
+
+	if async {
+		ctx, cancel := context.WithTimeout(c.UserContext(), importTimeout)
+		defer cancel()
+
+		job, err := h.jobs.SubmitImport(ctx, req, body)
+		if err != nil {
+			return writeJobError(c, err)
+		}
+		return accepted(c, job)
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), importTimeout)
+	defer cancel()
+
+	imp, err := h.uc.Import(ctx, req, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperr.ErrInvalidInput):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, apperr.ErrTooLarge):
+			c.Context().SetConnectionClose()
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": err.Error()})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	c.Location("/api/v1/cars/import/" + imp.ID)
+	return c.Status(fiber.StatusCreated).JSON(imp)
+}
+
+// Get — GET /cars/import/:id: итоги импорта.
+func (h *ImportHandler) Get(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	imp, err := h.uc.Get(ctx, id)
+	if err != nil {
+		return writeImportError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(imp)
+}
+
+// Report — GET /cars/import/:id/report?format=csv|ndjson: отчёт по строкам файлом,
+// выгружается постранично, не собираясь в памяти.
+func (h *ImportHandler) Report(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+	format := c.Query("format", models.ImportFormatCSV)
+	if format != models.ImportFormatCSV && format != models.ImportFormatNDJSON {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "format must be csv or ndjson"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+	if _, err := h.uc.Get(ctx, id); err != nil {
+		return writeImportError(c, err)
+	}
+
+	contentType := mimeCSV
+	if format == models.ImportFormatNDJSON {
+		contentType = mimeNDJSON
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="car-import-%s.%s"`, id, format))
+
+	uc := h.uc
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// обработчик уже вернулся, поэтому у каждой страницы свой контекст
+		write := writeReportNDJSON
+		if format == models.ImportFormatCSV {
+			cw := csv.NewWriter(w)
+			_ = cw.Write([]string{"line", "status", "car_id", "brand", "model", "year", "reason"})
+			write = func(w *bufio.Writer, rows []models.ImportRow) error {
+				for _, row := range rows {
+					_ = cw.Write([]string{strconv.Itoa(row.Line), row.Status, row.CarID, row.Brand, row.Model,
+						strconv.Itoa(row.Year), row.Reason})
+				}
+				cw.Flush()
+				return cw.Error()
+			}
+		}
+		after := 0
+		for {
+			pctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			rows, err := uc.Rows(pctx, id, after, reportPageSize)
+			cancel()
+			if err != nil {
+				slog.Error("import report", "id", id, "err", err)
+				return
+			}
+			if len(rows) == 0 {
+				return
+			}
+			if err := write(w, rows); err != nil {
+				return
+			}
+			if err := w.Flush(); err != nil { // клиент отключился
+				return
+			}
+			after = rows[len(rows)-1].Line
+		}
+	})
+	return nil
+}
+
+// boolQuery разбирает необязательный булев параметр запроса.
+func boolQuery(c *fiber.Ctx, name string) (bool, error) {
+	v := c.Query(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", name)
+	}
+	return b, nil
+}
+
+func writeReportNDJSON(w *bufio.Writer, rows []models.ImportRow) error {
+	for _, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeImportError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, apperr.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "import not found"})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
+
+// uploadBody ограничивает размер загрузки (max > 0) и запоминает первую ошибку чтения:
+// chunked-поток fasthttp при повторном чтении после io.EOF ждёт следующий чанк,
+// а bufio внутри парсеров читает повторно.
+type uploadBody struct {
+	r    io.Reader
+	max  int64
+	read int64
+	err  error
+}
+
+func (u *uploadBody) Read(p []byte) (int, error) {
+	if u.err != nil {
+		return 0, u.err
+	}
+	if u.max > 0 && u.read >= u.max {
+		// тело могло закончиться ровно на лимите — проверяем, есть ли ещё байт
+		var b [1]byte
+		if n, err := u.r.Read(b[:]); n == 0 {
+			u.err = err
+			return 0, err
+		}
+		u.err = fmt.Errorf("%w: upload is limited to %d bytes", apperr.ErrTooLarge, u.max)
+		return 0, u.err
+	}
+	if left := u.max - u.read; u.max > 0 && int64(len(p)) > left {
+		p = p[:left]
+	}
+	n, err := u.r.Read(p)
+	u.read += int64(n)
+	u.err = err
+	return n, err
+}
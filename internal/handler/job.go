@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+type JobHandler struct {
+	uc usecase.JobUsecase
+}
+
+func NewJobHandler(uc usecase.JobUsecase) *JobHandler {
+	return &JobHandler{uc: uc}
+}
+
+// Get — GET /jobs/:id: статус, прогресс и ссылка на результат.
+func (h *JobHandler) Get(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.Get(ctx, id)
+	if err != nil {
+		return writeJobError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(withResultURL(resp))
+}
+
+// Cancel — POST /jobs/:id/cancel. Задача из очереди отменяется сразу (200),
+// выполняемая — когда воркер это заметит (202).
+func (h *JobHandler) Cancel(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.Cancel(ctx, id)
+	if err != nil {
+		return writeJobError(c, err)
+	}
+	status := fiber.StatusOK
+	if resp.Status != models.JobCanceled {
+		status = fiber.StatusAccepted
+	}
+	return c.Status(status).JSON(withResultURL(resp))
+}
+
+// Result — GET /jobs/:id/result: файл завершённой выгрузки.
+func (h *JobHandler) Result(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	file, err := h.uc.Result(ctx, id)
+	if err != nil {
+		return writeJobError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, file.Name))
+	// fasthttp закроет файл после отправки
+	return c.Status(fiber.StatusOK).SendStream(file.Body, int(file.Size))
+}
+
+// accepted отвечает 202 со ссылкой на созданную задачу.
+func accepted(c *fiber.Ctx, job models.JobResponse) error {
+	c.Location("/api/v1/jobs/" + job.ID)
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// withResultURL добавляет ссылку на результат успешно завершённой задачи.
+func withResultURL(resp models.JobResponse) models.JobResponse {
+	if resp.Status != models.JobSucceeded {
+		return resp
+	}
+	switch resp.Kind {
+	case models.JobKindImport:
+		resp.ResultURL = "/api/v1/cars/import/" + resp.ID
+	case models.JobKindExport:
+		resp.ResultURL = "/api/v1/jobs/" + resp.ID + "/result"
+	}
+	return resp
+}
+
+func writeJobError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, apperr.ErrNotFound):
+		// у обёрнутой ошибки своё пояснение: например, у задачи нет файла результата
+		msg := "job not found"
+		if err != apperr.ErrNotFound {
+			msg = err.Error()
+		}
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": msg})
+	case errors.Is(err, apperr.ErrConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, apperr.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, apperr.ErrTooLarge):
+		c.Context().SetConnectionClose()
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
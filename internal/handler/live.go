@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/stream"
+)
+
+const (
+	liveReadLimit     = 4096 // команды клиента маленькие
+	liveMaxCarIDs     = 100  // car_ids в одной подписке
+	liveCommandBuffer = 16
+)
+
+var liveEventTypes = []string{
+	models.EventCarCreated,
+	models.EventCarUpdated,
+	models.EventCarDeleted,
+	models.EventCarRestored,
+}
+
+// LiveConfig — ограничения WebSocket-соединения.
+type LiveConfig struct {
+	MaxSubscriptions int
+	PingInterval     time.Duration
+	WriteTimeout     time.Duration
+}
+
+// LiveHandler обслуживает /ws: клиент подписывается на машины по ID или фильтрам
+// и получает события из того же брокера, что и SSE.
+type LiveHandler struct {
+	broker *stream.Broker
+	cfg    LiveConfig
+}
+
+func NewLiveHandler(broker *stream.Broker, cfg LiveConfig) *LiveHandler {
+	if cfg.MaxSubscriptions <= 0 {
+		cfg.MaxSubscriptions = 20
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 30 * time.Second
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	return &LiveHandler{broker: broker, cfg: cfg}
+}
+
+// liveCommand — сообщение клиента:
+// {"action":"subscribe","id":"s1","car_ids":[...],"brand":"BMW","types":["CarUpdated"]}
+// или {"action":"unsubscribe","id":"s1"}.
+type liveCommand struct {
+	Action string `json:"action"`
+	ID     string `json:"id"`
+	stream.Criteria
+
+	invalid bool // сообщение не разобралось как JSON
+}
+
+// liveMessage — сообщение сервера; Type: subscribed, unsubscribed, event или error.
+type liveMessage struct {
+	Type          string        `json:"type"`
+	ID            string        `json:"id,omitempty"`
+	Subscriptions []string      `json:"subscriptions,omitempty"`
+	Event         *stream.Event `json:"event,omitempty"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// Upgrade пропускает дальше только запросы на WebSocket.
+func (h *LiveHandler) Upgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{"error": "websocket upgrade required"})
+	}
+	return c.Next()
+}
+
+// Serve — обработчик GET /ws.
+func (h *LiveHandler) Serve() fiber.Handler {
+	return websocket.New(h.serve)
+}
+
+func (h *LiveHandler) serve(conn *websocket.Conn) {
+	sub := h.broker.Subscribe(0, false, nil)
+	defer sub.Close()
+
+	pongWait := 2 * h.cfg.PingInterval
+	conn.SetReadLimit(liveReadLimit)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	// Читатель только разбирает команды; состоянием подписок и записью в conn владеет эта горутина.
+	commands := make(chan liveCommand, liveCommandBuffer)
+	readErr := make(chan error, 1)
+	quit, readerDone := make(chan struct{}), make(chan struct{})
+	defer func() {
+		// после возврата conn уходит обратно в пул: будим читателя и дожидаемся его
+		close(quit)
+		_ = conn.SetReadDeadline(time.Now())
+		<-readerDone
+	}()
+	go func() {
+		defer close(readerDone)
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			var cmd liveCommand
+			if err := json.Unmarshal(data, &cmd); err != nil {
+				cmd = liveCommand{invalid: true}
+			}
+			select {
+			case commands <- cmd:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	subs := map[string]stream.Criteria{}
+	ticker := time.NewTicker(h.cfg.PingInterval)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case cmd := <-commands:
+			err = h.write(conn, h.apply(subs, cmd))
+		case e, ok := <-sub.C:
+			if !ok {
+				// не успевает читать (или сервис останавливается): клиент переподключится
+				h.close(conn, websocket.CloseTryAgainLater, "slow consumer")
+				return
+			}
+			if matched := matchSubscriptions(subs, e); len(matched) > 0 {
+				err = h.write(conn, liveMessage{Type: "event", Subscriptions: matched, Event: &e})
+			}
+		case <-ticker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.cfg.WriteTimeout))
+		case <-readErr:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// apply выполняет команду клиента и возвращает ответ на неё.
+func (h *LiveHandler) apply(subs map[string]stream.Criteria, cmd liveCommand) liveMessage {
+	fail := func(format string, args ...any) liveMessage {
+		return liveMessage{Type: "error", ID: cmd.ID, Error: fmt.Sprintf(format, args...)}
+	}
+	if cmd.invalid {
+		return fail("invalid JSON")
+	}
+	switch cmd.Action {
+	case "subscribe":
+		if cmd.ID == "" {
+			return fail("subscription id is required")
+		}
+		if _, ok := subs[cmd.ID]; ok {
+			return fail("subscription %q already exists", cmd.ID)
+		}
+		if len(subs) >= h.cfg.MaxSubscriptions {
+			return fail("too many subscriptions, max %d", h.cfg.MaxSubscriptions)
+		}
+		if len(cmd.CarIDs) > liveMaxCarIDs {
+			return fail("too many car_ids, max %d", liveMaxCarIDs)
+		}
+		for _, id := range cmd.CarIDs {
+			if _, err := uuid.Parse(id); err != nil {
+				return fail("invalid car id %q, must be UUID", id)
+			}
+		}
+		for _, t := range cmd.Types {
+			if !slices.Contains(liveEventTypes, t) {
+				return fail("unknown event type %q", t)
+			}
+		}
+		subs[cmd.ID] = cmd.Criteria
+		return liveMessage{Type: "subscribed", ID: cmd.ID}
+	case "unsubscribe":
+		if _, ok := subs[cmd.ID]; !ok {
+			return fail("subscription %q not found", cmd.ID)
+		}
+		delete(subs, cmd.ID)
+		return liveMessage{Type: "unsubscribed", ID: cmd.ID}
+	default:
+		return fail("unknown action %q", cmd.Action)
+	}
+}
+
+func (h *LiveHandler) write(conn *websocket.Conn, msg liveMessage) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(h.cfg.WriteTimeout)); err != nil {
+		return err
+	}
+	return conn.WriteJSON(msg)
+}
+
+func (h *LiveHandler) close(conn *websocket.Conn, code int, text string) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text),
+		time.Now().Add(h.cfg.WriteTimeout))
+}
+
+func matchSubscriptions(subs map[string]stream.Criteria, e stream.Event) []string {
+	var matched []string
+	for id, c := range subs {
+		if c.Match(e) {
+			matched = append(matched, id)
+		}
+	}
+	slices.Sort(matched)
+	return matched
+}
@@ -0,0 +1,262 @@
+package handler_test
+
+import (
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp/fasthttputil"
+
+	"github.com/pavel97go/service-cars/internal/handler"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/stream"
+)
+
+const liveTimeout = 5 * time.Second
+
+// liveMessage — ответ сервера в том виде, в каком его видит клиент.
+type liveMessage struct {
+	Type          string        `json:"type"`
+	ID            string        `json:"id"`
+	Subscriptions []string      `json:"subscriptions"`
+	Event         *stream.Event `json:"event"`
+	Error         string        `json:"error"`
+}
+
+// dialLive поднимает /ws поверх брокера на соединениях в памяти и подключает клиента.
+func dialLive(t *testing.T, broker *stream.Broker, cfg handler.LiveConfig) *websocket.Conn {
+	t.Helper()
+	h := handler.NewLiveHandler(broker, cfg)
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use("/ws", h.Upgrade)
+	app.Get("/ws", h.Serve())
+
+	ln := fasthttputil.NewInmemoryListener()
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	dialer := websocket.Dialer{
+		NetDial:          func(string, string) (net.Conn, error) { return ln.Dial() },
+		HandshakeTimeout: liveTimeout,
+	}
+	conn, _, err := dialer.Dial("ws://test/ws", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// send отправляет команду и возвращает ответ на неё.
+func send(t *testing.T, conn *websocket.Conn, cmd string) liveMessage {
+	t.Helper()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(cmd)); err != nil {
+		t.Fatalf("write %s: %v", cmd, err)
+	}
+	return receive(t, conn)
+}
+
+func receive(t *testing.T, conn *websocket.Conn) liveMessage {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(liveTimeout))
+	var msg liveMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return msg
+}
+
+func TestLive_RequiresUpgrade(t *testing.T) {
+	h := handler.NewLiveHandler(stream.NewBroker(16, 16), handler.LiveConfig{})
+	app := fiber.New()
+	app.Use("/ws", h.Upgrade)
+	app.Get("/ws", h.Serve())
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/ws", nil), -1)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusUpgradeRequired {
+		t.Fatalf("plain GET /ws: %d, want 426", resp.StatusCode)
+	}
+}
+
+func TestLive_CommandValidation(t *testing.T) {
+	conn := dialLive(t, stream.NewBroker(16, 16), handler.LiveConfig{})
+	carID := "7f0c5a9e-3f4b-4d5e-9a1b-2c3d4e5f6a7b"
+
+	for _, tc := range []struct {
+		cmd, wantType, wantErr string
+	}{
+		{`not json`, "error", "invalid JSON"},
+		{`{"action":"subscribe"}`, "error", "subscription id is required"},
+		{`{"action":"subscribe","id":"s1","car_ids":["42"]}`, "error", `invalid car id "42", must be UUID`},
+		{`{"action":"subscribe","id":"s1","types":["CarSold"]}`, "error", `unknown event type "CarSold"`},
+		{`{"action":"watch","id":"s1"}`, "error", `unknown action "watch"`},
+		{`{"action":"unsubscribe","id":"s1"}`, "error", `subscription "s1" not found`},
+		{`{"action":"subscribe","id":"s1","car_ids":["` + carID + `"],"types":["CarUpdated"]}`, "subscribed", ""},
+		{`{"action":"subscribe","id":"s1"}`, "error", `subscription "s1" already exists`},
+		{`{"action":"unsubscribe","id":"s1"}`, "unsubscribed", ""},
+		{`{"action":"unsubscribe","id":"s1"}`, "error", `subscription "s1" not found`},
+	} {
+		msg := send(t, conn, tc.cmd)
+		if msg.Type != tc.wantType || msg.Error != tc.wantErr {
+			t.Fatalf("%s: got %s %q, want %s %q", tc.cmd, msg.Type, msg.Error, tc.wantType, tc.wantErr)
+		}
+	}
+
+	ids := make([]string, 101)
+	for i := range ids {
+		ids[i] = `"` + carID + `"`
+	}
+	msg := send(t, conn, `{"action":"subscribe","id":"s2","car_ids":[`+strings.Join(ids, ",")+`]}`)
+	if msg.Type != "error" || msg.Error != "too many car_ids, max 100" {
+		t.Fatalf("101 car_ids: %s %q", msg.Type, msg.Error)
+	}
+}
+
+func TestLive_MaxSubscriptions(t *testing.T) {
+	conn := dialLive(t, stream.NewBroker(16, 16), handler.LiveConfig{MaxSubscriptions: 2})
+
+	for _, id := range []string{"s1", "s2"} {
+		if msg := send(t, conn, `{"action":"subscribe","id":"`+id+`"}`); msg.Type != "subscribed" {
+			t.Fatalf("subscribe %s: %s %q", id, msg.Type, msg.Error)
+		}
+	}
+	msg := send(t, conn, `{"action":"subscribe","id":"s3"}`)
+	if msg.Type != "error" || msg.Error != "too many subscriptions, max 2" || msg.ID != "s3" {
+		t.Fatalf("third subscription: %+v", msg)
+	}
+
+	// отписка освобождает место
+	if msg := send(t, conn, `{"action":"unsubscribe","id":"s1"}`); msg.Type != "unsubscribed" {
+		t.Fatalf("unsubscribe: %s %q", msg.Type, msg.Error)
+	}
+	if msg := send(t, conn, `{"action":"subscribe","id":"s3"}`); msg.Type != "subscribed" {
+		t.Fatalf("subscribe after unsubscribe: %s %q", msg.Type, msg.Error)
+	}
+}
+
+func TestLive_DeliversMatchingEvents(t *testing.T) {
+	broker := stream.NewBroker(16, 16)
+	conn := dialLive(t, broker, handler.LiveConfig{})
+
+	send(t, conn, `{"action":"subscribe","id":"bmw","brand":"bmw"}`)
+	send(t, conn, `{"action":"subscribe","id":"all"}`)
+	send(t, conn, `{"action":"subscribe","id":"deleted","types":["CarDeleted"]}`)
+
+	broker.Notify(models.CarEvent{Type: models.EventCarCreated, Car: models.CarResponse{ID: "1", Brand: "Kia"}})
+	broker.Notify(models.CarEvent{Type: models.EventCarUpdated, Car: models.CarResponse{ID: "2", Brand: "BMW"}})
+
+	for _, want := range []struct {
+		carID string
+		subs  string
+	}{
+		{"1", "all"},
+		{"2", "all,bmw"},
+	} {
+		msg := receive(t, conn)
+		if msg.Type != "event" || msg.Event == nil || msg.Event.Car.ID != want.carID {
+			t.Fatalf("want event for car %s, got %+v", want.carID, msg)
+		}
+		if got := strings.Join(msg.Subscriptions, ","); got != want.subs {
+			t.Fatalf("car %s: subscriptions %s, want %s", want.carID, got, want.subs)
+		}
+	}
+}
+
+func TestLive_PongKeepsConnection(t *testing.T) {
+	conn := dialLive(t, stream.NewBroker(16, 16), handler.LiveConfig{PingInterval: 20 * time.Millisecond})
+
+	pings := make(chan struct{}, 100)
+	conn.SetPingHandler(func(data string) error {
+		pings <- struct{}{}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(liveTimeout))
+	})
+	// обработчики контрольных кадров вызываются только из чтения
+	done := make(chan error, 1)
+	go func() {
+		_ = conn.SetReadDeadline(time.Now().Add(liveTimeout))
+		_, _, err := conn.ReadMessage()
+		done <- err
+	}()
+
+	// пять интервалов ping — больше, чем срок ожидания pong в 2 интервала
+	for i := 0; i < 5; i++ {
+		select {
+		case <-pings:
+		case err := <-done:
+			t.Fatalf("connection closed while answering pings: %v", err)
+		case <-time.After(liveTimeout):
+			t.Fatal("no ping from server")
+		}
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"action":"subscribe","id":"s1"}`)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("connection must stay open: %v", err)
+	}
+}
+
+func TestLive_ClosesWithoutPong(t *testing.T) {
+	conn := dialLive(t, stream.NewBroker(16, 16), handler.LiveConfig{PingInterval: 20 * time.Millisecond})
+	conn.SetPingHandler(func(string) error { return nil }) // не отвечаем
+
+	start := time.Now()
+	_ = conn.SetReadDeadline(time.Now().Add(liveTimeout))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				t.Fatalf("server kept the connection without pongs: %v", err)
+			}
+			break
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("closed after %v, before the pong deadline", elapsed)
+	}
+}
+
+func TestLive_ClosesSlowConsumer(t *testing.T) {
+	broker := stream.NewBroker(16, 1)
+	conn := dialLive(t, broker, handler.LiveConfig{})
+	send(t, conn, `{"action":"subscribe","id":"all"}`)
+
+	// публикуем, пока брокер не отключит подписчика: каждое событие сервер пишет в соединение,
+	// и буфер в одно событие переполняется
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				broker.Notify(models.CarEvent{Type: models.EventCarUpdated, Car: models.CarResponse{ID: "1", Brand: "Kia"}})
+			}
+		}
+	}()
+
+	_ = conn.SetReadDeadline(time.Now().Add(liveTimeout))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) {
+			t.Fatalf("want close frame, got %v", err)
+		}
+		if closeErr.Code != websocket.CloseTryAgainLater || closeErr.Text != "slow consumer" {
+			t.Fatalf("close %d %q, want %d \"slow consumer\"", closeErr.Code, closeErr.Text, websocket.CloseTryAgainLater)
+		}
+		return
+	}
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+
+	acceptPatch = fiber.MIMEApplicationJSON + ", " + mimeMergePatch + ", " + mimeJSONPatch
+)
+
+// mediaType возвращает Content-Type запроса без параметров в нижнем регистре.
+func mediaType(c *fiber.Ctx) string {
+	ct := c.Get(fiber.HeaderContentType)
+	if i := strings.IndexByte(ct, ';'); i != -1 {
+		ct = ct[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(ct))
+}
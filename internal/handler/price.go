@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+type PriceHandler struct {
+	uc usecase.PriceUsecase
+}
+
+func NewPriceHandler(uc usecase.PriceUsecase) *PriceHandler {
+	return &PriceHandler{uc: uc}
+}
+
+// History — GET /cars/:id/prices, последние изменения цены первыми.
+func (h *PriceHandler) History(c *fiber.Ctx) error {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	points, err := h.uc.History(ctx, id)
+	if err != nil {
+		return writePriceError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(points)
+}
+
+// Valuation — GET /cars/valuation?brand=&model=&year=&mileage=&currency=.
+func (h *PriceHandler) Valuation(c *fiber.Ctx) error {
+	q := models.ValuationQuery{Brand: c.Query("brand"), Model: c.Query("model"), Currency: c.Query("currency")}
+	var err error
+	if raw := c.Query("year"); raw != "" {
+		if q.Year, err = strconv.Atoi(raw); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid year, must be an integer"})
+		}
+	}
+	if raw := c.Query("mileage"); raw != "" {
+		mileage, err := strconv.Atoi(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid mileage, must be an integer"})
+		}
+		q.Mileage = &mileage
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.Valuate(ctx, q)
+	if err != nil {
+		return writePriceError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func writePriceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, apperr.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, apperr.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+type ReservationHandler struct {
+	uc usecase.ReservationUsecase
+}
+
+func NewReservationHandler(uc usecase.ReservationUsecase) *ReservationHandler {
+	return &ReservationHandler{uc: uc}
+}
+
+// Reserve — POST /cars/:id/reservations {"customer", "note", "ttl_seconds"}.
+func (h *ReservationHandler) Reserve(c *fiber.Ctx) error {
+	carID, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+	var req models.ReservationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	req.CarID = carID
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.Reserve(ctx, req)
+	if err != nil {
+		return writeReservationError(c, err, "car not found")
+	}
+	c.Location("/api/v1/cars/" + carID + "/reservations/" + resp.ID)
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// List — GET /cars/:id/reservations, новые брони первыми.
+func (h *ReservationHandler) List(c *fiber.Ctx) error {
+	carID, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.List(ctx, carID)
+	if err != nil {
+		return writeReservationError(c, err, "car not found")
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// Release — POST /cars/:id/reservations/:reservationId/release.
+func (h *ReservationHandler) Release(c *fiber.Ctx) error {
+	return h.close(c, h.uc.Release)
+}
+
+// Sell — POST /cars/:id/reservations/:reservationId/sell.
+func (h *ReservationHandler) Sell(c *fiber.Ctx) error {
+	return h.close(c, h.uc.Sell)
+}
+
+func (h *ReservationHandler) close(c *fiber.Ctx, fn func(ctx context.Context, carID, id string) (models.Reservation, error)) error {
+	carID, ok := uuidParam(c, "id")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id format, must be UUID"})
+	}
+	id, ok := uuidParam(c, "reservationId")
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid reservation id format, must be UUID"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := fn(ctx, carID, id)
+	if err != nil {
+		return writeReservationError(c, err, "reservation not found")
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func writeReservationError(c *fiber.Ctx, err error, notFound string) error {
+	switch {
+	case errors.Is(err, apperr.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": notFound})
+	case errors.Is(err, apperr.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, apperr.ErrConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
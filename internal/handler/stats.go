@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+type StatsHandler struct {
+	uc usecase.StatsUsecase
+}
+
+func NewStatsHandler(uc usecase.StatsUsecase) *StatsHandler {
+	return &StatsHandler{uc: uc}
+}
+
+// Stats — GET /cars/stats?group_by=brand|model|year|decade&period=day|week|month&from=&to=
+// с фильтрами списка; from и to — дата (YYYY-MM-DD) или RFC 3339.
+func (h *StatsHandler) Stats(c *fiber.Ctx) error {
+	filter, err := carFilterParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	q := models.StatsQuery{Filter: filter, GroupBy: c.Query("group_by"), Period: c.Query("period")}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		if *p.dst, err = timeParam(c, p.name); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.uc.Stats(ctx, q)
+	if err != nil {
+		if errors.Is(err, apperr.ErrInvalidInput) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// timeParam разбирает дату (начало дня UTC) или момент RFC 3339; нулевое время, если параметра нет.
+func timeParam(c *fiber.Ctx, name string) (time.Time, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s, must be a date (YYYY-MM-DD) or RFC 3339", name)
+	}
+	return t, nil
+}
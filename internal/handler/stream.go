@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/stream"
+)
+
+// eventResync сообщает клиенту, что часть событий потеряна и состояние нужно перечитать.
+const eventResync = "resync"
+
+type StreamHandler struct {
+	broker    *stream.Broker
+	heartbeat time.Duration
+}
+
+func NewStreamHandler(broker *stream.Broker, heartbeat time.Duration) *StreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &StreamHandler{broker: broker, heartbeat: heartbeat}
+}
+
+// Events — GET /cars/events?brand=: поток изменений в формате Server-Sent Events.
+// Возобновление — по заголовку Last-Event-ID (или ?last_event_id=).
+func (h *StreamHandler) Events(c *fiber.Ctx) error {
+	lastID := c.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	var afterID uint64
+	if lastID != "" {
+		id, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid Last-Event-ID"})
+		}
+		afterID = id
+	}
+
+	sub := h.broker.Subscribe(afterID, lastID != "", stream.BrandFilter(c.Query("brand")))
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	heartbeat := h.heartbeat
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+
+		if sub.Gap {
+			// без id: иначе клиент сдвинет Last-Event-ID за ещё не отправленный backlog
+			fmt.Fprintf(w, "event: %s\ndata: {}\n\n", eventResync)
+		}
+		for _, e := range sub.Backlog {
+			if err := writeSSE(w, e); err != nil {
+				return
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case e, ok := <-sub.C:
+				if !ok { // медленный клиент или остановка сервиса
+					return
+				}
+				if err := writeSSE(w, e); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if _, err := w.WriteString(": ping\n\n"); err != nil {
+					return
+				}
+			}
+			if err := w.Flush(); err != nil { // клиент отключился
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func writeSSE(w *bufio.Writer, e stream.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
@@ -0,0 +1,187 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/handler"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/stream"
+)
+
+// eventsApp — GET /cars/events поверх брокера; subscribed закрывается, когда обработчик
+// подписался и поток готов к записи.
+func eventsApp(broker *stream.Broker, heartbeat time.Duration) (*fiber.App, <-chan struct{}) {
+	h := handler.NewStreamHandler(broker, heartbeat)
+	subscribed := make(chan struct{})
+	app := fiber.New()
+	app.Get("/cars/events", func(c *fiber.Ctx) error {
+		err := c.Next()
+		close(subscribed)
+		return err
+	}, h.Events)
+	return app, subscribed
+}
+
+// readStream выполняет запрос, после подписки вызывает during и останавливает брокер:
+// app.Test возвращает ответ только после конца потока.
+func readStream(t *testing.T, broker *stream.Broker, app *fiber.App, subscribed <-chan struct{}, req *http.Request, during func()) (*http.Response, string) {
+	t.Helper()
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := app.Test(req, -1)
+		done <- result{resp, err}
+	}()
+	<-subscribed
+	if during != nil {
+		during()
+	}
+	broker.Close()
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("request: %v", res.err)
+	}
+	return res.resp, readBody(t, res.resp)
+}
+
+// frames сводит поток к списку кадров: "id event" для событий, "ping" для комментария
+// и имя события без id для служебных.
+func frames(body string) []string {
+	var out []string
+	for _, frame := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		if frame == "" {
+			continue
+		}
+		if strings.HasPrefix(frame, ":") {
+			out = append(out, strings.TrimSpace(strings.TrimPrefix(frame, ":")))
+			continue
+		}
+		var id, event string
+		for _, line := range strings.Split(frame, "\n") {
+			if v, ok := strings.CutPrefix(line, "id: "); ok {
+				id = v
+			}
+			if v, ok := strings.CutPrefix(line, "event: "); ok {
+				event = v
+			}
+		}
+		out = append(out, strings.TrimSpace(id+" "+event))
+	}
+	return out
+}
+
+func notifyCars(broker *stream.Broker, brands ...string) {
+	for _, brand := range brands {
+		broker.Notify(models.CarEvent{Type: models.EventCarUpdated, Car: models.CarResponse{ID: "1", Brand: brand}})
+	}
+}
+
+func eventsRequest(lastEventID, query string) *http.Request {
+	req := httptest.NewRequest(fiber.MethodGet, "/cars/events"+query, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	return req
+}
+
+func TestEvents_ReplaysAfterLastEventID(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		lastEventID string
+		query       string
+		want        []string
+	}{
+		{"header", "3", "", []string{"4 CarUpdated", "5 CarUpdated", "6 CarUpdated"}},
+		{"query", "", "?last_event_id=4", []string{"5 CarUpdated", "6 CarUpdated"}},
+		{"header wins over query", "5", "?last_event_id=1", []string{"6 CarUpdated"}},
+		{"up to date", "5", "", []string{"6 CarUpdated"}},
+		{"brand filter", "2", "?brand=bmw", []string{"4 CarUpdated", "6 CarUpdated"}},
+		{"no Last-Event-ID: only new events", "", "", []string{"6 CarUpdated"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			broker := stream.NewBroker(16, 16)
+			notifyCars(broker, "Kia", "Kia", "Kia", "BMW", "Kia")
+			app, subscribed := eventsApp(broker, time.Hour)
+
+			resp, body := readStream(t, broker, app, subscribed, eventsRequest(tc.lastEventID, tc.query), func() {
+				notifyCars(broker, "BMW")
+			})
+			if resp.StatusCode != fiber.StatusOK || resp.Header.Get(fiber.HeaderContentType) != "text/event-stream" {
+				t.Fatalf("status %d, Content-Type %q", resp.StatusCode, resp.Header.Get(fiber.HeaderContentType))
+			}
+			if got := frames(body); strings.Join(got, "|") != strings.Join(tc.want, "|") {
+				t.Fatalf("frames %q, want %q\n%s", got, tc.want, body)
+			}
+		})
+	}
+}
+
+func TestEvents_InvalidLastEventID(t *testing.T) {
+	app := fiber.New()
+	app.Get("/cars/events", handler.NewStreamHandler(stream.NewBroker(16, 16), time.Hour).Events)
+	for _, req := range []*http.Request{eventsRequest("abc", ""), eventsRequest("", "?last_event_id=-1")} {
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Fatalf("%s: %d, want 400", req.URL, resp.StatusCode)
+		}
+	}
+}
+
+func TestEvents_ResyncWhenLastEventIDIsGone(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		lastEventID string
+		want        []string
+	}{
+		// журнал на 3 события хранит только 4..6
+		{"evicted from log", "2", []string{"resync", "4 CarUpdated", "5 CarUpdated", "6 CarUpdated"}},
+		// журнал начат заново после рестарта
+		{"ahead of log", "100", []string{"resync"}},
+		{"oldest kept", "3", []string{"4 CarUpdated", "5 CarUpdated", "6 CarUpdated"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			broker := stream.NewBroker(3, 16)
+			notifyCars(broker, "Kia", "Kia", "Kia", "Kia", "Kia", "Kia")
+			app, subscribed := eventsApp(broker, time.Hour)
+
+			_, body := readStream(t, broker, app, subscribed, eventsRequest(tc.lastEventID, ""), nil)
+			if got := frames(body); strings.Join(got, "|") != strings.Join(tc.want, "|") {
+				t.Fatalf("frames %q, want %q\n%s", got, tc.want, body)
+			}
+			if strings.Contains(body, "event: resync") && !strings.HasPrefix(body, "event: resync\ndata: {}\n\n") {
+				t.Fatalf("resync must come first and carry no id:\n%s", body)
+			}
+		})
+	}
+}
+
+func TestEvents_Heartbeat(t *testing.T) {
+	broker := stream.NewBroker(16, 16)
+	app, subscribed := eventsApp(broker, 5*time.Millisecond)
+
+	_, body := readStream(t, broker, app, subscribed, eventsRequest("", ""), func() {
+		time.Sleep(50 * time.Millisecond)
+	})
+	got := frames(body)
+	if len(got) == 0 {
+		t.Fatalf("no heartbeat in %q", body)
+	}
+	for _, f := range got {
+		if f != "ping" {
+			t.Fatalf("want only heartbeat comments, got %q", got)
+		}
+	}
+}
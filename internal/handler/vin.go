@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/pavel97go/service-cars/internal/vin"
+)
+
+// VINHandler расшифровывает VIN по встроенной таблице, без обращения к хранилищу и внешним сервисам.
+type VINHandler struct{}
+
+func NewVINHandler() *VINHandler {
+	return &VINHandler{}
+}
+
+// Decode — GET /vin/:vin/decode.
+func (h *VINHandler) Decode(c *fiber.Ctx) error {
+	info, err := vin.Decode(c.Params("vin"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(info)
+}
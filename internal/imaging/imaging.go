@@ -0,0 +1,188 @@
+// Package imaging распознаёт загруженные изображения и строит их уменьшенные копии.
+// Используется только стандартная библиотека: JPEG, PNG и GIF (первый кадр).
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeGIF  = "image/gif"
+
+	// MaxPixels ограничивает размер кадра: распакованное изображение занимает 4 байта на пиксель.
+	MaxPixels = 25_000_000
+	// thumbnailQuality — качество JPEG миниатюр.
+	thumbnailQuality = 85
+)
+
+// Types — поддерживаемые типы исходных изображений.
+var Types = []string{TypeJPEG, TypePNG, TypeGIF}
+
+var (
+	// ErrUnsupported — содержимое не JPEG, PNG или GIF, что бы ни утверждал клиент.
+	ErrUnsupported = errors.New("unsupported image type")
+	// ErrTooManyPixels — кадр больше MaxPixels.
+	ErrTooManyPixels = errors.New("image is too large")
+)
+
+// Sniff определяет тип по первым байтам содержимого (http.DetectContentType);
+// ErrUnsupported, если это не поддерживаемое изображение.
+func Sniff(data []byte) (string, error) {
+	switch ct := http.DetectContentType(data); ct {
+	case TypeJPEG, TypePNG, TypeGIF:
+		return ct, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupported, ct)
+	}
+}
+
+// Decode распознаёт и декодирует изображение. Размер кадра проверяется по заголовку
+// до декодирования, чтобы маленький файл не развернулся в гигабайты памяти.
+func Decode(data []byte) (image.Image, string, error) {
+	ct, err := Sniff(data)
+	if err != nil {
+		return nil, "", err
+	}
+	var (
+		decodeConfig func(io.Reader) (image.Config, error)
+		decode       func(io.Reader) (image.Image, error)
+	)
+	switch ct {
+	case TypeJPEG:
+		decodeConfig, decode = jpeg.DecodeConfig, jpeg.Decode
+	case TypePNG:
+		decodeConfig, decode = png.DecodeConfig, png.Decode
+	case TypeGIF:
+		decodeConfig, decode = gif.DecodeConfig, gif.Decode
+	}
+	cfg, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, "", fmt.Errorf("%w: empty image", ErrUnsupported)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, "", fmt.Errorf("%w: %dx%d, max %d pixels", ErrTooManyPixels, cfg.Width, cfg.Height, MaxPixels)
+	}
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	return img, ct, nil
+}
+
+// Size — вариант миниатюры: изображение вписывается в квадрат Max×Max.
+type Size struct {
+	Name string
+	Max  int
+}
+
+// ParseSizes разбирает список вида "small:160,medium:480". Имена уникальны и годятся
+// как сегмент ключа хранилища.
+func ParseSizes(s string) ([]Size, error) {
+	var sizes []Size
+	seen := map[string]bool{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(item, ":")
+		side, err := strconv.Atoi(raw)
+		if !ok || err != nil || side <= 0 || name == "" || name == "original" ||
+			strings.Trim(name, "abcdefghijklmnopqrstuvwxyz0123456789_-") != "" {
+			return nil, fmt.Errorf("invalid thumbnail size %q, want name:pixels", item)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate thumbnail size %q", name)
+		}
+		seen[name] = true
+		sizes = append(sizes, Size{Name: name, Max: side})
+	}
+	return sizes, nil
+}
+
+// Fit возвращает размеры w×h, вписанные в квадрат side×side с сохранением пропорций.
+// Изображение не увеличивается.
+func Fit(w, h, side int) (int, int) {
+	if w <= side && h <= side {
+		return w, h
+	}
+	if w >= h {
+		return side, max(1, (h*side+w/2)/w)
+	}
+	return max(1, (w*side+h/2)/h), side
+}
+
+// Thumbnail вписывает img в квадрат side×side, усредняя пиксели (box-фильтр),
+// и кладёт прозрачные участки на белый фон, потому что миниатюры кодируются в JPEG.
+func Thumbnail(img image.Image, side int) *image.RGBA {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := Fit(sw, sh, side)
+
+	// каждому пикселю источника соответствует столбец и строка результата
+	col := make([]int, sw)
+	for x := range col {
+		col[x] = x * dw / sw
+	}
+	type acc struct{ r, g, b, a, n uint64 }
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	row := make([]acc, dw)
+	flush := func(dy int) {
+		for dx, s := range row {
+			if s.n == 0 {
+				continue
+			}
+			a := s.a / s.n
+			// значения премультиплицированы: добавляем белый с долей прозрачности
+			dst.SetRGBA(dx, dy, color.RGBA{
+				R: uint8((s.r/s.n + 0xffff - a) >> 8),
+				G: uint8((s.g/s.n + 0xffff - a) >> 8),
+				B: uint8((s.b/s.n + 0xffff - a) >> 8),
+				A: 0xff,
+			})
+			row[dx] = acc{}
+		}
+	}
+	prev := 0
+	for y := 0; y < sh; y++ {
+		if dy := y * dh / sh; dy != prev {
+			flush(prev)
+			prev = dy
+		}
+		for x := 0; x < sw; x++ {
+			r, g, bl, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			s := &row[col[x]]
+			s.r += uint64(r)
+			s.g += uint64(g)
+			s.b += uint64(bl)
+			s.a += uint64(a)
+			s.n++
+		}
+	}
+	flush(prev)
+	return dst
+}
+
+// EncodeJPEG кодирует миниатюру.
+func EncodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 30, 20))
+
+	_, ct, err := Decode(encodePNG(t, img))
+	require.NoError(t, err)
+	assert.Equal(t, TypePNG, ct)
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	got, ct, err := Decode(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, TypeJPEG, ct)
+	assert.Equal(t, image.Rect(0, 0, 30, 20), got.Bounds())
+
+	buf.Reset()
+	require.NoError(t, gif.Encode(&buf, img, nil))
+	_, ct, err = Decode(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, TypeGIF, ct)
+
+	// тип определяется по содержимому, а не по заявленному клиентом
+	_, _, err = Decode([]byte("%PDF-1.4 not an image"))
+	assert.True(t, errors.Is(err, ErrUnsupported), "got %v", err)
+	_, _, err = Decode(append([]byte("\x89PNG\r\n\x1a\n"), "broken"...))
+	assert.True(t, errors.Is(err, ErrUnsupported), "got %v", err)
+}
+
+func TestDecode_TooManyPixels(t *testing.T) {
+	// заголовок PNG с огромными размерами: ошибка должна появиться до декодирования
+	data := encodePNG(t, image.NewGray(image.Rect(0, 0, 10000, 10000)))
+	_, _, err := Decode(data)
+	assert.True(t, errors.Is(err, ErrTooManyPixels), "got %v", err)
+}
+
+func TestFit(t *testing.T) {
+	for _, tc := range []struct{ w, h, side, ww, wh int }{
+		{1600, 1200, 160, 160, 120},
+		{1200, 1600, 160, 120, 160},
+		{100, 50, 160, 100, 50},
+		{4000, 1, 100, 100, 1},
+	} {
+		w, h := Fit(tc.w, tc.h, tc.side)
+		assert.Equal(t, [2]int{tc.ww, tc.wh}, [2]int{w, h}, "%+v", tc)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	// левая половина красная, правая прозрачная
+	src := image.NewNRGBA(image.Rect(10, 10, 410, 210))
+	for y := 10; y < 210; y++ {
+		for x := 10; x < 210; x++ {
+			src.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	th := Thumbnail(src, 100)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), th.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, th.RGBAAt(10, 25))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, th.RGBAAt(90, 25), "transparency becomes white")
+
+	data, err := EncodeJPEG(th)
+	require.NoError(t, err)
+	_, ct, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, TypeJPEG, ct)
+}
+
+func TestParseSizes(t *testing.T) {
+	sizes, err := ParseSizes("small:160, medium:480,,large:1280")
+	require.NoError(t, err)
+	assert.Equal(t, []Size{{"small", 160}, {"medium", 480}, {"large", 1280}}, sizes)
+
+	for _, bad := range []string{"small", "small:0", "Small:10", "original:10", "a:1,a:2", "../x:10"} {
+		_, err := ParseSizes(bad)
+		assert.Error(t, err, bad)
+	}
+}
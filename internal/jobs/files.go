@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Files — каталог с входными и выходными файлами задач: <id>.in и <id>.out.
+// При нескольких экземплярах сервиса каталог должен быть общим, иначе задачу,
+// захваченную другим экземпляром, нечем будет выполнить.
+type Files struct {
+	dir string
+}
+
+func NewFiles(dir string) (*Files, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("jobs dir: %w", err)
+	}
+	return &Files{dir: dir}, nil
+}
+
+func (f *Files) path(id, ext string) string {
+	return filepath.Join(f.dir, id+ext)
+}
+
+// SaveInput записывает входной файл задачи. Файл появляется под итоговым именем
+// только целиком, поэтому воркер не увидит недописанную загрузку.
+func (f *Files) SaveInput(id string, r io.Reader) (int64, error) {
+	tmp, err := os.CreateTemp(f.dir, id+".in-*")
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.path(id, ".in"))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return 0, err
+	}
+	return n, nil
+}
+
+func (f *Files) OpenInput(id string) (*os.File, error) {
+	return os.Open(f.path(id, ".in"))
+}
+
+// CreateOutput создаёт (или обнуляет, если задача перезапущена) выходной файл.
+func (f *Files) CreateOutput(id string) (*os.File, error) {
+	return os.Create(f.path(id, ".out"))
+}
+
+func (f *Files) OpenOutput(id string) (*os.File, error) {
+	return os.Open(f.path(id, ".out"))
+}
+
+// RemoveInput удаляет загруженный файл, когда он больше не нужен.
+func (f *Files) RemoveInput(id string) error {
+	return removeIfExists(f.path(id, ".in"))
+}
+
+// Remove удаляет все файлы задачи.
+func (f *Files) Remove(id string) error {
+	return errors.Join(removeIfExists(f.path(id, ".in")), removeIfExists(f.path(id, ".out")))
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
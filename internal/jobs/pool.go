@@ -0,0 +1,254 @@
+// Package jobs выполняет долгие операции (импорт, выгрузку) в фоне: задачи лежат
+// в хранилище, пул воркеров захватывает их с арендой и продлевает её, пока работает.
+// Если процесс упал, аренда истекает и задачу подхватывает другой воркер.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+const purgeEvery = 10 * time.Minute
+
+var (
+	errCanceled  = errors.New("job canceled")
+	errLeaseLost = errors.New("job lease lost")
+)
+
+// Store — очередь задач (repository.JobProvider).
+type Store interface {
+	ClaimJob(ctx context.Context, lease time.Duration) (*models.Job, error)
+	ExtendJob(ctx context.Context, id string, attempt int, p models.JobProgress, lease time.Duration) (bool, error)
+	FinishJob(ctx context.Context, id string, attempt int, res models.JobResult) error
+	DeleteFinishedJobs(ctx context.Context, before time.Time) ([]string, error)
+}
+
+// Handler выполняет задачу своего вида и возвращает результат для Job.Result.
+// ctx отменяется при отмене задачи, потере аренды и остановке сервиса.
+// Задача может выполняться повторно (после падения воркера), поэтому Handler
+// должен либо быть идемпотентным, либо распознавать уже сделанную работу.
+type Handler func(ctx context.Context, job models.Job, progress *Progress) (json.RawMessage, error)
+
+// Progress — счётчики, которые воркер сохраняет при продлении аренды.
+type Progress struct {
+	processed atomic.Int64
+	total     atomic.Int64
+}
+
+func (p *Progress) Add(n int64)          { p.processed.Add(n) }
+func (p *Progress) SetTotal(n int64)     { p.total.Store(n) }
+func (p *Progress) SetProcessed(n int64) { p.processed.Store(n) }
+
+func (p *Progress) snapshot() models.JobProgress {
+	return models.JobProgress{Processed: p.processed.Load(), Total: p.total.Load()}
+}
+
+type Config struct {
+	Workers      int
+	PollInterval time.Duration
+	Lease        time.Duration // аренда продлевается каждую треть срока
+	MaxAttempts  int           // после стольких захватов задача считается проваленной
+	Retention    time.Duration // сколько хранить завершённые задачи и их файлы; 0 — всегда
+}
+
+type Pool struct {
+	store    Store
+	files    *Files
+	handlers map[string]Handler
+	cfg      Config
+}
+
+func NewPool(store Store, files *Files, handlers map[string]Handler, cfg Config) *Pool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	return &Pool{store: store, files: files, handlers: handlers, cfg: cfg}
+}
+
+// Run запускает воркеров и очистку старых задач и ждёт их остановки после отмены ctx.
+// Прерванные остановкой задачи не завершаются: их подхватят после истечения аренды.
+func (p *Pool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range p.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+	if p.cfg.Retention > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.purge(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Pool) work(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		wait := p.cfg.PollInterval
+		job, err := p.store.ClaimJob(ctx, p.cfg.Lease)
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				slog.Error("job claim failed", "err", err)
+			}
+		case job != nil:
+			p.run(ctx, *job)
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+func (p *Pool) run(ctx context.Context, job models.Job) {
+	res := models.JobResult{JobProgress: models.JobProgress{Processed: job.Processed, Total: job.Total}}
+	handler, ok := p.handlers[job.Kind]
+	switch {
+	case job.CancelRequested:
+		// отмену запросили, пока задача была у упавшего воркера
+		res.Status = models.JobCanceled
+	case job.Attempts > p.cfg.MaxAttempts:
+		res.Status = models.JobFailed
+		res.Error = fmt.Sprintf("gave up after %d attempts", job.Attempts-1)
+	case !ok:
+		res.Status = models.JobFailed
+		res.Error = fmt.Sprintf("unknown job kind %q", job.Kind)
+	default:
+		var done bool
+		if res, done = p.execute(ctx, job, handler); !done {
+			return
+		}
+	}
+
+	if err := p.store.FinishJob(ctx, job.ID, job.Attempts, res); err != nil {
+		if ctx.Err() == nil && !errors.Is(err, apperr.ErrConflict) {
+			slog.Error("job finish failed", "job", job.ID, "err", err)
+		}
+		return
+	}
+	if err := p.files.RemoveInput(job.ID); err != nil {
+		slog.Warn("job input cleanup failed", "job", job.ID, "err", err)
+	}
+	slog.Info("job finished", "job", job.ID, "kind", job.Kind, "status", res.Status,
+		"attempt", job.Attempts, "processed", res.Processed)
+}
+
+// execute запускает handler, пока параллельно продлевается аренда. done=false —
+// задача прервана остановкой сервиса или перехвачена другим воркером и не завершается.
+func (p *Pool) execute(ctx context.Context, job models.Job, handler Handler) (models.JobResult, bool) {
+	jctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	progress := &Progress{}
+	progress.SetProcessed(job.Processed)
+	progress.SetTotal(job.Total)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		p.heartbeat(jctx, job, progress, cancel, stop)
+	}()
+
+	result, err := handler(jctx, job, progress)
+	close(stop)
+	<-stopped
+
+	res := models.JobResult{JobProgress: progress.snapshot()}
+	switch cause := context.Cause(jctx); {
+	case err == nil:
+		res.Status = models.JobSucceeded
+		res.Result = result
+	case errors.Is(cause, errCanceled):
+		res.Status = models.JobCanceled
+	case errors.Is(cause, errLeaseLost):
+		slog.Warn("job lease lost, leaving it to the new owner", "job", job.ID, "attempt", job.Attempts)
+		return res, false
+	case ctx.Err() != nil:
+		return res, false
+	default:
+		res.Status = models.JobFailed
+		res.Error = err.Error()
+	}
+	return res, true
+}
+
+// heartbeat продлевает аренду и сохраняет прогресс, пока не закрыт stop; при отмене
+// задачи или потере аренды отменяет контекст handler.
+func (p *Pool) heartbeat(ctx context.Context, job models.Job, progress *Progress,
+	cancel context.CancelCauseFunc, stop <-chan struct{}) {
+	ticker := time.NewTicker(max(p.cfg.Lease/3, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		canceled, err := p.store.ExtendJob(ctx, job.ID, job.Attempts, progress.snapshot(), p.cfg.Lease)
+		switch {
+		case errors.Is(err, apperr.ErrConflict):
+			cancel(errLeaseLost)
+			return
+		case err != nil:
+			// аренда ещё действует, попробуем на следующем тике
+			if ctx.Err() == nil {
+				slog.Error("job heartbeat failed", "job", job.ID, "err", err)
+			}
+		case canceled:
+			cancel(errCanceled)
+			return
+		}
+	}
+}
+
+func (p *Pool) purge(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		ids, err := p.store.DeleteFinishedJobs(ctx, time.Now().Add(-p.cfg.Retention))
+		if err != nil && ctx.Err() == nil {
+			slog.Error("job purge failed", "err", err)
+		}
+		for _, id := range ids {
+			if err := p.files.Remove(id); err != nil {
+				slog.Warn("job files cleanup failed", "job", id, "err", err)
+			}
+		}
+		if len(ids) > 0 {
+			slog.Info("purged finished jobs", "count", len(ids))
+		}
+		timer.Reset(purgeEvery)
+	}
+}
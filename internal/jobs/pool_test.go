@@ -0,0 +1,175 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+var testConfig = Config{
+	Workers:      2,
+	PollInterval: 5 * time.Millisecond,
+	Lease:        30 * time.Millisecond,
+	MaxAttempts:  2,
+}
+
+func setupPool(t *testing.T, handlers map[string]Handler) (*repository.MemoryCarRepo, *Files) {
+	t.Helper()
+	repo := repository.NewMemoryCarRepo()
+	files, err := NewFiles(t.TempDir())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewPool(repo, files, handlers, testConfig).Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return repo, files
+}
+
+func submit(t *testing.T, repo *repository.MemoryCarRepo, kind string) models.Job {
+	t.Helper()
+	j := models.Job{Kind: kind}
+	require.NoError(t, repo.CreateJob(context.Background(), &j))
+	return j
+}
+
+// waitJob ждёт, пока задача не окажется в одном из статусов.
+func waitJob(t *testing.T, repo *repository.MemoryCarRepo, id string, statuses ...string) models.Job {
+	t.Helper()
+	var j *models.Job
+	require.Eventually(t, func() bool {
+		var err error
+		j, err = repo.GetJob(context.Background(), id)
+		require.NoError(t, err)
+		for _, s := range statuses {
+			if j.Status == s {
+				return true
+			}
+		}
+		return false
+	}, 2*time.Second, 5*time.Millisecond, "job never reached %v", statuses)
+	return *j
+}
+
+func TestPool_Succeeds(t *testing.T) {
+	repo, files := setupPool(t, map[string]Handler{
+		"test": func(ctx context.Context, job models.Job, p *Progress) (json.RawMessage, error) {
+			p.SetTotal(3)
+			p.Add(3)
+			return json.RawMessage(`{"ok":true}`), nil
+		},
+	})
+	j := models.Job{ID: "8f0d2c52-7a1c-4a57-9a39-5b0f2c1e0001", Kind: "test"}
+	_, err := files.SaveInput(j.ID, strings.NewReader("payload"))
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateJob(context.Background(), &j))
+
+	got := waitJob(t, repo, j.ID, models.JobSucceeded)
+	assert.JSONEq(t, `{"ok":true}`, string(got.Result))
+	assert.Equal(t, int64(3), got.Processed)
+	assert.Equal(t, int64(3), got.Total)
+	assert.Equal(t, 1, got.Attempts)
+
+	_, err = files.OpenInput(j.ID)
+	assert.Error(t, err, "input file is removed once the job is finished")
+}
+
+func TestPool_HandlerErrorFails(t *testing.T) {
+	repo, _ := setupPool(t, map[string]Handler{
+		"test": func(ctx context.Context, job models.Job, p *Progress) (json.RawMessage, error) {
+			return nil, errors.New("bad file")
+		},
+	})
+	j := submit(t, repo, "test")
+	unknown := submit(t, repo, "unknown")
+
+	got := waitJob(t, repo, j.ID, models.JobFailed)
+	assert.Equal(t, "bad file", got.Error)
+	got = waitJob(t, repo, unknown.ID, models.JobFailed)
+	assert.Contains(t, got.Error, "unknown job kind")
+}
+
+func TestPool_CancelRunning(t *testing.T) {
+	started := make(chan struct{})
+	repo, _ := setupPool(t, map[string]Handler{
+		"test": func(ctx context.Context, job models.Job, p *Progress) (json.RawMessage, error) {
+			p.Add(7)
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	j := submit(t, repo, "test")
+	<-started
+
+	_, err := repo.CancelJob(context.Background(), j.ID)
+	require.NoError(t, err)
+	got := waitJob(t, repo, j.ID, models.JobCanceled)
+	assert.Equal(t, int64(7), got.Processed)
+	assert.Empty(t, got.Error)
+}
+
+// TestPool_ResumesAfterCrash: задачу захватил воркер, который не продлевает аренду
+// (упал); после её истечения задачу выполняет пул.
+func TestPool_ResumesAfterCrash(t *testing.T) {
+	repo := repository.NewMemoryCarRepo()
+	j := submit(t, repo, "test")
+	crashed, err := repo.ClaimJob(context.Background(), 20*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, j.ID, crashed.ID)
+
+	files, err := NewFiles(t.TempDir())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	attempts := make(chan int, 1)
+	go NewPool(repo, files, map[string]Handler{
+		"test": func(ctx context.Context, job models.Job, p *Progress) (json.RawMessage, error) {
+			attempts <- job.Attempts
+			return nil, nil
+		},
+	}, testConfig).Run(ctx)
+
+	got := waitJob(t, repo, j.ID, models.JobSucceeded)
+	assert.Equal(t, 2, <-attempts)
+	assert.Equal(t, 2, got.Attempts)
+}
+
+func TestPool_GivesUpAfterMaxAttempts(t *testing.T) {
+	repo := repository.NewMemoryCarRepo()
+	j := submit(t, repo, "test")
+	for range testConfig.MaxAttempts {
+		_, err := repo.ClaimJob(context.Background(), time.Millisecond)
+		require.NoError(t, err)
+		time.Sleep(3 * time.Millisecond)
+	}
+
+	files, err := NewFiles(t.TempDir())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go NewPool(repo, files, map[string]Handler{
+		"test": func(ctx context.Context, job models.Job, p *Progress) (json.RawMessage, error) {
+			t.Error("handler must not run after max attempts")
+			return nil, nil
+		},
+	}, testConfig).Run(ctx)
+
+	got := waitJob(t, repo, j.ID, models.JobFailed)
+	assert.Contains(t, got.Error, "gave up after 2 attempts")
+}
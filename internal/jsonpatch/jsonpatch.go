@@ -0,0 +1,123 @@
+// Package jsonpatch применяет JSON Patch (RFC 6902) к плоскому JSON-объекту.
+// Поддерживаются все шесть операций, но только пути верхнего уровня ("/brand"):
+// документы сервиса не содержат вложенных объектов.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	ErrInvalidPatch = errors.New("invalid json patch")
+	ErrTestFailed   = errors.New("json patch test failed")
+)
+
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply применяет операции к документу doc по порядку и возвращает результат.
+// Операции атомарны: при ошибке исходный документ не меняется.
+func Apply(doc []byte, ops []Operation) ([]byte, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &obj); err != nil {
+		return nil, fmt.Errorf("%w: document must be a JSON object", ErrInvalidPatch)
+	}
+	for i, op := range ops {
+		if err := apply(obj, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(obj)
+}
+
+func apply(obj map[string]json.RawMessage, op Operation) error {
+	key, err := member(op.Path)
+	if err != nil {
+		return err
+	}
+	switch op.Op {
+	case "add":
+		if op.Value == nil {
+			return fmt.Errorf("%w: missing value", ErrInvalidPatch)
+		}
+		obj[key] = op.Value
+	case "remove":
+		if _, ok := obj[key]; !ok {
+			return fmt.Errorf("%w: path not found", ErrInvalidPatch)
+		}
+		delete(obj, key)
+	case "replace":
+		if _, ok := obj[key]; !ok {
+			return fmt.Errorf("%w: path not found", ErrInvalidPatch)
+		}
+		if op.Value == nil {
+			return fmt.Errorf("%w: missing value", ErrInvalidPatch)
+		}
+		obj[key] = op.Value
+	case "move", "copy":
+		from, err := member(op.From)
+		if err != nil {
+			return err
+		}
+		v, ok := obj[from]
+		if !ok {
+			return fmt.Errorf("%w: from path not found", ErrInvalidPatch)
+		}
+		if op.Op == "move" {
+			delete(obj, from)
+		}
+		obj[key] = v
+	case "test":
+		v, ok := obj[key]
+		if !ok {
+			return ErrTestFailed
+		}
+		equal, err := jsonEqual(v, op.Value)
+		if err != nil {
+			return err
+		}
+		if !equal {
+			return ErrTestFailed
+		}
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+	}
+	return nil
+}
+
+// member разбирает JSON Pointer (RFC 6901) из одного сегмента.
+func member(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("%w: path %q must start with /", ErrInvalidPatch, path)
+	}
+	token := path[1:]
+	if token == "" || strings.Contains(token, "/") {
+		return "", fmt.Errorf("%w: only top-level paths are supported", ErrInvalidPatch)
+	}
+	token = strings.ReplaceAll(token, "~1", "/")
+	token = strings.ReplaceAll(token, "~0", "~")
+	return token, nil
+}
+
+func jsonEqual(a, b json.RawMessage) (bool, error) {
+	if b == nil {
+		return false, fmt.Errorf("%w: missing value", ErrInvalidPatch)
+	}
+	var av, bv interface{}
+	if err := json.NewDecoder(bytes.NewReader(a)).Decode(&av); err != nil {
+		return false, err
+	}
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&bv); err != nil {
+		return false, fmt.Errorf("%w: %s", ErrInvalidPatch, err.Error())
+	}
+	return reflect.DeepEqual(av, bv), nil
+}
@@ -0,0 +1,65 @@
+package jsonpatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/jsonpatch"
+)
+
+const doc = `{"brand":"Toyota","model":"Camry","year":2020}`
+
+func op(kind, path, value string) jsonpatch.Operation {
+	o := jsonpatch.Operation{Op: kind, Path: path}
+	if value != "" {
+		o.Value = json.RawMessage(value)
+	}
+	return o
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		ops  []jsonpatch.Operation
+		want string
+	}{
+		{"replace", []jsonpatch.Operation{op("replace", "/model", `"Corolla"`)}, `{"brand":"Toyota","model":"Corolla","year":2020}`},
+		{"add", []jsonpatch.Operation{op("add", "/color", `"red"`)}, `{"brand":"Toyota","color":"red","model":"Camry","year":2020}`},
+		{"remove", []jsonpatch.Operation{op("remove", "/year", "")}, `{"brand":"Toyota","model":"Camry"}`},
+		{"test then replace", []jsonpatch.Operation{
+			op("test", "/year", `2020`),
+			op("replace", "/year", `2021`),
+		}, `{"brand":"Toyota","model":"Camry","year":2021}`},
+		{"copy", []jsonpatch.Operation{{Op: "copy", From: "/brand", Path: "/model"}}, `{"brand":"Toyota","model":"Toyota","year":2020}`},
+		{"move", []jsonpatch.Operation{{Op: "move", From: "/brand", Path: "/make"}}, `{"make":"Toyota","model":"Camry","year":2020}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonpatch.Apply([]byte(doc), tt.ops)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := jsonpatch.Apply([]byte(doc), []jsonpatch.Operation{op("test", "/year", `1999`)})
+	assert.True(t, errors.Is(err, jsonpatch.ErrTestFailed), "got %v", err)
+
+	_, err = jsonpatch.Apply([]byte(doc), []jsonpatch.Operation{op("replace", "/vin", `"x"`)})
+	assert.True(t, errors.Is(err, jsonpatch.ErrInvalidPatch), "got %v", err)
+
+	_, err = jsonpatch.Apply([]byte(doc), []jsonpatch.Operation{op("add", "/a/b", `1`)})
+	assert.True(t, errors.Is(err, jsonpatch.ErrInvalidPatch), "got %v", err)
+
+	_, err = jsonpatch.Apply([]byte(doc), []jsonpatch.Operation{op("frobnicate", "/year", `1`)})
+	assert.True(t, errors.Is(err, jsonpatch.ErrInvalidPatch), "got %v", err)
+}
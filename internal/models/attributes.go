@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+)
+
+// Статусы машины в продаже.
+const (
+	CarStatusAvailable = "available"
+	CarStatusReserved  = "reserved"
+	CarStatusSold      = "sold"
+)
+
+// CheckManualStatus проверяет статус, заданный при создании и правке машины: в reserved машину
+// переводит и выводит только бронь. Пустой from — текущий статус неизвестен (новая машина).
+func CheckManualStatus(from, to string) error {
+	switch {
+	case to == "" || from == to:
+		return nil
+	case to == CarStatusReserved:
+		return fmt.Errorf("%w: status reserved is set by creating a reservation", apperr.ErrInvalidInput)
+	case from == CarStatusReserved:
+		return fmt.Errorf("%w: car is reserved, release or sell the reservation first", apperr.ErrConflict)
+	}
+	return nil
+}
+
+// Допустимые значения перечислимых атрибутов; проверяет usecase (значения в нижнем регистре,
+// валюта — код ISO 4217 в верхнем).
+var (
+	BodyTypes     = []string{"sedan", "hatchback", "wagon", "coupe", "convertible", "suv", "crossover", "minivan", "pickup", "van"}
+	FuelTypes     = []string{"petrol", "diesel", "hybrid", "plugin_hybrid", "electric", "lpg"}
+	Transmissions = []string{"manual", "automatic", "robot", "cvt"}
+	Conditions    = []string{"new", "used", "damaged"}
+	CarStatuses   = []string{CarStatusAvailable, CarStatusReserved, CarStatusSold}
+	Currencies    = []string{"RUB", "USD", "EUR", "CNY", "KZT", "BYN", "GBP", "JPY"}
+)
+
+// CarAttributes — коммерческие атрибуты машины. Пустая строка и nil — значение не указано.
+// Пробег в километрах, объём двигателя в см³, мощность в л.с., цена в Currency с точностью до сотых.
+type CarAttributes struct {
+	Mileage      *int   `json:"mileage,omitempty" db:"mileage" validate:"omitempty,gte=0,lte=5000000"`
+	Color        string `json:"color,omitempty" db:"color" validate:"omitempty,max=30"`
+	BodyType     string `json:"body_type,omitempty" db:"body_type"`
+	FuelType     string `json:"fuel_type,omitempty" db:"fuel_type"`
+	Transmission string `json:"transmission,omitempty" db:"transmission"`
+	EngineVolume *int   `json:"engine_volume,omitempty" db:"engine_volume" validate:"omitempty,gte=50,lte=20000"`
+	EnginePower  *int   `json:"engine_power,omitempty" db:"engine_power" validate:"omitempty,gte=1,lte=3000"`
+	Price        *Money `json:"price,omitempty" db:"price" validate:"omitempty,gte=0,lte=99999999999999"`
+	Currency     string `json:"currency,omitempty" db:"currency"`
+	Condition    string `json:"condition,omitempty" db:"condition"`
+	Status       string `json:"status,omitempty" db:"status"`
+}
+
+// UpdateCarAttributes — атрибуты в JSON Merge Patch: null очищает атрибут, для status null — ошибка.
+type UpdateCarAttributes struct {
+	Mileage      Optional[int]    `json:"mileage" validate:"omitempty,gte=0,lte=5000000"`
+	Color        Optional[string] `json:"color" validate:"omitempty,max=30"`
+	BodyType     Optional[string] `json:"body_type"`
+	FuelType     Optional[string] `json:"fuel_type"`
+	Transmission Optional[string] `json:"transmission"`
+	EngineVolume Optional[int]    `json:"engine_volume" validate:"omitempty,gte=50,lte=20000"`
+	EnginePower  Optional[int]    `json:"engine_power" validate:"omitempty,gte=1,lte=3000"`
+	Price        Optional[Money]  `json:"price" validate:"omitempty,gte=0,lte=99999999999999"`
+	Currency     Optional[string] `json:"currency"`
+	Condition    Optional[string] `json:"condition"`
+	Status       Optional[string] `json:"status"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+)
+
+// CarSnapshot — состояние машины, которое попадает в before/after записи аудита.
+type CarSnapshot struct {
+	Brand string `json:"brand"`
+	Model string `json:"model"`
+	Year  int    `json:"year"`
+	VIN   string `json:"vin,omitempty"`
+	CarAttributes
+	Version   int        `json:"version"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func NewCarSnapshot(car Car) CarSnapshot {
+	return CarSnapshot{
+		Brand:         car.Brand,
+		Model:         car.Model,
+		Year:          car.Year,
+		VIN:           car.VIN,
+		CarAttributes: car.CarAttributes,
+		Version:       car.Version,
+		DeletedAt:     car.DeletedAt,
+	}
+}
+
+type AuditChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+type AuditEntry struct {
+	ID        int64                  `json:"id"`
+	CarID     string                 `json:"car_id"`
+	Action    string                 `json:"action"`
+	Actor     string                 `json:"actor"`
+	RequestID string                 `json:"request_id"`
+	Before    json.RawMessage        `json:"before"`
+	After     json.RawMessage        `json:"after"`
+	Diff      map[string]AuditChange `json:"diff"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// AuditFilter — условия выборки аудита; пустые поля не фильтруют.
+type AuditFilter struct {
+	CarID  string
+	Actor  string
+	Action string
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+type AuditQuery struct {
+	CarID  string `query:"car_id" validate:"omitempty,uuid"`
+	Actor  string `query:"actor" validate:"omitempty,max=100"`
+	Action string `query:"action" validate:"omitempty,oneof=create update delete restore"`
+	From   string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To     string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=200"`
+	Offset int    `query:"offset" validate:"omitempty,min=0"`
+}
+
+type AuditPage struct {
+	Items  []AuditEntry `json:"items"`
+	Total  int          `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
@@ -0,0 +1,54 @@
+package models
+
+// Операции пакетного запроса POST /cars/batch.
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+// BatchOperation — элемент пакета. Для update поля машины и атрибуты заменяют
+// текущие (как PUT); version — ожидаемая версия записи, 0 — без проверки.
+type BatchOperation struct {
+	Op    string `json:"op"`
+	ID    string `json:"id,omitempty"`
+	Brand string `json:"brand,omitempty"`
+	Model string `json:"model,omitempty"`
+	Year  int    `json:"year,omitempty"`
+	VIN   string `json:"vin,omitempty"`
+	CarAttributes
+	Version int `json:"version,omitempty"`
+}
+
+// BatchRequest — тело POST /cars/batch. Atomic=true: все операции в одной транзакции,
+// при первой ошибке не применяется ни одна; иначе применяются все успешные.
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// CarMutation — проверенная операция пакета для репозитория.
+// Для update и delete Car.Version — ожидаемая версия (0 — любая).
+type CarMutation struct {
+	Op  string
+	Car Car
+}
+
+// MutationResult — итог операции пакета: состояние машины после изменения либо ошибка.
+type MutationResult struct {
+	Car *Car
+	Err error
+}
+
+// BatchItem — результат операции пакета в порядке запроса.
+type BatchItem struct {
+	Index int
+	Op    string
+	Car   *CarResponse
+	Err   error
+}
+
+type BatchResult struct {
+	Atomic bool
+	Items  []BatchItem
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Brand — марка из справочника. Name — каноническое написание, которое
+// сохраняется в машинах; Aliases — другие написания, которые к нему приводятся.
+type Brand struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Aliases   []string  `json:"aliases"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	YearRange
+}
+
+// YearRange — годы выпуска марки или модели; nil — граница не задана.
+type YearRange struct {
+	From *int `json:"year_from"`
+	To   *int `json:"year_to"`
+}
+
+// CarModel — модель марки BrandID из справочника.
+type CarModel struct {
+	ID        string    `json:"id"`
+	BrandID   string    `json:"brand_id"`
+	Name      string    `json:"name"`
+	Aliases   []string  `json:"aliases"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	YearRange
+}
+
+// BrandRequest — создание (POST) и полная замена (PUT) марки.
+type BrandRequest struct {
+	ID      string   `json:"-"`
+	Name    string   `json:"name" validate:"required,carname,max=50"`
+	Aliases []string `json:"aliases" validate:"max=20,dive,required,carname,max=50"`
+}
+
+// CarModelRequest — создание (POST) и полная замена (PUT) модели.
+type CarModelRequest struct {
+	ID      string   `json:"-"`
+	BrandID string   `json:"-" validate:"required,uuid"`
+	Name    string   `json:"name" validate:"required,carname,max=50"`
+	Aliases []string `json:"aliases" validate:"max=20,dive,required,carname,max=50"`
+}
+
+// YearRangeRequest — замена годов выпуска марки (PUT .../brands/:id/years)
+// или модели (PUT .../models/:modelId/years); для марки BrandID пуст.
+type YearRangeRequest struct {
+	ID      string `json:"-" validate:"required,uuid"`
+	BrandID string `json:"-" validate:"omitempty,uuid"`
+	From    *int   `json:"year_from" validate:"omitempty,gte=1886"`
+	To      *int   `json:"year_to" validate:"omitempty,gte=1886"`
+}
+
+// CatalogKey — ключ сравнения названий в справочнике: буквы и цифры в нижнем регистре,
+// без пробелов и знаков. "Mercedes-Benz", "mercedes benz" и "MERCEDESBENZ" дают один ключ.
+func CatalogKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// CatalogKeys — ключи названия и всех синонимов без повторов, ключ названия первый.
+func CatalogKeys(name string, aliases []string) []string {
+	keys := []string{CatalogKey(name)}
+	for _, a := range aliases {
+		k := CatalogKey(a)
+		if k != "" && !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EventCarCreated  = "CarCreated"
+	EventCarUpdated  = "CarUpdated"
+	EventCarDeleted  = "CarDeleted"
+	EventCarRestored = "CarRestored"
+)
+
+// OutboxEvent — доменное событие из таблицы car_outbox в том виде, в каком оно уходит подписчикам.
+type OutboxEvent struct {
+	ID         int64           `json:"id"`
+	Type       string          `json:"type"`
+	CarID      string          `json:"car_id"`
+	Actor      string          `json:"actor,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Attempts   int             `json:"-"`
+}
+
+// CarEvent — изменение машины, о котором usecase уведомляет подписчиков в реальном времени.
+type CarEvent struct {
+	Type       string      `json:"type"`
+	Car        CarResponse `json:"car"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "strings"
+
+// CarFilter — фильтры списка машин; пустые поля не ограничивают выборку.
+// Строковые поля сравниваются без учёта регистра. Границы цены и пробега
+// исключают машины, у которых значение не указано.
+type CarFilter struct {
+	Brand        string `json:"brand,omitempty"`
+	Model        string `json:"model,omitempty"`
+	YearFrom     int    `json:"year_from,omitempty"`
+	YearTo       int    `json:"year_to,omitempty"`
+	Color        string `json:"color,omitempty"`
+	BodyType     string `json:"body_type,omitempty"`
+	FuelType     string `json:"fuel_type,omitempty"`
+	Transmission string `json:"transmission,omitempty"`
+	Condition    string `json:"condition,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Currency     string `json:"currency,omitempty"`
+	PriceFrom    Money  `json:"price_from,omitempty"`
+	PriceTo      Money  `json:"price_to,omitempty"`
+	MileageFrom  int    `json:"mileage_from,omitempty"`
+	MileageTo    int    `json:"mileage_to,omitempty"`
+}
+
+func (f CarFilter) Match(c Car) bool {
+	for _, s := range []struct{ want, got string }{
+		{f.Brand, c.Brand}, {f.Model, c.Model}, {f.Color, c.Color}, {f.BodyType, c.BodyType},
+		{f.FuelType, c.FuelType}, {f.Transmission, c.Transmission}, {f.Condition, c.Condition},
+		{f.Status, c.Status}, {f.Currency, c.Currency},
+	} {
+		if s.want != "" && !strings.EqualFold(s.got, s.want) {
+			return false
+		}
+	}
+	if f.YearFrom != 0 && c.Year < f.YearFrom {
+		return false
+	}
+	if f.YearTo != 0 && c.Year > f.YearTo {
+		return false
+	}
+	return inRange(c.Price, f.PriceFrom, f.PriceTo) && inRange(c.Mileage, f.MileageFrom, f.MileageTo)
+}
+
+// inRange проверяет необязательное значение по границам; 0 — граница не задана.
+func inRange[T int | Money](v *T, from, to T) bool {
+	if from == 0 && to == 0 {
+		return true
+	}
+	if v == nil {
+		return false
+	}
+	return (from == 0 || *v >= from) && (to == 0 || *v <= to)
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// IdempotencyKey — ключ Idempotency-Key в пределах Scope (автор, метод и маршрут).
+// Response == nil, пока первый запрос с этим ключом выполняется.
+type IdempotencyKey struct {
+	Scope       string
+	Key         string
+	RequestHash string
+	Response    *StoredResponse
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// StoredResponse — сохранённый ответ, который повторяется для повторов запроса.
+type StoredResponse struct {
+	Status  int
+	Headers map[string]string
+	Body    []byte
+}
+
+// IdempotentRequest — запрос с Idempotency-Key; Query и Body сравниваются с первым запросом по хешу.
+type IdempotentRequest struct {
+	Scope string
+	Key   string
+	Query string
+	Body  []byte
+}
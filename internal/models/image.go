@@ -0,0 +1,68 @@
+package models
+
+import (
+	"net/url"
+	"time"
+)
+
+// CarImage — фотография машины. Файлы оригинала и миниатюр лежат в хранилище объектов,
+// в базе — их описание и место в галерее.
+type CarImage struct {
+	ID          string
+	CarID       string
+	Position    int // место в галерее машины, с 0
+	ContentType string
+	Size        int64 // размер оригинала в байтах
+	Width       int
+	Height      int
+	Thumbnails  []string // имена размеров миниатюр, построенных при загрузке
+	CreatedAt   time.Time
+}
+
+// CarImageResponse — фотография в ответе API со ссылками на оригинал и миниатюры.
+type CarImageResponse struct {
+	ID          string            `json:"id"`
+	Position    int               `json:"position"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	URL         string            `json:"url"`
+	Thumbnails  map[string]string `json:"thumbnails,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// ImageOrderRequest — тело PUT /cars/:id/images/order: все фотографии машины в новом порядке.
+type ImageOrderRequest struct {
+	CarID string   `json:"-" validate:"required,uuid4"`
+	IDs   []string `json:"ids" validate:"required,min=1,dive,uuid4"`
+}
+
+func NewCarImageResponse(img CarImage) CarImageResponse {
+	u := "/api/v1/cars/" + img.CarID + "/images/" + img.ID
+	resp := CarImageResponse{
+		ID:          img.ID,
+		Position:    img.Position,
+		ContentType: img.ContentType,
+		Size:        img.Size,
+		Width:       img.Width,
+		Height:      img.Height,
+		URL:         u,
+		CreatedAt:   img.CreatedAt,
+	}
+	if len(img.Thumbnails) > 0 {
+		resp.Thumbnails = make(map[string]string, len(img.Thumbnails))
+		for _, name := range img.Thumbnails {
+			resp.Thumbnails[name] = u + "?size=" + url.QueryEscape(name)
+		}
+	}
+	return resp
+}
+
+func ToImageResponse(images []CarImage) []CarImageResponse {
+	response := make([]CarImageResponse, len(images))
+	for i, img := range images {
+		response[i] = NewCarImageResponse(img)
+	}
+	return response
+}
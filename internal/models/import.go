@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Форматы файла импорта.
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// Статусы строки импорта.
+const (
+	ImportRowAccepted = "accepted"
+	ImportRowRejected = "rejected"
+)
+
+// CarImport — сводка импорта. При DryRun машины не создаются, но отчёт сохраняется.
+type CarImport struct {
+	ID        string    `json:"id"`
+	Format    string    `json:"format"`
+	DryRun    bool      `json:"dry_run"`
+	Total     int       `json:"total"`
+	Accepted  int       `json:"accepted"`
+	Rejected  int       `json:"rejected"`
+	Actor     string    `json:"actor,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ImportRow — строка отчёта: исходные значения, итог и причина отклонения.
+// Парсер заполняет Reason для отклонённых строк; Status и CarID выставляет хранилище.
+// VIN и атрибуты переходят в машину, но в отчёт не попадают.
+type ImportRow struct {
+	Line       int           `json:"line"`
+	Status     string        `json:"status"`
+	CarID      string        `json:"car_id,omitempty"`
+	Brand      string        `json:"brand"`
+	Model      string        `json:"model"`
+	Year       int           `json:"year"`
+	Reason     string        `json:"reason,omitempty"`
+	VIN        string        `json:"-"`
+	Attributes CarAttributes `json:"-"`
+}
+
+// ImportedCar — машина в том виде, в каком её записала принятая строка Line импорта.
+type ImportedCar struct {
+	Line int
+	Car  Car
+}
+
+type ImportRequest struct {
+	// ID — заранее выбранный ID импорта (у фоновой задачи он совпадает с ID задачи); пусто — новый.
+	ID     string
+	Format string
+	DryRun bool
+}
+
+// Car — машина, которую создаёт принятая строка импорта.
+func (r ImportRow) Car(createdAt time.Time) Car {
+	car := Car{ID: r.CarID, Brand: r.Brand, Model: r.Model, Year: r.Year, VIN: r.VIN, Version: 1,
+		CreatedAt: createdAt, CarAttributes: r.Attributes}
+	if car.Status == "" {
+		car.Status = CarStatusAvailable
+	}
+	return car
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Виды фоновых задач.
+const (
+	JobKindImport = "import"
+	JobKindExport = "export"
+)
+
+// Статусы задачи: queued → running → succeeded | failed | canceled.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCanceled  = "canceled"
+)
+
+// Job — фоновая задача. Params и Result — JSON, формат которого зависит от Kind.
+// Attempts растёт при каждом захвате задачи воркером и служит токеном аренды:
+// продлить или завершить задачу может только воркер с последней попыткой.
+type Job struct {
+	ID              string
+	Kind            string
+	Status          string
+	Params          json.RawMessage
+	Result          json.RawMessage
+	Error           string
+	Processed       int64
+	Total           int64
+	Attempts        int
+	CancelRequested bool
+	LockedUntil     *time.Time
+	Actor           string
+	RequestID       string
+	CreatedAt       time.Time
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+}
+
+func (j Job) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCanceled
+}
+
+// JobProgress — прогресс, который воркер сохраняет при продлении аренды.
+type JobProgress struct {
+	Processed int64
+	Total     int64
+}
+
+// JobResult — итог выполнения, который сохраняет FinishJob.
+type JobResult struct {
+	Status string
+	JobProgress
+	Result json.RawMessage
+	Error  string
+}
+
+// ImportJobParams — параметры фонового импорта; файл лежит в хранилище файлов задач.
+type ImportJobParams struct {
+	Format string `json:"format"`
+	DryRun bool   `json:"dry_run"`
+}
+
+type ExportJobParams struct {
+	Format string    `json:"format"`
+	Filter CarFilter `json:"filter"`
+}
+
+// ExportJobResult — итог фоновой выгрузки; сам файл отдаётся по /jobs/:id/result.
+type ExportJobResult struct {
+	Format   string `json:"format"`
+	Filename string `json:"filename"`
+	Rows     int64  `json:"rows"`
+	Bytes    int64  `json:"bytes"`
+}
+
+type JobResponse struct {
+	ID              string          `json:"id"`
+	Kind            string          `json:"kind"`
+	Status          string          `json:"status"`
+	Processed       int64           `json:"processed"`
+	Total           int64           `json:"total,omitempty"`
+	Attempts        int             `json:"attempts"`
+	CancelRequested bool            `json:"cancel_requested,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	Result          json.RawMessage `json:"result,omitempty"`
+	ResultURL       string          `json:"result_url,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	StartedAt       *time.Time      `json:"started_at,omitempty"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty"`
+}
+
+func NewJobResponse(j Job) JobResponse {
+	return JobResponse{
+		ID:              j.ID,
+		Kind:            j.Kind,
+		Status:          j.Status,
+		Processed:       j.Processed,
+		Total:           j.Total,
+		Attempts:        j.Attempts,
+		CancelRequested: j.CancelRequested && !j.Finished(),
+		Error:           j.Error,
+		Result:          j.Result,
+		CreatedAt:       j.CreatedAt,
+		StartedAt:       j.StartedAt,
+		FinishedAt:      j.FinishedAt,
+	}
+}
@@ -1,40 +1,96 @@
 package models
 
 import (
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/jsonpatch"
+	"github.com/pavel97go/service-cars/internal/vin"
 )
 
 var validate *validator.Validate
 
+// carNameRe — марка или модель: слова из букв и цифр через одиночный пробел, дефис или апостроф
+// ("Mercedes-Benz", "Land Rover", "X5").
+var carNameRe = regexp.MustCompile(`^[\p{L}\p{N}]+(?:[ '-][\p{L}\p{N}]+)*$`)
+
 func Validate() {
 	validate = validator.New()
+	validate.RegisterCustomTypeFunc(optionalValue, Optional[string]{}, Optional[int]{}, Optional[Money]{})
+	_ = validate.RegisterValidation("vin", func(fl validator.FieldLevel) bool {
+		return vin.Valid(fl.Field().String())
+	})
+	_ = validate.RegisterValidation("carname", func(fl validator.FieldLevel) bool {
+		return carNameRe.MatchString(fl.Field().String())
+	})
 }
 
 func ValidateStruct(v interface{}) error {
-	return validate.Struct(v)
+	if err := validate.Struct(v); err != nil {
+		return fmt.Errorf("%w: %s", apperr.ErrInvalidInput, err.Error())
+	}
+	return nil
 }
 
 type CreateCarRequest struct {
-	Brand string `json:"brand" validate:"required,alphaunicode,min=1,max=50"`
-	Model string `json:"model" validate:"required,alphaunicode,min=1,max=50"`
+	Brand string `json:"brand" validate:"required,carname,min=1,max=50"`
+	Model string `json:"model" validate:"required,carname,min=1,max=50"`
 	Year  int    `json:"year" validate:"required,gte=1886"`
+	// VIN необязателен; перед проверкой приводится к верхнему регистру (vin.Normalize).
+	VIN string `json:"vin,omitempty" validate:"omitempty,vin"`
+	CarAttributes
+	// PrefillFromVIN — заполнить пустые Brand и Year расшифровкой VIN (параметр prefill_from_vin).
+	PrefillFromVIN bool `json:"-"`
 }
 
+// UpdateCarRequest — частичное обновление по RFC 7396 (JSON Merge Patch):
+// отсутствующее поле не меняется, null очищает поле, значение заменяет его.
 type UpdateCarRequest struct {
-	ID    string `json:"id" validate:"required,uuid4"`
-	Brand string `json:"brand" validate:"omitempty,min=1,max=50"`
-	Model string `json:"model" validate:"omitempty,min=1,max=50"`
-	Year  int    `json:"year" validate:"omitempty,gte=1886"`
+	ID    string           `json:"id" validate:"required,uuid4"`
+	Brand Optional[string] `json:"brand" validate:"omitempty,carname,min=1,max=50"`
+	Model Optional[string] `json:"model" validate:"omitempty,carname,min=1,max=50"`
+	Year  Optional[int]    `json:"year" validate:"omitempty,gte=1886"`
+	// VIN: null удаляет номер у машины.
+	VIN Optional[string] `json:"vin" validate:"omitempty,vin"`
+	UpdateCarAttributes
+	// IfMatch — ожидаемые версии из заголовка If-Match, пусто — без условия.
+	IfMatch []int `json:"-"`
+}
+
+// ReplaceCarRequest — полная замена записи (PUT), все поля обязательны.
+type ReplaceCarRequest struct {
+	ID    string `json:"-" validate:"required,uuid4"`
+	Brand string `json:"brand" validate:"required,carname,min=1,max=50"`
+	Model string `json:"model" validate:"required,carname,min=1,max=50"`
+	Year  int    `json:"year" validate:"required,gte=1886"`
+	VIN   string `json:"vin,omitempty" validate:"omitempty,vin"`
+	CarAttributes
+	IfMatch []int `json:"-"`
+}
+
+// PatchOperation — операция JSON Patch (RFC 6902).
+type PatchOperation = jsonpatch.Operation
+
+type JSONPatchRequest struct {
+	ID         string           `validate:"required,uuid4"`
+	Operations []PatchOperation `validate:"required,min=1"`
+	IfMatch    []int
 }
 
 type Car struct {
-	ID        string    `db:"id"`
-	Brand     string    `db:"brand"`
-	Model     string    `db:"model"`
-	Year      int       `db:"year"`
-	CreatedAt time.Time `db:"created_at"`
+	ID    string `db:"id"`
+	Brand string `db:"brand"`
+	Model string `db:"model"`
+	Year  int    `db:"year"`
+	VIN   string `db:"vin"` // пустая строка — VIN не указан
+	CarAttributes
+	Version   int        `db:"version"`
+	CreatedAt time.Time  `db:"created_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
 }
 
 type CarResponse struct {
@@ -42,17 +98,31 @@ type CarResponse struct {
 	Brand string `json:"brand"`
 	Model string `json:"model"`
 	Year  int    `json:"year"`
+	VIN   string `json:"vin,omitempty"`
+	CarAttributes
+	Version   int        `json:"version"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Images — фотографии в порядке галереи; заполняются только там, где usecase их загружает.
+	Images []CarImageResponse `json:"images,omitempty"`
+}
+
+func NewCarResponse(car Car) CarResponse {
+	return CarResponse{
+		ID:            car.ID,
+		Brand:         car.Brand,
+		Model:         car.Model,
+		Year:          car.Year,
+		VIN:           car.VIN,
+		CarAttributes: car.CarAttributes,
+		Version:       car.Version,
+		DeletedAt:     car.DeletedAt,
+	}
 }
 
 func ToResponse(dbCars []Car) []CarResponse {
 	response := make([]CarResponse, len(dbCars))
 	for i, car := range dbCars {
-		response[i] = CarResponse{
-			ID:    car.ID,
-			Brand: car.Brand,
-			Model: car.Model,
-			Year:  car.Year,
-		}
+		response[i] = NewCarResponse(car)
 	}
 	return response
 }
@@ -60,9 +130,10 @@ func ToResponse(dbCars []Car) []CarResponse {
 func UpdatedCarDTO(updatedCars UpdateCarRequest) Car {
 	updated := Car{}
 	updated.ID = updatedCars.ID
-	updated.Brand = updatedCars.Brand
-	updated.Model = updatedCars.Model
-	updated.Year = updatedCars.Year
+	updated.Brand = updatedCars.Brand.Value
+	updated.Model = updatedCars.Model.Value
+	updated.Year = updatedCars.Year.Value
+	updated.VIN = updatedCars.VIN.Value
 
 	return updated
 }
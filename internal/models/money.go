@@ -0,0 +1,115 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money — денежная сумма в сотых долях валюты (копейки, центы), чтобы цена вроде 19999.99
+// хранилась и сравнивалась точно. В JSON, CSV и параметрах запроса — десятичное число
+// с не более чем двумя знаками после точки, в PostgreSQL — NUMERIC(14,2).
+type Money int64
+
+// moneyScale — сколько сотых долей в единице валюты.
+const moneyScale = 100
+
+// MaxMoney — наибольшая сумма, которая помещается в NUMERIC(14,2).
+const MaxMoney Money = 99999999999999
+
+var errMoneyFormat = errors.New("must be a decimal number with at most 2 decimal places")
+
+// ParseMoney разбирает десятичную запись суммы: "19999.99", "900000", "0.5".
+// Экспонента и больше двух знаков после точки — ошибка.
+func ParseMoney(s string) (Money, error) {
+	neg := strings.HasPrefix(s, "-")
+	whole, frac, hasFrac := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if whole == "" || len(frac) > 2 || hasFrac && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return 0, errMoneyFormat
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > int64(MaxMoney)/moneyScale {
+		return 0, fmt.Errorf("must be at most %s", MaxMoney)
+	}
+	var cents int64
+	if frac != "" {
+		cents, _ = strconv.ParseInt(frac+strings.Repeat("0", 2-len(frac)), 10, 64)
+	}
+	m := Money(units*moneyScale + cents)
+	if neg {
+		m = -m
+	}
+	return m, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String возвращает сумму без лишних нулей: 900000, 19999.99, 19999.90.
+func (m Money) String() string {
+	u := uint64(m)
+	sign := ""
+	if m < 0 {
+		u, sign = -u, "-"
+	}
+	s := sign + strconv.FormatUint(u/moneyScale, 10)
+	if cents := u % moneyScale; cents != 0 {
+		s += fmt.Sprintf(".%02d", cents)
+	}
+	return s
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(b []byte) error {
+	v, err := ParseMoney(string(b))
+	if err != nil {
+		return fmt.Errorf("amount %s %w", b, err)
+	}
+	*m = v
+	return nil
+}
+
+// Value передаёт сумму драйверу точной десятичной строкой, без двоичной плавающей точки:
+// PostgreSQL разбирает её в NUMERIC(14,2), SQLite приводит по аффинности колонки.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan принимает NUMERIC от pgx (строка) и число из SQLite, где целые суммы хранятся как INTEGER,
+// а дробные — как REAL.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		*m = Money(v * moneyScale)
+	case float64:
+		*m = Money(math.Round(v * moneyScale))
+	case string:
+		return m.scanText(v)
+	case []byte:
+		return m.scanText(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	return nil
+}
+
+func (m *Money) scanText(s string) error {
+	v, err := ParseMoney(s)
+	if err != nil {
+		return fmt.Errorf("scan money %q: %w", s, err)
+	}
+	*m = v
+	return nil
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	for in, want := range map[string]Money{
+		"19999.99": 1999999, "900000": 90000000, "0.5": 50, "0.05": 5, "-1.25": -125, "999999999999.99": MaxMoney,
+	} {
+		got, err := ParseMoney(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseMoney(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", ".", "1.", ".5", "1.005", "1e3", "1,5", "abc", "--1", " 1", "1000000000000"} {
+		if _, err := ParseMoney(in); err == nil {
+			t.Fatalf("ParseMoney(%q) must fail", in)
+		}
+	}
+}
+
+func TestMoney_JSONAndScan(t *testing.T) {
+	var a CarAttributes
+	if err := json.Unmarshal([]byte(`{"price": 19999.9, "currency": "RUB"}`), &a); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	out, err := json.Marshal(a)
+	if err != nil || string(out) != `{"price":19999.90,"currency":"RUB"}` {
+		t.Fatalf("marshal = %s, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"price": 1.999}`), &a); err == nil {
+		t.Fatal("more than 2 decimal places must fail")
+	}
+
+	// PostgreSQL отдаёт NUMERIC строкой, SQLite — целым или дробным числом
+	for _, tc := range []struct {
+		src  any
+		want Money
+	}{{"900000.00", 90000000}, {[]byte("0.10"), 10}, {int64(900000), 90000000}, {19999.99, 1999999}} {
+		var m Money
+		if err := m.Scan(tc.src); err != nil || m != tc.want {
+			t.Fatalf("Scan(%v) = %d, %v; want %d", tc.src, m, err, tc.want)
+		}
+	}
+	if v, _ := Money(1999999).Value(); v != "19999.99" {
+		t.Fatalf("Value = %v", v)
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Optional различает три состояния поля в PATCH-запросе (RFC 7396):
+// поле отсутствует (Set=false), явно null (Set=true, Null=true) и задано значением.
+type Optional[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// Some возвращает заданное значение.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{Set: true, Value: v}
+}
+
+// Null возвращает явный null.
+func Null[T any]() Optional[T] {
+	return Optional[T]{Set: true, Null: true}
+}
+
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	o.Set = true
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		o.Null = true
+		var zero T
+		o.Value = zero
+		return nil
+	}
+	o.Null = false
+	return json.Unmarshal(b, &o.Value)
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// optionalValue отдаёт валидатору значение поля либо nil, если его нет,
+// чтобы теги omitempty/min/max работали так же, как для обычных полей.
+func optionalValue(v reflect.Value) interface{} {
+	if !v.FieldByName("Set").Bool() || v.FieldByName("Null").Bool() {
+		return nil
+	}
+	return v.FieldByName("Value").Interface()
+}
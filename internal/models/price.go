@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// PricePoint — запись истории цены машины. Price и Currency nil и пустая строка, если цену сняли.
+type PricePoint struct {
+	ID        int64     `json:"id"`
+	CarID     string    `json:"car_id"`
+	Price     *Money    `json:"price"`
+	Currency  string    `json:"currency,omitempty"`
+	Actor     string    `json:"actor"`
+	RequestID string    `json:"request_id"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ValuationQuery — машина, для которой оценивается рыночная цена. Mileage nil — пробег не учитывается,
+// пустая Currency — валюта, в которой выставлено больше всего похожих машин.
+type ValuationQuery struct {
+	Brand    string `validate:"required,max=50"`
+	Model    string `validate:"required,max=50"`
+	Year     int    `validate:"required,gte=1886"`
+	Mileage  *int   `validate:"omitempty,gte=0,lte=5000000"`
+	Currency string
+}
+
+// Valuation — оценка цены по похожим машинам в продаже: взвешенная медиана и перцентили.
+type Valuation struct {
+	Brand       string `json:"brand"`
+	Model       string `json:"model"`
+	Year        int    `json:"year"`
+	Mileage     *int   `json:"mileage,omitempty"`
+	Currency    string `json:"currency"`
+	Comparables int    `json:"comparables"`
+	Median      Money  `json:"median"`
+	P10         Money  `json:"p10"`
+	P25         Money  `json:"p25"`
+	P75         Money  `json:"p75"`
+	P90         Money  `json:"p90"`
+	Min         Money  `json:"min"`
+	Max         Money  `json:"max"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Статусы брони. Активная бронь у машины одна; остальные статусы конечные.
+const (
+	ReservationActive   = "active"
+	ReservationReleased = "released"
+	ReservationSold     = "sold"
+	ReservationExpired  = "expired"
+)
+
+// Reservation — временная бронь машины за покупателем. Пока бронь активна, машина в статусе reserved.
+type Reservation struct {
+	ID        string     `json:"id"`
+	CarID     string     `json:"car_id"`
+	Customer  string     `json:"customer"`
+	Note      string     `json:"note,omitempty"`
+	Status    string     `json:"status"`
+	Actor     string     `json:"actor"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+}
+
+// ReservationRequest — тело POST /cars/:id/reservations. TTLSeconds 0 — срок брони по умолчанию.
+type ReservationRequest struct {
+	CarID      string `json:"-" validate:"required,uuid4"`
+	Customer   string `json:"customer" validate:"required,max=100"`
+	Note       string `json:"note" validate:"max=500"`
+	TTLSeconds int    `json:"ttl_seconds" validate:"gte=0"`
+}
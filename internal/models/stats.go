@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// Группировки статистики (параметр group_by).
+const (
+	StatsGroupBrand  = "brand"
+	StatsGroupModel  = "model"
+	StatsGroupYear   = "year"
+	StatsGroupDecade = "decade"
+)
+
+// Шаг ряда созданных машин (параметр period). Неделя начинается с понедельника, границы — в UTC.
+const (
+	StatsPeriodDay   = "day"
+	StatsPeriodWeek  = "week"
+	StatsPeriodMonth = "month"
+)
+
+// StatsQuery — запрос GET /cars/stats. Filter ограничивает все показатели,
+// From и To (полуинтервал [From, To)) — только ряд Created.
+type StatsQuery struct {
+	Filter  CarFilter
+	GroupBy string
+	Period  string
+	From    time.Time
+	To      time.Time
+}
+
+// StatsGroup — число машин с одним значением группировки. Key — марка, модель,
+// год или первый год десятилетия ("1990"); для group_by=model Brand — марка модели.
+type StatsGroup struct {
+	Brand string `json:"brand,omitempty"`
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// StatsBucket — число машин, созданных за период, начинающийся в Start.
+type StatsBucket struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
+}
+
+// CarStats — ответ GET /cars/stats. Год — по неудалённым машинам под фильтром;
+// без машин MinYear, MaxYear и AvgYear — null.
+type CarStats struct {
+	Total   int64         `json:"total"`
+	MinYear *int          `json:"min_year"`
+	MaxYear *int          `json:"max_year"`
+	AvgYear *float64      `json:"avg_year"`
+	GroupBy string        `json:"group_by"`
+	Groups  []StatsGroup  `json:"groups"`
+	Period  string        `json:"period"`
+	From    time.Time     `json:"from"`
+	To      time.Time     `json:"to"`
+	Created []StatsBucket `json:"created"`
+}
+
+// StatsBucketStart возвращает начало периода period (в UTC), в который попадает t.
+func StatsBucketStart(t time.Time, period string) time.Time {
+	y, m, d := t.UTC().Date()
+	switch period {
+	case StatsPeriodWeek:
+		day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case StatsPeriodMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextStatsBucket возвращает начало периода, следующего за начинающимся в start.
+func NextStatsBucket(start time.Time, period string) time.Time {
+	switch period {
+	case StatsPeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case StatsPeriodMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// WebhookSubscription — подписка партнёра на доменные события.
+// Пустой EventTypes означает подписку на все события.
+type WebhookSubscription struct {
+	ID         string
+	URL        string
+	EventTypes []string
+	Secret     string
+	Active     bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,http_url,max=2000"`
+	EventTypes []string `json:"event_types" validate:"omitempty,dive,oneof=CarCreated CarUpdated CarDeleted CarRestored"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=200"`
+	Active     *bool    `json:"active"`
+}
+
+// ReplaceWebhookRequest — PUT: пустой Secret оставляет текущий секрет.
+type ReplaceWebhookRequest struct {
+	ID         string   `json:"-" validate:"required,uuid"`
+	URL        string   `json:"url" validate:"required,http_url,max=2000"`
+	EventTypes []string `json:"event_types" validate:"omitempty,dive,oneof=CarCreated CarUpdated CarDeleted CarRestored"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=200"`
+	Active     bool     `json:"active"`
+}
+
+// WebhookResponse не содержит секрет: он возвращается один раз, при создании.
+type WebhookResponse struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func NewWebhookResponse(s WebhookSubscription) WebhookResponse {
+	types := s.EventTypes
+	if types == nil {
+		types = []string{}
+	}
+	return WebhookResponse{
+		ID:         s.ID,
+		URL:        s.URL,
+		EventTypes: types,
+		Active:     s.Active,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+// WebhookDelivery — отправка одного события одной подписке; Payload — тело запроса.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastError      string          `json:"last_error,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// DeliveryResult — итог попытки отправки, который сохраняет RecordDeliveryAttempt.
+type DeliveryResult struct {
+	Status         string
+	NextAttemptAt  time.Time
+	ResponseStatus int
+	Error          string
+}
+
+type DeliveryPage struct {
+	Items  []WebhookDelivery `json:"items"`
+	Total  int               `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+type DeliveryQuery struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending succeeded dead"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=200"`
+	Offset int    `query:"offset" validate:"omitempty,min=0"`
+}
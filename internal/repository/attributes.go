@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// carAttrColumns — колонки коммерческих атрибутов (миграция 00012_car_attributes)
+// в порядке attrArgs и attrScan.dest. Общие для PostgreSQL и SQLite.
+const carAttrColumns = `mileage, color, body_type, fuel_type, transmission, engine_volume, engine_power, price, currency, condition, status`
+
+// carAttrColumnNames — те же колонки списком, для COPY.
+var carAttrColumnNames = strings.Split(carAttrColumns, ", ")
+
+// attrArgs возвращает значения атрибутов для записи; пустые строки пишутся как NULL.
+func attrArgs(a models.CarAttributes) []any {
+	return []any{a.Mileage, nullString(a.Color), nullString(a.BodyType), nullString(a.FuelType),
+		nullString(a.Transmission), a.EngineVolume, a.EnginePower, a.Price, nullString(a.Currency),
+		nullString(a.Condition), defaultStatus(a.Status)}
+}
+
+// attrScan принимает NULL в строковых атрибутах при чтении.
+type attrScan struct {
+	color, bodyType, fuelType, transmission, currency, condition *string
+}
+
+func (s *attrScan) dest(a *models.CarAttributes) []any {
+	return []any{&a.Mileage, &s.color, &s.bodyType, &s.fuelType, &s.transmission, &a.EngineVolume,
+		&a.EnginePower, &a.Price, &s.currency, &s.condition, &a.Status}
+}
+
+func (s *attrScan) apply(a *models.CarAttributes) {
+	for _, f := range []struct {
+		dst *string
+		src *string
+	}{
+		{&a.Color, s.color}, {&a.BodyType, s.bodyType}, {&a.FuelType, s.fuelType},
+		{&a.Transmission, s.transmission}, {&a.Currency, s.currency}, {&a.Condition, s.condition},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+}
+
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// defaultStatus — статус новой машины, если вызывающий его не задал.
+func defaultStatus(status string) string {
+	if status == "" {
+		return models.CarStatusAvailable
+	}
+	return status
+}
+
+// cloneAttributes копирует числовые атрибуты, чтобы копия машины не делила их с оригиналом.
+func cloneAttributes(a models.CarAttributes) models.CarAttributes {
+	a.Mileage = clonePtr(a.Mileage)
+	a.EngineVolume = clonePtr(a.EngineVolume)
+	a.EnginePower = clonePtr(a.EnginePower)
+	a.Price = clonePtr(a.Price)
+	return a
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	n := *p
+	return &n
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/reqctx"
+)
+
+// defaultAuditLimit применяется, если в фильтре не задан Limit.
+const defaultAuditLimit = 50
+
+// newAuditEntry собирает запись аудита: снимки до/после, diff по полям,
+// автора и идентификатор запроса из ctx. before или after может быть nil.
+func newAuditEntry(ctx context.Context, action, carID string, before, after *models.Car) (models.AuditEntry, error) {
+	entry := models.AuditEntry{
+		CarID:     carID,
+		Action:    action,
+		Actor:     reqctx.Actor(ctx),
+		RequestID: reqctx.RequestID(ctx),
+	}
+	beforeFields, err := snapshot(before, &entry.Before)
+	if err != nil {
+		return models.AuditEntry{}, err
+	}
+	afterFields, err := snapshot(after, &entry.After)
+	if err != nil {
+		return models.AuditEntry{}, err
+	}
+	entry.Diff = diffFields(beforeFields, afterFields)
+	return entry, nil
+}
+
+func snapshot(car *models.Car, raw *json.RawMessage) (map[string]any, error) {
+	if car == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(models.NewCarSnapshot(*car))
+	if err != nil {
+		return nil, err
+	}
+	*raw = b
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// diffFields возвращает изменившиеся поля; version не включается — она меняется при каждой записи.
+func diffFields(before, after map[string]any) map[string]models.AuditChange {
+	diff := map[string]models.AuditChange{}
+	for k, to := range after {
+		if from := before[k]; !reflect.DeepEqual(from, to) {
+			diff[k] = models.AuditChange{From: from, To: to}
+		}
+	}
+	for k, from := range before {
+		if _, ok := after[k]; !ok {
+			diff[k] = models.AuditChange{From: from, To: nil}
+		}
+	}
+	delete(diff, "version")
+	return diff
+}
+
+// carAsOf собирает машину из снимка after последней записи аудита до нужного момента.
+// ok=false, если машина в этот момент не существовала или была удалена.
+func carAsOf(carID string, after []byte, createdAt time.Time) (car models.Car, ok bool, err error) {
+	if after == nil {
+		return models.Car{}, false, nil
+	}
+	var snap models.CarSnapshot
+	if err := json.Unmarshal(after, &snap); err != nil {
+		return models.Car{}, false, fmt.Errorf("parse audit snapshot of %s: %w", carID, err)
+	}
+	if snap.DeletedAt != nil {
+		return models.Car{}, false, nil
+	}
+	// в снимках до появления атрибутов статуса нет
+	snap.Status = defaultStatus(snap.Status)
+	return models.Car{
+		ID:            carID,
+		Brand:         snap.Brand,
+		Model:         snap.Model,
+		Year:          snap.Year,
+		VIN:           snap.VIN,
+		CarAttributes: snap.CarAttributes,
+		Version:       snap.Version,
+		CreatedAt:     createdAt,
+	}, true, nil
+}
+
+func auditLimit(limit int) int {
+	if limit <= 0 {
+		return defaultAuditLimit
+	}
+	return limit
+}
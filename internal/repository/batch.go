@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// plannedMutation — операция пакета, проверенная в памяти: состояние до/после
+// для записи в хранилище и аудит либо ошибка.
+type plannedMutation struct {
+	action string
+	before *models.Car
+	after  *models.Car
+	err    error
+}
+
+// planBatch проверяет операции по порядку на состояниях current (затронутые машины
+// по ID, заблокированные вызывающим) и вычисляет результат каждой. Успешная операция
+// меняет current, поэтому следующие операции пакета видят её результат.
+// vins — владельцы VIN из пакета среди машин вне корзины (VIN → ID), тоже меняется по ходу.
+// now — время транзакции для created_at и deleted_at.
+func planBatch(ops []models.CarMutation, current map[string]*models.Car, vins map[string]string, now time.Time) []plannedMutation {
+	plan := make([]plannedMutation, len(ops))
+	for i, op := range ops {
+		p := &plan[i]
+		switch op.Op {
+		case models.BatchOpCreate:
+			p.action = models.AuditActionCreate
+			if p.err = checkYear(op.Car.Year); p.err != nil {
+				continue
+			}
+			if p.err = checkVIN(vins, op.Car.VIN, ""); p.err != nil {
+				continue
+			}
+			after := op.Car
+			after.Status = defaultStatus(after.Status)
+			after.ID = uuid.NewString()
+			after.Version = 1
+			after.CreatedAt = now
+			after.DeletedAt = nil
+			p.after = &after
+			current[after.ID] = &after
+			takeVIN(vins, &after)
+		case models.BatchOpUpdate, models.BatchOpDelete:
+			before, ok := current[op.Car.ID]
+			if !ok || before.DeletedAt != nil {
+				p.err = apperr.ErrNotFound
+				continue
+			}
+			if op.Car.Version != 0 && op.Car.Version != before.Version {
+				p.err = fmt.Errorf("%w: version mismatch", apperr.ErrConflict)
+				continue
+			}
+			after := *before
+			after.Version++
+			if op.Op == models.BatchOpUpdate {
+				p.action = models.AuditActionUpdate
+				if p.err = checkYear(op.Car.Year); p.err != nil {
+					continue
+				}
+				if p.err = checkVIN(vins, op.Car.VIN, before.ID); p.err != nil {
+					continue
+				}
+				after.Brand = op.Car.Brand
+				after.Model = op.Car.Model
+				after.Year = op.Car.Year
+				after.VIN = op.Car.VIN
+				after.CarAttributes = op.Car.CarAttributes
+				if after.Status == "" {
+					after.Status = before.Status
+				}
+				if p.err = models.CheckManualStatus(before.Status, after.Status); p.err != nil {
+					continue
+				}
+			} else {
+				p.action = models.AuditActionDelete
+				deletedAt := now
+				after.DeletedAt = &deletedAt
+			}
+			p.before = before
+			p.after = &after
+			current[after.ID] = &after
+			releaseVIN(vins, before)
+			takeVIN(vins, &after)
+		default:
+			p.err = fmt.Errorf("%w: unknown batch operation %q", apperr.ErrInvalidInput, op.Op)
+		}
+	}
+	return plan
+}
+
+// batchResults переводит план в результаты. applied=false означает, что атомарный
+// пакет отменён и в хранилище ничего писать не нужно.
+func batchResults(plan []plannedMutation, atomic bool) (results []models.MutationResult, applied bool) {
+	failed := false
+	for _, p := range plan {
+		if p.err != nil {
+			failed = true
+			break
+		}
+	}
+	results = make([]models.MutationResult, len(plan))
+	for i, p := range plan {
+		switch {
+		case p.err != nil:
+			results[i].Err = p.err
+		case atomic && failed:
+			results[i].Err = apperr.ErrAborted
+		default:
+			c := cloneCar(*p.after)
+			results[i].Car = &c
+		}
+	}
+	return results, !(atomic && failed)
+}
+
+// batchCarIDs возвращает ID машин, которые меняют операции update/delete.
+func batchCarIDs(ops []models.CarMutation) []string {
+	seen := make(map[string]bool)
+	ids := make([]string, 0, len(ops))
+	for _, op := range ops {
+		if op.Op == models.BatchOpCreate || seen[op.Car.ID] {
+			continue
+		}
+		seen[op.Car.ID] = true
+		ids = append(ids, op.Car.ID)
+	}
+	return ids
+}
+
+// batchVINs возвращает VIN, которые задают операции create/update.
+func batchVINs(ops []models.CarMutation) []string {
+	vins := make([]string, 0, len(ops))
+	for _, op := range ops {
+		if op.Op != models.BatchOpDelete && op.Car.VIN != "" {
+			vins = append(vins, op.Car.VIN)
+		}
+	}
+	return vins
+}
+
+// checkVIN возвращает apperr.ErrConflict, если vin занят машиной, отличной от id.
+func checkVIN(vins map[string]string, vin, id string) error {
+	if owner, ok := vins[vin]; vin != "" && ok && owner != id {
+		return fmt.Errorf("%w: %s", ErrVINTaken, vin)
+	}
+	return nil
+}
+
+func releaseVIN(vins map[string]string, car *models.Car) {
+	if car.VIN != "" && vins[car.VIN] == car.ID {
+		delete(vins, car.VIN)
+	}
+}
+
+// takeVIN закрепляет VIN за машиной, если она не в корзине.
+func takeVIN(vins map[string]string, car *models.Car) {
+	if car.VIN != "" && car.DeletedAt == nil {
+		vins[car.VIN] = car.ID
+	}
+}
@@ -2,9 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/pavel97go/service-cars/internal/apperr"
@@ -15,17 +20,71 @@ type CarRepo struct {
 	pool *pgxpool.Pool
 }
 
+var (
+	_ CarProvider    = (*CarRepo)(nil)
+	_ AuditProvider  = (*CarRepo)(nil)
+	_ OutboxProvider = (*CarRepo)(nil)
+)
+
 func NewCarRepo(pool *pgxpool.Pool) *CarRepo {
 	return &CarRepo{pool: pool}
 
 }
-func (r *CarRepo) ListCars(ctx context.Context) ([]models.Car, error) {
-	query := `
-		SELECT id, brand, model, year, created_at
-		FROM cars
-		ORDER BY created_at DESC;
-		`
-	rows, err := r.pool.Query(ctx, query)
+
+// ErrVINTaken — VIN уже есть у другой машины вне корзины. Это apperr.ErrConflict,
+// но не конфликт версий: usecase не должен превращать его в 412.
+var ErrVINTaken = fmt.Errorf("%w: vin already exists", apperr.ErrConflict)
+
+// vinIndex — уникальный индекс по VIN (миграция 00011_car_vin).
+const vinIndex = "cars_vin_key"
+
+// mapPgErr переводит нарушение CHECK-ограничения (например, на год) в apperr.ErrInvalidInput,
+// а нарушение уникальности — в apperr.ErrConflict.
+func mapPgErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23514":
+			return fmt.Errorf("%w: %s", apperr.ErrInvalidInput, pgErr.ConstraintName)
+		case "23505":
+			if pgErr.ConstraintName == vinIndex {
+				return ErrVINTaken
+			}
+			return fmt.Errorf("%w: %s", apperr.ErrConflict, pgErr.ConstraintName)
+		}
+	}
+	return err
+}
+
+// carColumns — порядок колонок, который ожидает scanCar.
+const carColumns = `id, brand, model, year, version, created_at, deleted_at, vin, ` + carAttrColumns
+
+func scanCar(row pgx.Row) (models.Car, error) {
+	var (
+		c     models.Car
+		vin   *string
+		attrs attrScan
+	)
+	dest := append([]any{&c.ID, &c.Brand, &c.Model, &c.Year, &c.Version, &c.CreatedAt, &c.DeletedAt, &vin},
+		attrs.dest(&c.CarAttributes)...)
+	err := row.Scan(dest...)
+	if vin != nil {
+		c.VIN = *vin
+	}
+	attrs.apply(&c.CarAttributes)
+	return c, err
+}
+
+// nullVIN превращает пустой VIN в NULL: уникальный индекс не должен срабатывать на машины без VIN.
+func nullVIN(vin string) any {
+	if vin == "" {
+		return nil
+	}
+	return vin
+}
+
+func (r *CarRepo) queryCars(ctx context.Context, query string, args ...any) ([]models.Car, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -34,8 +93,7 @@ func (r *CarRepo) ListCars(ctx context.Context) ([]models.Car, error) {
 	cars := []models.Car{}
 
 	for rows.Next() {
-		var c models.Car
-		err := rows.Scan(&c.ID, &c.Brand, &c.Model, &c.Year, &c.CreatedAt)
+		c, err := scanCar(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -47,18 +105,72 @@ func (r *CarRepo) ListCars(ctx context.Context) ([]models.Car, error) {
 	}
 
 	return cars, nil
+}
 
+func (r *CarRepo) ListCars(ctx context.Context) ([]models.Car, error) {
+	query := `
+		SELECT ` + carColumns + `
+		FROM cars
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC;
+		`
+	return r.queryCars(ctx, query)
 }
 func (r *CarRepo) GetCarByID(ctx context.Context, id string) (*models.Car, error) {
 	const query = `
-		SELECT id, brand, model, year, created_at
+		SELECT ` + carColumns + `
 		FROM cars
-		WHERE id = $1;
+		WHERE id = $1 AND deleted_at IS NULL;
 	`
-	row := r.pool.QueryRow(ctx, query, id)
+	c, err := scanCar(r.pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *CarRepo) GetCarByVIN(ctx context.Context, vin string) (*models.Car, error) {
+	const query = `
+		SELECT ` + carColumns + `
+		FROM cars
+		WHERE vin = $1 AND deleted_at IS NULL;
+	`
+	c, err := scanCar(r.pool.QueryRow(ctx, query, vin))
+	if err == pgx.ErrNoRows {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// inTx выполняет fn в транзакции: изменение машины и запись аудита фиксируются вместе.
+func (r *CarRepo) inTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
 
-	var c models.Car
-	err := row.Scan(&c.ID, &c.Brand, &c.Model, &c.Year, &c.CreatedAt)
+// lockCar блокирует строку до конца транзакции; удалённые записи тоже возвращаются.
+func lockCar(ctx context.Context, tx pgx.Tx, id string) (*models.Car, error) {
+	const query = `
+		SELECT ` + carColumns + `
+		FROM cars
+		WHERE id = $1
+		FOR UPDATE;
+	`
+	c, err := scanCar(tx.QueryRow(ctx, query, id))
 	if err == pgx.ErrNoRows {
 		return nil, apperr.ErrNotFound
 	}
@@ -68,43 +180,528 @@ func (r *CarRepo) GetCarByID(ctx context.Context, id string) (*models.Car, error
 	return &c, nil
 }
 
+// recordChange пишет запись аудита, доменное событие и изменение цены в транзакции изменения.
+func recordChange(ctx context.Context, tx pgx.Tx, action, carID string, before, after *models.Car) error {
+	if err := writeAudit(ctx, tx, action, carID, before, after); err != nil {
+		return err
+	}
+	if err := writePrice(ctx, tx, before, after); err != nil {
+		return err
+	}
+	return writeOutbox(ctx, tx, action, after)
+}
+
+func writeOutbox(ctx context.Context, tx pgx.Tx, action string, car *models.Car) error {
+	evt, err := newOutboxEvent(ctx, action, car)
+	if err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO car_outbox (event_type, car_id, actor, request_id, payload)
+		VALUES ($1, $2, $3, $4, $5);
+	`
+	_, err = tx.Exec(ctx, query, evt.Type, evt.CarID, evt.Actor, evt.RequestID, evt.Payload)
+	return err
+}
+
+func writeAudit(ctx context.Context, tx pgx.Tx, action, carID string, before, after *models.Car) error {
+	entry, err := newAuditEntry(ctx, action, carID, before, after)
+	if err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO car_audit (car_id, action, actor, request_id, before, after, diff)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);
+	`
+	_, err = tx.Exec(ctx, query, entry.CarID, entry.Action, entry.Actor, entry.RequestID,
+		entry.Before, entry.After, entry.Diff)
+	return err
+}
+
 func (r *CarRepo) InsertCar(ctx context.Context, newCar *models.Car) error {
 	const query = `
-		INSERT INTO cars (brand, model, year)
-		VALUES ($1, $2, $3)
-		RETURNING id, created_at;
+		INSERT INTO cars (brand, model, year, vin, ` + carAttrColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, version, created_at;
 	`
-	return r.pool.QueryRow(ctx, query, newCar.Brand, newCar.Model, newCar.Year).
-		Scan(&newCar.ID, &newCar.CreatedAt)
+	newCar.Status = defaultStatus(newCar.Status)
+	args := append([]any{newCar.Brand, newCar.Model, newCar.Year, nullVIN(newCar.VIN)}, attrArgs(newCar.CarAttributes)...)
+	return r.inTx(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, args...).
+			Scan(&newCar.ID, &newCar.Version, &newCar.CreatedAt)
+		if err != nil {
+			return mapPgErr(err)
+		}
+		newCar.DeletedAt = nil
+		return recordChange(ctx, tx, models.AuditActionCreate, newCar.ID, nil, newCar)
+	})
 }
 
+// UpdateCar обновляет запись, только если её версия равна c.Version,
+// и записывает в c новую версию. При несовпадении версии возвращает apperr.ErrConflict.
 func (r *CarRepo) UpdateCar(ctx context.Context, c *models.Car) error {
 	query := `
 	UPDATE cars
-	SET brand=$2,model=$3,year=$4
-	WHERE id=$1;
+	SET brand=$2,model=$3,year=$4,vin=$5,
+		mileage=$6,color=$7,body_type=$8,fuel_type=$9,transmission=$10,engine_volume=$11,
+		engine_power=$12,price=$13,currency=$14,condition=$15,status=$16,
+		version=version+1
+	WHERE id=$1
+	RETURNING ` + carColumns + `;
 	`
-	ct, err := r.pool.Exec(ctx, query, c.ID, c.Brand, c.Model, c.Year)
+	var updated models.Car
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		before, err := lockCar(ctx, tx, c.ID)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt != nil {
+			return apperr.ErrNotFound
+		}
+		if before.Version != c.Version {
+			return fmt.Errorf("%w: version mismatch", apperr.ErrConflict)
+		}
+		args := append([]any{c.ID, c.Brand, c.Model, c.Year, nullVIN(c.VIN)}, attrArgs(c.CarAttributes)...)
+		updated, err = scanCar(tx.QueryRow(ctx, query, args...))
+		if err != nil {
+			return mapPgErr(err)
+		}
+		return recordChange(ctx, tx, models.AuditActionUpdate, c.ID, before, &updated)
+	})
 	if err != nil {
 		return err
 	}
-	if ct.RowsAffected() == 0 {
-		return apperr.ErrNotFound
-	}
-	slog.Debug("car updated", "id", c.ID, "rows", ct.RowsAffected())
+	c.Version = updated.Version
+	slog.Debug("car updated", "id", c.ID, "version", updated.Version)
 	return nil
 }
+
 func (r *CarRepo) DeleteByID(ctx context.Context, id string) error {
 	query := `
-	DELETE FROM cars WHERE id = $1;
+	UPDATE cars
+	SET deleted_at = NOW(), version = version + 1
+	WHERE id = $1
+	RETURNING ` + carColumns + `;
 	`
-	ct, err := r.pool.Exec(ctx, query, id)
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		before, err := lockCar(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt != nil {
+			return apperr.ErrNotFound
+		}
+		after, err := scanCar(tx.QueryRow(ctx, query, id))
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, models.AuditActionDelete, id, before, &after)
+	})
 	if err != nil {
 		return err
 	}
-	if ct.RowsAffected() == 0 {
-		return apperr.ErrNotFound
+	slog.Debug("car deleted", "id", id)
+	return nil
+}
+
+// ApplyBatch блокирует затронутые машины одним запросом, проверяет операции в памяти
+// и пишет результат пачками: новые машины, аудит и outbox — через COPY,
+// обновления и удаления — одним pgx.Batch.
+func (r *CarRepo) ApplyBatch(ctx context.Context, ops []models.CarMutation, atomic bool) ([]models.MutationResult, error) {
+	var results []models.MutationResult
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		// created_at — TIMESTAMP, deleted_at — TIMESTAMPTZ: берём оба значения времени транзакции
+		var now, localNow time.Time
+		if err := tx.QueryRow(ctx, `SELECT NOW(), LOCALTIMESTAMP;`).Scan(&now, &localNow); err != nil {
+			return err
+		}
+		current, err := lockCars(ctx, tx, batchCarIDs(ops))
+		if err != nil {
+			return err
+		}
+		vins, err := vinOwners(ctx, tx, batchVINs(ops))
+		if err != nil {
+			return err
+		}
+		plan := planBatch(ops, current, vins, now)
+		for _, p := range plan {
+			if p.err == nil && p.before == nil {
+				p.after.CreatedAt = localNow
+			}
+		}
+		var applied bool
+		results, applied = batchResults(plan, atomic)
+		if !applied {
+			return nil
+		}
+		return writeBatch(ctx, tx, plan)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// lockCars блокирует машины в порядке id, чтобы параллельные пакеты не взаимоблокировались.
+func lockCars(ctx context.Context, tx pgx.Tx, ids []string) (map[string]*models.Car, error) {
+	cars := make(map[string]*models.Car, len(ids))
+	if len(ids) == 0 {
+		return cars, nil
+	}
+	const query = `
+		SELECT ` + carColumns + `
+		FROM cars
+		WHERE id = ANY($1::text[]::uuid[])
+		ORDER BY id
+		FOR UPDATE;
+	`
+	rows, err := tx.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		c, err := scanCar(rows)
+		if err != nil {
+			return nil, err
+		}
+		cars[c.ID] = &c
+	}
+	return cars, rows.Err()
+}
+
+// vinOwners возвращает машины вне корзины, которым принадлежат vins (VIN → ID).
+// Параллельный пакет может занять VIN после проверки — тогда пакет целиком
+// получит apperr.ErrConflict от уникального индекса.
+func vinOwners(ctx context.Context, tx pgx.Tx, vins []string) (map[string]string, error) {
+	owners := make(map[string]string, len(vins))
+	if len(vins) == 0 {
+		return owners, nil
+	}
+	const query = `SELECT vin, id FROM cars WHERE vin = ANY($1) AND deleted_at IS NULL;`
+	rows, err := tx.Query(ctx, query, vins)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var vin, id string
+		if err := rows.Scan(&vin, &id); err != nil {
+			return nil, err
+		}
+		owners[vin] = id
+	}
+	return owners, rows.Err()
+}
+
+func writeBatch(ctx context.Context, tx pgx.Tx, plan []plannedMutation) error {
+	const updateQuery = `
+		UPDATE cars
+		SET brand = $2, model = $3, year = $4, version = $5, deleted_at = $6, vin = $7,
+			mileage = $8, color = $9, body_type = $10, fuel_type = $11, transmission = $12, engine_volume = $13,
+			engine_power = $14, price = $15, currency = $16, condition = $17, status = $18
+		WHERE id = $1;
+	`
+	var created, audit, outbox, prices [][]any
+	updates := &pgx.Batch{}
+	for _, p := range plan {
+		if p.err != nil {
+			continue
+		}
+		c := p.after
+		if p.before == nil {
+			created = append(created, append([]any{c.ID, c.Brand, c.Model, c.Year, nullVIN(c.VIN), c.Version, c.CreatedAt},
+				attrArgs(c.CarAttributes)...))
+		} else {
+			updates.Queue(updateQuery, append([]any{c.ID, c.Brand, c.Model, c.Year, c.Version, c.DeletedAt, nullVIN(c.VIN)},
+				attrArgs(c.CarAttributes)...)...)
+		}
+
+		entry, err := newAuditEntry(ctx, p.action, c.ID, p.before, c)
+		if err != nil {
+			return err
+		}
+		audit = append(audit, []any{entry.CarID, entry.Action, entry.Actor, entry.RequestID,
+			entry.Before, entry.After, entry.Diff})
+		evt, err := newOutboxEvent(ctx, p.action, c)
+		if err != nil {
+			return err
+		}
+		outbox = append(outbox, []any{evt.Type, evt.CarID, evt.Actor, evt.RequestID, evt.Payload})
+		if pp, ok := newPricePoint(ctx, p.before, c); ok {
+			prices = append(prices, []any{pp.CarID, pp.Price, nullString(pp.Currency), pp.Actor, pp.RequestID})
+		}
+	}
+
+	if len(created) > 0 {
+		_, err := tx.CopyFrom(ctx, pgx.Identifier{"cars"},
+			append([]string{"id", "brand", "model", "year", "vin", "version", "created_at"}, carAttrColumnNames...),
+			pgx.CopyFromRows(created))
+		if err != nil {
+			return mapPgErr(err)
+		}
+	}
+	if updates.Len() > 0 {
+		if err := tx.SendBatch(ctx, updates).Close(); err != nil {
+			return mapPgErr(err)
+		}
+	}
+	if len(audit) > 0 {
+		_, err := tx.CopyFrom(ctx, pgx.Identifier{"car_audit"},
+			[]string{"car_id", "action", "actor", "request_id", "before", "after", "diff"}, pgx.CopyFromRows(audit))
+		if err != nil {
+			return err
+		}
+		_, err = tx.CopyFrom(ctx, pgx.Identifier{"car_outbox"},
+			[]string{"event_type", "car_id", "actor", "request_id", "payload"}, pgx.CopyFromRows(outbox))
+		if err != nil {
+			return err
+		}
+	}
+	if len(prices) > 0 {
+		_, err := tx.CopyFrom(ctx, pgx.Identifier{"car_prices"},
+			[]string{"car_id", "price", "currency", "actor", "request_id"}, pgx.CopyFromRows(prices))
+		if err != nil {
+			return err
+		}
 	}
-	slog.Debug("car deleted", "id", id, "rows", ct.RowsAffected())
 	return nil
 }
+
+func (r *CarRepo) ListDeletedCars(ctx context.Context) ([]models.Car, error) {
+	query := `
+		SELECT ` + carColumns + `
+		FROM cars
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC;
+		`
+	return r.queryCars(ctx, query)
+}
+
+func (r *CarRepo) RestoreByID(ctx context.Context, id string) (*models.Car, error) {
+	query := `
+	UPDATE cars
+	SET deleted_at = NULL, version = version + 1
+	WHERE id = $1
+	RETURNING ` + carColumns + `;
+	`
+	var restored models.Car
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		before, err := lockCar(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt == nil {
+			return apperr.ErrNotFound
+		}
+		restored, err = scanCar(tx.QueryRow(ctx, query, id))
+		if err != nil {
+			return mapPgErr(err) // VIN уже занят другой машиной
+		}
+		return recordChange(ctx, tx, models.AuditActionRestore, id, before, &restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("car restored", "id", id)
+	return &restored, nil
+}
+
+func (r *CarRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+	DELETE FROM cars
+	WHERE deleted_at IS NOT NULL AND deleted_at < $1;
+	`
+	ct, err := r.pool.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
+
+func (r *CarRepo) ListAudit(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, int, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	add := func(cond string, v any) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.CarID != "" {
+		add("car_id = $%d", f.CarID)
+	}
+	if f.Actor != "" {
+		add("actor = $%d", f.Actor)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if !f.From.IsZero() {
+		add("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("created_at < $%d", f.To)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM car_audit `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, auditLimit(f.Limit), f.Offset)
+	query := fmt.Sprintf(`
+		SELECT id, car_id, action, actor, request_id, before, after, diff, created_at
+		FROM car_audit
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d;
+	`, where, len(args)-1, len(args))
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var (
+			e             models.AuditEntry
+			before, after []byte
+		)
+		if err := rows.Scan(&e.ID, &e.CarID, &e.Action, &e.Actor, &e.RequestID,
+			&before, &after, &e.Diff, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		e.Before, e.After = before, after
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// carsAsOfQuery восстанавливает состояние по car_audit: для каждой машины берётся
+// снимок after последней записи не позже $1, created_at — время записи create.
+const carsAsOfQuery = `
+	WITH state AS (
+		SELECT DISTINCT ON (car_id) car_id, after
+		FROM car_audit
+		WHERE created_at <= $1 AND ($2 = '' OR car_id::text = $2)
+		ORDER BY car_id, created_at DESC, id DESC
+	), born AS (
+		SELECT car_id, MIN(created_at) AS created_at
+		FROM car_audit
+		WHERE action = 'create' AND created_at <= $1 AND ($2 = '' OR car_id::text = $2)
+		GROUP BY car_id
+	)
+	SELECT s.car_id, s.after, b.created_at
+	FROM state s
+	JOIN born b USING (car_id)
+	ORDER BY b.created_at DESC;
+`
+
+func (r *CarRepo) carsAsOf(ctx context.Context, at time.Time, id string) ([]models.Car, error) {
+	rows, err := r.pool.Query(ctx, carsAsOfQuery, at, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cars := []models.Car{}
+	for rows.Next() {
+		var (
+			carID     string
+			after     []byte
+			createdAt time.Time
+		)
+		if err := rows.Scan(&carID, &after, &createdAt); err != nil {
+			return nil, err
+		}
+		car, ok, err := carAsOf(carID, after, createdAt)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			cars = append(cars, car)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return cars, nil
+}
+
+func (r *CarRepo) GetCarAsOf(ctx context.Context, id string, at time.Time) (*models.Car, error) {
+	cars, err := r.carsAsOf(ctx, at, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(cars) == 0 {
+		return nil, apperr.ErrNotFound
+	}
+	return &cars[0], nil
+}
+
+func (r *CarRepo) ListCarsAsOf(ctx context.Context, at time.Time) ([]models.Car, error) {
+	return r.carsAsOf(ctx, at, "")
+}
+
+func (r *CarRepo) PendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	const query = `
+		SELECT id, event_type, car_id, actor, request_id, payload, created_at, attempts
+		FROM car_outbox o
+		WHERE published_at IS NULL
+			AND next_attempt_at <= NOW()
+			AND id = (
+				SELECT MIN(p.id) FROM car_outbox p
+				WHERE p.car_id = o.car_id AND p.published_at IS NULL
+			)
+		ORDER BY id
+		LIMIT $1;
+	`
+	rows, err := r.pool.Query(ctx, query, outboxLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.OutboxEvent{}
+	for rows.Next() {
+		var (
+			e       models.OutboxEvent
+			payload []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Type, &e.CarID, &e.Actor, &e.RequestID,
+			&payload, &e.OccurredAt, &e.Attempts); err != nil {
+			return nil, err
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *CarRepo) MarkPublished(ctx context.Context, id int64) error {
+	const query = `UPDATE car_outbox SET published_at = NOW() WHERE id = $1;`
+	_, err := r.pool.Exec(ctx, query, id)
+	return err
+}
+
+func (r *CarRepo) MarkFailed(ctx context.Context, id int64, retryAt time.Time, reason string) error {
+	const query = `
+		UPDATE car_outbox
+		SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+		WHERE id = $1;
+	`
+	_, err := r.pool.Exec(ctx, query, id, retryAt, truncateError(reason))
+	return err
+}
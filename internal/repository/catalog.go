@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ CatalogProvider = (*CarRepo)(nil)
+
+const brandColumns = `id, name, aliases, created_at, updated_at, year_from, year_to`
+
+const modelColumns = `id, brand_id, name, aliases, created_at, updated_at, year_from, year_to`
+
+func scanBrand(row pgx.Row) (models.Brand, error) {
+	var b models.Brand
+	err := row.Scan(&b.ID, &b.Name, &b.Aliases, &b.CreatedAt, &b.UpdatedAt, &b.From, &b.To)
+	return b, err
+}
+
+func scanModel(row pgx.Row) (models.CarModel, error) {
+	var m models.CarModel
+	err := row.Scan(&m.ID, &m.BrandID, &m.Name, &m.Aliases, &m.CreatedAt, &m.UpdatedAt, &m.From, &m.To)
+	return m, err
+}
+
+// mapCatalogErr дополняет mapPgErr: ссылка на несуществующую марку — apperr.ErrNotFound.
+func mapCatalogErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return apperr.ErrNotFound
+	}
+	return mapPgErr(err)
+}
+
+func (r *CarRepo) queryBrands(ctx context.Context, query string, args ...any) ([]models.Brand, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	brands := []models.Brand{}
+	for rows.Next() {
+		b, err := scanBrand(rows)
+		if err != nil {
+			return nil, err
+		}
+		brands = append(brands, b)
+	}
+	return brands, rows.Err()
+}
+
+func (r *CarRepo) queryModels(ctx context.Context, query string, args ...any) ([]models.CarModel, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.CarModel{}
+	for rows.Next() {
+		m, err := scanModel(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+func (r *CarRepo) CreateBrand(ctx context.Context, b *models.Brand) error {
+	const query = `
+		INSERT INTO brands (name, name_key, aliases, keys, year_from, year_to)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + brandColumns + `;
+	`
+	keys := models.CatalogKeys(b.Name, b.Aliases)
+	created, err := scanBrand(r.pool.QueryRow(ctx, query, b.Name, keys[0], nonNilTypes(b.Aliases), keys, b.From, b.To))
+	if err != nil {
+		return mapPgErr(err)
+	}
+	*b = created
+	return nil
+}
+
+func (r *CarRepo) ListBrands(ctx context.Context) ([]models.Brand, error) {
+	const query = `
+		SELECT ` + brandColumns + `
+		FROM brands
+		ORDER BY name_key;
+	`
+	return r.queryBrands(ctx, query)
+}
+
+func (r *CarRepo) GetBrand(ctx context.Context, id string) (*models.Brand, error) {
+	const query = `
+		SELECT ` + brandColumns + `
+		FROM brands
+		WHERE id = $1;
+	`
+	b, err := scanBrand(r.pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *CarRepo) UpdateBrand(ctx context.Context, b *models.Brand) error {
+	const query = `
+		UPDATE brands
+		SET name = $2, name_key = $3, aliases = $4, keys = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + brandColumns + `;
+	`
+	keys := models.CatalogKeys(b.Name, b.Aliases)
+	updated, err := scanBrand(r.pool.QueryRow(ctx, query, b.ID, b.Name, keys[0], nonNilTypes(b.Aliases), keys))
+	if err == pgx.ErrNoRows {
+		return apperr.ErrNotFound
+	}
+	if err != nil {
+		return mapPgErr(err)
+	}
+	*b = updated
+	return nil
+}
+
+func (r *CarRepo) SetBrandYears(ctx context.Context, id string, years models.YearRange) (*models.Brand, error) {
+	const query = `
+		UPDATE brands
+		SET year_from = $2, year_to = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + brandColumns + `;
+	`
+	b, err := scanBrand(r.pool.QueryRow(ctx, query, id, years.From, years.To))
+	if err == pgx.ErrNoRows {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, mapPgErr(err)
+	}
+	return &b, nil
+}
+
+func (r *CarRepo) DeleteBrand(ctx context.Context, id string) error {
+	ct, err := r.pool.Exec(ctx, `DELETE FROM brands WHERE id = $1;`, id)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return apperr.ErrNotFound
+	}
+	return nil
+}
+
+func (r *CarRepo) FindBrand(ctx context.Context, key string) (*models.Brand, error) {
+	const query = `
+		SELECT ` + brandColumns + `
+		FROM brands
+		WHERE keys @> ARRAY[$1::text]
+		ORDER BY name_key = $1 DESC, name_key
+		LIMIT 1;
+	`
+	b, err := scanBrand(r.pool.QueryRow(ctx, query, key))
+	if err == pgx.ErrNoRows {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *CarRepo) CreateModel(ctx context.Context, m *models.CarModel) error {
+	const query = `
+		INSERT INTO models (brand_id, name, name_key, aliases, keys, year_from, year_to)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + modelColumns + `;
+	`
+	keys := models.CatalogKeys(m.Name, m.Aliases)
+	created, err := scanModel(r.pool.QueryRow(ctx, query, m.BrandID, m.Name, keys[0], nonNilTypes(m.Aliases), keys,
+		m.From, m.To))
+	if err != nil {
+		return mapCatalogErr(err)
+	}
+	*m = created
+	return nil
+}
+
+func (r *CarRepo) ListModels(ctx context.Context, brandID string) ([]models.CarModel, error) {
+	const query = `
+		SELECT ` + modelColumns + `
+		FROM models
+		WHERE $1 = '' OR brand_id::text = $1
+		ORDER BY name_key, brand_id;
+	`
+	return r.queryModels(ctx, query, brandID)
+}
+
+func (r *CarRepo) GetModel(ctx context.Context, id string) (*models.CarModel, error) {
+	const query = `
+		SELECT ` + modelColumns + `
+		FROM models
+		WHERE id = $1;
+	`
+	m, err := scanModel(r.pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *CarRepo) UpdateModel(ctx context.Context, m *models.CarModel) error {
+	const query = `
+		UPDATE models
+		SET name = $2, name_key = $3, aliases = $4, keys = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + modelColumns + `;
+	`
+	keys := models.CatalogKeys(m.Name, m.Aliases)
+	updated, err := scanModel(r.pool.QueryRow(ctx, query, m.ID, m.Name, keys[0], nonNilTypes(m.Aliases), keys))
+	if err == pgx.ErrNoRows {
+		return apperr.ErrNotFound
+	}
+	if err != nil {
+		return mapPgErr(err)
+	}
+	*m = updated
+	return nil
+}
+
+func (r *CarRepo) SetModelYears(ctx context.Context, id string, years models.YearRange) (*models.CarModel, error) {
+	const query = `
+		UPDATE models
+		SET year_from = $2, year_to = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + modelColumns + `;
+	`
+	m, err := scanModel(r.pool.QueryRow(ctx, query, id, years.From, years.To))
+	if err == pgx.ErrNoRows {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, mapPgErr(err)
+	}
+	return &m, nil
+}
+
+func (r *CarRepo) DeleteModel(ctx context.Context, id string) error {
+	ct, err := r.pool.Exec(ctx, `DELETE FROM models WHERE id = $1;`, id)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return apperr.ErrNotFound
+	}
+	return nil
+}
+
+func (r *CarRepo) FindModel(ctx context.Context, brandID, key string) (*models.CarModel, error) {
+	const query = `
+		SELECT ` + modelColumns + `
+		FROM models
+		WHERE brand_id = $1 AND keys @> ARRAY[$2::text]
+		ORDER BY name_key = $2 DESC, name_key
+		LIMIT 1;
+	`
+	m, err := scanModel(r.pool.QueryRow(ctx, query, brandID, key))
+	if err == pgx.ErrNoRows {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
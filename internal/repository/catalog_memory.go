@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ CatalogProvider = (*MemoryCarRepo)(nil)
+
+// memoryCatalog — справочник марок и моделей MemoryCarRepo со своим мьютексом.
+type memoryCatalog struct {
+	mu     sync.RWMutex
+	brands []models.Brand
+	models []models.CarModel
+}
+
+func cloneBrand(b models.Brand) models.Brand {
+	b.Aliases = slices.Clone(nonNilTypes(b.Aliases))
+	b.YearRange = cloneYears(b.YearRange)
+	return b
+}
+
+func cloneCarModel(m models.CarModel) models.CarModel {
+	m.Aliases = slices.Clone(nonNilTypes(m.Aliases))
+	m.YearRange = cloneYears(m.YearRange)
+	return m
+}
+
+func cloneYears(y models.YearRange) models.YearRange {
+	return models.YearRange{From: clonePtr(y.From), To: clonePtr(y.To)}
+}
+
+// matchesKey повторяет поиск по колонке keys.
+func matchesKey(name string, aliases []string, key string) bool {
+	return slices.Contains(models.CatalogKeys(name, aliases), key)
+}
+
+func (c *memoryCatalog) brandIndex(id string) int {
+	return slices.IndexFunc(c.brands, func(b models.Brand) bool { return b.ID == id })
+}
+
+func (c *memoryCatalog) modelIndex(id string) int {
+	return slices.IndexFunc(c.models, func(m models.CarModel) bool { return m.ID == id })
+}
+
+// brandNameTaken повторяет уникальный индекс brands_name_key.
+func (c *memoryCatalog) brandNameTaken(name, id string) bool {
+	key := models.CatalogKey(name)
+	return slices.ContainsFunc(c.brands, func(b models.Brand) bool {
+		return b.ID != id && models.CatalogKey(b.Name) == key
+	})
+}
+
+// modelNameTaken повторяет уникальный индекс models_brand_name_key.
+func (c *memoryCatalog) modelNameTaken(brandID, name, id string) bool {
+	key := models.CatalogKey(name)
+	return slices.ContainsFunc(c.models, func(m models.CarModel) bool {
+		return m.ID != id && m.BrandID == brandID && models.CatalogKey(m.Name) == key
+	})
+}
+
+func byNameKey(a, b string) int {
+	return strings.Compare(models.CatalogKey(a), models.CatalogKey(b))
+}
+
+func (r *MemoryCarRepo) CreateBrand(ctx context.Context, b *models.Brand) error {
+	c := &r.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.brandNameTaken(b.Name, "") {
+		return apperr.ErrConflict
+	}
+	created := cloneBrand(*b)
+	created.ID = uuid.NewString()
+	created.CreatedAt = time.Now().UTC()
+	created.UpdatedAt = created.CreatedAt
+	c.brands = append(c.brands, created)
+	*b = cloneBrand(created)
+	return nil
+}
+
+func (r *MemoryCarRepo) ListBrands(ctx context.Context) ([]models.Brand, error) {
+	c := &r.catalog
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	brands := make([]models.Brand, len(c.brands))
+	for i, b := range c.brands {
+		brands[i] = cloneBrand(b)
+	}
+	slices.SortStableFunc(brands, func(a, b models.Brand) int { return byNameKey(a.Name, b.Name) })
+	return brands, nil
+}
+
+func (r *MemoryCarRepo) GetBrand(ctx context.Context, id string) (*models.Brand, error) {
+	c := &r.catalog
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	i := c.brandIndex(id)
+	if i < 0 {
+		return nil, apperr.ErrNotFound
+	}
+	b := cloneBrand(c.brands[i])
+	return &b, nil
+}
+
+func (r *MemoryCarRepo) UpdateBrand(ctx context.Context, b *models.Brand) error {
+	c := &r.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.brandIndex(b.ID)
+	if i < 0 {
+		return apperr.ErrNotFound
+	}
+	if c.brandNameTaken(b.Name, b.ID) {
+		return apperr.ErrConflict
+	}
+	updated := cloneBrand(*b)
+	updated.YearRange = c.brands[i].YearRange
+	updated.CreatedAt = c.brands[i].CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+	c.brands[i] = updated
+	*b = cloneBrand(updated)
+	return nil
+}
+
+func (r *MemoryCarRepo) SetBrandYears(ctx context.Context, id string, years models.YearRange) (*models.Brand, error) {
+	c := &r.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.brandIndex(id)
+	if i < 0 {
+		return nil, apperr.ErrNotFound
+	}
+	c.brands[i].YearRange = cloneYears(years)
+	c.brands[i].UpdatedAt = time.Now().UTC()
+	b := cloneBrand(c.brands[i])
+	return &b, nil
+}
+
+func (r *MemoryCarRepo) DeleteBrand(ctx context.Context, id string) error {
+	c := &r.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.brandIndex(id)
+	if i < 0 {
+		return apperr.ErrNotFound
+	}
+	c.brands = slices.Delete(c.brands, i, i+1)
+	c.models = slices.DeleteFunc(c.models, func(m models.CarModel) bool { return m.BrandID == id })
+	return nil
+}
+
+func (r *MemoryCarRepo) FindBrand(ctx context.Context, key string) (*models.Brand, error) {
+	brands, _ := r.ListBrands(ctx)
+	// точное совпадение с названием важнее синонима другой марки
+	for _, b := range brands {
+		if models.CatalogKey(b.Name) == key {
+			return &b, nil
+		}
+	}
+	for _, b := range brands {
+		if matchesKey(b.Name, b.Aliases, key) {
+			return &b, nil
+		}
+	}
+	return nil, apperr.ErrNotFound
+}
+
+func (r *MemoryCarRepo) CreateModel(ctx context.Context, m *models.CarModel) error {
+	c := &r.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.brandIndex(m.BrandID) < 0 {
+		return apperr.ErrNotFound
+	}
+	if c.modelNameTaken(m.BrandID, m.Name, "") {
+		return apperr.ErrConflict
+	}
+	created := cloneCarModel(*m)
+	created.ID = uuid.NewString()
+	created.CreatedAt = time.Now().UTC()
+	created.UpdatedAt = created.CreatedAt
+	c.models = append(c.models, created)
+	*m = cloneCarModel(created)
+	return nil
+}
+
+func (r *MemoryCarRepo) ListModels(ctx context.Context, brandID string) ([]models.CarModel, error) {
+	c := &r.catalog
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	list := []models.CarModel{}
+	for _, m := range c.models {
+		if brandID == "" || m.BrandID == brandID {
+			list = append(list, cloneCarModel(m))
+		}
+	}
+	slices.SortStableFunc(list, func(a, b models.CarModel) int {
+		if n := byNameKey(a.Name, b.Name); n != 0 {
+			return n
+		}
+		return strings.Compare(a.BrandID, b.BrandID)
+	})
+	return list, nil
+}
+
+func (r *MemoryCarRepo) GetModel(ctx context.Context, id string) (*models.CarModel, error) {
+	c := &r.catalog
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	i := c.modelIndex(id)
+	if i < 0 {
+		return nil, apperr.ErrNotFound
+	}
+	m := cloneCarModel(c.models[i])
+	return &m, nil
+}
+
+func (r *MemoryCarRepo) UpdateModel(ctx context.Context, m *models.CarModel) error {
+	c := &r.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.modelIndex(m.ID)
+	if i < 0 {
+		return apperr.ErrNotFound
+	}
+	current := c.models[i]
+	if c.modelNameTaken(current.BrandID, m.Name, m.ID) {
+		return apperr.ErrConflict
+	}
+	updated := cloneCarModel(*m)
+	updated.BrandID = current.BrandID
+	updated.YearRange = current.YearRange
+	updated.CreatedAt = current.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+	c.models[i] = updated
+	*m = cloneCarModel(updated)
+	return nil
+}
+
+func (r *MemoryCarRepo) SetModelYears(ctx context.Context, id string, years models.YearRange) (*models.CarModel, error) {
+	c := &r.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.modelIndex(id)
+	if i < 0 {
+		return nil, apperr.ErrNotFound
+	}
+	c.models[i].YearRange = cloneYears(years)
+	c.models[i].UpdatedAt = time.Now().UTC()
+	m := cloneCarModel(c.models[i])
+	return &m, nil
+}
+
+func (r *MemoryCarRepo) DeleteModel(ctx context.Context, id string) error {
+	c := &r.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.modelIndex(id)
+	if i < 0 {
+		return apperr.ErrNotFound
+	}
+	c.models = slices.Delete(c.models, i, i+1)
+	return nil
+}
+
+func (r *MemoryCarRepo) FindModel(ctx context.Context, brandID, key string) (*models.CarModel, error) {
+	list, _ := r.ListModels(ctx, brandID)
+	for _, m := range list {
+		if models.CatalogKey(m.Name) == key {
+			return &m, nil
+		}
+	}
+	for _, m := range list {
+		if matchesKey(m.Name, m.Aliases, key) {
+			return &m, nil
+		}
+	}
+	return nil, apperr.ErrNotFound
+}
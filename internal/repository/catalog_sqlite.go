@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ CatalogProvider = (*SQLiteCarRepo)(nil)
+
+func scanSQLiteBrand(row rowScanner) (models.Brand, error) {
+	var (
+		b                    models.Brand
+		aliases              string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&b.ID, &b.Name, &aliases, &createdAt, &updatedAt, &b.From, &b.To); err != nil {
+		return models.Brand{}, err
+	}
+	if err := json.Unmarshal([]byte(aliases), &b.Aliases); err != nil {
+		return models.Brand{}, fmt.Errorf("parse aliases: %w", err)
+	}
+	var err error
+	if b.CreatedAt, err = time.Parse(sqliteTimeLayout, createdAt); err != nil {
+		return models.Brand{}, err
+	}
+	if b.UpdatedAt, err = time.Parse(sqliteTimeLayout, updatedAt); err != nil {
+		return models.Brand{}, err
+	}
+	return b, nil
+}
+
+func scanSQLiteModel(row rowScanner) (models.CarModel, error) {
+	var (
+		m                    models.CarModel
+		aliases              string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&m.ID, &m.BrandID, &m.Name, &aliases, &createdAt, &updatedAt, &m.From, &m.To); err != nil {
+		return models.CarModel{}, err
+	}
+	if err := json.Unmarshal([]byte(aliases), &m.Aliases); err != nil {
+		return models.CarModel{}, fmt.Errorf("parse aliases: %w", err)
+	}
+	var err error
+	if m.CreatedAt, err = time.Parse(sqliteTimeLayout, createdAt); err != nil {
+		return models.CarModel{}, err
+	}
+	if m.UpdatedAt, err = time.Parse(sqliteTimeLayout, updatedAt); err != nil {
+		return models.CarModel{}, err
+	}
+	return m, nil
+}
+
+// catalogJSON возвращает синонимы и ключи поиска JSON-массивами.
+func catalogJSON(name string, aliases []string) (aliasesJSON, keysJSON string, err error) {
+	a, err := json.Marshal(nonNilTypes(aliases))
+	if err != nil {
+		return "", "", err
+	}
+	k, err := json.Marshal(models.CatalogKeys(name, aliases))
+	if err != nil {
+		return "", "", err
+	}
+	return string(a), string(k), nil
+}
+
+// mapSQLiteCatalogErr дополняет mapSQLiteErr: ссылка на несуществующую марку — apperr.ErrNotFound.
+func mapSQLiteCatalogErr(err error) error {
+	var sErr *sqlite.Error
+	if errors.As(err, &sErr) && sErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY {
+		return apperr.ErrNotFound
+	}
+	return mapSQLiteErr(err)
+}
+
+func (r *SQLiteCarRepo) queryBrands(ctx context.Context, query string, args ...any) ([]models.Brand, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	brands := []models.Brand{}
+	for rows.Next() {
+		b, err := scanSQLiteBrand(rows)
+		if err != nil {
+			return nil, err
+		}
+		brands = append(brands, b)
+	}
+	return brands, rows.Err()
+}
+
+func (r *SQLiteCarRepo) queryModels(ctx context.Context, query string, args ...any) ([]models.CarModel, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.CarModel{}
+	for rows.Next() {
+		m, err := scanSQLiteModel(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+func (r *SQLiteCarRepo) CreateBrand(ctx context.Context, b *models.Brand) error {
+	aliases, keys, err := catalogJSON(b.Name, b.Aliases)
+	if err != nil {
+		return err
+	}
+	created := *b
+	created.ID = uuid.NewString()
+	created.Aliases = nonNilTypes(b.Aliases)
+	created.CreatedAt = sqliteNow()
+	created.UpdatedAt = created.CreatedAt
+	const query = `
+		INSERT INTO brands (id, name, name_key, aliases, keys, created_at, updated_at, year_from, year_to)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`
+	_, err = r.db.ExecContext(ctx, query, created.ID, created.Name, models.CatalogKey(created.Name), aliases, keys,
+		formatSQLiteTime(created.CreatedAt), formatSQLiteTime(created.UpdatedAt), created.From, created.To)
+	if err != nil {
+		return mapSQLiteErr(err)
+	}
+	*b = created
+	return nil
+}
+
+func (r *SQLiteCarRepo) ListBrands(ctx context.Context) ([]models.Brand, error) {
+	const query = `
+		SELECT ` + brandColumns + `
+		FROM brands
+		ORDER BY name_key;
+	`
+	return r.queryBrands(ctx, query)
+}
+
+func (r *SQLiteCarRepo) GetBrand(ctx context.Context, id string) (*models.Brand, error) {
+	const query = `
+		SELECT ` + brandColumns + `
+		FROM brands
+		WHERE id = ?;
+	`
+	b, err := scanSQLiteBrand(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *SQLiteCarRepo) UpdateBrand(ctx context.Context, b *models.Brand) error {
+	aliases, keys, err := catalogJSON(b.Name, b.Aliases)
+	if err != nil {
+		return err
+	}
+	const query = `
+		UPDATE brands
+		SET name = ?, name_key = ?, aliases = ?, keys = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING ` + brandColumns + `;
+	`
+	updated, err := scanSQLiteBrand(r.db.QueryRowContext(ctx, query, b.Name, models.CatalogKey(b.Name), aliases, keys,
+		formatSQLiteTime(sqliteNow()), b.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperr.ErrNotFound
+	}
+	if err != nil {
+		return mapSQLiteErr(err)
+	}
+	*b = updated
+	return nil
+}
+
+func (r *SQLiteCarRepo) SetBrandYears(ctx context.Context, id string, years models.YearRange) (*models.Brand, error) {
+	const query = `
+		UPDATE brands
+		SET year_from = ?, year_to = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING ` + brandColumns + `;
+	`
+	b, err := scanSQLiteBrand(r.db.QueryRowContext(ctx, query, years.From, years.To, formatSQLiteTime(sqliteNow()), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, mapSQLiteErr(err)
+	}
+	return &b, nil
+}
+
+func (r *SQLiteCarRepo) DeleteBrand(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM brands WHERE id = ?;`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperr.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteCarRepo) FindBrand(ctx context.Context, key string) (*models.Brand, error) {
+	const query = `
+		SELECT ` + brandColumns + `
+		FROM brands
+		WHERE EXISTS (SELECT 1 FROM json_each(brands.keys) WHERE json_each.value = ?)
+		ORDER BY name_key = ? DESC, name_key
+		LIMIT 1;
+	`
+	b, err := scanSQLiteBrand(r.db.QueryRowContext(ctx, query, key, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *SQLiteCarRepo) CreateModel(ctx context.Context, m *models.CarModel) error {
+	aliases, keys, err := catalogJSON(m.Name, m.Aliases)
+	if err != nil {
+		return err
+	}
+	created := *m
+	created.ID = uuid.NewString()
+	created.Aliases = nonNilTypes(m.Aliases)
+	created.CreatedAt = sqliteNow()
+	created.UpdatedAt = created.CreatedAt
+	const query = `
+		INSERT INTO models (id, brand_id, name, name_key, aliases, keys, created_at, updated_at, year_from, year_to)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`
+	_, err = r.db.ExecContext(ctx, query, created.ID, created.BrandID, created.Name, models.CatalogKey(created.Name),
+		aliases, keys, formatSQLiteTime(created.CreatedAt), formatSQLiteTime(created.UpdatedAt), created.From, created.To)
+	if err != nil {
+		return mapSQLiteCatalogErr(err)
+	}
+	*m = created
+	return nil
+}
+
+func (r *SQLiteCarRepo) ListModels(ctx context.Context, brandID string) ([]models.CarModel, error) {
+	const query = `
+		SELECT ` + modelColumns + `
+		FROM models
+		WHERE ? = '' OR brand_id = ?
+		ORDER BY name_key, brand_id;
+	`
+	return r.queryModels(ctx, query, brandID, brandID)
+}
+
+func (r *SQLiteCarRepo) GetModel(ctx context.Context, id string) (*models.CarModel, error) {
+	const query = `
+		SELECT ` + modelColumns + `
+		FROM models
+		WHERE id = ?;
+	`
+	m, err := scanSQLiteModel(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *SQLiteCarRepo) UpdateModel(ctx context.Context, m *models.CarModel) error {
+	aliases, keys, err := catalogJSON(m.Name, m.Aliases)
+	if err != nil {
+		return err
+	}
+	const query = `
+		UPDATE models
+		SET name = ?, name_key = ?, aliases = ?, keys = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING ` + modelColumns + `;
+	`
+	updated, err := scanSQLiteModel(r.db.QueryRowContext(ctx, query, m.Name, models.CatalogKey(m.Name), aliases, keys,
+		formatSQLiteTime(sqliteNow()), m.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperr.ErrNotFound
+	}
+	if err != nil {
+		return mapSQLiteErr(err)
+	}
+	*m = updated
+	return nil
+}
+
+func (r *SQLiteCarRepo) SetModelYears(ctx context.Context, id string, years models.YearRange) (*models.CarModel, error) {
+	const query = `
+		UPDATE models
+		SET year_from = ?, year_to = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING ` + modelColumns + `;
+	`
+	m, err := scanSQLiteModel(r.db.QueryRowContext(ctx, query, years.From, years.To, formatSQLiteTime(sqliteNow()), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, mapSQLiteErr(err)
+	}
+	return &m, nil
+}
+
+func (r *SQLiteCarRepo) DeleteModel(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM models WHERE id = ?;`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperr.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteCarRepo) FindModel(ctx context.Context, brandID, key string) (*models.CarModel, error) {
+	const query = `
+		SELECT ` + modelColumns + `
+		FROM models
+		WHERE brand_id = ? AND EXISTS (SELECT 1 FROM json_each(models.keys) WHERE json_each.value = ?)
+		ORDER BY name_key = ? DESC, name_key
+		LIMIT 1;
+	`
+	m, err := scanSQLiteModel(r.db.QueryRowContext(ctx, query, brandID, key, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
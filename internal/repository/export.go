@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// exportFetchSize — сколько строк курсора читается за один FETCH.
+const exportFetchSize = 500
+
+var _ ExportProvider = (*CarRepo)(nil)
+
+// carFilterSQL собирает условия фильтра для WHERE (каждое начинается с AND);
+// placeholder возвращает обозначение n-го параметра (с единицы).
+func carFilterSQL(f models.CarFilter, placeholder func(n int) string) (string, []any) {
+	var (
+		sb   strings.Builder
+		args []any
+	)
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		fmt.Fprintf(&sb, " AND "+cond, placeholder(len(args)))
+	}
+	for _, s := range []struct{ column, value string }{
+		{"brand", f.Brand}, {"model", f.Model}, {"color", f.Color}, {"body_type", f.BodyType},
+		{"fuel_type", f.FuelType}, {"transmission", f.Transmission}, {"condition", f.Condition},
+		{"status", f.Status}, {"currency", f.Currency},
+	} {
+		if s.value != "" {
+			add("lower("+s.column+") = lower(%s)", s.value)
+		}
+	}
+	for _, b := range []struct {
+		cond  string
+		value int
+	}{
+		{"year >= %s", f.YearFrom}, {"year <= %s", f.YearTo},
+		{"mileage >= %s", f.MileageFrom}, {"mileage <= %s", f.MileageTo},
+	} {
+		if b.value != 0 {
+			add(b.cond, b.value)
+		}
+	}
+	// Money передаётся драйверу десятичным числом, как хранится в колонке price
+	for _, b := range []struct {
+		cond  string
+		value models.Money
+	}{{"price >= %s", f.PriceFrom}, {"price <= %s", f.PriceTo}} {
+		if b.value != 0 {
+			add(b.cond, b.value)
+		}
+	}
+	return sb.String(), args
+}
+
+func pgPlaceholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+// StreamCars читает выборку серверным курсором в read-only транзакции REPEATABLE READ:
+// выгрузка видит один снимок данных, а в памяти держится не больше exportFetchSize строк.
+func (r *CarRepo) StreamCars(ctx context.Context, f models.CarFilter, fn func(models.Car) error) error {
+	where, args := carFilterSQL(f, pgPlaceholder)
+	declare := `
+		DECLARE car_export NO SCROLL CURSOR FOR
+		SELECT ` + carColumns + `
+		FROM cars
+		WHERE deleted_at IS NULL` + where + `
+		ORDER BY created_at DESC;
+	`
+	fetch := fmt.Sprintf(`FETCH %d FROM car_export;`, exportFetchSize)
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, declare, args...); err != nil {
+		return err
+	}
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return err
+		}
+		n := 0
+		for rows.Next() {
+			c, err := scanCar(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			n++
+			if err := fn(c); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if n < exportFetchSize {
+			return tx.Commit(ctx)
+		}
+	}
+}
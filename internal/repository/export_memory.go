@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ ExportProvider = (*MemoryCarRepo)(nil)
+
+// StreamCars работает со снимком списка: данные и так целиком в памяти,
+// а fn не должна выполняться под r.mu.
+func (r *MemoryCarRepo) StreamCars(ctx context.Context, f models.CarFilter, fn func(models.Car) error) error {
+	cars, err := r.ListCars(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range cars {
+		if !f.Match(c) {
+			continue
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
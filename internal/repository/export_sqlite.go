@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ ExportProvider = (*SQLiteCarRepo)(nil)
+
+// rowidScanner читает rowid перед колонками машины.
+type rowidScanner struct {
+	row   rowScanner
+	rowid *int64
+}
+
+func (s rowidScanner) Scan(dest ...any) error {
+	return s.row.Scan(append([]any{s.rowid}, dest...)...)
+}
+
+// StreamCars читает страницами по ключу (created_at, rowid): у SQLite одно соединение,
+// и открытый на всю выгрузку запрос заблокировал бы остальные запросы.
+func (r *SQLiteCarRepo) StreamCars(ctx context.Context, f models.CarFilter, fn func(models.Car) error) error {
+	where, args := carFilterSQL(f, func(int) string { return "?" })
+	const order = ` ORDER BY created_at DESC, rowid DESC LIMIT ?;`
+	base := `SELECT rowid, ` + sqliteCarColumns + ` FROM cars WHERE deleted_at IS NULL` + where
+
+	var (
+		lastRowid   int64
+		lastCreated string
+		first       = true
+	)
+	for {
+		query, qargs := base+order, append(append([]any{}, args...), exportFetchSize)
+		if !first {
+			query = base + ` AND (created_at < ? OR (created_at = ? AND rowid < ?))` + order
+			qargs = append(append([]any{}, args...), lastCreated, lastCreated, lastRowid, exportFetchSize)
+		}
+		page, err := r.exportPage(ctx, query, qargs, &lastRowid)
+		if err != nil {
+			return err
+		}
+		for _, c := range page {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+		if len(page) < exportFetchSize {
+			return nil
+		}
+		first = false
+		lastCreated = formatSQLiteTime(page[len(page)-1].CreatedAt)
+	}
+}
+
+// exportPage читает страницу целиком до вызова fn, чтобы не держать соединение, пока клиент принимает данные.
+func (r *SQLiteCarRepo) exportPage(ctx context.Context, query string, args []any, lastRowid *int64) ([]models.Car, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := make([]models.Car, 0, exportFetchSize)
+	for rows.Next() {
+		c, err := scanSQLiteCar(rowidScanner{row: rows, rowid: lastRowid})
+		if err != nil {
+			return nil, err
+		}
+		page = append(page, c)
+	}
+	return page, rows.Err()
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ IdempotencyProvider = (*CarRepo)(nil)
+
+// storedResponse собирает ответ из колонок; status_code = 0 — ответа ещё нет.
+func storedResponse(status int, headers, body []byte) (*models.StoredResponse, error) {
+	if status == 0 {
+		return nil, nil
+	}
+	resp := &models.StoredResponse{Status: status, Body: body}
+	if err := json.Unmarshal(headers, &resp.Headers); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ReserveIdempotencyKey: ON CONFLICT ... DO UPDATE WHERE перезанимает только истёкшую
+// или брошенную запись; если строка не вернулась, ключ занят и читается текущая запись.
+func (r *CarRepo) ReserveIdempotencyKey(ctx context.Context, k *models.IdempotencyKey, staleBefore time.Time) (*models.IdempotencyKey, error) {
+	const (
+		reserveQuery = `
+			INSERT INTO idempotency_keys (scope, key, request_hash, expires_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (scope, key) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash, status_code = 0, headers = '{}', body = NULL,
+				created_at = NOW(), expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= NOW()
+				OR (idempotency_keys.status_code = 0 AND idempotency_keys.created_at < $5)
+			RETURNING created_at;
+		`
+		selectQuery = `
+			SELECT request_hash, status_code, headers, body, created_at, expires_at
+			FROM idempotency_keys
+			WHERE scope = $1 AND key = $2;
+		`
+	)
+	err := r.pool.QueryRow(ctx, reserveQuery, k.Scope, k.Key, k.RequestHash, k.ExpiresAt, staleBefore).
+		Scan(&k.CreatedAt)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	existing := models.IdempotencyKey{Scope: k.Scope, Key: k.Key}
+	var (
+		status        int
+		headers, body []byte
+	)
+	err = r.pool.QueryRow(ctx, selectQuery, k.Scope, k.Key).Scan(&existing.RequestHash, &status, &headers, &body,
+		&existing.CreatedAt, &existing.ExpiresAt)
+	if err != nil {
+		// запись могли удалить между запросами; клиент повторит
+		return nil, err
+	}
+	if existing.Response, err = storedResponse(status, headers, body); err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+func (r *CarRepo) CompleteIdempotencyKey(ctx context.Context, scope, key string, resp models.StoredResponse) error {
+	const query = `
+		UPDATE idempotency_keys
+		SET status_code = $3, headers = $4, body = $5
+		WHERE scope = $1 AND key = $2;
+	`
+	headers, err := json.Marshal(nonNilHeaders(resp.Headers))
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, query, scope, key, resp.Status, headers, resp.Body)
+	return err
+}
+
+func (r *CarRepo) ReleaseIdempotencyKey(ctx context.Context, scope, key string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status_code = 0;`,
+		scope, key)
+	return err
+}
+
+func (r *CarRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	ct, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1;`, before)
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
+
+func nonNilHeaders(h map[string]string) map[string]string {
+	if h == nil {
+		return map[string]string{}
+	}
+	return h
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ IdempotencyProvider = (*MemoryCarRepo)(nil)
+
+// memoryIdempotency — ключи идемпотентности MemoryCarRepo по scope и key.
+type memoryIdempotency struct {
+	mu   sync.Mutex
+	keys map[[2]string]models.IdempotencyKey
+}
+
+func cloneIdempotencyKey(k models.IdempotencyKey) models.IdempotencyKey {
+	if k.Response != nil {
+		resp := *k.Response
+		resp.Headers = maps.Clone(resp.Headers)
+		resp.Body = slices.Clone(resp.Body)
+		k.Response = &resp
+	}
+	return k
+}
+
+func (r *MemoryCarRepo) ReserveIdempotencyKey(ctx context.Context, k *models.IdempotencyKey, staleBefore time.Time) (*models.IdempotencyKey, error) {
+	s := &r.idempotency
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := [2]string{k.Scope, k.Key}
+	now := time.Now().UTC()
+	if existing, ok := s.keys[id]; ok {
+		abandoned := existing.Response == nil && existing.CreatedAt.Before(staleBefore)
+		if existing.ExpiresAt.After(now) && !abandoned {
+			c := cloneIdempotencyKey(existing)
+			return &c, nil
+		}
+	}
+	if s.keys == nil {
+		s.keys = map[[2]string]models.IdempotencyKey{}
+	}
+	k.CreatedAt = now
+	k.Response = nil
+	s.keys[id] = *k
+	return nil, nil
+}
+
+func (r *MemoryCarRepo) CompleteIdempotencyKey(ctx context.Context, scope, key string, resp models.StoredResponse) error {
+	s := &r.idempotency
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := [2]string{scope, key}
+	k, ok := s.keys[id]
+	if !ok {
+		return nil
+	}
+	k.Response = &resp
+	s.keys[id] = cloneIdempotencyKey(k)
+	return nil
+}
+
+func (r *MemoryCarRepo) ReleaseIdempotencyKey(ctx context.Context, scope, key string) error {
+	s := &r.idempotency
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := [2]string{scope, key}
+	if k, ok := s.keys[id]; ok && k.Response == nil {
+		delete(s.keys, id)
+	}
+	return nil
+}
+
+func (r *MemoryCarRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	s := &r.idempotency
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for id, k := range s.keys {
+		if k.ExpiresAt.Before(before) {
+			delete(s.keys, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ IdempotencyProvider = (*SQLiteCarRepo)(nil)
+
+func (r *SQLiteCarRepo) ReserveIdempotencyKey(ctx context.Context, k *models.IdempotencyKey, staleBefore time.Time) (*models.IdempotencyKey, error) {
+	const (
+		reserveQuery = `
+			INSERT INTO idempotency_keys (scope, key, request_hash, created_at, expires_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (scope, key) DO UPDATE
+			SET request_hash = excluded.request_hash, status_code = 0, headers = '{}', body = NULL,
+				created_at = excluded.created_at, expires_at = excluded.expires_at
+			WHERE idempotency_keys.expires_at <= excluded.created_at
+				OR (idempotency_keys.status_code = 0 AND idempotency_keys.created_at < ?)
+			RETURNING created_at;
+		`
+		selectQuery = `
+			SELECT request_hash, status_code, headers, body, created_at, expires_at
+			FROM idempotency_keys
+			WHERE scope = ? AND key = ?;
+		`
+	)
+	now := sqliteNow()
+	var createdAt string
+	err := r.db.QueryRowContext(ctx, reserveQuery, k.Scope, k.Key, k.RequestHash, formatSQLiteTime(now),
+		formatSQLiteTime(k.ExpiresAt), formatSQLiteTime(staleBefore)).Scan(&createdAt)
+	if err == nil {
+		k.CreatedAt = now
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	existing := models.IdempotencyKey{Scope: k.Scope, Key: k.Key}
+	var (
+		status               int
+		headers              string
+		body                 []byte
+		created, expiresText string
+	)
+	err = r.db.QueryRowContext(ctx, selectQuery, k.Scope, k.Key).Scan(&existing.RequestHash, &status, &headers,
+		&body, &created, &expiresText)
+	if err != nil {
+		return nil, err
+	}
+	if existing.CreatedAt, err = time.Parse(sqliteTimeLayout, created); err != nil {
+		return nil, err
+	}
+	if existing.ExpiresAt, err = time.Parse(sqliteTimeLayout, expiresText); err != nil {
+		return nil, err
+	}
+	if existing.Response, err = storedResponse(status, []byte(headers), body); err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+func (r *SQLiteCarRepo) CompleteIdempotencyKey(ctx context.Context, scope, key string, resp models.StoredResponse) error {
+	const query = `
+		UPDATE idempotency_keys
+		SET status_code = ?, headers = ?, body = ?
+		WHERE scope = ? AND key = ?;
+	`
+	headers, err := json.Marshal(nonNilHeaders(resp.Headers))
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, query, resp.Status, string(headers), resp.Body, scope, key)
+	return err
+}
+
+func (r *SQLiteCarRepo) ReleaseIdempotencyKey(ctx context.Context, scope, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE scope = ? AND key = ? AND status_code = 0;`,
+		scope, key)
+	return err
+}
+
+func (r *SQLiteCarRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < ?;`, formatSQLiteTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ ImageProvider = (*CarRepo)(nil)
+
+const imageColumns = `id, car_id, position, content_type, size, width, height, thumbnails, created_at`
+
+func imageLimitError(limit int) error {
+	return fmt.Errorf("%w: car already has %d images", apperr.ErrConflict, limit)
+}
+
+// orderImages проверяет, что ids перечисляют фотографии current ровно по разу,
+// и возвращает их в этом порядке с новыми позициями.
+func orderImages(current []models.CarImage, ids []string) ([]models.CarImage, error) {
+	if len(ids) != len(current) {
+		return nil, fmt.Errorf("%w: ids must list all %d images of the car", apperr.ErrInvalidInput, len(current))
+	}
+	byID := make(map[string]models.CarImage, len(current))
+	for _, img := range current {
+		byID[img.ID] = img
+	}
+	ordered := make([]models.CarImage, len(ids))
+	for i, id := range ids {
+		img, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: image %s is not an image of the car or is listed twice", apperr.ErrInvalidInput, id)
+		}
+		delete(byID, id)
+		img.Position = i
+		ordered[i] = img
+	}
+	return ordered, nil
+}
+
+func scanImage(row rowScanner) (models.CarImage, error) {
+	var img models.CarImage
+	err := row.Scan(&img.ID, &img.CarID, &img.Position, &img.ContentType, &img.Size, &img.Width, &img.Height,
+		&img.Thumbnails, &img.CreatedAt)
+	return img, err
+}
+
+// lockLiveCar блокирует машину вне корзины до конца транзакции.
+func lockLiveCar(ctx context.Context, tx pgx.Tx, id string) error {
+	car, err := lockCar(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if car.DeletedAt != nil {
+		return apperr.ErrNotFound
+	}
+	return nil
+}
+
+func touchCar(ctx context.Context, tx pgx.Tx, id string) error {
+	_, err := tx.Exec(ctx, `UPDATE cars SET version = version + 1 WHERE id = $1;`, id)
+	return err
+}
+
+func (r *CarRepo) AddImage(ctx context.Context, img *models.CarImage, limit int) error {
+	const query = `
+		INSERT INTO car_images (id, car_id, position, content_type, size, width, height, thumbnails)
+		SELECT $1::uuid, $2::uuid, COALESCE(MAX(position) + 1, 0), $3::text, $4::bigint, $5::int, $6::int, $7::text[]
+		FROM car_images
+		WHERE car_id = $2::uuid
+		HAVING COUNT(*) < $8
+		RETURNING position, created_at;
+	`
+	return r.inTx(ctx, func(tx pgx.Tx) error {
+		// блокировка машины упорядочивает конкурентные загрузки и не даёт превысить limit
+		if err := lockLiveCar(ctx, tx, img.CarID); err != nil {
+			return err
+		}
+		thumbnails := img.Thumbnails
+		if thumbnails == nil {
+			thumbnails = []string{}
+		}
+		err := tx.QueryRow(ctx, query, img.ID, img.CarID, img.ContentType, img.Size, img.Width, img.Height,
+			thumbnails, limit).Scan(&img.Position, &img.CreatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return imageLimitError(limit)
+		}
+		if err != nil {
+			return mapPgErr(err)
+		}
+		return touchCar(ctx, tx, img.CarID)
+	})
+}
+
+func (r *CarRepo) ListImages(ctx context.Context, carIDs ...string) ([]models.CarImage, error) {
+	const query = `
+		SELECT ` + imageColumns + `
+		FROM car_images
+		WHERE car_id = ANY($1::text[]::uuid[])
+		ORDER BY car_id, position;
+	`
+	return r.queryImages(ctx, query, carIDs)
+}
+
+func (r *CarRepo) queryImages(ctx context.Context, query string, args ...any) ([]models.CarImage, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.CarImage{}
+	for rows.Next() {
+		img, err := scanImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, img)
+	}
+	return out, rows.Err()
+}
+
+func (r *CarRepo) GetImage(ctx context.Context, carID, id string) (*models.CarImage, error) {
+	const query = `
+		SELECT ` + imageColumns + `
+		FROM car_images
+		WHERE id = $1 AND car_id = $2;
+	`
+	img, err := scanImage(r.pool.QueryRow(ctx, query, id, carID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+func (r *CarRepo) DeleteImage(ctx context.Context, carID, id string) error {
+	const (
+		deleteQuery = `
+			DELETE FROM car_images
+			WHERE id = $1 AND car_id = $2
+			RETURNING position;
+		`
+		shiftQuery = `
+			UPDATE car_images
+			SET position = position - 1
+			WHERE car_id = $1 AND position > $2;
+		`
+	)
+	return r.inTx(ctx, func(tx pgx.Tx) error {
+		if err := lockLiveCar(ctx, tx, carID); err != nil {
+			return err
+		}
+		var pos int
+		err := tx.QueryRow(ctx, deleteQuery, id, carID).Scan(&pos)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperr.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, shiftQuery, carID, pos); err != nil {
+			return err
+		}
+		return touchCar(ctx, tx, carID)
+	})
+}
+
+func (r *CarRepo) ReorderImages(ctx context.Context, carID string, ids []string) ([]models.CarImage, error) {
+	const (
+		listQuery = `
+			SELECT ` + imageColumns + `
+			FROM car_images
+			WHERE car_id = $1
+			ORDER BY position;
+		`
+		updateQuery = `
+			UPDATE car_images AS i
+			SET position = o.position - 1
+			FROM unnest($2::text[]::uuid[]) WITH ORDINALITY AS o(id, position)
+			WHERE i.car_id = $1 AND i.id = o.id;
+		`
+	)
+	var ordered []models.CarImage
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		if err := lockLiveCar(ctx, tx, carID); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, listQuery, carID)
+		if err != nil {
+			return err
+		}
+		current, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.CarImage, error) {
+			return scanImage(row)
+		})
+		if err != nil {
+			return err
+		}
+		if ordered, err = orderImages(current, ids); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, updateQuery, carID, ids); err != nil {
+			return err
+		}
+		return touchCar(ctx, tx, carID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+func (r *CarRepo) OrphanedImages(ctx context.Context, limit int) ([]models.CarImage, error) {
+	const query = `
+		SELECT ` + imageColumns + `
+		FROM car_images AS i
+		WHERE NOT EXISTS (SELECT 1 FROM cars AS c WHERE c.id = i.car_id)
+		ORDER BY created_at
+		LIMIT $1;
+	`
+	return r.queryImages(ctx, query, limit)
+}
+
+func (r *CarRepo) DeleteOrphanedImages(ctx context.Context, ids []string) (int64, error) {
+	const query = `
+		DELETE FROM car_images AS i
+		WHERE i.id = ANY($1::text[]::uuid[])
+			AND NOT EXISTS (SELECT 1 FROM cars AS c WHERE c.id = i.car_id);
+	`
+	ct, err := r.pool.Exec(ctx, query, ids)
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ ImageProvider = (*MemoryCarRepo)(nil)
+
+func cloneImage(img models.CarImage) models.CarImage {
+	img.Thumbnails = slices.Clone(img.Thumbnails)
+	return img
+}
+
+// liveCar возвращает машину вне корзины для изменения под r.mu.
+func (r *MemoryCarRepo) liveCar(id string) (*memoryCar, error) {
+	item, ok := r.cars[id]
+	if !ok || item.car.DeletedAt != nil {
+		return nil, apperr.ErrNotFound
+	}
+	return &item, nil
+}
+
+// touchCar увеличивает версию машины после изменения её галереи.
+func (r *MemoryCarRepo) touchCar(item *memoryCar) {
+	item.car.Version++
+	r.cars[item.car.ID] = *item
+}
+
+// carImages возвращает индексы фотографий машины в r.images по порядку галереи.
+func (r *MemoryCarRepo) carImages(carID string) []int {
+	var idx []int
+	for i, img := range r.images {
+		if img.CarID == carID {
+			idx = append(idx, i)
+		}
+	}
+	slices.SortFunc(idx, func(a, b int) int { return r.images[a].Position - r.images[b].Position })
+	return idx
+}
+
+func (r *MemoryCarRepo) AddImage(ctx context.Context, img *models.CarImage, limit int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, err := r.liveCar(img.CarID)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(r.images, func(other models.CarImage) bool { return other.ID == img.ID }) {
+		return fmt.Errorf("%w: car_images_pkey", apperr.ErrConflict)
+	}
+	idx := r.carImages(img.CarID)
+	if len(idx) >= limit {
+		return imageLimitError(limit)
+	}
+	img.Position = 0
+	if len(idx) > 0 {
+		img.Position = r.images[idx[len(idx)-1]].Position + 1
+	}
+	img.CreatedAt = time.Now().UTC()
+	r.images = append(r.images, cloneImage(*img))
+	r.touchCar(item)
+	return nil
+}
+
+func (r *MemoryCarRepo) ListImages(ctx context.Context, carIDs ...string) ([]models.CarImage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := []models.CarImage{}
+	for _, img := range r.images {
+		if slices.Contains(carIDs, img.CarID) {
+			out = append(out, cloneImage(img))
+		}
+	}
+	slices.SortFunc(out, func(a, b models.CarImage) int {
+		return cmp.Or(strings.Compare(a.CarID, b.CarID), a.Position-b.Position)
+	})
+	return out, nil
+}
+
+func (r *MemoryCarRepo) GetImage(ctx context.Context, carID, id string) (*models.CarImage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, img := range r.images {
+		if img.ID == id && img.CarID == carID {
+			img = cloneImage(img)
+			return &img, nil
+		}
+	}
+	return nil, apperr.ErrNotFound
+}
+
+func (r *MemoryCarRepo) DeleteImage(ctx context.Context, carID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, err := r.liveCar(carID)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(r.images, func(img models.CarImage) bool { return img.ID == id && img.CarID == carID })
+	if i < 0 {
+		return apperr.ErrNotFound
+	}
+	pos := r.images[i].Position
+	r.images = slices.Delete(r.images, i, i+1)
+	for j := range r.images {
+		if r.images[j].CarID == carID && r.images[j].Position > pos {
+			r.images[j].Position--
+		}
+	}
+	r.touchCar(item)
+	return nil
+}
+
+func (r *MemoryCarRepo) ReorderImages(ctx context.Context, carID string, ids []string) ([]models.CarImage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, err := r.liveCar(carID)
+	if err != nil {
+		return nil, err
+	}
+	idx := r.carImages(carID)
+	current := make([]models.CarImage, len(idx))
+	for k, i := range idx {
+		current[k] = r.images[i]
+	}
+	ordered, err := orderImages(current, ids)
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[string]int, len(ordered))
+	for _, img := range ordered {
+		positions[img.ID] = img.Position
+	}
+	for _, i := range idx {
+		r.images[i].Position = positions[r.images[i].ID]
+	}
+	r.touchCar(item)
+
+	out := make([]models.CarImage, len(ordered))
+	for k, img := range ordered {
+		out[k] = cloneImage(img)
+	}
+	return out, nil
+}
+
+func (r *MemoryCarRepo) OrphanedImages(ctx context.Context, limit int) ([]models.CarImage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := []models.CarImage{}
+	for _, img := range r.images {
+		if len(out) == limit {
+			break
+		}
+		if _, ok := r.cars[img.CarID]; !ok {
+			out = append(out, cloneImage(img))
+		}
+	}
+	return out, nil
+}
+
+func (r *MemoryCarRepo) DeleteOrphanedImages(ctx context.Context, ids []string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	r.images = slices.DeleteFunc(r.images, func(img models.CarImage) bool {
+		_, live := r.cars[img.CarID]
+		if !live && slices.Contains(ids, img.ID) {
+			n++
+			return true
+		}
+		return false
+	})
+	return n, nil
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ ImageProvider = (*SQLiteCarRepo)(nil)
+
+func scanSQLiteImage(row rowScanner) (models.CarImage, error) {
+	var (
+		img                   models.CarImage
+		thumbnails, createdAt string
+	)
+	err := row.Scan(&img.ID, &img.CarID, &img.Position, &img.ContentType, &img.Size, &img.Width, &img.Height,
+		&thumbnails, &createdAt)
+	if err != nil {
+		return models.CarImage{}, err
+	}
+	if err := json.Unmarshal([]byte(thumbnails), &img.Thumbnails); err != nil {
+		return models.CarImage{}, fmt.Errorf("parse image thumbnails %q: %w", thumbnails, err)
+	}
+	if img.CreatedAt, err = time.Parse(sqliteTimeLayout, createdAt); err != nil {
+		return models.CarImage{}, fmt.Errorf("parse image created_at %q: %w", createdAt, err)
+	}
+	return img, nil
+}
+
+// sqliteLiveCar проверяет, что машина есть и не в корзине. Транзакции SQLite идут по очереди,
+// поэтому отдельная блокировка строки не нужна.
+func sqliteLiveCar(ctx context.Context, tx *sql.Tx, id string) error {
+	car, err := sqliteGetCar(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if car.DeletedAt != nil {
+		return apperr.ErrNotFound
+	}
+	return nil
+}
+
+func sqliteTouchCar(ctx context.Context, tx *sql.Tx, id string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE cars SET version = version + 1 WHERE id = ?;`, id)
+	return err
+}
+
+func (r *SQLiteCarRepo) AddImage(ctx context.Context, img *models.CarImage, limit int) error {
+	const (
+		countQuery = `
+			SELECT COUNT(*), COALESCE(MAX(position) + 1, 0)
+			FROM car_images
+			WHERE car_id = ?;
+		`
+		insertQuery = `
+			INSERT INTO car_images (id, car_id, position, content_type, size, width, height, thumbnails, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+		`
+	)
+	thumbnails, err := json.Marshal(nonNilTypes(img.Thumbnails))
+	if err != nil {
+		return err
+	}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := sqliteLiveCar(ctx, tx, img.CarID); err != nil {
+			return err
+		}
+		var count, next int
+		if err := tx.QueryRowContext(ctx, countQuery, img.CarID).Scan(&count, &next); err != nil {
+			return err
+		}
+		if count >= limit {
+			return imageLimitError(limit)
+		}
+		now := sqliteNow()
+		_, err := tx.ExecContext(ctx, insertQuery, img.ID, img.CarID, next, img.ContentType, img.Size,
+			img.Width, img.Height, string(thumbnails), formatSQLiteTime(now))
+		if err != nil {
+			return mapSQLiteErr(err)
+		}
+		img.Position, img.CreatedAt = next, now
+		return sqliteTouchCar(ctx, tx, img.CarID)
+	})
+}
+
+func (r *SQLiteCarRepo) ListImages(ctx context.Context, carIDs ...string) ([]models.CarImage, error) {
+	const query = `
+		SELECT ` + imageColumns + `
+		FROM car_images
+		WHERE car_id IN (SELECT value FROM json_each(?))
+		ORDER BY car_id, position;
+	`
+	ids, err := json.Marshal(nonNilTypes(carIDs))
+	if err != nil {
+		return nil, err
+	}
+	return queryImagesSQLite(ctx, r.db, query, string(ids))
+}
+
+type sqliteQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func queryImagesSQLite(ctx context.Context, db sqliteQueryer, query string, args ...any) ([]models.CarImage, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.CarImage{}
+	for rows.Next() {
+		img, err := scanSQLiteImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, img)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteCarRepo) GetImage(ctx context.Context, carID, id string) (*models.CarImage, error) {
+	const query = `
+		SELECT ` + imageColumns + `
+		FROM car_images
+		WHERE id = ? AND car_id = ?;
+	`
+	img, err := scanSQLiteImage(r.db.QueryRowContext(ctx, query, id, carID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+func (r *SQLiteCarRepo) DeleteImage(ctx context.Context, carID, id string) error {
+	const (
+		deleteQuery = `
+			DELETE FROM car_images
+			WHERE id = ? AND car_id = ?
+			RETURNING position;
+		`
+		shiftQuery = `
+			UPDATE car_images
+			SET position = position - 1
+			WHERE car_id = ? AND position > ?;
+		`
+	)
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := sqliteLiveCar(ctx, tx, carID); err != nil {
+			return err
+		}
+		var pos int
+		err := tx.QueryRowContext(ctx, deleteQuery, id, carID).Scan(&pos)
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperr.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, shiftQuery, carID, pos); err != nil {
+			return err
+		}
+		return sqliteTouchCar(ctx, tx, carID)
+	})
+}
+
+func (r *SQLiteCarRepo) ReorderImages(ctx context.Context, carID string, ids []string) ([]models.CarImage, error) {
+	const (
+		listQuery = `
+			SELECT ` + imageColumns + `
+			FROM car_images
+			WHERE car_id = ?
+			ORDER BY position;
+		`
+		updateQuery = `UPDATE car_images SET position = ? WHERE id = ?;`
+	)
+	var ordered []models.CarImage
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := sqliteLiveCar(ctx, tx, carID); err != nil {
+			return err
+		}
+		current, err := queryImagesSQLite(ctx, tx, listQuery, carID)
+		if err != nil {
+			return err
+		}
+		if ordered, err = orderImages(current, ids); err != nil {
+			return err
+		}
+		for _, img := range ordered {
+			if _, err := tx.ExecContext(ctx, updateQuery, img.Position, img.ID); err != nil {
+				return err
+			}
+		}
+		return sqliteTouchCar(ctx, tx, carID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+func (r *SQLiteCarRepo) OrphanedImages(ctx context.Context, limit int) ([]models.CarImage, error) {
+	const query = `
+		SELECT ` + imageColumns + `
+		FROM car_images AS i
+		WHERE NOT EXISTS (SELECT 1 FROM cars AS c WHERE c.id = i.car_id)
+		ORDER BY created_at
+		LIMIT ?;
+	`
+	return queryImagesSQLite(ctx, r.db, query, limit)
+}
+
+func (r *SQLiteCarRepo) DeleteOrphanedImages(ctx context.Context, ids []string) (int64, error) {
+	const query = `
+		DELETE FROM car_images
+		WHERE id IN (SELECT value FROM json_each(?))
+			AND NOT EXISTS (SELECT 1 FROM cars AS c WHERE c.id = car_images.car_id);
+	`
+	list, err := json.Marshal(nonNilTypes(ids))
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.db.ExecContext(ctx, query, string(list))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/reqctx"
+)
+
+// defaultImportRowsLimit применяется, если limit в ListImportRows не задан.
+const defaultImportRowsLimit = 500
+
+var _ ImportProvider = (*CarRepo)(nil)
+
+// startImport заполняет ID (если он не выбран заранее) и автора импорта и обнуляет счётчики.
+func startImport(ctx context.Context, imp *models.CarImport) {
+	if imp.ID == "" {
+		imp.ID = uuid.NewString()
+	}
+	imp.Actor = reqctx.Actor(ctx)
+	imp.RequestID = reqctx.RequestID(ctx)
+	imp.Total, imp.Accepted, imp.Rejected = 0, 0, 0
+}
+
+// countImportRow выставляет статус строки (и ID будущей машины для принятой) и обновляет счётчики.
+func countImportRow(imp *models.CarImport, row *models.ImportRow) {
+	imp.Total++
+	if row.Reason != "" {
+		row.Status = models.ImportRowRejected
+		imp.Rejected++
+		return
+	}
+	row.Status = models.ImportRowAccepted
+	row.CarID = uuid.NewString()
+	imp.Accepted++
+}
+
+// importVINTaken — причина отклонения строки, VIN которой уже есть у машины вне корзины
+// или у принятой строки выше в том же файле.
+const importVINTaken = "vin already exists"
+
+// rejectImportRow отклоняет строку, которую countImportRow уже посчитал принятой.
+func rejectImportRow(imp *models.CarImport, row *models.ImportRow, reason string) {
+	row.Status, row.CarID, row.Reason = models.ImportRowRejected, "", reason
+	imp.Accepted--
+	imp.Rejected++
+}
+
+// importedCarsQuery выбирает страницу принятых строк и их машины. Колонки машины без префикса:
+// у подзапроса есть только line и car_id, а в cars таких колонок нет. Плейсхолдеры — ? для SQLite,
+// CarRepo подставляет $n.
+const importedCarsQuery = `
+	SELECT p.line, %s
+	FROM (
+		SELECT line, car_id FROM car_import_rows
+		WHERE import_id = %s AND status = 'accepted' AND line > %s
+		ORDER BY line
+		LIMIT %s
+	) p
+	JOIN cars ON cars.id = p.car_id
+	ORDER BY p.line;
+`
+
+// lineRow сканирует номер строки импорта перед колонками машины.
+type lineRow struct {
+	row  rowScanner
+	line *int
+}
+
+func (r lineRow) Scan(dest ...any) error {
+	return r.row.Scan(append([]any{r.line}, dest...)...)
+}
+
+func importRowsLimit(limit int) int {
+	if limit <= 0 {
+		return defaultImportRowsLimit
+	}
+	return limit
+}
+
+// ImportCars копирует строки потоком (COPY) во временную таблицу car_import_staging,
+// отклоняет в ней строки с занятым VIN, затем INSERT ... SELECT переносит отчёт и принятые
+// машины вместе с историей цены, аудитом и outbox. Снимки для аудита и outbox собираются в Go
+// теми же функциями, что и для одиночных изменений.
+func (r *CarRepo) ImportCars(ctx context.Context, imp *models.CarImport, next func() (models.ImportRow, error)) error {
+	const (
+		// колонки атрибутов названы как в cars, поэтому статус строки отчёта — row_status
+		stagingQuery = `
+			CREATE TEMP TABLE car_import_staging (
+				line INT NOT NULL,
+				car_id UUID NULL,
+				row_status VARCHAR(16) NOT NULL,
+				brand TEXT NOT NULL,
+				model TEXT NOT NULL,
+				year INT NOT NULL,
+				reason TEXT NOT NULL,
+				vin TEXT NULL,
+				mileage INT NULL,
+				color TEXT NULL,
+				body_type TEXT NULL,
+				fuel_type TEXT NULL,
+				transmission TEXT NULL,
+				engine_volume INT NULL,
+				engine_power INT NULL,
+				price NUMERIC(14,2) NULL,
+				currency TEXT NULL,
+				condition TEXT NULL,
+				status TEXT NULL,
+				audit_after JSONB NULL,
+				audit_diff JSONB NULL,
+				payload JSONB NULL
+			) ON COMMIT DROP;
+		`
+		// подзапрос видит статусы до UPDATE, так что повтор VIN внутри файла отклоняется
+		// и тогда, когда первую строку с ним отклонила та же проверка
+		vinQuery = `
+			UPDATE car_import_staging s
+			SET row_status = 'rejected', car_id = NULL, reason = $1,
+				audit_after = NULL, audit_diff = NULL, payload = NULL
+			WHERE s.row_status = 'accepted' AND s.vin IS NOT NULL AND (
+				EXISTS (SELECT 1 FROM cars c WHERE c.vin = s.vin AND c.deleted_at IS NULL)
+				OR EXISTS (
+					SELECT 1 FROM car_import_staging p
+					WHERE p.vin = s.vin AND p.line < s.line AND p.row_status = 'accepted'
+				)
+			);
+		`
+		headerQuery = `
+			INSERT INTO car_imports (id, format, dry_run, total, accepted, rejected, actor, request_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING created_at;
+		`
+		reportQuery = `
+			INSERT INTO car_import_rows (import_id, line, status, car_id, brand, model, year, reason)
+			SELECT $1, line, row_status, car_id, brand, model, year, reason
+			FROM car_import_staging;
+		`
+		carsQuery = `
+			INSERT INTO cars (id, brand, model, year, vin, created_at, ` + carAttrColumns + `)
+			SELECT car_id, brand, model, year, vin, $1, ` + carAttrColumns + `
+			FROM car_import_staging
+			WHERE row_status = 'accepted'
+			ORDER BY line;
+		`
+		pricesQuery = `
+			INSERT INTO car_prices (car_id, price, currency, actor, request_id)
+			SELECT car_id, price, currency, $1, $2
+			FROM car_import_staging
+			WHERE row_status = 'accepted' AND price IS NOT NULL
+			ORDER BY line;
+		`
+		auditQuery = `
+			INSERT INTO car_audit (car_id, action, actor, request_id, after, diff)
+			SELECT car_id, $1, $2, $3, audit_after, audit_diff
+			FROM car_import_staging
+			WHERE row_status = 'accepted'
+			ORDER BY line;
+		`
+		outboxQuery = `
+			INSERT INTO car_outbox (event_type, car_id, actor, request_id, payload)
+			SELECT $1, car_id, $2, $3, payload
+			FROM car_import_staging
+			WHERE row_status = 'accepted'
+			ORDER BY line;
+		`
+	)
+	startImport(ctx, imp)
+	columns := append([]string{"line", "car_id", "row_status", "brand", "model", "year", "reason", "vin"},
+		carAttrColumnNames...)
+	columns = append(columns, "audit_after", "audit_diff", "payload")
+
+	return r.inTx(ctx, func(tx pgx.Tx) error {
+		var now time.Time
+		if err := tx.QueryRow(ctx, `SELECT LOCALTIMESTAMP;`).Scan(&now); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, stagingQuery); err != nil {
+			return err
+		}
+		// ошибку источника pgx заменяет ответом сервера на CopyFail, поэтому сохраняем её сами
+		var srcErr error
+		_, err := tx.CopyFrom(ctx, pgx.Identifier{"car_import_staging"}, columns, pgx.CopyFromFunc(func() ([]any, error) {
+			row, err := next()
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			if err != nil {
+				srcErr = err
+				return nil, err
+			}
+			countImportRow(imp, &row)
+			values := append([]any{row.Line, nil, row.Status, row.Brand, row.Model, row.Year, row.Reason,
+				nullVIN(row.VIN)}, attrArgs(row.Attributes)...)
+			if row.Status == models.ImportRowRejected {
+				return append(values, nil, nil, nil), nil
+			}
+			car := row.Car(now)
+			entry, err := newAuditEntry(ctx, models.AuditActionCreate, car.ID, nil, &car)
+			if err != nil {
+				return nil, err
+			}
+			evt, err := newOutboxEvent(ctx, models.AuditActionCreate, &car)
+			if err != nil {
+				return nil, err
+			}
+			values[1] = row.CarID
+			return append(values, entry.After, entry.Diff, evt.Payload), nil
+		}))
+		if srcErr != nil {
+			return srcErr
+		}
+		if err != nil {
+			return mapPgErr(err)
+		}
+		if imp.Accepted > 0 {
+			tag, err := tx.Exec(ctx, vinQuery, importVINTaken)
+			if err != nil {
+				return err
+			}
+			imp.Accepted -= int(tag.RowsAffected())
+			imp.Rejected += int(tag.RowsAffected())
+		}
+
+		err = tx.QueryRow(ctx, headerQuery, imp.ID, imp.Format, imp.DryRun, imp.Total, imp.Accepted,
+			imp.Rejected, imp.Actor, imp.RequestID).Scan(&imp.CreatedAt)
+		if err != nil {
+			return mapPgErr(err)
+		}
+		if _, err := tx.Exec(ctx, reportQuery, imp.ID); err != nil {
+			return err
+		}
+		if imp.DryRun || imp.Accepted == 0 {
+			return nil
+		}
+		if _, err := tx.Exec(ctx, carsQuery, now); err != nil {
+			return mapPgErr(err)
+		}
+		if _, err := tx.Exec(ctx, pricesQuery, imp.Actor, imp.RequestID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, auditQuery, models.AuditActionCreate, imp.Actor, imp.RequestID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, outboxQuery, models.EventCarCreated, imp.Actor, imp.RequestID)
+		return err
+	})
+}
+
+func (r *CarRepo) GetImport(ctx context.Context, id string) (*models.CarImport, error) {
+	const query = `
+		SELECT id, format, dry_run, total, accepted, rejected, actor, request_id, created_at
+		FROM car_imports
+		WHERE id = $1;
+	`
+	var imp models.CarImport
+	err := r.pool.QueryRow(ctx, query, id).Scan(&imp.ID, &imp.Format, &imp.DryRun, &imp.Total,
+		&imp.Accepted, &imp.Rejected, &imp.Actor, &imp.RequestID, &imp.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+func (r *CarRepo) ListImportRows(ctx context.Context, importID string, afterLine, limit int) ([]models.ImportRow, error) {
+	const query = `
+		SELECT line, status, COALESCE(car_id::text, ''), brand, model, year, reason
+		FROM car_import_rows
+		WHERE import_id = $1 AND line > $2
+		ORDER BY line
+		LIMIT $3;
+	`
+	rows, err := r.pool.Query(ctx, query, importID, afterLine, importRowsLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.ImportRow{}
+	for rows.Next() {
+		var row models.ImportRow
+		if err := rows.Scan(&row.Line, &row.Status, &row.CarID, &row.Brand, &row.Model, &row.Year, &row.Reason); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+func (r *CarRepo) ListImportedCars(ctx context.Context, importID string, afterLine, limit int) ([]models.ImportedCar, error) {
+	query := fmt.Sprintf(importedCarsQuery, carColumns, "$1", "$2", "$3")
+	rows, err := r.pool.Query(ctx, query, importID, afterLine, importRowsLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.ImportedCar{}
+	for rows.Next() {
+		var ic models.ImportedCar
+		if ic.Car, err = scanCar(lineRow{row: rows, line: &ic.Line}); err != nil {
+			return nil, err
+		}
+		out = append(out, ic)
+	}
+	return out, rows.Err()
+}
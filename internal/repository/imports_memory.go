@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ ImportProvider = (*MemoryCarRepo)(nil)
+
+// memoryImport — импорт и его отчёт; хранятся под r.mu вместе с машинами.
+type memoryImport struct {
+	imp  models.CarImport
+	rows []models.ImportRow
+}
+
+// ImportCars сначала читает все строки, а затем применяет их под блокировкой:
+// держать r.mu, пока клиент загружает файл, нельзя. Строки с занятым VIN отклоняются
+// под той же блокировкой.
+func (r *MemoryCarRepo) ImportCars(ctx context.Context, imp *models.CarImport, next func() (models.ImportRow, error)) error {
+	startImport(ctx, imp)
+	var rows []models.ImportRow
+	for {
+		row, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		countImportRow(imp, &row)
+		rows = append(rows, row)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.importIndex(imp.ID) >= 0 {
+		return fmt.Errorf("%w: import %s already exists", apperr.ErrConflict, imp.ID)
+	}
+	now := time.Now().UTC()
+	vins := map[string]string{}
+	for _, item := range r.cars {
+		takeVIN(vins, &item.car)
+	}
+	for i := range rows {
+		row := &rows[i]
+		if row.Status != models.ImportRowAccepted {
+			continue
+		}
+		car := row.Car(now)
+		if checkVIN(vins, car.VIN, car.ID) != nil {
+			rejectImportRow(imp, row, importVINTaken)
+			continue
+		}
+		takeVIN(vins, &car)
+		if imp.DryRun {
+			continue
+		}
+		if err := r.recordChange(ctx, models.AuditActionCreate, nil, &car); err != nil {
+			return err
+		}
+		r.seq++
+		r.cars[car.ID] = memoryCar{car: car, seq: r.seq}
+	}
+	imp.CreatedAt = now
+	r.imports = append(r.imports, memoryImport{imp: *imp, rows: rows})
+	return nil
+}
+
+func (r *MemoryCarRepo) importIndex(id string) int {
+	return slices.IndexFunc(r.imports, func(m memoryImport) bool { return m.imp.ID == id })
+}
+
+func (r *MemoryCarRepo) GetImport(ctx context.Context, id string) (*models.CarImport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i := r.importIndex(id)
+	if i < 0 {
+		return nil, apperr.ErrNotFound
+	}
+	imp := r.imports[i].imp
+	return &imp, nil
+}
+
+func (r *MemoryCarRepo) ListImportRows(ctx context.Context, importID string, afterLine, limit int) ([]models.ImportRow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := []models.ImportRow{}
+	i := r.importIndex(importID)
+	if i < 0 {
+		return out, nil
+	}
+	limit = importRowsLimit(limit)
+	for _, row := range r.imports[i].rows {
+		if row.Line <= afterLine {
+			continue
+		}
+		if len(out) == limit {
+			break
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+func (r *MemoryCarRepo) ListImportedCars(ctx context.Context, importID string, afterLine, limit int) ([]models.ImportedCar, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := []models.ImportedCar{}
+	i := r.importIndex(importID)
+	if i < 0 {
+		return out, nil
+	}
+	limit = importRowsLimit(limit)
+	seen := 0
+	for _, row := range r.imports[i].rows {
+		if row.Line <= afterLine || row.Status != models.ImportRowAccepted {
+			continue
+		}
+		if seen == limit {
+			break
+		}
+		seen++
+		if item, ok := r.cars[row.CarID]; ok {
+			out = append(out, models.ImportedCar{Line: row.Line, Car: cloneCar(item.car)})
+		}
+	}
+	return out, nil
+}
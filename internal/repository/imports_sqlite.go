@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ ImportProvider = (*SQLiteCarRepo)(nil)
+
+// ImportCars пишет строки по одной внутри транзакции: в SQLite нет COPY,
+// а запись в пределах одной транзакции и так дешёвая. VIN проверяется перед каждой
+// строкой; seen помнит VIN принятых строк и при DryRun, когда машины не пишутся.
+func (r *SQLiteCarRepo) ImportCars(ctx context.Context, imp *models.CarImport, next func() (models.ImportRow, error)) error {
+	const (
+		headerQuery = `
+			INSERT INTO car_imports (id, format, dry_run, total, accepted, rejected, actor, request_id, created_at)
+			VALUES (?, ?, ?, 0, 0, 0, ?, ?, ?);
+		`
+		rowQuery = `
+			INSERT INTO car_import_rows (import_id, line, status, car_id, brand, model, year, reason)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+		`
+		carQuery = `
+			INSERT INTO cars (id, brand, model, year, vin, created_at, ` + carAttrColumns + `)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+		`
+		vinQuery = `
+			SELECT EXISTS (SELECT 1 FROM cars WHERE vin = ? AND deleted_at IS NULL);
+		`
+		countsQuery = `
+			UPDATE car_imports SET total = ?, accepted = ?, rejected = ? WHERE id = ?;
+		`
+	)
+	startImport(ctx, imp)
+	now := sqliteNow()
+	seen := map[string]bool{}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, headerQuery, imp.ID, imp.Format, imp.DryRun, imp.Actor, imp.RequestID,
+			formatSQLiteTime(now))
+		if err != nil {
+			return mapSQLiteErr(err)
+		}
+		for {
+			row, err := next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			countImportRow(imp, &row)
+			if row.Status == models.ImportRowAccepted && row.VIN != "" {
+				taken := seen[row.VIN]
+				if !taken {
+					if err := tx.QueryRowContext(ctx, vinQuery, row.VIN).Scan(&taken); err != nil {
+						return err
+					}
+				}
+				if taken {
+					rejectImportRow(imp, &row, importVINTaken)
+				} else {
+					seen[row.VIN] = true
+				}
+			}
+			var carID any
+			if row.CarID != "" {
+				carID = row.CarID
+			}
+			_, err = tx.ExecContext(ctx, rowQuery, imp.ID, row.Line, row.Status, carID, row.Brand, row.Model,
+				row.Year, row.Reason)
+			if err != nil {
+				return mapSQLiteErr(err)
+			}
+			if imp.DryRun || row.Status != models.ImportRowAccepted {
+				continue
+			}
+			car := row.Car(now)
+			args := append([]any{car.ID, car.Brand, car.Model, car.Year, nullVIN(car.VIN), formatSQLiteTime(now)},
+				attrArgs(car.CarAttributes)...)
+			_, err = tx.ExecContext(ctx, carQuery, args...)
+			if err != nil {
+				return mapSQLiteErr(err)
+			}
+			if err := sqliteRecordChange(ctx, tx, models.AuditActionCreate, car.ID, nil, &car); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, countsQuery, imp.Total, imp.Accepted, imp.Rejected, imp.ID); err != nil {
+			return err
+		}
+		imp.CreatedAt = now
+		return nil
+	})
+}
+
+func (r *SQLiteCarRepo) GetImport(ctx context.Context, id string) (*models.CarImport, error) {
+	const query = `
+		SELECT id, format, dry_run, total, accepted, rejected, actor, request_id, created_at
+		FROM car_imports
+		WHERE id = ?;
+	`
+	var (
+		imp       models.CarImport
+		createdAt string
+	)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&imp.ID, &imp.Format, &imp.DryRun, &imp.Total,
+		&imp.Accepted, &imp.Rejected, &imp.Actor, &imp.RequestID, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if imp.CreatedAt, err = time.Parse(sqliteTimeLayout, createdAt); err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+func (r *SQLiteCarRepo) ListImportRows(ctx context.Context, importID string, afterLine, limit int) ([]models.ImportRow, error) {
+	const query = `
+		SELECT line, status, COALESCE(car_id, ''), brand, model, year, reason
+		FROM car_import_rows
+		WHERE import_id = ? AND line > ?
+		ORDER BY line
+		LIMIT ?;
+	`
+	rows, err := r.db.QueryContext(ctx, query, importID, afterLine, importRowsLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.ImportRow{}
+	for rows.Next() {
+		var row models.ImportRow
+		if err := rows.Scan(&row.Line, &row.Status, &row.CarID, &row.Brand, &row.Model, &row.Year, &row.Reason); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteCarRepo) ListImportedCars(ctx context.Context, importID string, afterLine, limit int) ([]models.ImportedCar, error) {
+	query := fmt.Sprintf(importedCarsQuery, sqliteCarColumns, "?", "?", "?")
+	rows, err := r.db.QueryContext(ctx, query, importID, afterLine, importRowsLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.ImportedCar{}
+	for rows.Next() {
+		var ic models.ImportedCar
+		if ic.Car, err = scanSQLiteCar(lineRow{row: rows, line: &ic.Line}); err != nil {
+			return nil, err
+		}
+		out = append(out, ic)
+	}
+	return out, rows.Err()
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/pavel97go/service-cars/internal/models"
 )
@@ -9,7 +10,217 @@ import (
 type CarProvider interface {
 	ListCars(ctx context.Context) ([]models.Car, error)
 	GetCarByID(ctx context.Context, id string) (*models.Car, error)
+	// GetCarByVIN ищет машину вне корзины по VIN; apperr.ErrNotFound, если такой нет.
+	GetCarByVIN(ctx context.Context, vin string) (*models.Car, error)
+	// InsertCar, UpdateCar, RestoreByID и ApplyBatch возвращают apperr.ErrConflict,
+	// если VIN уже занят другой машиной вне корзины.
 	InsertCar(ctx context.Context, newCar *models.Car) error
 	UpdateCar(ctx context.Context, updatedCar *models.Car) error
+	// DeleteByID помечает запись удалённой (deleted_at); такие записи не видны в ListCars/GetCarByID.
 	DeleteByID(ctx context.Context, id string) error
+	// ListDeletedCars возвращает корзину: помеченные удалёнными записи, последние удалённые первыми.
+	ListDeletedCars(ctx context.Context) ([]models.Car, error)
+	// RestoreByID снимает пометку удаления; apperr.ErrNotFound, если запись не в корзине.
+	RestoreByID(ctx context.Context, id string) (*models.Car, error)
+	// PurgeDeleted окончательно удаляет записи, помеченные удалёнными раньше before.
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	// GetCarAsOf восстанавливает запись на момент at по журналу аудита;
+	// apperr.ErrNotFound, если машины тогда не было или она была в корзине.
+	GetCarAsOf(ctx context.Context, id string, at time.Time) (*models.Car, error)
+	// ListCarsAsOf возвращает список на момент at в том же порядке, что и ListCars.
+	ListCarsAsOf(ctx context.Context, at time.Time) ([]models.Car, error)
+	// ApplyBatch выполняет операции по порядку в одной транзакции и возвращает результат
+	// каждой. atomic=true: при ошибке любой операции не применяется ни одна, остальные
+	// получают apperr.ErrAborted. Ошибка возвращается только при сбое хранилища.
+	ApplyBatch(ctx context.Context, ops []models.CarMutation, atomic bool) ([]models.MutationResult, error)
+}
+
+// AuditProvider отдаёт журнал изменений. Записи аудита создаются реализациями
+// CarProvider в той же транзакции, что и изменение машины.
+type AuditProvider interface {
+	// ListAudit возвращает страницу записей (новые первыми) и общее число подходящих под фильтр.
+	ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int, error)
+}
+
+// OutboxProvider отдаёт доменные события из outbox. События пишутся реализациями
+// CarProvider в той же транзакции, что и изменение машины.
+type OutboxProvider interface {
+	// PendingEvents возвращает до limit неопубликованных событий, готовых к отправке.
+	// Для каждой машины отдаётся только самое раннее неопубликованное событие,
+	// поэтому следующие ждут, пока предыдущее не будет опубликовано.
+	PendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id int64) error
+	// MarkFailed увеличивает счётчик попыток и откладывает событие до retryAt.
+	MarkFailed(ctx context.Context, id int64, retryAt time.Time, reason string) error
+}
+
+// WebhookProvider хранит подписки на события и очередь их доставки.
+type WebhookProvider interface {
+	CreateWebhook(ctx context.Context, s *models.WebhookSubscription) error
+	ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, s *models.WebhookSubscription) error
+	// DeleteWebhook удаляет подписку вместе с журналом её доставок.
+	DeleteWebhook(ctx context.Context, id string) error
+
+	// EnqueueDeliveries ставит событие в очередь всем активным подпискам на его тип.
+	// Повторный вызов для того же события не создаёт дубликатов.
+	EnqueueDeliveries(ctx context.Context, evt models.OutboxEvent, payload []byte) (int, error)
+	// PendingDeliveries возвращает до limit доставок, время повтора которых наступило.
+	PendingDeliveries(ctx context.Context, limit int) ([]models.WebhookDelivery, error)
+	// RecordDeliveryAttempt увеличивает счётчик попыток и сохраняет результат.
+	RecordDeliveryAttempt(ctx context.Context, id int64, res models.DeliveryResult) error
+	ListDeliveries(ctx context.Context, subscriptionID, status string, limit, offset int) ([]models.WebhookDelivery, int, error)
+	GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error)
+	// RedeliverDelivery возвращает доставку в очередь с обнулённым счётчиком попыток.
+	RedeliverDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error)
+}
+
+// ImportProvider загружает машины из файла импорта и хранит отчёты по строкам.
+type ImportProvider interface {
+	// ImportCars читает строки из next до io.EOF и в одной транзакции сохраняет отчёт,
+	// а без imp.DryRun — создаёт машины из принятых строк (с аудитом и outbox).
+	// Заполняет imp.ID (если он пуст), счётчики и CreatedAt. Ошибка next отменяет весь импорт;
+	// apperr.ErrConflict, если импорт с таким ID уже есть.
+	ImportCars(ctx context.Context, imp *models.CarImport, next func() (models.ImportRow, error)) error
+	GetImport(ctx context.Context, id string) (*models.CarImport, error)
+	// ListImportRows возвращает строки отчёта с номером больше afterLine по возрастанию.
+	ListImportRows(ctx context.Context, importID string, afterLine, limit int) ([]models.ImportRow, error)
+	// ListImportedCars возвращает сохранённые машины принятых строк с номером больше afterLine
+	// по возрастанию номера. Строки, чьи машины уже удалены насовсем, пропускаются, поэтому
+	// страница может быть короче limit и до конца отчёта.
+	ListImportedCars(ctx context.Context, importID string, afterLine, limit int) ([]models.ImportedCar, error)
+}
+
+// ExportProvider отдаёт машины по одной, не загружая весь список в память.
+type ExportProvider interface {
+	// StreamCars вызывает fn для каждой неудалённой машины под фильтром в порядке ListCars.
+	// Ошибка fn останавливает выборку и возвращается как есть.
+	StreamCars(ctx context.Context, f models.CarFilter, fn func(models.Car) error) error
+}
+
+// JobProvider — очередь фоновых задач. attempt — значение Job.Attempts, полученное
+// воркером при захвате: если задачу с тех пор перехватил другой воркер, вызовы с
+// устаревшим attempt возвращают apperr.ErrConflict.
+type JobProvider interface {
+	// CreateJob ставит задачу в очередь. Пустой j.ID заполняется новым UUID.
+	CreateJob(ctx context.Context, j *models.Job) error
+	GetJob(ctx context.Context, id string) (*models.Job, error)
+	// ClaimJob захватывает самую старую задачу из очереди либо running-задачу с истёкшей
+	// арендой (её воркер упал), продлевает аренду на lease и увеличивает Attempts.
+	// Если задач нет, возвращает nil, nil.
+	ClaimJob(ctx context.Context, lease time.Duration) (*models.Job, error)
+	// ExtendJob сохраняет прогресс, продлевает аренду и сообщает, запрошена ли отмена.
+	ExtendJob(ctx context.Context, id string, attempt int, p models.JobProgress, lease time.Duration) (bool, error)
+	FinishJob(ctx context.Context, id string, attempt int, res models.JobResult) error
+	// CancelJob сразу отменяет задачу из очереди, а у выполняемой выставляет CancelRequested.
+	// apperr.ErrConflict, если задача уже завершена.
+	CancelJob(ctx context.Context, id string) (*models.Job, error)
+	// DeleteFinishedJobs удаляет задачи, завершённые раньше before, и возвращает их ID.
+	DeleteFinishedJobs(ctx context.Context, before time.Time) ([]string, error)
+}
+
+// IdempotencyProvider хранит ответы на запросы с Idempotency-Key.
+type IdempotencyProvider interface {
+	// ReserveIdempotencyKey сохраняет k как выполняемый и возвращает nil. Если ключ уже
+	// есть и не истёк, возвращает существующую запись, не меняя её; выполняемую запись,
+	// созданную раньше staleBefore (запрос оборвался), перезанимает.
+	ReserveIdempotencyKey(ctx context.Context, k *models.IdempotencyKey, staleBefore time.Time) (*models.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, scope, key string, resp models.StoredResponse) error
+	// ReleaseIdempotencyKey удаляет незавершённую запись, чтобы повтор выполнился заново.
+	ReleaseIdempotencyKey(ctx context.Context, scope, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+}
+
+// CatalogProvider хранит справочник марок и моделей. Названия и синонимы ищутся
+// по models.CatalogKey; реализация хранит ключи вместе с записью.
+type CatalogProvider interface {
+	// CreateBrand заполняет ID и время создания; apperr.ErrConflict, если марка
+	// с тем же ключом названия уже есть.
+	CreateBrand(ctx context.Context, b *models.Brand) error
+	// ListBrands возвращает марки по алфавиту.
+	ListBrands(ctx context.Context) ([]models.Brand, error)
+	GetBrand(ctx context.Context, id string) (*models.Brand, error)
+	// UpdateBrand меняет название и синонимы; годы выпуска не меняются.
+	UpdateBrand(ctx context.Context, b *models.Brand) error
+	// SetBrandYears заменяет годы выпуска марки и возвращает её.
+	SetBrandYears(ctx context.Context, id string, years models.YearRange) (*models.Brand, error)
+	// DeleteBrand удаляет марку вместе с её моделями. Машины с этой маркой не меняются.
+	DeleteBrand(ctx context.Context, id string) error
+	// FindBrand ищет марку по ключу названия или синонима; apperr.ErrNotFound, если такой нет.
+	FindBrand(ctx context.Context, key string) (*models.Brand, error)
+
+	// CreateModel — apperr.ErrNotFound, если марки нет, apperr.ErrConflict, если у марки
+	// уже есть модель с тем же ключом названия.
+	CreateModel(ctx context.Context, m *models.CarModel) error
+	// ListModels возвращает модели марки по алфавиту; пустой brandID — модели всех марок.
+	ListModels(ctx context.Context, brandID string) ([]models.CarModel, error)
+	GetModel(ctx context.Context, id string) (*models.CarModel, error)
+	// UpdateModel меняет название и синонимы; BrandID и годы выпуска не меняются.
+	UpdateModel(ctx context.Context, m *models.CarModel) error
+	// SetModelYears заменяет годы выпуска модели и возвращает её.
+	SetModelYears(ctx context.Context, id string, years models.YearRange) (*models.CarModel, error)
+	DeleteModel(ctx context.Context, id string) error
+	// FindModel ищет модель марки по ключу названия или синонима.
+	FindModel(ctx context.Context, brandID, key string) (*models.CarModel, error)
+}
+
+// StatsProvider считает статистику по неудалённым машинам агрегатами хранилища.
+type StatsProvider interface {
+	// CarStats возвращает итоги и год по q.Filter, группы q.GroupBy (марки и модели — по убыванию
+	// числа машин, годы и десятилетия — по возрастанию) и непустые периоды q.Period, в которые
+	// созданы машины из [q.From, q.To), по возрастанию. From, To и Period ответа не заполняются.
+	CarStats(ctx context.Context, q models.StatsQuery) (*models.CarStats, error)
+}
+
+// PriceProvider отдаёт историю цен. Записи создаются реализациями CarProvider в той же
+// транзакции, что и изменение машины, когда меняется цена или валюта.
+type PriceProvider interface {
+	// ListPrices возвращает историю цены машины, последние изменения первыми.
+	ListPrices(ctx context.Context, carID string) ([]models.PricePoint, error)
+}
+
+// ReservationFunc получает заблокированную машину и её активную бронь (nil, если брони нет).
+// Она может сменить car.Status и возвращает бронь для записи: новую (с пустым ID) или
+// переданную активную с новым статусом; nil — бронь не меняется.
+type ReservationFunc func(car *models.Car, active *models.Reservation) (*models.Reservation, error)
+
+// ReservationProvider хранит брони машин. Правила переходов задаёт вызывающий в ReservationFunc,
+// а хранилище выполняет её под блокировкой строки машины, поэтому конкурирующие брони одной
+// машины применяются по очереди.
+type ReservationProvider interface {
+	// ChangeReservation блокирует машину (в том числе из корзины) до конца транзакции и вызывает fn.
+	// Новая бронь получает ID, автора из ctx и время создания; новый статус машины сохраняется
+	// с увеличением версии, аудитом и outbox. Ошибка fn откатывает транзакцию и возвращается
+	// как есть; apperr.ErrConflict, если у машины уже есть другая активная бронь.
+	// Возвращает записанную бронь (nil, если fn её не вернула) и машину после изменения.
+	ChangeReservation(ctx context.Context, carID string, fn ReservationFunc) (*models.Reservation, *models.Car, error)
+	GetReservation(ctx context.Context, id string) (*models.Reservation, error)
+	// ListReservations возвращает брони машины, новые первыми.
+	ListReservations(ctx context.Context, carID string) ([]models.Reservation, error)
+	// ExpiredReservations возвращает до limit активных броней со сроком раньше now, старые первыми.
+	ExpiredReservations(ctx context.Context, now time.Time, limit int) ([]models.Reservation, error)
+}
+
+// ImageProvider хранит описания фотографий машин; сами файлы лежат в хранилище объектов.
+// Изменение галереи увеличивает версию машины, чтобы ETag ответа с фотографиями менялся.
+// Фотографии машины из корзины сохраняются для восстановления, а после окончательного
+// удаления машины их строки остаются, пока вызывающий не удалит файлы (OrphanedImages).
+type ImageProvider interface {
+	// AddImage добавляет фотографию в конец галереи машины вне корзины. ID задаёт вызывающий,
+	// Position и CreatedAt заполняются. apperr.ErrNotFound — машины нет, apperr.ErrConflict —
+	// у машины уже limit фотографий.
+	AddImage(ctx context.Context, img *models.CarImage, limit int) error
+	// ListImages возвращает фотографии машин по порядку галереи.
+	ListImages(ctx context.Context, carIDs ...string) ([]models.CarImage, error)
+	GetImage(ctx context.Context, carID, id string) (*models.CarImage, error)
+	// DeleteImage удаляет фотографию машины вне корзины и сдвигает следующие за ней.
+	DeleteImage(ctx context.Context, carID, id string) error
+	// ReorderImages расставляет фотографии машины в порядке ids. ids должны перечислять
+	// все фотографии машины ровно по разу, иначе apperr.ErrInvalidInput.
+	ReorderImages(ctx context.Context, carID string, ids []string) ([]models.CarImage, error)
+	// OrphanedImages возвращает до limit фотографий машин, которых больше нет.
+	OrphanedImages(ctx context.Context, limit int) ([]models.CarImage, error)
+	// DeleteOrphanedImages удаляет строки фотографий из ids, если их машин больше нет.
+	DeleteOrphanedImages(ctx context.Context, ids []string) (int64, error)
 }
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/reqctx"
+)
+
+var _ JobProvider = (*CarRepo)(nil)
+
+const jobColumns = `id, kind, status, params, result, error, processed, total, attempts, cancel_requested,
+	locked_until, actor, request_id, created_at, started_at, finished_at`
+
+func scanJob(row pgx.Row) (models.Job, error) {
+	var (
+		j              models.Job
+		params, result []byte
+	)
+	err := row.Scan(&j.ID, &j.Kind, &j.Status, &params, &result, &j.Error, &j.Processed, &j.Total, &j.Attempts,
+		&j.CancelRequested, &j.LockedUntil, &j.Actor, &j.RequestID, &j.CreatedAt, &j.StartedAt, &j.FinishedAt)
+	j.Params, j.Result = params, result
+	return j, err
+}
+
+// startJob заполняет поля новой задачи, которые не приходят от клиента.
+func startJob(ctx context.Context, j *models.Job) {
+	if j.ID == "" {
+		j.ID = uuid.NewString()
+	}
+	if len(j.Params) == 0 {
+		j.Params = []byte("{}")
+	}
+	j.Status = models.JobQueued
+	j.Actor = reqctx.Actor(ctx)
+	j.RequestID = reqctx.RequestID(ctx)
+}
+
+// leaseMillis — аренда для SQL-выражения NOW() + $n * INTERVAL '1 millisecond'.
+func leaseMillis(lease time.Duration) int64 {
+	return lease.Milliseconds()
+}
+
+func (r *CarRepo) CreateJob(ctx context.Context, j *models.Job) error {
+	const query = `
+		INSERT INTO jobs (id, kind, status, params, actor, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + jobColumns + `;
+	`
+	startJob(ctx, j)
+	created, err := scanJob(r.pool.QueryRow(ctx, query, j.ID, j.Kind, j.Status, j.Params, j.Actor, j.RequestID))
+	if err != nil {
+		return err
+	}
+	*j = created
+	return nil
+}
+
+func (r *CarRepo) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	const query = `
+		SELECT ` + jobColumns + `
+		FROM jobs
+		WHERE id = $1;
+	`
+	j, err := scanJob(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// ClaimJob: SKIP LOCKED позволяет нескольким воркерам (и экземплярам сервиса)
+// разбирать очередь параллельно, не ожидая друг друга.
+func (r *CarRepo) ClaimJob(ctx context.Context, lease time.Duration) (*models.Job, error) {
+	const query = `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1,
+			locked_until = NOW() + $1 * INTERVAL '1 millisecond',
+			started_at = COALESCE(started_at, NOW())
+		WHERE id = (
+			SELECT id
+			FROM jobs
+			WHERE status = 'queued' OR (status = 'running' AND locked_until < NOW())
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns + `;
+	`
+	j, err := scanJob(r.pool.QueryRow(ctx, query, leaseMillis(lease)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (r *CarRepo) ExtendJob(ctx context.Context, id string, attempt int, p models.JobProgress, lease time.Duration) (bool, error) {
+	const query = `
+		UPDATE jobs
+		SET processed = $3, total = $4, locked_until = NOW() + $5 * INTERVAL '1 millisecond'
+		WHERE id = $1 AND attempts = $2 AND status = 'running'
+		RETURNING cancel_requested;
+	`
+	var cancel bool
+	err := r.pool.QueryRow(ctx, query, id, attempt, p.Processed, p.Total, leaseMillis(lease)).Scan(&cancel)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, apperr.ErrConflict
+	}
+	return cancel, err
+}
+
+func (r *CarRepo) FinishJob(ctx context.Context, id string, attempt int, res models.JobResult) error {
+	const query = `
+		UPDATE jobs
+		SET status = $3, processed = $4, total = $5, result = $6, error = $7,
+			locked_until = NULL, finished_at = NOW()
+		WHERE id = $1 AND attempts = $2 AND status = 'running';
+	`
+	var result []byte
+	if len(res.Result) > 0 {
+		result = res.Result
+	}
+	ct, err := r.pool.Exec(ctx, query, id, attempt, res.Status, res.Processed, res.Total, result,
+		truncateError(res.Error))
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return apperr.ErrConflict
+	}
+	return nil
+}
+
+func (r *CarRepo) CancelJob(ctx context.Context, id string) (*models.Job, error) {
+	const query = `
+		UPDATE jobs
+		SET cancel_requested = TRUE,
+			status = CASE WHEN status = 'queued' THEN 'canceled' ELSE status END,
+			finished_at = CASE WHEN status = 'queued' THEN NOW() ELSE finished_at END
+		WHERE id = $1 AND status IN ('queued', 'running')
+		RETURNING ` + jobColumns + `;
+	`
+	j, err := scanJob(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := r.GetJob(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, apperr.ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (r *CarRepo) DeleteFinishedJobs(ctx context.Context, before time.Time) ([]string, error) {
+	const query = `
+		DELETE FROM jobs
+		WHERE status IN ('succeeded', 'failed', 'canceled') AND finished_at < $1
+		RETURNING id;
+	`
+	rows, err := r.pool.Query(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	return ids, nil
+}
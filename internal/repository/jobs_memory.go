@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ JobProvider = (*MemoryCarRepo)(nil)
+
+// memoryJobs — очередь задач MemoryCarRepo в порядке создания; свой мьютекс, как у webhooks.
+type memoryJobs struct {
+	mu   sync.Mutex
+	jobs []models.Job
+}
+
+func cloneJob(j models.Job) models.Job {
+	j.Params = slices.Clone(j.Params)
+	j.Result = slices.Clone(j.Result)
+	for _, t := range []**time.Time{&j.LockedUntil, &j.StartedAt, &j.FinishedAt} {
+		if *t != nil {
+			v := **t
+			*t = &v
+		}
+	}
+	return j
+}
+
+func (q *memoryJobs) find(id string) *models.Job {
+	i := slices.IndexFunc(q.jobs, func(j models.Job) bool { return j.ID == id })
+	if i < 0 {
+		return nil
+	}
+	return &q.jobs[i]
+}
+
+// leased возвращает выполняемую задачу, если attempt совпадает с последним захватом.
+func (q *memoryJobs) leased(id string, attempt int) (*models.Job, error) {
+	j := q.find(id)
+	if j == nil || j.Status != models.JobRunning || j.Attempts != attempt {
+		return nil, apperr.ErrConflict
+	}
+	return j, nil
+}
+
+func (r *MemoryCarRepo) CreateJob(ctx context.Context, j *models.Job) error {
+	q := &r.jobs
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	startJob(ctx, j)
+	created := cloneJob(*j)
+	created.CreatedAt = time.Now().UTC()
+	q.jobs = append(q.jobs, created)
+	*j = cloneJob(created)
+	return nil
+}
+
+func (r *MemoryCarRepo) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	q := &r.jobs
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j := q.find(id)
+	if j == nil {
+		return nil, apperr.ErrNotFound
+	}
+	c := cloneJob(*j)
+	return &c, nil
+}
+
+func (r *MemoryCarRepo) ClaimJob(ctx context.Context, lease time.Duration) (*models.Job, error) {
+	q := &r.jobs
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now().UTC()
+	for i := range q.jobs {
+		j := &q.jobs[i]
+		expired := j.Status == models.JobRunning && j.LockedUntil != nil && j.LockedUntil.Before(now)
+		if j.Status != models.JobQueued && !expired {
+			continue
+		}
+		j.Status = models.JobRunning
+		j.Attempts++
+		until := now.Add(lease)
+		j.LockedUntil = &until
+		if j.StartedAt == nil {
+			j.StartedAt = &now
+		}
+		c := cloneJob(*j)
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (r *MemoryCarRepo) ExtendJob(ctx context.Context, id string, attempt int, p models.JobProgress, lease time.Duration) (bool, error) {
+	q := &r.jobs
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.leased(id, attempt)
+	if err != nil {
+		return false, err
+	}
+	j.Processed, j.Total = p.Processed, p.Total
+	until := time.Now().UTC().Add(lease)
+	j.LockedUntil = &until
+	return j.CancelRequested, nil
+}
+
+func (r *MemoryCarRepo) FinishJob(ctx context.Context, id string, attempt int, res models.JobResult) error {
+	q := &r.jobs
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.leased(id, attempt)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	j.Status = res.Status
+	j.Processed, j.Total = res.Processed, res.Total
+	j.Result = slices.Clone(res.Result)
+	j.Error = truncateError(res.Error)
+	j.LockedUntil = nil
+	j.FinishedAt = &now
+	return nil
+}
+
+func (r *MemoryCarRepo) CancelJob(ctx context.Context, id string) (*models.Job, error) {
+	q := &r.jobs
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j := q.find(id)
+	if j == nil {
+		return nil, apperr.ErrNotFound
+	}
+	if j.Finished() {
+		return nil, apperr.ErrConflict
+	}
+	j.CancelRequested = true
+	if j.Status == models.JobQueued {
+		now := time.Now().UTC()
+		j.Status = models.JobCanceled
+		j.FinishedAt = &now
+	}
+	c := cloneJob(*j)
+	return &c, nil
+}
+
+func (r *MemoryCarRepo) DeleteFinishedJobs(ctx context.Context, before time.Time) ([]string, error) {
+	q := &r.jobs
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ids := []string{}
+	q.jobs = slices.DeleteFunc(q.jobs, func(j models.Job) bool {
+		if !j.Finished() || j.FinishedAt == nil || !j.FinishedAt.Before(before) {
+			return false
+		}
+		ids = append(ids, j.ID)
+		return true
+	})
+	return ids, nil
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ JobProvider = (*SQLiteCarRepo)(nil)
+
+func parseSQLiteNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := time.Parse(sqliteTimeLayout, s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func scanSQLiteJob(row rowScanner) (models.Job, error) {
+	var (
+		j                                models.Job
+		params, createdAt                string
+		result                           sql.NullString
+		lockedUntil, startedAt, finished sql.NullString
+	)
+	err := row.Scan(&j.ID, &j.Kind, &j.Status, &params, &result, &j.Error, &j.Processed, &j.Total, &j.Attempts,
+		&j.CancelRequested, &lockedUntil, &j.Actor, &j.RequestID, &createdAt, &startedAt, &finished)
+	if err != nil {
+		return models.Job{}, err
+	}
+	j.Params = []byte(params)
+	if result.Valid {
+		j.Result = []byte(result.String)
+	}
+	if j.CreatedAt, err = time.Parse(sqliteTimeLayout, createdAt); err != nil {
+		return models.Job{}, err
+	}
+	if j.LockedUntil, err = parseSQLiteNullTime(lockedUntil); err != nil {
+		return models.Job{}, err
+	}
+	if j.StartedAt, err = parseSQLiteNullTime(startedAt); err != nil {
+		return models.Job{}, err
+	}
+	if j.FinishedAt, err = parseSQLiteNullTime(finished); err != nil {
+		return models.Job{}, err
+	}
+	return j, nil
+}
+
+func (r *SQLiteCarRepo) CreateJob(ctx context.Context, j *models.Job) error {
+	const query = `
+		INSERT INTO jobs (id, kind, status, params, actor, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + jobColumns + `;
+	`
+	startJob(ctx, j)
+	created, err := scanSQLiteJob(r.db.QueryRowContext(ctx, query, j.ID, j.Kind, j.Status, string(j.Params),
+		j.Actor, j.RequestID, formatSQLiteTime(sqliteNow())))
+	if err != nil {
+		return err
+	}
+	*j = created
+	return nil
+}
+
+func (r *SQLiteCarRepo) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	const query = `
+		SELECT ` + jobColumns + `
+		FROM jobs
+		WHERE id = ?;
+	`
+	j, err := scanSQLiteJob(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// ClaimJob — выбор и захват одним UPDATE: SQLite выполняет его целиком под блокировкой записи.
+func (r *SQLiteCarRepo) ClaimJob(ctx context.Context, lease time.Duration) (*models.Job, error) {
+	const query = `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, locked_until = ?, started_at = COALESCE(started_at, ?)
+		WHERE id = (
+			SELECT id
+			FROM jobs
+			WHERE status = 'queued' OR (status = 'running' AND locked_until < ?)
+			ORDER BY created_at
+			LIMIT 1
+		)
+		RETURNING ` + jobColumns + `;
+	`
+	now := sqliteNow()
+	j, err := scanSQLiteJob(r.db.QueryRowContext(ctx, query, formatSQLiteTime(now.Add(lease)),
+		formatSQLiteTime(now), formatSQLiteTime(now)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (r *SQLiteCarRepo) ExtendJob(ctx context.Context, id string, attempt int, p models.JobProgress, lease time.Duration) (bool, error) {
+	const query = `
+		UPDATE jobs
+		SET processed = ?, total = ?, locked_until = ?
+		WHERE id = ? AND attempts = ? AND status = 'running'
+		RETURNING cancel_requested;
+	`
+	var cancel bool
+	err := r.db.QueryRowContext(ctx, query, p.Processed, p.Total, formatSQLiteTime(sqliteNow().Add(lease)),
+		id, attempt).Scan(&cancel)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, apperr.ErrConflict
+	}
+	return cancel, err
+}
+
+func (r *SQLiteCarRepo) FinishJob(ctx context.Context, id string, attempt int, res models.JobResult) error {
+	const query = `
+		UPDATE jobs
+		SET status = ?, processed = ?, total = ?, result = ?, error = ?, locked_until = NULL, finished_at = ?
+		WHERE id = ? AND attempts = ? AND status = 'running';
+	`
+	var result any
+	if len(res.Result) > 0 {
+		result = string(res.Result)
+	}
+	ct, err := r.db.ExecContext(ctx, query, res.Status, res.Processed, res.Total, result, truncateError(res.Error),
+		formatSQLiteTime(sqliteNow()), id, attempt)
+	if err != nil {
+		return err
+	}
+	if n, err := ct.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return apperr.ErrConflict
+	}
+	return nil
+}
+
+func (r *SQLiteCarRepo) CancelJob(ctx context.Context, id string) (*models.Job, error) {
+	const query = `
+		UPDATE jobs
+		SET cancel_requested = 1,
+			status = CASE WHEN status = 'queued' THEN 'canceled' ELSE status END,
+			finished_at = CASE WHEN status = 'queued' THEN ? ELSE finished_at END
+		WHERE id = ? AND status IN ('queued', 'running')
+		RETURNING ` + jobColumns + `;
+	`
+	j, err := scanSQLiteJob(r.db.QueryRowContext(ctx, query, formatSQLiteTime(sqliteNow()), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := r.GetJob(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, apperr.ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (r *SQLiteCarRepo) DeleteFinishedJobs(ctx context.Context, before time.Time) ([]string, error) {
+	const query = `
+		DELETE FROM jobs
+		WHERE status IN ('succeeded', 'failed', 'canceled') AND finished_at < ?
+		RETURNING id;
+	`
+	rows, err := r.db.QueryContext(ctx, query, formatSQLiteTime(before))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
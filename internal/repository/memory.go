@@ -0,0 +1,500 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+const minCarYear = 1886
+
+// MemoryCarRepo — потокобезопасная in-memory реализация CarProvider.
+// Повторяет семантику CarRepo: генерирует UUID и created_at,
+// возвращает apperr.ErrNotFound, сортирует список по created_at DESC
+// и проверяет ограничение на год так же, как CHECK в таблице cars.
+type MemoryCarRepo struct {
+	mu      sync.RWMutex
+	cars    map[string]memoryCar
+	seq     uint64
+	audit   []models.AuditEntry
+	outbox  []memoryEvent
+	prices  []models.PricePoint
+	imports []memoryImport
+
+	// reservations — брони всех машин по порядку создания; меняются под mu вместе со статусом машины.
+	reservations []models.Reservation
+	// images — фотографии машин по порядку загрузки; место в галерее задаёт Position.
+	images []models.CarImage
+
+	hooks       memoryWebhooks
+	jobs        memoryJobs
+	idempotency memoryIdempotency
+	catalog     memoryCatalog
+}
+
+type memoryCar struct {
+	car models.Car
+	seq uint64
+}
+
+type memoryEvent struct {
+	event       models.OutboxEvent
+	nextAttempt time.Time
+	lastError   string
+	published   bool
+}
+
+var (
+	_ CarProvider    = (*MemoryCarRepo)(nil)
+	_ AuditProvider  = (*MemoryCarRepo)(nil)
+	_ OutboxProvider = (*MemoryCarRepo)(nil)
+)
+
+func NewMemoryCarRepo() *MemoryCarRepo {
+	return &MemoryCarRepo{cars: make(map[string]memoryCar)}
+}
+
+func checkYear(year int) error {
+	maxYear := time.Now().Year() + 1
+	if year < minCarYear || year > maxYear {
+		return fmt.Errorf("%w: year must be between %d and %d", apperr.ErrInvalidInput, minCarYear, maxYear)
+	}
+	return nil
+}
+
+// cloneCar копирует запись вместе с указателями, чтобы вызывающий не мог изменить хранилище.
+func cloneCar(c models.Car) models.Car {
+	if c.DeletedAt != nil {
+		t := *c.DeletedAt
+		c.DeletedAt = &t
+	}
+	c.CarAttributes = cloneAttributes(c.CarAttributes)
+	return c
+}
+
+// snapshot возвращает записи, отобранные keep, под read-локом.
+func (r *MemoryCarRepo) snapshot(keep func(models.Car) bool) []memoryCar {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := make([]memoryCar, 0, len(r.cars))
+	for _, item := range r.cars {
+		if keep(item.car) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func toCars(items []memoryCar) []models.Car {
+	cars := make([]models.Car, len(items))
+	for i, item := range items {
+		cars[i] = cloneCar(item.car)
+	}
+	return cars
+}
+
+func (r *MemoryCarRepo) ListCars(ctx context.Context) ([]models.Car, error) {
+	items := r.snapshot(func(c models.Car) bool { return c.DeletedAt == nil })
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].car.CreatedAt.Equal(items[j].car.CreatedAt) {
+			return items[i].car.CreatedAt.After(items[j].car.CreatedAt)
+		}
+		return items[i].seq > items[j].seq
+	})
+	return toCars(items), nil
+}
+
+func (r *MemoryCarRepo) GetCarByID(ctx context.Context, id string) (*models.Car, error) {
+	r.mu.RLock()
+	item, ok := r.cars[id]
+	r.mu.RUnlock()
+	if !ok || item.car.DeletedAt != nil {
+		return nil, apperr.ErrNotFound
+	}
+	c := cloneCar(item.car)
+	return &c, nil
+}
+
+func (r *MemoryCarRepo) GetCarByVIN(ctx context.Context, vin string) (*models.Car, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, item := range r.cars {
+		if item.car.VIN == vin && item.car.DeletedAt == nil {
+			c := cloneCar(item.car)
+			return &c, nil
+		}
+	}
+	return nil, apperr.ErrNotFound
+}
+
+// checkVINLocked повторяет уникальный индекс cars_vin_key: VIN не должен быть
+// у другой машины вне корзины. Вызывается под r.mu.
+func (r *MemoryCarRepo) checkVINLocked(vin, id string) error {
+	if vin == "" {
+		return nil
+	}
+	owners := map[string]string{}
+	for _, item := range r.cars {
+		takeVIN(owners, &item.car)
+	}
+	return checkVIN(owners, vin, id)
+}
+
+func (r *MemoryCarRepo) InsertCar(ctx context.Context, newCar *models.Car) error {
+	if err := checkYear(newCar.Year); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkVINLocked(newCar.VIN, ""); err != nil {
+		return err
+	}
+
+	car := cloneCar(*newCar)
+	car.ID = uuid.NewString()
+	car.Version = 1
+	car.CreatedAt = time.Now().UTC()
+	car.DeletedAt = nil
+	car.Status = defaultStatus(car.Status)
+	if err := r.recordChange(ctx, models.AuditActionCreate, nil, &car); err != nil {
+		return err
+	}
+	r.seq++
+	r.cars[car.ID] = memoryCar{car: cloneCar(car), seq: r.seq}
+	*newCar = car
+	return nil
+}
+
+func (r *MemoryCarRepo) UpdateCar(ctx context.Context, c *models.Car) error {
+	if err := checkYear(c.Year); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, ok := r.cars[c.ID]
+	if !ok || item.car.DeletedAt != nil {
+		return apperr.ErrNotFound
+	}
+	if item.car.Version != c.Version {
+		return fmt.Errorf("%w: version mismatch", apperr.ErrConflict)
+	}
+	if err := r.checkVINLocked(c.VIN, c.ID); err != nil {
+		return err
+	}
+	before := item.car
+	item.car.Brand = c.Brand
+	item.car.Model = c.Model
+	item.car.Year = c.Year
+	item.car.VIN = c.VIN
+	item.car.CarAttributes = cloneAttributes(c.CarAttributes)
+	item.car.Status = defaultStatus(c.Status)
+	item.car.Version++
+	if err := r.recordChange(ctx, models.AuditActionUpdate, &before, &item.car); err != nil {
+		return err
+	}
+	r.cars[c.ID] = item
+	c.Version = item.car.Version
+	return nil
+}
+
+func (r *MemoryCarRepo) DeleteByID(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, ok := r.cars[id]
+	if !ok || item.car.DeletedAt != nil {
+		return apperr.ErrNotFound
+	}
+	before := item.car
+	now := time.Now().UTC()
+	item.car.DeletedAt = &now
+	item.car.Version++
+	if err := r.recordChange(ctx, models.AuditActionDelete, &before, &item.car); err != nil {
+		return err
+	}
+	r.cars[id] = item
+	return nil
+}
+
+func (r *MemoryCarRepo) ListDeletedCars(ctx context.Context) ([]models.Car, error) {
+	items := r.snapshot(func(c models.Car) bool { return c.DeletedAt != nil })
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].car.DeletedAt.After(*items[j].car.DeletedAt)
+	})
+	return toCars(items), nil
+}
+
+func (r *MemoryCarRepo) RestoreByID(ctx context.Context, id string) (*models.Car, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, ok := r.cars[id]
+	if !ok || item.car.DeletedAt == nil {
+		return nil, apperr.ErrNotFound
+	}
+	if err := r.checkVINLocked(item.car.VIN, id); err != nil {
+		return nil, err
+	}
+	before := item.car
+	item.car.DeletedAt = nil
+	item.car.Version++
+	if err := r.recordChange(ctx, models.AuditActionRestore, &before, &item.car); err != nil {
+		return nil, err
+	}
+	r.cars[id] = item
+	c := cloneCar(item.car)
+	return &c, nil
+}
+
+func (r *MemoryCarRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for id, item := range r.cars {
+		if item.car.DeletedAt != nil && item.car.DeletedAt.Before(before) {
+			delete(r.cars, id)
+			n++
+		}
+	}
+	// брони удаляются вместе с машиной, как ON DELETE CASCADE в таблице
+	r.reservations = slices.DeleteFunc(r.reservations, func(res models.Reservation) bool {
+		_, ok := r.cars[res.CarID]
+		return !ok
+	})
+	return n, nil
+}
+
+func (r *MemoryCarRepo) ApplyBatch(ctx context.Context, ops []models.CarMutation, atomic bool) ([]models.MutationResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := make(map[string]*models.Car)
+	for _, id := range batchCarIDs(ops) {
+		if item, ok := r.cars[id]; ok {
+			c := cloneCar(item.car)
+			current[id] = &c
+		}
+	}
+	vins := map[string]string{}
+	for _, item := range r.cars {
+		takeVIN(vins, &item.car)
+	}
+	plan := planBatch(ops, current, vins, time.Now().UTC())
+	results, applied := batchResults(plan, atomic)
+	if !applied {
+		return results, nil
+	}
+
+	// план уже проверен, поэтому изменения применяются без повторных проверок
+	for _, p := range plan {
+		if p.err != nil {
+			continue
+		}
+		if err := r.recordChange(ctx, p.action, p.before, p.after); err != nil {
+			return nil, err
+		}
+		item, ok := r.cars[p.after.ID]
+		if !ok {
+			r.seq++
+			item.seq = r.seq
+		}
+		item.car = cloneCar(*p.after)
+		r.cars[p.after.ID] = item
+	}
+	return results, nil
+}
+
+// recordChange вызывается под r.mu вместе с изменением, поэтому журнал, outbox, история цен
+// и данные согласованы.
+func (r *MemoryCarRepo) recordChange(ctx context.Context, action string, before, after *models.Car) error {
+	carID := ""
+	if after != nil {
+		carID = after.ID
+	} else if before != nil {
+		carID = before.ID
+	}
+	entry, err := newAuditEntry(ctx, action, carID, before, after)
+	if err != nil {
+		return err
+	}
+	evt, err := newOutboxEvent(ctx, action, after)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	entry.ID = int64(len(r.audit) + 1)
+	entry.CreatedAt = now
+	r.audit = append(r.audit, entry)
+
+	evt.ID = int64(len(r.outbox) + 1)
+	evt.OccurredAt = now
+	r.outbox = append(r.outbox, memoryEvent{event: evt, nextAttempt: now})
+
+	if p, ok := newPricePoint(ctx, before, after); ok {
+		p.ID = int64(len(r.prices) + 1)
+		p.ChangedAt = now
+		r.prices = append(r.prices, p)
+	}
+	return nil
+}
+
+func (r *MemoryCarRepo) ListAudit(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := []models.AuditEntry{}
+	for i := len(r.audit) - 1; i >= 0; i-- {
+		e := r.audit[i]
+		switch {
+		case f.CarID != "" && e.CarID != f.CarID,
+			f.Actor != "" && e.Actor != f.Actor,
+			f.Action != "" && e.Action != f.Action,
+			!f.From.IsZero() && e.CreatedAt.Before(f.From),
+			!f.To.IsZero() && !e.CreatedAt.Before(f.To):
+			continue
+		}
+		matched = append(matched, e)
+	}
+
+	total := len(matched)
+	start := min(f.Offset, total)
+	end := min(start+auditLimit(f.Limit), total)
+	return matched[start:end], total, nil
+}
+
+// GetCarAsOf и ListCarsAsOf проигрывают журнал аудита до момента at.
+func (r *MemoryCarRepo) GetCarAsOf(ctx context.Context, id string, at time.Time) (*models.Car, error) {
+	cars, err := r.replayAudit(at, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(cars) == 0 {
+		return nil, apperr.ErrNotFound
+	}
+	return &cars[0], nil
+}
+
+func (r *MemoryCarRepo) ListCarsAsOf(ctx context.Context, at time.Time) ([]models.Car, error) {
+	return r.replayAudit(at, "")
+}
+
+// replayAudit возвращает машины, существовавшие на момент at (только carID, если он задан),
+// новые первыми. created_at — время записи create в журнале.
+func (r *MemoryCarRepo) replayAudit(at time.Time, carID string) ([]models.Car, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type state struct {
+		last    models.AuditEntry
+		created time.Time
+		order   int
+	}
+	states := map[string]*state{}
+	for i, e := range r.audit {
+		if e.CreatedAt.After(at) {
+			break
+		}
+		if carID != "" && e.CarID != carID {
+			continue
+		}
+		st, ok := states[e.CarID]
+		if !ok {
+			st = &state{order: i}
+			states[e.CarID] = st
+		}
+		if e.Action == models.AuditActionCreate {
+			st.created = e.CreatedAt
+		}
+		st.last = e
+	}
+
+	type item struct {
+		car   models.Car
+		order int
+	}
+	items := make([]item, 0, len(states))
+	for id, st := range states {
+		car, ok, err := carAsOf(id, st.last.After, st.created)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			items = append(items, item{car: car, order: st.order})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].order > items[j].order })
+
+	cars := make([]models.Car, len(items))
+	for i, it := range items {
+		cars[i] = it.car
+	}
+	return cars, nil
+}
+
+func (r *MemoryCarRepo) PendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	limit = outboxLimit(limit)
+	now := time.Now().UTC()
+	blocked := map[string]bool{}
+	events := []models.OutboxEvent{}
+	for _, item := range r.outbox {
+		if item.published || blocked[item.event.CarID] {
+			continue
+		}
+		blocked[item.event.CarID] = true // дальше по этой машине — только после публикации текущего
+		if item.nextAttempt.After(now) {
+			continue
+		}
+		events = append(events, item.event)
+		if len(events) == limit {
+			break
+		}
+	}
+	return events, nil
+}
+
+func (r *MemoryCarRepo) MarkPublished(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if item := r.outboxItem(id); item != nil {
+		item.published = true
+	}
+	return nil
+}
+
+func (r *MemoryCarRepo) MarkFailed(ctx context.Context, id int64, retryAt time.Time, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if item := r.outboxItem(id); item != nil {
+		item.event.Attempts++
+		item.nextAttempt = retryAt
+		item.lastError = truncateError(reason)
+	}
+	return nil
+}
+
+func (r *MemoryCarRepo) outboxItem(id int64) *memoryEvent {
+	if id < 1 || id > int64(len(r.outbox)) {
+		return nil
+	}
+	return &r.outbox[id-1]
+}
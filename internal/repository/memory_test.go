@@ -0,0 +1,43 @@
+package repository_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/repository/repotest"
+)
+
+func TestMemoryCarRepo_Contract(t *testing.T) {
+	repotest.RunCarProviderSuite(t, func(t *testing.T) repository.CarProvider {
+		return repository.NewMemoryCarRepo()
+	})
+}
+
+func TestMemoryCarRepo_ConcurrentInsert(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := repository.NewMemoryCarRepo()
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := models.Car{Brand: "Toyota", Model: "Corolla", Year: 2020}
+			assert.NoError(t, repo.InsertCar(ctx, &c))
+		}()
+	}
+	wg.Wait()
+
+	cars, err := repo.ListCars(ctx)
+	require.NoError(t, err)
+	assert.Len(t, cars, n)
+}
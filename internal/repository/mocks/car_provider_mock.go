@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/pavel97go/service-cars/internal/models"
@@ -35,6 +36,21 @@ func (m *MockCarProvider) EXPECT() *MockCarProviderMockRecorder {
 	return m.recorder
 }
 
+// ApplyBatch mocks base method.
+func (m *MockCarProvider) ApplyBatch(ctx context.Context, ops []models.CarMutation, atomic bool) ([]models.MutationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyBatch", ctx, ops, atomic)
+	ret0, _ := ret[0].([]models.MutationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyBatch indicates an expected call of ApplyBatch.
+func (mr *MockCarProviderMockRecorder) ApplyBatch(ctx, ops, atomic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyBatch", reflect.TypeOf((*MockCarProvider)(nil).ApplyBatch), ctx, ops, atomic)
+}
+
 // DeleteByID mocks base method.
 func (m *MockCarProvider) DeleteByID(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -49,6 +65,21 @@ func (mr *MockCarProviderMockRecorder) DeleteByID(ctx, id interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockCarProvider)(nil).DeleteByID), ctx, id)
 }
 
+// GetCarAsOf mocks base method.
+func (m *MockCarProvider) GetCarAsOf(ctx context.Context, id string, at time.Time) (*models.Car, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCarAsOf", ctx, id, at)
+	ret0, _ := ret[0].(*models.Car)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCarAsOf indicates an expected call of GetCarAsOf.
+func (mr *MockCarProviderMockRecorder) GetCarAsOf(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCarAsOf", reflect.TypeOf((*MockCarProvider)(nil).GetCarAsOf), ctx, id, at)
+}
+
 // GetCarByID mocks base method.
 func (m *MockCarProvider) GetCarByID(ctx context.Context, id string) (*models.Car, error) {
 	m.ctrl.T.Helper()
@@ -64,6 +95,21 @@ func (mr *MockCarProviderMockRecorder) GetCarByID(ctx, id interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCarByID", reflect.TypeOf((*MockCarProvider)(nil).GetCarByID), ctx, id)
 }
 
+// GetCarByVIN mocks base method.
+func (m *MockCarProvider) GetCarByVIN(ctx context.Context, vin string) (*models.Car, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCarByVIN", ctx, vin)
+	ret0, _ := ret[0].(*models.Car)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCarByVIN indicates an expected call of GetCarByVIN.
+func (mr *MockCarProviderMockRecorder) GetCarByVIN(ctx, vin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCarByVIN", reflect.TypeOf((*MockCarProvider)(nil).GetCarByVIN), ctx, vin)
+}
+
 // InsertCar mocks base method.
 func (m *MockCarProvider) InsertCar(ctx context.Context, newCar *models.Car) error {
 	m.ctrl.T.Helper()
@@ -93,6 +139,66 @@ func (mr *MockCarProviderMockRecorder) ListCars(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCars", reflect.TypeOf((*MockCarProvider)(nil).ListCars), ctx)
 }
 
+// ListCarsAsOf mocks base method.
+func (m *MockCarProvider) ListCarsAsOf(ctx context.Context, at time.Time) ([]models.Car, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCarsAsOf", ctx, at)
+	ret0, _ := ret[0].([]models.Car)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCarsAsOf indicates an expected call of ListCarsAsOf.
+func (mr *MockCarProviderMockRecorder) ListCarsAsOf(ctx, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCarsAsOf", reflect.TypeOf((*MockCarProvider)(nil).ListCarsAsOf), ctx, at)
+}
+
+// ListDeletedCars mocks base method.
+func (m *MockCarProvider) ListDeletedCars(ctx context.Context) ([]models.Car, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeletedCars", ctx)
+	ret0, _ := ret[0].([]models.Car)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeletedCars indicates an expected call of ListDeletedCars.
+func (mr *MockCarProviderMockRecorder) ListDeletedCars(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedCars", reflect.TypeOf((*MockCarProvider)(nil).ListDeletedCars), ctx)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockCarProvider) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockCarProviderMockRecorder) PurgeDeleted(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockCarProvider)(nil).PurgeDeleted), ctx, before)
+}
+
+// RestoreByID mocks base method.
+func (m *MockCarProvider) RestoreByID(ctx context.Context, id string) (*models.Car, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreByID", ctx, id)
+	ret0, _ := ret[0].(*models.Car)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreByID indicates an expected call of RestoreByID.
+func (mr *MockCarProviderMockRecorder) RestoreByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreByID", reflect.TypeOf((*MockCarProvider)(nil).RestoreByID), ctx, id)
+}
+
 // UpdateCar mocks base method.
 func (m *MockCarProvider) UpdateCar(ctx context.Context, updatedCar *models.Car) error {
 	m.ctrl.T.Helper()
@@ -106,3 +212,619 @@ func (mr *MockCarProviderMockRecorder) UpdateCar(ctx, updatedCar interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCar", reflect.TypeOf((*MockCarProvider)(nil).UpdateCar), ctx, updatedCar)
 }
+
+// MockAuditProvider is a mock of AuditProvider interface.
+type MockAuditProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditProviderMockRecorder
+}
+
+// MockAuditProviderMockRecorder is the mock recorder for MockAuditProvider.
+type MockAuditProviderMockRecorder struct {
+	mock *MockAuditProvider
+}
+
+// NewMockAuditProvider creates a new mock instance.
+func NewMockAuditProvider(ctrl *gomock.Controller) *MockAuditProvider {
+	mock := &MockAuditProvider{ctrl: ctrl}
+	mock.recorder = &MockAuditProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditProvider) EXPECT() *MockAuditProviderMockRecorder {
+	return m.recorder
+}
+
+// ListAudit mocks base method.
+func (m *MockAuditProvider) ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAudit", ctx, filter)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListAudit indicates an expected call of ListAudit.
+func (mr *MockAuditProviderMockRecorder) ListAudit(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAudit", reflect.TypeOf((*MockAuditProvider)(nil).ListAudit), ctx, filter)
+}
+
+// MockOutboxProvider is a mock of OutboxProvider interface.
+type MockOutboxProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxProviderMockRecorder
+}
+
+// MockOutboxProviderMockRecorder is the mock recorder for MockOutboxProvider.
+type MockOutboxProviderMockRecorder struct {
+	mock *MockOutboxProvider
+}
+
+// NewMockOutboxProvider creates a new mock instance.
+func NewMockOutboxProvider(ctrl *gomock.Controller) *MockOutboxProvider {
+	mock := &MockOutboxProvider{ctrl: ctrl}
+	mock.recorder = &MockOutboxProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxProvider) EXPECT() *MockOutboxProviderMockRecorder {
+	return m.recorder
+}
+
+// MarkFailed mocks base method.
+func (m *MockOutboxProvider) MarkFailed(ctx context.Context, id int64, retryAt time.Time, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ctx, id, retryAt, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockOutboxProviderMockRecorder) MarkFailed(ctx, id, retryAt, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockOutboxProvider)(nil).MarkFailed), ctx, id, retryAt, reason)
+}
+
+// MarkPublished mocks base method.
+func (m *MockOutboxProvider) MarkPublished(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPublished", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPublished indicates an expected call of MarkPublished.
+func (mr *MockOutboxProviderMockRecorder) MarkPublished(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPublished", reflect.TypeOf((*MockOutboxProvider)(nil).MarkPublished), ctx, id)
+}
+
+// PendingEvents mocks base method.
+func (m *MockOutboxProvider) PendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingEvents", ctx, limit)
+	ret0, _ := ret[0].([]models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingEvents indicates an expected call of PendingEvents.
+func (mr *MockOutboxProviderMockRecorder) PendingEvents(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingEvents", reflect.TypeOf((*MockOutboxProvider)(nil).PendingEvents), ctx, limit)
+}
+
+// MockWebhookProvider is a mock of WebhookProvider interface.
+type MockWebhookProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookProviderMockRecorder
+}
+
+// MockWebhookProviderMockRecorder is the mock recorder for MockWebhookProvider.
+type MockWebhookProviderMockRecorder struct {
+	mock *MockWebhookProvider
+}
+
+// NewMockWebhookProvider creates a new mock instance.
+func NewMockWebhookProvider(ctrl *gomock.Controller) *MockWebhookProvider {
+	mock := &MockWebhookProvider{ctrl: ctrl}
+	mock.recorder = &MockWebhookProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookProvider) EXPECT() *MockWebhookProviderMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookProvider) CreateWebhook(ctx context.Context, s *models.WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookProviderMockRecorder) CreateWebhook(ctx, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookProvider)(nil).CreateWebhook), ctx, s)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookProvider) DeleteWebhook(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookProviderMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookProvider)(nil).DeleteWebhook), ctx, id)
+}
+
+// EnqueueDeliveries mocks base method.
+func (m *MockWebhookProvider) EnqueueDeliveries(ctx context.Context, evt models.OutboxEvent, payload []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueDeliveries", ctx, evt, payload)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueDeliveries indicates an expected call of EnqueueDeliveries.
+func (mr *MockWebhookProviderMockRecorder) EnqueueDeliveries(ctx, evt, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueDeliveries", reflect.TypeOf((*MockWebhookProvider)(nil).EnqueueDeliveries), ctx, evt, payload)
+}
+
+// GetDelivery mocks base method.
+func (m *MockWebhookProvider) GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDelivery", ctx, id)
+	ret0, _ := ret[0].(*models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDelivery indicates an expected call of GetDelivery.
+func (mr *MockWebhookProviderMockRecorder) GetDelivery(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelivery", reflect.TypeOf((*MockWebhookProvider)(nil).GetDelivery), ctx, id)
+}
+
+// GetWebhook mocks base method.
+func (m *MockWebhookProvider) GetWebhook(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, id)
+	ret0, _ := ret[0].(*models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockWebhookProviderMockRecorder) GetWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockWebhookProvider)(nil).GetWebhook), ctx, id)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockWebhookProvider) ListDeliveries(ctx context.Context, subscriptionID, status string, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, subscriptionID, status, limit, offset)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockWebhookProviderMockRecorder) ListDeliveries(ctx, subscriptionID, status, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockWebhookProvider)(nil).ListDeliveries), ctx, subscriptionID, status, limit, offset)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockWebhookProvider) ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx)
+	ret0, _ := ret[0].([]models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockWebhookProviderMockRecorder) ListWebhooks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockWebhookProvider)(nil).ListWebhooks), ctx)
+}
+
+// PendingDeliveries mocks base method.
+func (m *MockWebhookProvider) PendingDeliveries(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingDeliveries", ctx, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingDeliveries indicates an expected call of PendingDeliveries.
+func (mr *MockWebhookProviderMockRecorder) PendingDeliveries(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingDeliveries", reflect.TypeOf((*MockWebhookProvider)(nil).PendingDeliveries), ctx, limit)
+}
+
+// RecordDeliveryAttempt mocks base method.
+func (m *MockWebhookProvider) RecordDeliveryAttempt(ctx context.Context, id int64, res models.DeliveryResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDeliveryAttempt", ctx, id, res)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDeliveryAttempt indicates an expected call of RecordDeliveryAttempt.
+func (mr *MockWebhookProviderMockRecorder) RecordDeliveryAttempt(ctx, id, res interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDeliveryAttempt", reflect.TypeOf((*MockWebhookProvider)(nil).RecordDeliveryAttempt), ctx, id, res)
+}
+
+// RedeliverDelivery mocks base method.
+func (m *MockWebhookProvider) RedeliverDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeliverDelivery", ctx, id)
+	ret0, _ := ret[0].(*models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeliverDelivery indicates an expected call of RedeliverDelivery.
+func (mr *MockWebhookProviderMockRecorder) RedeliverDelivery(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverDelivery", reflect.TypeOf((*MockWebhookProvider)(nil).RedeliverDelivery), ctx, id)
+}
+
+// UpdateWebhook mocks base method.
+func (m *MockWebhookProvider) UpdateWebhook(ctx context.Context, s *models.WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockWebhookProviderMockRecorder) UpdateWebhook(ctx, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockWebhookProvider)(nil).UpdateWebhook), ctx, s)
+}
+
+// MockImportProvider is a mock of ImportProvider interface.
+type MockImportProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportProviderMockRecorder
+}
+
+// MockImportProviderMockRecorder is the mock recorder for MockImportProvider.
+type MockImportProviderMockRecorder struct {
+	mock *MockImportProvider
+}
+
+// NewMockImportProvider creates a new mock instance.
+func NewMockImportProvider(ctrl *gomock.Controller) *MockImportProvider {
+	mock := &MockImportProvider{ctrl: ctrl}
+	mock.recorder = &MockImportProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImportProvider) EXPECT() *MockImportProviderMockRecorder {
+	return m.recorder
+}
+
+// GetImport mocks base method.
+func (m *MockImportProvider) GetImport(ctx context.Context, id string) (*models.CarImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImport", ctx, id)
+	ret0, _ := ret[0].(*models.CarImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImport indicates an expected call of GetImport.
+func (mr *MockImportProviderMockRecorder) GetImport(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImport", reflect.TypeOf((*MockImportProvider)(nil).GetImport), ctx, id)
+}
+
+// ImportCars mocks base method.
+func (m *MockImportProvider) ImportCars(ctx context.Context, imp *models.CarImport, next func() (models.ImportRow, error)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportCars", ctx, imp, next)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportCars indicates an expected call of ImportCars.
+func (mr *MockImportProviderMockRecorder) ImportCars(ctx, imp, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportCars", reflect.TypeOf((*MockImportProvider)(nil).ImportCars), ctx, imp, next)
+}
+
+// ListImportRows mocks base method.
+func (m *MockImportProvider) ListImportRows(ctx context.Context, importID string, afterLine, limit int) ([]models.ImportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListImportRows", ctx, importID, afterLine, limit)
+	ret0, _ := ret[0].([]models.ImportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListImportRows indicates an expected call of ListImportRows.
+func (mr *MockImportProviderMockRecorder) ListImportRows(ctx, importID, afterLine, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImportRows", reflect.TypeOf((*MockImportProvider)(nil).ListImportRows), ctx, importID, afterLine, limit)
+}
+
+// ListImportedCars mocks base method.
+func (m *MockImportProvider) ListImportedCars(ctx context.Context, importID string, afterLine, limit int) ([]models.ImportedCar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListImportedCars", ctx, importID, afterLine, limit)
+	ret0, _ := ret[0].([]models.ImportedCar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListImportedCars indicates an expected call of ListImportedCars.
+func (mr *MockImportProviderMockRecorder) ListImportedCars(ctx, importID, afterLine, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImportedCars", reflect.TypeOf((*MockImportProvider)(nil).ListImportedCars), ctx, importID, afterLine, limit)
+}
+
+// MockExportProvider is a mock of ExportProvider interface.
+type MockExportProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportProviderMockRecorder
+}
+
+// MockExportProviderMockRecorder is the mock recorder for MockExportProvider.
+type MockExportProviderMockRecorder struct {
+	mock *MockExportProvider
+}
+
+// NewMockExportProvider creates a new mock instance.
+func NewMockExportProvider(ctrl *gomock.Controller) *MockExportProvider {
+	mock := &MockExportProvider{ctrl: ctrl}
+	mock.recorder = &MockExportProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportProvider) EXPECT() *MockExportProviderMockRecorder {
+	return m.recorder
+}
+
+// StreamCars mocks base method.
+func (m *MockExportProvider) StreamCars(ctx context.Context, f models.CarFilter, fn func(models.Car) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamCars", ctx, f, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamCars indicates an expected call of StreamCars.
+func (mr *MockExportProviderMockRecorder) StreamCars(ctx, f, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamCars", reflect.TypeOf((*MockExportProvider)(nil).StreamCars), ctx, f, fn)
+}
+
+// MockJobProvider is a mock of JobProvider interface.
+type MockJobProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobProviderMockRecorder
+}
+
+// MockJobProviderMockRecorder is the mock recorder for MockJobProvider.
+type MockJobProviderMockRecorder struct {
+	mock *MockJobProvider
+}
+
+// NewMockJobProvider creates a new mock instance.
+func NewMockJobProvider(ctrl *gomock.Controller) *MockJobProvider {
+	mock := &MockJobProvider{ctrl: ctrl}
+	mock.recorder = &MockJobProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobProvider) EXPECT() *MockJobProviderMockRecorder {
+	return m.recorder
+}
+
+// CancelJob mocks base method.
+func (m *MockJobProvider) CancelJob(ctx context.Context, id string) (*models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelJob", ctx, id)
+	ret0, _ := ret[0].(*models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelJob indicates an expected call of CancelJob.
+func (mr *MockJobProviderMockRecorder) CancelJob(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelJob", reflect.TypeOf((*MockJobProvider)(nil).CancelJob), ctx, id)
+}
+
+// ClaimJob mocks base method.
+func (m *MockJobProvider) ClaimJob(ctx context.Context, lease time.Duration) (*models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimJob", ctx, lease)
+	ret0, _ := ret[0].(*models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimJob indicates an expected call of ClaimJob.
+func (mr *MockJobProviderMockRecorder) ClaimJob(ctx, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimJob", reflect.TypeOf((*MockJobProvider)(nil).ClaimJob), ctx, lease)
+}
+
+// CreateJob mocks base method.
+func (m *MockJobProvider) CreateJob(ctx context.Context, j *models.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", ctx, j)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockJobProviderMockRecorder) CreateJob(ctx, j interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockJobProvider)(nil).CreateJob), ctx, j)
+}
+
+// DeleteFinishedJobs mocks base method.
+func (m *MockJobProvider) DeleteFinishedJobs(ctx context.Context, before time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFinishedJobs", ctx, before)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFinishedJobs indicates an expected call of DeleteFinishedJobs.
+func (mr *MockJobProviderMockRecorder) DeleteFinishedJobs(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFinishedJobs", reflect.TypeOf((*MockJobProvider)(nil).DeleteFinishedJobs), ctx, before)
+}
+
+// ExtendJob mocks base method.
+func (m *MockJobProvider) ExtendJob(ctx context.Context, id string, attempt int, p models.JobProgress, lease time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendJob", ctx, id, attempt, p, lease)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExtendJob indicates an expected call of ExtendJob.
+func (mr *MockJobProviderMockRecorder) ExtendJob(ctx, id, attempt, p, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendJob", reflect.TypeOf((*MockJobProvider)(nil).ExtendJob), ctx, id, attempt, p, lease)
+}
+
+// FinishJob mocks base method.
+func (m *MockJobProvider) FinishJob(ctx context.Context, id string, attempt int, res models.JobResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishJob", ctx, id, attempt, res)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishJob indicates an expected call of FinishJob.
+func (mr *MockJobProviderMockRecorder) FinishJob(ctx, id, attempt, res interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishJob", reflect.TypeOf((*MockJobProvider)(nil).FinishJob), ctx, id, attempt, res)
+}
+
+// GetJob mocks base method.
+func (m *MockJobProvider) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(*models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockJobProviderMockRecorder) GetJob(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockJobProvider)(nil).GetJob), ctx, id)
+}
+
+// MockIdempotencyProvider is a mock of IdempotencyProvider interface.
+type MockIdempotencyProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyProviderMockRecorder
+}
+
+// MockIdempotencyProviderMockRecorder is the mock recorder for MockIdempotencyProvider.
+type MockIdempotencyProviderMockRecorder struct {
+	mock *MockIdempotencyProvider
+}
+
+// NewMockIdempotencyProvider creates a new mock instance.
+func NewMockIdempotencyProvider(ctrl *gomock.Controller) *MockIdempotencyProvider {
+	mock := &MockIdempotencyProvider{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyProvider) EXPECT() *MockIdempotencyProviderMockRecorder {
+	return m.recorder
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockIdempotencyProvider) CompleteIdempotencyKey(ctx context.Context, scope, key string, resp models.StoredResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", ctx, scope, key, resp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockIdempotencyProviderMockRecorder) CompleteIdempotencyKey(ctx, scope, key, resp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockIdempotencyProvider)(nil).CompleteIdempotencyKey), ctx, scope, key, resp)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockIdempotencyProvider) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockIdempotencyProviderMockRecorder) DeleteExpiredIdempotencyKeys(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockIdempotencyProvider)(nil).DeleteExpiredIdempotencyKeys), ctx, before)
+}
+
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockIdempotencyProvider) ReleaseIdempotencyKey(ctx context.Context, scope, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", ctx, scope, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockIdempotencyProviderMockRecorder) ReleaseIdempotencyKey(ctx, scope, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockIdempotencyProvider)(nil).ReleaseIdempotencyKey), ctx, scope, key)
+}
+
+// ReserveIdempotencyKey mocks base method.
+func (m *MockIdempotencyProvider) ReserveIdempotencyKey(ctx context.Context, k *models.IdempotencyKey, staleBefore time.Time) (*models.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveIdempotencyKey", ctx, k, staleBefore)
+	ret0, _ := ret[0].(*models.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveIdempotencyKey indicates an expected call of ReserveIdempotencyKey.
+func (mr *MockIdempotencyProviderMockRecorder) ReserveIdempotencyKey(ctx, k, staleBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIdempotencyKey", reflect.TypeOf((*MockIdempotencyProvider)(nil).ReserveIdempotencyKey), ctx, k, staleBefore)
+}
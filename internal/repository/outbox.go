@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/reqctx"
+)
+
+// defaultOutboxLimit применяется, если PendingEvents вызван без лимита.
+const defaultOutboxLimit = 100
+
+// maxOutboxError ограничивает длину last_error.
+const maxOutboxError = 1000
+
+var eventTypes = map[string]string{
+	models.AuditActionCreate:  models.EventCarCreated,
+	models.AuditActionUpdate:  models.EventCarUpdated,
+	models.AuditActionDelete:  models.EventCarDeleted,
+	models.AuditActionRestore: models.EventCarRestored,
+}
+
+// newOutboxEvent строит событие для действия аудита; payload — состояние машины после изменения.
+func newOutboxEvent(ctx context.Context, action string, car *models.Car) (models.OutboxEvent, error) {
+	typ, ok := eventTypes[action]
+	if !ok {
+		return models.OutboxEvent{}, fmt.Errorf("no event type for action %q", action)
+	}
+	payload, err := json.Marshal(models.NewCarResponse(*car))
+	if err != nil {
+		return models.OutboxEvent{}, err
+	}
+	return models.OutboxEvent{
+		Type:      typ,
+		CarID:     car.ID,
+		Actor:     reqctx.Actor(ctx),
+		RequestID: reqctx.RequestID(ctx),
+		Payload:   payload,
+	}, nil
+}
+
+func outboxLimit(limit int) int {
+	if limit <= 0 {
+		return defaultOutboxLimit
+	}
+	return limit
+}
+
+// truncateError обрезает текст ошибки до maxOutboxError байт по границе символа:
+// PostgreSQL не примет строку с разрезанным посередине UTF-8.
+func truncateError(reason string) string {
+	if len(reason) <= maxOutboxError {
+		return reason
+	}
+	n := maxOutboxError
+	for n > 0 && !utf8.RuneStart(reason[n]) {
+		n--
+	}
+	return reason[:n]
+}
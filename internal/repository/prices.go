@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/reqctx"
+)
+
+var _ PriceProvider = (*CarRepo)(nil)
+
+// newPricePoint возвращает запись истории цены, если изменение задаёт цену новой машине
+// или меняет цену либо валюту существующей. Удаление и восстановление цену не меняют.
+func newPricePoint(ctx context.Context, before, after *models.Car) (models.PricePoint, bool) {
+	if after == nil {
+		return models.PricePoint{}, false
+	}
+	if before == nil {
+		if after.Price == nil {
+			return models.PricePoint{}, false
+		}
+	} else if equalPtr(before.Price, after.Price) && before.Currency == after.Currency {
+		return models.PricePoint{}, false
+	}
+	return models.PricePoint{
+		CarID:     after.ID,
+		Price:     clonePtr(after.Price),
+		Currency:  after.Currency,
+		Actor:     reqctx.Actor(ctx),
+		RequestID: reqctx.RequestID(ctx),
+	}, true
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func writePrice(ctx context.Context, tx pgx.Tx, before, after *models.Car) error {
+	p, ok := newPricePoint(ctx, before, after)
+	if !ok {
+		return nil
+	}
+	const query = `
+		INSERT INTO car_prices (car_id, price, currency, actor, request_id)
+		VALUES ($1, $2, $3, $4, $5);
+	`
+	_, err := tx.Exec(ctx, query, p.CarID, p.Price, nullString(p.Currency), p.Actor, p.RequestID)
+	return err
+}
+
+func (r *CarRepo) ListPrices(ctx context.Context, carID string) ([]models.PricePoint, error) {
+	const query = `
+		SELECT id, car_id, price, COALESCE(currency, ''), actor, request_id, changed_at
+		FROM car_prices
+		WHERE car_id = $1
+		ORDER BY id DESC;
+	`
+	rows, err := r.pool.Query(ctx, query, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []models.PricePoint{}
+	for rows.Next() {
+		var p models.PricePoint
+		if err := rows.Scan(&p.ID, &p.CarID, &p.Price, &p.Currency, &p.Actor, &p.RequestID, &p.ChangedAt); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
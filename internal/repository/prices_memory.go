@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ PriceProvider = (*MemoryCarRepo)(nil)
+
+func (r *MemoryCarRepo) ListPrices(ctx context.Context, carID string) ([]models.PricePoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	points := []models.PricePoint{}
+	for i := len(r.prices) - 1; i >= 0; i-- {
+		if p := r.prices[i]; p.CarID == carID {
+			p.Price = clonePtr(p.Price)
+			points = append(points, p)
+		}
+	}
+	return points, nil
+}
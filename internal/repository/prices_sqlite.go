@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ PriceProvider = (*SQLiteCarRepo)(nil)
+
+func sqliteWritePrice(ctx context.Context, tx *sql.Tx, before, after *models.Car) error {
+	p, ok := newPricePoint(ctx, before, after)
+	if !ok {
+		return nil
+	}
+	const query = `
+		INSERT INTO car_prices (car_id, price, currency, actor, request_id, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?);
+	`
+	_, err := tx.ExecContext(ctx, query, p.CarID, p.Price, nullString(p.Currency), p.Actor, p.RequestID,
+		formatSQLiteTime(sqliteNow()))
+	return err
+}
+
+func (r *SQLiteCarRepo) ListPrices(ctx context.Context, carID string) ([]models.PricePoint, error) {
+	const query = `
+		SELECT id, car_id, price, COALESCE(currency, ''), actor, request_id, changed_at
+		FROM car_prices
+		WHERE car_id = ?
+		ORDER BY id DESC;
+	`
+	rows, err := r.db.QueryContext(ctx, query, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []models.PricePoint{}
+	for rows.Next() {
+		var (
+			p         models.PricePoint
+			changedAt string
+		)
+		if err := rows.Scan(&p.ID, &p.CarID, &p.Price, &p.Currency, &p.Actor, &p.RequestID, &changedAt); err != nil {
+			return nil, err
+		}
+		if p.ChangedAt, err = time.Parse(sqliteTimeLayout, changedAt); err != nil {
+			return nil, fmt.Errorf("parse price changed_at %q: %w", changedAt, err)
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
@@ -0,0 +1,84 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// tick возвращает момент между двумя изменениями: хранилища хранят время с точностью до микросекунд.
+func tick() time.Time {
+	time.Sleep(2 * time.Millisecond)
+	at := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	return at
+}
+
+func testGetAsOf(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	beforeCreate := tick()
+	c := insert(t, repo, "Toyota", "Camry", 2018)
+	afterCreate := tick()
+	c.Year = 2019
+	require.NoError(t, repo.UpdateCar(ctx, &c))
+	afterUpdate := tick()
+	require.NoError(t, repo.DeleteByID(ctx, c.ID))
+	afterDelete := tick()
+	_, err := repo.RestoreByID(ctx, c.ID)
+	require.NoError(t, err)
+
+	_, err = repo.GetCarAsOf(ctx, c.ID, beforeCreate)
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+
+	got, err := repo.GetCarAsOf(ctx, c.ID, afterCreate)
+	require.NoError(t, err)
+	assert.Equal(t, 2018, got.Year)
+	assert.Equal(t, 1, got.Version)
+	assert.Equal(t, "Toyota", got.Brand)
+
+	got, err = repo.GetCarAsOf(ctx, c.ID, afterUpdate)
+	require.NoError(t, err)
+	assert.Equal(t, 2019, got.Year)
+	assert.Equal(t, 2, got.Version)
+
+	_, err = repo.GetCarAsOf(ctx, c.ID, afterDelete)
+	assert.ErrorIs(t, err, apperr.ErrNotFound, "car was in trash at that moment")
+
+	got, err = repo.GetCarAsOf(ctx, c.ID, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 4, got.Version)
+	assert.Nil(t, got.DeletedAt)
+}
+
+func testListAsOf(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	a := insert(t, repo, "BMW", "X5", 2019)
+	afterA := tick()
+	b := insert(t, repo, "Audi", "A6", 2020)
+	afterB := tick()
+	require.NoError(t, repo.DeleteByID(ctx, a.ID))
+	require.NoError(t, repo.DeleteByID(ctx, b.ID))
+	_, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	list, err := repo.ListCarsAsOf(ctx, afterA)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, a.ID, list[0].ID)
+
+	list, err = repo.ListCarsAsOf(ctx, afterB)
+	require.NoError(t, err)
+	require.Len(t, list, 2, "purged cars are still visible in the past")
+	assert.Equal(t, b.ID, list[0].ID, "newest first")
+	assert.Equal(t, a.ID, list[1].ID)
+
+	list, err = repo.ListCarsAsOf(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
@@ -0,0 +1,139 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+func intPtr(v int) *int { return &v }
+
+// money разбирает сумму из десятичной записи, как в API.
+func money(s string) *models.Money {
+	m, err := models.ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return &m
+}
+
+func fullAttributes() models.CarAttributes {
+	return models.CarAttributes{
+		Mileage:      intPtr(42000),
+		Color:        "black",
+		BodyType:     "sedan",
+		FuelType:     "diesel",
+		Transmission: "automatic",
+		EngineVolume: intPtr(1995),
+		EnginePower:  intPtr(190),
+		Price:        money("3499999.99"),
+		Currency:     "RUB",
+		Condition:    "used",
+		Status:       models.CarStatusReserved,
+	}
+}
+
+func testAttributesRoundTrip(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	c := models.Car{Brand: "BMW", Model: "Xfive", Year: 2020, CarAttributes: fullAttributes()}
+	require.NoError(t, repo.InsertCar(ctx, &c))
+
+	got, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, fullAttributes(), got.CarAttributes)
+
+	// без атрибутов машина доступна к продаже, остальное не указано
+	plain := insert(t, repo, "Kia", "Rio", 2021)
+	assert.Equal(t, models.CarAttributes{Status: models.CarStatusAvailable}, plain.CarAttributes)
+	got, err = repo.GetCarByID(ctx, plain.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CarAttributes{Status: models.CarStatusAvailable}, got.CarAttributes)
+
+	// обновление заменяет атрибуты целиком, пустые значения очищают их
+	upd, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	upd.CarAttributes = models.CarAttributes{Mileage: intPtr(43000), Status: models.CarStatusSold}
+	require.NoError(t, repo.UpdateCar(ctx, upd))
+	got, err = repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CarAttributes{Mileage: intPtr(43000), Status: models.CarStatusSold}, got.CarAttributes)
+}
+
+// testPriceRoundTrip: цена с копейками читается ровно такой, какой записана, в том числе
+// суммы, которые не представимы в двоичной плавающей точке.
+func testPriceRoundTrip(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	for _, price := range []string{"0.10", "0.01", "19999.99", "900000.01", "999999999999.99"} {
+		c := models.Car{Brand: "Kia", Model: "Rio", Year: 2021,
+			CarAttributes: models.CarAttributes{Price: money(price), Currency: "RUB"}}
+		require.NoError(t, repo.InsertCar(ctx, &c))
+		got, err := repo.GetCarByID(ctx, c.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got.Price, price)
+		assert.Equal(t, *money(price), *got.Price, price)
+
+		if prices, ok := repo.(repository.PriceProvider); ok {
+			history, err := prices.ListPrices(ctx, c.ID)
+			require.NoError(t, err)
+			require.Len(t, history, 1, price)
+			assert.Equal(t, *money(price), *history[0].Price, price)
+		}
+	}
+}
+
+func testBatchAttributes(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	existing := insert(t, repo, "Kia", "Rio", 2021)
+	sold := models.CarAttributes{Price: money("899999.5"), Currency: "RUB", Status: models.CarStatusSold}
+
+	res, err := repo.ApplyBatch(ctx, []models.CarMutation{
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "BMW", Model: "Xfive", Year: 2020, CarAttributes: fullAttributes()}},
+		{Op: models.BatchOpUpdate, Car: models.Car{ID: existing.ID, Brand: "Kia", Model: "Rio", Year: 2021, CarAttributes: sold}},
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "Lada", Model: "Vesta", Year: 2022}},
+	}, true)
+	require.NoError(t, err)
+	for _, r := range res {
+		require.NoError(t, r.Err)
+	}
+
+	got, err := repo.GetCarByID(ctx, res[0].Car.ID)
+	require.NoError(t, err)
+	assert.Equal(t, fullAttributes(), got.CarAttributes)
+	got, err = repo.GetCarByID(ctx, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sold, got.CarAttributes)
+	got, err = repo.GetCarByID(ctx, res[2].Car.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CarStatusAvailable, got.Status)
+}
+
+func testExportAttributeFilter(t *testing.T, repo repository.CarProvider, export repository.ExportProvider) {
+	ctx := context.Background()
+	a := models.Car{Brand: "BMW", Model: "Xfive", Year: 2020, CarAttributes: fullAttributes()}
+	require.NoError(t, repo.InsertCar(ctx, &a))
+	b := models.Car{Brand: "Kia", Model: "Rio", Year: 2021, CarAttributes: models.CarAttributes{
+		Mileage: intPtr(10), FuelType: "petrol", Price: money("900000"), Currency: "RUB",
+	}}
+	require.NoError(t, repo.InsertCar(ctx, &b))
+	insert(t, repo, "Lada", "Vesta", 2022) // без цены и пробега
+
+	ids := func(f models.CarFilter) []string {
+		var out []string
+		for _, c := range collect(t, export, f) {
+			out = append(out, c.ID)
+		}
+		return out
+	}
+	assert.Equal(t, []string{a.ID}, ids(models.CarFilter{Status: "RESERVED"}))
+	assert.Equal(t, []string{b.ID}, ids(models.CarFilter{FuelType: "petrol", Currency: "rub"}))
+	assert.Equal(t, []string{a.ID}, ids(models.CarFilter{PriceFrom: *money("900000.01")}))
+	assert.Equal(t, []string{b.ID}, ids(models.CarFilter{PriceTo: *money("900000")}))
+	assert.Equal(t, []string{b.ID, a.ID}, ids(models.CarFilter{PriceTo: *money("3499999.99")}))
+	assert.Equal(t, []string{b.ID}, ids(models.CarFilter{MileageTo: 100}))
+	assert.Empty(t, ids(models.CarFilter{Color: "red"}))
+}
@@ -0,0 +1,156 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/reqctx"
+)
+
+// runAuditSuite проверяет журнал изменений, если реализация поддерживает repository.AuditProvider.
+func runAuditSuite(t *testing.T, factory Factory) {
+	auditRepo := func(t *testing.T) (repository.CarProvider, repository.AuditProvider) {
+		repo := factory(t)
+		audit, ok := repo.(repository.AuditProvider)
+		if !ok {
+			t.Skip("repository does not implement AuditProvider")
+		}
+		return repo, audit
+	}
+
+	t.Run("AuditRecordsMutations", func(t *testing.T) {
+		repo, audit := auditRepo(t)
+		testAuditRecordsMutations(t, repo, audit)
+	})
+	t.Run("AuditFilters", func(t *testing.T) {
+		repo, audit := auditRepo(t)
+		testAuditFilters(t, repo, audit)
+	})
+	t.Run("AuditPagination", func(t *testing.T) {
+		repo, audit := auditRepo(t)
+		testAuditPagination(t, repo, audit)
+	})
+	t.Run("AuditSkipsFailedMutations", func(t *testing.T) {
+		repo, audit := auditRepo(t)
+		testAuditSkipsFailedMutations(t, repo, audit)
+	})
+}
+
+func testAuditRecordsMutations(t *testing.T, repo repository.CarProvider, audit repository.AuditProvider) {
+	ctx := reqctx.WithRequestID(reqctx.WithActor(context.Background(), "alice"), "req-1")
+
+	c := models.Car{Brand: "Toyota", Model: "Camry", Year: 2020}
+	require.NoError(t, repo.InsertCar(ctx, &c))
+	c.Year = 2021
+	require.NoError(t, repo.UpdateCar(ctx, &c))
+	require.NoError(t, repo.DeleteByID(ctx, c.ID))
+	_, err := repo.RestoreByID(ctx, c.ID)
+	require.NoError(t, err)
+
+	entries, total, err := audit.ListAudit(ctx, models.AuditFilter{CarID: c.ID})
+	require.NoError(t, err)
+	require.Equal(t, 4, total)
+	require.Len(t, entries, 4)
+
+	actions := make([]string, len(entries))
+	for i, e := range entries {
+		actions[i] = e.Action
+		assert.Equal(t, c.ID, e.CarID)
+		assert.Equal(t, "alice", e.Actor)
+		assert.Equal(t, "req-1", e.RequestID)
+		assert.False(t, e.CreatedAt.IsZero())
+	}
+	assert.Equal(t, []string{
+		models.AuditActionRestore,
+		models.AuditActionDelete,
+		models.AuditActionUpdate,
+		models.AuditActionCreate,
+	}, actions, "newest first")
+
+	update := entries[2]
+	require.Contains(t, update.Diff, "year")
+	assert.EqualValues(t, 2020, update.Diff["year"].From)
+	assert.EqualValues(t, 2021, update.Diff["year"].To)
+	assert.NotContains(t, update.Diff, "version")
+	assert.JSONEq(t, `{"brand":"Toyota","model":"Camry","year":2020,"status":"available","version":1}`,
+		string(update.Before))
+
+	create := entries[3]
+	assert.Nil(t, create.Before)
+	assert.NotEmpty(t, create.After)
+	assert.Contains(t, entries[1].Diff, "deleted_at")
+}
+
+func testAuditFilters(t *testing.T, repo repository.CarProvider, audit repository.AuditProvider) {
+	bg := context.Background()
+	alice := reqctx.WithActor(bg, "alice")
+	bob := reqctx.WithActor(bg, "bob")
+
+	a := models.Car{Brand: "BMW", Model: "X5", Year: 2019}
+	require.NoError(t, repo.InsertCar(alice, &a))
+	b := models.Car{Brand: "Audi", Model: "A6", Year: 2018}
+	require.NoError(t, repo.InsertCar(bob, &b))
+	require.NoError(t, repo.DeleteByID(bob, a.ID))
+
+	entries, total, err := audit.ListAudit(bg, models.AuditFilter{Actor: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, entries, 2)
+
+	entries, total, err = audit.ListAudit(bg, models.AuditFilter{Actor: "bob", Action: models.AuditActionDelete})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, a.ID, entries[0].CarID)
+
+	_, total, err = audit.ListAudit(bg, models.AuditFilter{From: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	_, total, err = audit.ListAudit(bg, models.AuditFilter{
+		From: time.Now().Add(-time.Hour),
+		To:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+}
+
+func testAuditPagination(t *testing.T, repo repository.CarProvider, audit repository.AuditProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Kia", "Rio", 2015)
+	for year := 2016; year <= 2019; year++ {
+		c.Year = year
+		require.NoError(t, repo.UpdateCar(ctx, &c))
+	}
+
+	page, total, err := audit.ListAudit(ctx, models.AuditFilter{CarID: c.ID, Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	require.Len(t, page, 2)
+	assert.EqualValues(t, 2018, page[0].Diff["year"].To)
+	assert.EqualValues(t, 2017, page[1].Diff["year"].To)
+
+	page, _, err = audit.ListAudit(ctx, models.AuditFilter{CarID: c.ID, Offset: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func testAuditSkipsFailedMutations(t *testing.T, repo repository.CarProvider, audit repository.AuditProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Lada", "Vesta", 2020)
+
+	stale := c
+	c.Year = 2021
+	require.NoError(t, repo.UpdateCar(ctx, &c))
+	stale.Year = 2022
+	require.Error(t, repo.UpdateCar(ctx, &stale))
+
+	_, total, err := audit.ListAudit(ctx, models.AuditFilter{CarID: c.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total, "rejected update must not be audited")
+}
@@ -0,0 +1,132 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+func testBatchMixed(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	a := insert(t, repo, "Toyota", "Camry", 2020)
+	b := insert(t, repo, "Honda", "Civic", 2019)
+
+	results, err := repo.ApplyBatch(ctx, []models.CarMutation{
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "Kia", Model: "Rio", Year: 2021}},
+		{Op: models.BatchOpUpdate, Car: models.Car{ID: a.ID, Brand: "Toyota", Model: "Corolla", Year: 2020, Version: a.Version}},
+		{Op: models.BatchOpDelete, Car: models.Car{ID: b.ID}},
+	}, true)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for i, r := range results {
+		require.NoError(t, r.Err, "operation %d", i)
+	}
+
+	created := results[0].Car
+	_, err = uuid.Parse(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, created.Version)
+	assert.False(t, created.CreatedAt.IsZero())
+	got, err := repo.GetCarByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Rio", got.Model)
+
+	assert.Equal(t, a.Version+1, results[1].Car.Version)
+	got, err = repo.GetCarByID(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Corolla", got.Model)
+	assert.Equal(t, a.Version+1, got.Version)
+
+	assert.NotNil(t, results[2].Car.DeletedAt)
+	_, err = repo.GetCarByID(ctx, b.ID)
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+
+	list, err := repo.ListCars(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
+func testBatchAtomicRollsBack(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	a := insert(t, repo, "Toyota", "Camry", 2020)
+
+	results, err := repo.ApplyBatch(ctx, []models.CarMutation{
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "Kia", Model: "Rio", Year: 2021}},
+		{Op: models.BatchOpUpdate, Car: models.Car{ID: a.ID, Brand: "Toyota", Model: "Corolla", Year: 2020}},
+		{Op: models.BatchOpDelete, Car: models.Car{ID: uuid.NewString()}},
+		{Op: models.BatchOpUpdate, Car: models.Car{ID: a.ID, Brand: "Toyota", Model: "Yaris", Year: 2020, Version: 99}},
+	}, true)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.ErrorIs(t, results[0].Err, apperr.ErrAborted)
+	assert.ErrorIs(t, results[1].Err, apperr.ErrAborted)
+	assert.ErrorIs(t, results[2].Err, apperr.ErrNotFound)
+	assert.ErrorIs(t, results[3].Err, apperr.ErrConflict)
+
+	list, err := repo.ListCars(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1, "nothing must be created")
+	assert.Equal(t, "Camry", list[0].Model)
+	assert.Equal(t, a.Version, list[0].Version)
+}
+
+func testBatchBestEffort(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	a := insert(t, repo, "Toyota", "Camry", 2020)
+
+	results, err := repo.ApplyBatch(ctx, []models.CarMutation{
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "Kia", Model: "Rio", Year: 1800}},
+		{Op: models.BatchOpUpdate, Car: models.Car{ID: a.ID, Brand: "Toyota", Model: "Corolla", Year: 2020, Version: 99}},
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "Lada", Model: "Vesta", Year: 2022}},
+	}, false)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.ErrorIs(t, results[0].Err, apperr.ErrInvalidInput)
+	assert.ErrorIs(t, results[1].Err, apperr.ErrConflict)
+	require.NoError(t, results[2].Err)
+
+	list, err := repo.ListCars(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	got, err := repo.GetCarByID(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Camry", got.Model)
+}
+
+// testBatchSequential: операции над одной машиной видят результат предыдущих.
+func testBatchSequential(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	a := insert(t, repo, "Toyota", "Camry", 2020)
+
+	results, err := repo.ApplyBatch(ctx, []models.CarMutation{
+		{Op: models.BatchOpUpdate, Car: models.Car{ID: a.ID, Brand: "Toyota", Model: "Corolla", Year: 2020, Version: a.Version}},
+		{Op: models.BatchOpUpdate, Car: models.Car{ID: a.ID, Brand: "Toyota", Model: "Yaris", Year: 2021, Version: a.Version + 1}},
+		{Op: models.BatchOpDelete, Car: models.Car{ID: a.ID}},
+		{Op: models.BatchOpDelete, Car: models.Car{ID: a.ID}},
+	}, false)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	require.NoError(t, results[1].Err)
+	require.NoError(t, results[2].Err)
+	assert.ErrorIs(t, results[3].Err, apperr.ErrNotFound, "already deleted in this batch")
+
+	trash, err := repo.ListDeletedCars(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, "Yaris", trash[0].Model)
+	assert.Equal(t, a.Version+3, trash[0].Version)
+
+	if audit, ok := repo.(repository.AuditProvider); ok {
+		entries, total, err := audit.ListAudit(ctx, models.AuditFilter{CarID: a.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 4, total, "create + 3 batch mutations")
+		assert.Equal(t, models.AuditActionDelete, entries[0].Action)
+	}
+}
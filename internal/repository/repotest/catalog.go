@@ -0,0 +1,215 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// runCatalogSuite проверяет справочник марок и моделей, если реализация поддерживает repository.CatalogProvider.
+func runCatalogSuite(t *testing.T, factory Factory) {
+	catalogRepo := func(t *testing.T) repository.CatalogProvider {
+		catalog, ok := factory(t).(repository.CatalogProvider)
+		if !ok {
+			t.Skip("repository does not implement CatalogProvider")
+		}
+		return catalog
+	}
+
+	t.Run("CatalogBrandCRUD", func(t *testing.T) {
+		testCatalogBrandCRUD(t, catalogRepo(t))
+	})
+	t.Run("CatalogFindByAlias", func(t *testing.T) {
+		testCatalogFindByAlias(t, catalogRepo(t))
+	})
+	t.Run("CatalogBrandNameUnique", func(t *testing.T) {
+		testCatalogBrandNameUnique(t, catalogRepo(t))
+	})
+	t.Run("CatalogModels", func(t *testing.T) {
+		testCatalogModels(t, catalogRepo(t))
+	})
+	t.Run("CatalogDeleteBrandCascades", func(t *testing.T) {
+		testCatalogDeleteBrandCascades(t, catalogRepo(t))
+	})
+	t.Run("CatalogYears", func(t *testing.T) {
+		testCatalogYears(t, catalogRepo(t))
+	})
+}
+
+func createBrand(t *testing.T, catalog repository.CatalogProvider, name string, aliases ...string) models.Brand {
+	t.Helper()
+	b := models.Brand{Name: name, Aliases: aliases}
+	require.NoError(t, catalog.CreateBrand(context.Background(), &b))
+	return b
+}
+
+func createModel(t *testing.T, catalog repository.CatalogProvider, brandID, name string, aliases ...string) models.CarModel {
+	t.Helper()
+	m := models.CarModel{BrandID: brandID, Name: name, Aliases: aliases}
+	require.NoError(t, catalog.CreateModel(context.Background(), &m))
+	return m
+}
+
+func testCatalogBrandCRUD(t *testing.T, catalog repository.CatalogProvider) {
+	ctx := context.Background()
+	vw := createBrand(t, catalog, "Volkswagen", "VW")
+	_, err := uuid.Parse(vw.ID)
+	require.NoError(t, err)
+	assert.False(t, vw.CreatedAt.IsZero())
+	createBrand(t, catalog, "Audi")
+
+	brands, err := catalog.ListBrands(ctx)
+	require.NoError(t, err)
+	require.Len(t, brands, 2)
+	assert.Equal(t, "Audi", brands[0].Name, "brands are sorted by name")
+	assert.Empty(t, brands[0].Aliases)
+	assert.NotNil(t, brands[0].Aliases)
+
+	vw.Aliases = []string{"VW", "Фольксваген"}
+	require.NoError(t, catalog.UpdateBrand(ctx, &vw))
+	got, err := catalog.GetBrand(ctx, vw.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"VW", "Фольксваген"}, got.Aliases)
+
+	require.NoError(t, catalog.DeleteBrand(ctx, vw.ID))
+	_, err = catalog.GetBrand(ctx, vw.ID)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "got %v", err)
+	assert.True(t, errors.Is(catalog.DeleteBrand(ctx, vw.ID), apperr.ErrNotFound))
+	missing := models.Brand{ID: uuid.NewString(), Name: "Lada"}
+	assert.True(t, errors.Is(catalog.UpdateBrand(ctx, &missing), apperr.ErrNotFound))
+}
+
+func testCatalogFindByAlias(t *testing.T, catalog repository.CatalogProvider) {
+	ctx := context.Background()
+	mb := createBrand(t, catalog, "Mercedes-Benz", "Mercedes", "MB")
+	createBrand(t, catalog, "Lada", "ВАЗ")
+
+	for _, name := range []string{"mercedes benz", "MERCEDES", "mb"} {
+		got, err := catalog.FindBrand(ctx, models.CatalogKey(name))
+		require.NoError(t, err, name)
+		assert.Equal(t, mb.ID, got.ID, name)
+	}
+	got, err := catalog.FindBrand(ctx, models.CatalogKey("ваз"))
+	require.NoError(t, err)
+	assert.Equal(t, "Lada", got.Name)
+
+	_, err = catalog.FindBrand(ctx, models.CatalogKey("Tesla"))
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "got %v", err)
+}
+
+func testCatalogBrandNameUnique(t *testing.T, catalog repository.CatalogProvider) {
+	ctx := context.Background()
+	createBrand(t, catalog, "Land Rover")
+
+	dup := models.Brand{Name: "land-rover"}
+	err := catalog.CreateBrand(ctx, &dup)
+	assert.True(t, errors.Is(err, apperr.ErrConflict), "got %v", err)
+
+	other := createBrand(t, catalog, "Rover")
+	other.Name = "LAND ROVER"
+	err = catalog.UpdateBrand(ctx, &other)
+	assert.True(t, errors.Is(err, apperr.ErrConflict), "got %v", err)
+}
+
+func testCatalogModels(t *testing.T, catalog repository.CatalogProvider) {
+	ctx := context.Background()
+	bmw := createBrand(t, catalog, "BMW")
+	audi := createBrand(t, catalog, "Audi")
+	x5 := createModel(t, catalog, bmw.ID, "X5", "E70")
+	createModel(t, catalog, bmw.ID, "M3")
+	createModel(t, catalog, audi.ID, "A4")
+
+	list, err := catalog.ListModels(ctx, bmw.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "M3", list[0].Name)
+	all, err := catalog.ListModels(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	got, err := catalog.FindModel(ctx, bmw.ID, models.CatalogKey("e70"))
+	require.NoError(t, err)
+	assert.Equal(t, x5.ID, got.ID)
+	_, err = catalog.FindModel(ctx, audi.ID, models.CatalogKey("X5"))
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "models are looked up within the brand, got %v", err)
+
+	dup := models.CarModel{BrandID: bmw.ID, Name: "x-5"}
+	assert.True(t, errors.Is(catalog.CreateModel(ctx, &dup), apperr.ErrConflict))
+	sameName := models.CarModel{BrandID: audi.ID, Name: "X5"}
+	require.NoError(t, catalog.CreateModel(ctx, &sameName), "other brand may have a model with the same name")
+	orphan := models.CarModel{BrandID: uuid.NewString(), Name: "Niva"}
+	assert.True(t, errors.Is(catalog.CreateModel(ctx, &orphan), apperr.ErrNotFound))
+
+	x5.Name = "X5 M"
+	x5.Aliases = nil
+	require.NoError(t, catalog.UpdateModel(ctx, &x5))
+	assert.Equal(t, bmw.ID, x5.BrandID)
+	_, err = catalog.FindModel(ctx, bmw.ID, models.CatalogKey("E70"))
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "removed alias must not match, got %v", err)
+
+	require.NoError(t, catalog.DeleteModel(ctx, x5.ID))
+	_, err = catalog.GetModel(ctx, x5.ID)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "got %v", err)
+}
+
+func testCatalogDeleteBrandCascades(t *testing.T, catalog repository.CatalogProvider) {
+	ctx := context.Background()
+	kia := createBrand(t, catalog, "Kia")
+	rio := createModel(t, catalog, kia.ID, "Rio")
+
+	require.NoError(t, catalog.DeleteBrand(ctx, kia.ID))
+	_, err := catalog.GetModel(ctx, rio.ID)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "got %v", err)
+}
+
+func testCatalogYears(t *testing.T, catalog repository.CatalogProvider) {
+	ctx := context.Background()
+	from, to := 2000, 2003
+	bmw := createBrand(t, catalog, "BMW")
+	z8 := createModel(t, catalog, bmw.ID, "Z8")
+	assert.Nil(t, bmw.From)
+	assert.Nil(t, z8.To)
+
+	got, err := catalog.SetBrandYears(ctx, bmw.ID, models.YearRange{From: &from})
+	require.NoError(t, err)
+	require.NotNil(t, got.From)
+	assert.Equal(t, from, *got.From)
+	assert.Nil(t, got.To)
+
+	m, err := catalog.SetModelYears(ctx, z8.ID, models.YearRange{From: &from, To: &to})
+	require.NoError(t, err)
+	require.NotNil(t, m.To)
+	assert.Equal(t, to, *m.To)
+
+	// замена названия и синонимов не сбрасывает годы
+	bmw.Aliases = []string{"БМВ"}
+	require.NoError(t, catalog.UpdateBrand(ctx, &bmw))
+	require.NotNil(t, bmw.From)
+	assert.Equal(t, from, *bmw.From)
+	z8.Name = "Z 8"
+	require.NoError(t, catalog.UpdateModel(ctx, &z8))
+	found, err := catalog.FindModel(ctx, bmw.ID, models.CatalogKey("Z8"))
+	require.NoError(t, err)
+	require.NotNil(t, found.From)
+	require.NotNil(t, found.To)
+	assert.Equal(t, from, *found.From)
+	assert.Equal(t, to, *found.To)
+
+	cleared, err := catalog.SetModelYears(ctx, z8.ID, models.YearRange{})
+	require.NoError(t, err)
+	assert.Nil(t, cleared.From)
+	assert.Nil(t, cleared.To)
+
+	_, err = catalog.SetBrandYears(ctx, uuid.NewString(), models.YearRange{})
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "got %v", err)
+	_, err = catalog.SetModelYears(ctx, uuid.NewString(), models.YearRange{})
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "got %v", err)
+}
@@ -0,0 +1,119 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// runExportSuite проверяет потоковую выгрузку, если реализация поддерживает repository.ExportProvider.
+func runExportSuite(t *testing.T, factory Factory) {
+	exportRepo := func(t *testing.T) (repository.CarProvider, repository.ExportProvider) {
+		repo := factory(t)
+		export, ok := repo.(repository.ExportProvider)
+		if !ok {
+			t.Skip("repository does not implement ExportProvider")
+		}
+		return repo, export
+	}
+
+	t.Run("ExportMatchesList", func(t *testing.T) {
+		repo, export := exportRepo(t)
+		testExportMatchesList(t, repo, export)
+	})
+	t.Run("ExportFilter", func(t *testing.T) {
+		repo, export := exportRepo(t)
+		testExportFilter(t, repo, export)
+	})
+	t.Run("ExportAttributeFilter", func(t *testing.T) {
+		repo, export := exportRepo(t)
+		testExportAttributeFilter(t, repo, export)
+	})
+	t.Run("ExportManyPages", func(t *testing.T) {
+		repo, export := exportRepo(t)
+		testExportManyPages(t, repo, export)
+	})
+	t.Run("ExportStopsOnError", func(t *testing.T) {
+		repo, export := exportRepo(t)
+		testExportStopsOnError(t, repo, export)
+	})
+}
+
+func collect(t *testing.T, export repository.ExportProvider, f models.CarFilter) []models.Car {
+	t.Helper()
+	var cars []models.Car
+	require.NoError(t, export.StreamCars(context.Background(), f, func(c models.Car) error {
+		cars = append(cars, c)
+		return nil
+	}))
+	return cars
+}
+
+func testExportMatchesList(t *testing.T, repo repository.CarProvider, export repository.ExportProvider) {
+	insert(t, repo, "Toyota", "Camry", 2020)
+	b := insert(t, repo, "Honda", "Civic", 2019)
+	insert(t, repo, "Kia", "Rio", 2021)
+	require.NoError(t, repo.DeleteByID(context.Background(), b.ID))
+
+	list, err := repo.ListCars(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, list, collect(t, export, models.CarFilter{}))
+}
+
+func testExportFilter(t *testing.T, repo repository.CarProvider, export repository.ExportProvider) {
+	insert(t, repo, "Toyota", "Camry", 2015)
+	insert(t, repo, "Toyota", "Corolla", 2020)
+	insert(t, repo, "Honda", "Civic", 2020)
+
+	got := collect(t, export, models.CarFilter{Brand: "toyota"})
+	assert.Len(t, got, 2)
+
+	got = collect(t, export, models.CarFilter{Brand: "TOYOTA", YearFrom: 2016})
+	require.Len(t, got, 1)
+	assert.Equal(t, "Corolla", got[0].Model)
+
+	got = collect(t, export, models.CarFilter{Model: "civic", YearFrom: 2020, YearTo: 2020})
+	require.Len(t, got, 1)
+	assert.Equal(t, "Honda", got[0].Brand)
+
+	assert.Empty(t, collect(t, export, models.CarFilter{YearTo: 2010}))
+}
+
+// testExportManyPages проверяет переход между страницами курсора, в том числе при одинаковом created_at.
+func testExportManyPages(t *testing.T, repo repository.CarProvider, export repository.ExportProvider) {
+	const n = 1234
+	ops := make([]models.CarMutation, n)
+	for i := range ops {
+		ops[i] = models.CarMutation{Op: models.BatchOpCreate, Car: models.Car{Brand: "Lada", Model: fmt.Sprintf("M%d", i), Year: 2000}}
+	}
+	_, err := repo.ApplyBatch(context.Background(), ops, true)
+	require.NoError(t, err)
+
+	seen := make(map[string]bool, n)
+	for _, c := range collect(t, export, models.CarFilter{Brand: "Lada"}) {
+		require.False(t, seen[c.ID], "duplicate %s", c.ID)
+		seen[c.ID] = true
+	}
+	assert.Len(t, seen, n)
+}
+
+func testExportStopsOnError(t *testing.T, repo repository.CarProvider, export repository.ExportProvider) {
+	insert(t, repo, "Toyota", "Camry", 2020)
+	insert(t, repo, "Honda", "Civic", 2019)
+
+	boom := errors.New("client gone")
+	calls := 0
+	err := export.StreamCars(context.Background(), models.CarFilter{}, func(models.Car) error {
+		calls++
+		return boom
+	})
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,141 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// runIdempotencySuite проверяет ключи идемпотентности, если реализация поддерживает repository.IdempotencyProvider.
+func runIdempotencySuite(t *testing.T, factory Factory) {
+	idemRepo := func(t *testing.T) repository.IdempotencyProvider {
+		idem, ok := factory(t).(repository.IdempotencyProvider)
+		if !ok {
+			t.Skip("repository does not implement IdempotencyProvider")
+		}
+		return idem
+	}
+
+	t.Run("IdempotencyReserveAndReplay", func(t *testing.T) {
+		testIdempotencyReserveAndReplay(t, idemRepo(t))
+	})
+	t.Run("IdempotencyRelease", func(t *testing.T) {
+		testIdempotencyRelease(t, idemRepo(t))
+	})
+	t.Run("IdempotencyTakeover", func(t *testing.T) {
+		testIdempotencyTakeover(t, idemRepo(t))
+	})
+	t.Run("IdempotencyDeleteExpired", func(t *testing.T) {
+		testIdempotencyDeleteExpired(t, idemRepo(t))
+	})
+}
+
+func idempotencyKey(key, hash string, ttl time.Duration) *models.IdempotencyKey {
+	return &models.IdempotencyKey{
+		Scope:       "anonymous POST /api/v1/cars",
+		Key:         key,
+		RequestHash: hash,
+		ExpiresAt:   time.Now().UTC().Add(ttl),
+	}
+}
+
+func testIdempotencyReserveAndReplay(t *testing.T, idem repository.IdempotencyProvider) {
+	ctx := context.Background()
+	staleBefore := time.Now().UTC().Add(-time.Hour)
+
+	existing, err := idem.ReserveIdempotencyKey(ctx, idempotencyKey("k1", "h1", time.Hour), staleBefore)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// пока запрос выполняется, повтор видит запись без ответа
+	existing, err = idem.ReserveIdempotencyKey(ctx, idempotencyKey("k1", "h1", time.Hour), staleBefore)
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.Equal(t, "h1", existing.RequestHash)
+	assert.Nil(t, existing.Response)
+
+	resp := models.StoredResponse{
+		Status:  201,
+		Headers: map[string]string{"Location": "/api/v1/cars/1"},
+		Body:    []byte(`{"id":"1"}`),
+	}
+	require.NoError(t, idem.CompleteIdempotencyKey(ctx, "anonymous POST /api/v1/cars", "k1", resp))
+
+	existing, err = idem.ReserveIdempotencyKey(ctx, idempotencyKey("k1", "h2", time.Hour), staleBefore)
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.Equal(t, "h1", existing.RequestHash)
+	require.NotNil(t, existing.Response)
+	assert.Equal(t, resp, *existing.Response)
+
+	// другой ключ независим
+	existing, err = idem.ReserveIdempotencyKey(ctx, idempotencyKey("k2", "h1", time.Hour), staleBefore)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+}
+
+func testIdempotencyRelease(t *testing.T, idem repository.IdempotencyProvider) {
+	ctx := context.Background()
+	staleBefore := time.Now().UTC().Add(-time.Hour)
+
+	_, err := idem.ReserveIdempotencyKey(ctx, idempotencyKey("k1", "h1", time.Hour), staleBefore)
+	require.NoError(t, err)
+	require.NoError(t, idem.ReleaseIdempotencyKey(ctx, "anonymous POST /api/v1/cars", "k1"))
+
+	existing, err := idem.ReserveIdempotencyKey(ctx, idempotencyKey("k1", "h2", time.Hour), staleBefore)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// завершённый ответ освобождение не удаляет
+	require.NoError(t, idem.CompleteIdempotencyKey(ctx, "anonymous POST /api/v1/cars", "k1",
+		models.StoredResponse{Status: 201, Body: []byte(`{}`)}))
+	require.NoError(t, idem.ReleaseIdempotencyKey(ctx, "anonymous POST /api/v1/cars", "k1"))
+	existing, err = idem.ReserveIdempotencyKey(ctx, idempotencyKey("k1", "h2", time.Hour), staleBefore)
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.Equal(t, 201, existing.Response.Status)
+}
+
+func testIdempotencyTakeover(t *testing.T, idem repository.IdempotencyProvider) {
+	ctx := context.Background()
+
+	// брошенная незавершённая запись перезанимается
+	_, err := idem.ReserveIdempotencyKey(ctx, idempotencyKey("stale", "h1", time.Hour), time.Now().UTC().Add(-time.Hour))
+	require.NoError(t, err)
+	existing, err := idem.ReserveIdempotencyKey(ctx, idempotencyKey("stale", "h2", time.Hour), time.Now().UTC().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// истёкшая запись перезанимается даже с ответом
+	_, err = idem.ReserveIdempotencyKey(ctx, idempotencyKey("expired", "h1", -time.Second), time.Now().UTC().Add(-time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, idem.CompleteIdempotencyKey(ctx, "anonymous POST /api/v1/cars", "expired",
+		models.StoredResponse{Status: 201}))
+	existing, err = idem.ReserveIdempotencyKey(ctx, idempotencyKey("expired", "h2", time.Hour), time.Now().UTC().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+}
+
+func testIdempotencyDeleteExpired(t *testing.T, idem repository.IdempotencyProvider) {
+	ctx := context.Background()
+	staleBefore := time.Now().UTC().Add(-time.Hour)
+
+	_, err := idem.ReserveIdempotencyKey(ctx, idempotencyKey("old", "h1", -time.Minute), staleBefore)
+	require.NoError(t, err)
+	_, err = idem.ReserveIdempotencyKey(ctx, idempotencyKey("fresh", "h1", time.Hour), staleBefore)
+	require.NoError(t, err)
+
+	n, err := idem.DeleteExpiredIdempotencyKeys(ctx, time.Now().UTC())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	existing, err := idem.ReserveIdempotencyKey(ctx, idempotencyKey("fresh", "h1", time.Hour), staleBefore)
+	require.NoError(t, err)
+	assert.NotNil(t, existing)
+}
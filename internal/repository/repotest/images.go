@@ -0,0 +1,194 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// runImageSuite проверяет фотографии машин, если реализация поддерживает repository.ImageProvider.
+func runImageSuite(t *testing.T, factory Factory) {
+	imageRepo := func(t *testing.T) (repository.CarProvider, repository.ImageProvider) {
+		repo := factory(t)
+		images, ok := repo.(repository.ImageProvider)
+		if !ok {
+			t.Skip("repository does not implement ImageProvider")
+		}
+		return repo, images
+	}
+
+	t.Run("ImageGallery", func(t *testing.T) {
+		repo, images := imageRepo(t)
+		testImageGallery(t, repo, images)
+	})
+	t.Run("ImageReorder", func(t *testing.T) {
+		repo, images := imageRepo(t)
+		testImageReorder(t, repo, images)
+	})
+	t.Run("ImageTrashAndOrphans", func(t *testing.T) {
+		repo, images := imageRepo(t)
+		testImageTrashAndOrphans(t, repo, images)
+	})
+}
+
+func addImage(t *testing.T, images repository.ImageProvider, carID string) models.CarImage {
+	t.Helper()
+	img := models.CarImage{ID: uuid.NewString(), CarID: carID, ContentType: "image/png", Size: 100,
+		Width: 40, Height: 30, Thumbnails: []string{"small", "large"}}
+	require.NoError(t, images.AddImage(context.Background(), &img, 10))
+	return img
+}
+
+func imageIDs(list []models.CarImage) []string {
+	ids := make([]string, len(list))
+	for i, img := range list {
+		ids[i] = img.ID
+	}
+	return ids
+}
+
+func testImageGallery(t *testing.T, repo repository.CarProvider, images repository.ImageProvider) {
+	ctx := context.Background()
+	car := insert(t, repo, "BMW", "Xfive", 2020)
+	other := insert(t, repo, "Kia", "Rio", 2021)
+
+	before := time.Now().Add(-time.Minute)
+	a := addImage(t, images, car.ID)
+	b := addImage(t, images, car.ID)
+	c := addImage(t, images, car.ID)
+	x := addImage(t, images, other.ID)
+	assert.Equal(t, []int{0, 1, 2}, []int{a.Position, b.Position, c.Position})
+	assert.Equal(t, 0, x.Position)
+	assert.True(t, a.CreatedAt.After(before), "created_at must be set")
+
+	got, err := images.GetImage(ctx, car.ID, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, b.ID, got.ID)
+	assert.Equal(t, models.CarImage{ID: b.ID, CarID: car.ID, Position: 1, ContentType: "image/png", Size: 100,
+		Width: 40, Height: 30, Thumbnails: []string{"small", "large"}, CreatedAt: got.CreatedAt}, *got)
+	_, err = images.GetImage(ctx, other.ID, b.ID)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "image of another car, got %v", err)
+
+	// изменения галереи увеличивают версию машины
+	reloaded, err := repo.GetCarByID(ctx, car.ID)
+	require.NoError(t, err)
+	assert.Equal(t, car.Version+3, reloaded.Version)
+
+	list, err := images.ListImages(ctx, car.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.ID, b.ID, c.ID}, imageIDs(list))
+	list, err = images.ListImages(ctx, car.ID, other.ID)
+	require.NoError(t, err)
+	assert.Len(t, list, 4)
+	list, err = images.ListImages(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// удаление сдвигает следующие фотографии
+	require.NoError(t, images.DeleteImage(ctx, car.ID, b.ID))
+	assert.True(t, errors.Is(images.DeleteImage(ctx, car.ID, b.ID), apperr.ErrNotFound))
+	assert.True(t, errors.Is(images.DeleteImage(ctx, other.ID, a.ID), apperr.ErrNotFound))
+	list, err = images.ListImages(ctx, car.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.ID, c.ID}, imageIDs(list))
+	assert.Equal(t, 1, list[1].Position)
+	d := addImage(t, images, car.ID)
+	assert.Equal(t, 2, d.Position)
+
+	// лимит фотографий на машину
+	extra := models.CarImage{ID: uuid.NewString(), CarID: car.ID, ContentType: "image/png", Size: 1, Width: 1, Height: 1}
+	err = images.AddImage(ctx, &extra, 3)
+	assert.True(t, errors.Is(err, apperr.ErrConflict), "got %v", err)
+	dup := models.CarImage{ID: a.ID, CarID: other.ID, ContentType: "image/png", Size: 1, Width: 1, Height: 1}
+	err = images.AddImage(ctx, &dup, 10)
+	assert.True(t, errors.Is(err, apperr.ErrConflict), "duplicate id, got %v", err)
+
+	missing := models.CarImage{ID: uuid.NewString(), CarID: uuid.NewString(), ContentType: "image/png", Size: 1, Width: 1, Height: 1}
+	err = images.AddImage(ctx, &missing, 10)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "got %v", err)
+}
+
+func testImageReorder(t *testing.T, repo repository.CarProvider, images repository.ImageProvider) {
+	ctx := context.Background()
+	car := insert(t, repo, "BMW", "Xfive", 2020)
+	other := insert(t, repo, "Kia", "Rio", 2021)
+	a := addImage(t, images, car.ID)
+	b := addImage(t, images, car.ID)
+	c := addImage(t, images, car.ID)
+	x := addImage(t, images, other.ID)
+
+	ordered, err := images.ReorderImages(ctx, car.ID, []string{c.ID, a.ID, b.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{c.ID, a.ID, b.ID}, imageIDs(ordered))
+	assert.Equal(t, 2, ordered[2].Position)
+	list, err := images.ListImages(ctx, car.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{c.ID, a.ID, b.ID}, imageIDs(list))
+	assert.Equal(t, []int{0, 1, 2}, []int{list[0].Position, list[1].Position, list[2].Position})
+
+	for name, ids := range map[string][]string{
+		"missing":       {c.ID, a.ID},
+		"duplicate":     {c.ID, a.ID, a.ID},
+		"another car's": {c.ID, a.ID, x.ID},
+	} {
+		_, err := images.ReorderImages(ctx, car.ID, ids)
+		assert.True(t, errors.Is(err, apperr.ErrInvalidInput), "%s: got %v", name, err)
+	}
+	list, err = images.ListImages(ctx, car.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{c.ID, a.ID, b.ID}, imageIDs(list), "failed reorder must not change the order")
+
+	_, err = images.ReorderImages(ctx, uuid.NewString(), nil)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "got %v", err)
+}
+
+func testImageTrashAndOrphans(t *testing.T, repo repository.CarProvider, images repository.ImageProvider) {
+	ctx := context.Background()
+	car := insert(t, repo, "BMW", "Xfive", 2020)
+	live := insert(t, repo, "Kia", "Rio", 2021)
+	a := addImage(t, images, car.ID)
+	b := addImage(t, images, car.ID)
+	x := addImage(t, images, live.ID)
+
+	// в корзине фотографии сохраняются для восстановления, но галерея не меняется
+	require.NoError(t, repo.DeleteByID(ctx, car.ID))
+	extra := models.CarImage{ID: uuid.NewString(), CarID: car.ID, ContentType: "image/png", Size: 1, Width: 1, Height: 1}
+	assert.True(t, errors.Is(images.AddImage(ctx, &extra, 10), apperr.ErrNotFound))
+	assert.True(t, errors.Is(images.DeleteImage(ctx, car.ID, a.ID), apperr.ErrNotFound))
+	list, err := images.ListImages(ctx, car.ID)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	orphans, err := images.OrphanedImages(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, orphans)
+
+	// после окончательного удаления машины её фотографии — сироты
+	_, err = repo.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	orphans, err = images.OrphanedImages(ctx, 10)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{a.ID, b.ID}, imageIDs(orphans))
+	orphans, err = images.OrphanedImages(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, orphans, 1)
+
+	// фотографии живых машин так не удаляются
+	n, err := images.DeleteOrphanedImages(ctx, []string{a.ID, b.ID, x.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	orphans, err = images.OrphanedImages(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, orphans)
+	list, err = images.ListImages(ctx, live.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{x.ID}, imageIDs(list))
+}
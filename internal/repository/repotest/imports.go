@@ -0,0 +1,269 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// runImportSuite проверяет импорт, если реализация поддерживает repository.ImportProvider.
+func runImportSuite(t *testing.T, factory Factory) {
+	importRepo := func(t *testing.T) (repository.CarProvider, repository.ImportProvider) {
+		repo := factory(t)
+		imports, ok := repo.(repository.ImportProvider)
+		if !ok {
+			t.Skip("repository does not implement ImportProvider")
+		}
+		return repo, imports
+	}
+
+	t.Run("ImportCreatesAcceptedRows", func(t *testing.T) {
+		repo, imports := importRepo(t)
+		testImportCreatesAcceptedRows(t, repo, imports)
+	})
+	t.Run("ImportDryRun", func(t *testing.T) {
+		repo, imports := importRepo(t)
+		testImportDryRun(t, repo, imports)
+	})
+	t.Run("ImportSourceErrorRollsBack", func(t *testing.T) {
+		repo, imports := importRepo(t)
+		testImportSourceErrorRollsBack(t, repo, imports)
+	})
+	t.Run("ImportPresetID", func(t *testing.T) {
+		repo, imports := importRepo(t)
+		testImportPresetID(t, repo, imports)
+	})
+	t.Run("ImportVINAndAttributes", func(t *testing.T) {
+		repo, imports := importRepo(t)
+		testImportVINAndAttributes(t, repo, imports)
+	})
+	t.Run("ImportedCars", func(t *testing.T) {
+		repo, imports := importRepo(t)
+		testImportedCars(t, repo, imports)
+	})
+	t.Run("ImportRowsPaged", func(t *testing.T) {
+		_, imports := importRepo(t)
+		testImportRowsPaged(t, imports)
+	})
+}
+
+// rowSource отдаёт строки по одной, как парсер загружаемого файла.
+func rowSource(rows ...models.ImportRow) func() (models.ImportRow, error) {
+	return func() (models.ImportRow, error) {
+		if len(rows) == 0 {
+			return models.ImportRow{}, io.EOF
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row, nil
+	}
+}
+
+func sampleImportRows() []models.ImportRow {
+	return []models.ImportRow{
+		{Line: 2, Brand: "BMW", Model: "Xfive", Year: 2020},
+		{Line: 3, Brand: "", Model: "Camry", Year: 2019, Reason: "brand: required"},
+		{Line: 4, Brand: "Toyota", Model: "Corolla", Year: 2018},
+	}
+}
+
+func testImportCreatesAcceptedRows(t *testing.T, repo repository.CarProvider, imports repository.ImportProvider) {
+	ctx := context.Background()
+	imp := models.CarImport{Format: models.ImportFormatCSV}
+	require.NoError(t, imports.ImportCars(ctx, &imp, rowSource(sampleImportRows()...)))
+	require.NotEmpty(t, imp.ID)
+	assert.False(t, imp.CreatedAt.IsZero())
+	assert.Equal(t, 3, imp.Total)
+	assert.Equal(t, 2, imp.Accepted)
+	assert.Equal(t, 1, imp.Rejected)
+
+	got, err := imports.GetImport(ctx, imp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, imp.Total, got.Total)
+	assert.Equal(t, imp.Accepted, got.Accepted)
+	assert.Equal(t, models.ImportFormatCSV, got.Format)
+	assert.False(t, got.DryRun)
+
+	rows, err := imports.ListImportRows(ctx, imp.ID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []int{2, 3, 4}, []int{rows[0].Line, rows[1].Line, rows[2].Line})
+	assert.Equal(t, models.ImportRowRejected, rows[1].Status)
+	assert.Equal(t, "brand: required", rows[1].Reason)
+	assert.Empty(t, rows[1].CarID)
+
+	for _, i := range []int{0, 2} {
+		assert.Equal(t, models.ImportRowAccepted, rows[i].Status)
+		car, err := repo.GetCarByID(ctx, rows[i].CarID)
+		require.NoError(t, err)
+		assert.Equal(t, rows[i].Brand, car.Brand)
+		assert.Equal(t, rows[i].Model, car.Model)
+		assert.Equal(t, 1, car.Version)
+	}
+
+	if audit, ok := repo.(repository.AuditProvider); ok {
+		entries, total, err := audit.ListAudit(ctx, models.AuditFilter{CarID: rows[0].CarID})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, models.AuditActionCreate, entries[0].Action)
+	}
+}
+
+func testImportDryRun(t *testing.T, repo repository.CarProvider, imports repository.ImportProvider) {
+	ctx := context.Background()
+	imp := models.CarImport{Format: models.ImportFormatNDJSON, DryRun: true}
+	require.NoError(t, imports.ImportCars(ctx, &imp, rowSource(sampleImportRows()...)))
+	assert.Equal(t, 2, imp.Accepted)
+
+	cars, err := repo.ListCars(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, cars)
+
+	got, err := imports.GetImport(ctx, imp.ID)
+	require.NoError(t, err)
+	assert.True(t, got.DryRun)
+	rows, err := imports.ListImportRows(ctx, imp.ID, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, rows, 3)
+}
+
+// testImportPresetID: фоновая задача передаёт свой ID, и повторный запуск не создаёт машины дважды.
+func testImportPresetID(t *testing.T, repo repository.CarProvider, imports repository.ImportProvider) {
+	ctx := context.Background()
+	id := uuid.NewString()
+	imp := models.CarImport{ID: id, Format: models.ImportFormatCSV}
+	require.NoError(t, imports.ImportCars(ctx, &imp, rowSource(sampleImportRows()...)))
+	assert.Equal(t, id, imp.ID)
+
+	again := models.CarImport{ID: id, Format: models.ImportFormatCSV}
+	err := imports.ImportCars(ctx, &again, rowSource(sampleImportRows()...))
+	assert.ErrorIs(t, err, apperr.ErrConflict)
+
+	cars, err := repo.ListCars(ctx)
+	require.NoError(t, err)
+	assert.Len(t, cars, 2)
+}
+
+func testImportSourceErrorRollsBack(t *testing.T, repo repository.CarProvider, imports repository.ImportProvider) {
+	ctx := context.Background()
+	boom := errors.New("connection reset")
+	src := rowSource(sampleImportRows()[:1]...)
+	failing := func() (models.ImportRow, error) {
+		row, err := src()
+		if errors.Is(err, io.EOF) {
+			return models.ImportRow{}, boom
+		}
+		return row, err
+	}
+	imp := models.CarImport{Format: models.ImportFormatCSV}
+	require.ErrorIs(t, imports.ImportCars(ctx, &imp, failing), boom)
+
+	cars, err := repo.ListCars(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, cars)
+	_, err = imports.GetImport(ctx, imp.ID)
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+}
+
+func testImportRowsPaged(t *testing.T, imports repository.ImportProvider) {
+	ctx := context.Background()
+	imp := models.CarImport{Format: models.ImportFormatCSV, DryRun: true}
+	require.NoError(t, imports.ImportCars(ctx, &imp, rowSource(sampleImportRows()...)))
+
+	page, err := imports.ListImportRows(ctx, imp.ID, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	page, err = imports.ListImportRows(ctx, imp.ID, page[1].Line, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, 4, page[0].Line)
+
+	_, err = imports.GetImport(ctx, "7b1f4a7e-0000-4000-8000-000000000099")
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+}
+
+// testImportVINAndAttributes: строка переносит VIN и атрибуты в машину, а строка с VIN,
+// который занят машиной или строкой выше, отклоняется.
+func testImportVINAndAttributes(t *testing.T, repo repository.CarProvider, imports repository.ImportProvider) {
+	ctx := context.Background()
+	require.NoError(t, repo.InsertCar(ctx, &models.Car{Brand: "Porsche", Model: "Carrera", Year: 1996, VIN: testVIN}))
+
+	mileage := 42000
+	attrs := models.CarAttributes{Mileage: &mileage, Color: "red", Price: money("19999.99"), Currency: "RUB",
+		Status: models.CarStatusSold}
+	src := []models.ImportRow{
+		{Line: 2, Brand: "BMW", Model: "Xfive", Year: 2020, VIN: testVIN},
+		{Line: 3, Brand: "Honda", Model: "Accord", Year: 2003, VIN: otherTestVIN, Attributes: attrs},
+		{Line: 4, Brand: "Honda", Model: "Civic", Year: 2004, VIN: otherTestVIN},
+		{Line: 5, Brand: "Kia", Model: "Rio", Year: 2021},
+	}
+
+	dry := models.CarImport{Format: models.ImportFormatCSV, DryRun: true}
+	require.NoError(t, imports.ImportCars(ctx, &dry, rowSource(src...)))
+	assert.Equal(t, 2, dry.Accepted)
+	assert.Equal(t, 2, dry.Rejected)
+
+	imp := models.CarImport{Format: models.ImportFormatCSV}
+	require.NoError(t, imports.ImportCars(ctx, &imp, rowSource(src...)))
+	assert.Equal(t, 4, imp.Total)
+	assert.Equal(t, 2, imp.Accepted)
+	assert.Equal(t, 2, imp.Rejected)
+
+	rows, err := imports.ListImportRows(ctx, imp.ID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	for _, i := range []int{0, 2} {
+		assert.Equal(t, models.ImportRowRejected, rows[i].Status)
+		assert.Equal(t, "vin already exists", rows[i].Reason)
+		assert.Empty(t, rows[i].CarID)
+	}
+	assert.Equal(t, models.ImportRowAccepted, rows[3].Status)
+
+	car, err := repo.GetCarByID(ctx, rows[1].CarID)
+	require.NoError(t, err)
+	assert.Equal(t, otherTestVIN, car.VIN)
+	assert.Equal(t, attrs, car.CarAttributes)
+
+	if prices, ok := repo.(repository.PriceProvider); ok {
+		history, err := prices.ListPrices(ctx, car.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, money("19999.99"), history[0].Price)
+	}
+}
+
+// testImportedCars: машины принятых строк читаются страницами такими, какими их записало хранилище.
+func testImportedCars(t *testing.T, repo repository.CarProvider, imports repository.ImportProvider) {
+	ctx := context.Background()
+	imp := models.CarImport{Format: models.ImportFormatCSV}
+	require.NoError(t, imports.ImportCars(ctx, &imp, rowSource(sampleImportRows()...)))
+
+	page, err := imports.ListImportedCars(ctx, imp.ID, 0, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, 2, page[0].Line)
+	stored, err := repo.GetCarByID(ctx, page[0].Car.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *stored, page[0].Car)
+
+	page, err = imports.ListImportedCars(ctx, imp.ID, page[0].Line, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1, "rejected line 3 has no car")
+	assert.Equal(t, 4, page[0].Line)
+	assert.Equal(t, "Corolla", page[0].Car.Model)
+
+	dry := models.CarImport{Format: models.ImportFormatCSV, DryRun: true}
+	require.NoError(t, imports.ImportCars(ctx, &dry, rowSource(sampleImportRows()...)))
+	page, err = imports.ListImportedCars(ctx, dry.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
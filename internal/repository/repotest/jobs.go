@@ -0,0 +1,212 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// runJobSuite проверяет очередь задач, если реализация поддерживает repository.JobProvider.
+func runJobSuite(t *testing.T, factory Factory) {
+	jobRepo := func(t *testing.T) repository.JobProvider {
+		jobs, ok := factory(t).(repository.JobProvider)
+		if !ok {
+			t.Skip("repository does not implement JobProvider")
+		}
+		return jobs
+	}
+
+	t.Run("JobCreateAndGet", func(t *testing.T) {
+		testJobCreateAndGet(t, jobRepo(t))
+	})
+	t.Run("JobClaimOldestFirst", func(t *testing.T) {
+		testJobClaimOldestFirst(t, jobRepo(t))
+	})
+	t.Run("JobExpiredLeaseIsReclaimed", func(t *testing.T) {
+		testJobExpiredLeaseIsReclaimed(t, jobRepo(t))
+	})
+	t.Run("JobFinish", func(t *testing.T) {
+		testJobFinish(t, jobRepo(t))
+	})
+	t.Run("JobCancel", func(t *testing.T) {
+		testJobCancel(t, jobRepo(t))
+	})
+	t.Run("JobDeleteFinished", func(t *testing.T) {
+		testJobDeleteFinished(t, jobRepo(t))
+	})
+}
+
+func createJob(t *testing.T, jobs repository.JobProvider, kind string) models.Job {
+	t.Helper()
+	j := models.Job{Kind: kind, Params: json.RawMessage(`{"format":"csv"}`)}
+	require.NoError(t, jobs.CreateJob(context.Background(), &j))
+	return j
+}
+
+func testJobCreateAndGet(t *testing.T, jobs repository.JobProvider) {
+	ctx := context.Background()
+	j := createJob(t, jobs, models.JobKindExport)
+	_, err := uuid.Parse(j.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobQueued, j.Status)
+	assert.False(t, j.CreatedAt.IsZero())
+
+	preset := models.Job{ID: uuid.NewString(), Kind: models.JobKindImport}
+	require.NoError(t, jobs.CreateJob(ctx, &preset))
+
+	got, err := jobs.GetJob(ctx, j.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobKindExport, got.Kind)
+	assert.JSONEq(t, `{"format":"csv"}`, string(got.Params))
+	assert.Nil(t, got.Result)
+	assert.Nil(t, got.StartedAt)
+
+	got, err = jobs.GetJob(ctx, preset.ID)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(got.Params))
+
+	_, err = jobs.GetJob(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+}
+
+func testJobClaimOldestFirst(t *testing.T, jobs repository.JobProvider) {
+	ctx := context.Background()
+	none, err := jobs.ClaimJob(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	first := createJob(t, jobs, models.JobKindExport)
+	time.Sleep(5 * time.Millisecond)
+	second := createJob(t, jobs, models.JobKindImport)
+
+	claimed, err := jobs.ClaimJob(ctx, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, first.ID, claimed.ID)
+	assert.Equal(t, models.JobRunning, claimed.Status)
+	assert.Equal(t, 1, claimed.Attempts)
+	require.NotNil(t, claimed.StartedAt)
+	require.NotNil(t, claimed.LockedUntil)
+
+	claimed, err = jobs.ClaimJob(ctx, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, second.ID, claimed.ID)
+
+	none, err = jobs.ClaimJob(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, none, "running jobs with a valid lease are not claimed again")
+}
+
+func testJobExpiredLeaseIsReclaimed(t *testing.T, jobs repository.JobProvider) {
+	ctx := context.Background()
+	j := createJob(t, jobs, models.JobKindExport)
+
+	first, err := jobs.ClaimJob(ctx, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	cancel, err := jobs.ExtendJob(ctx, j.ID, first.Attempts, models.JobProgress{Processed: 10}, 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.False(t, cancel)
+
+	time.Sleep(30 * time.Millisecond)
+	second, err := jobs.ClaimJob(ctx, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, second, "job of a crashed worker must be claimable")
+	assert.Equal(t, j.ID, second.ID)
+	assert.Equal(t, 2, second.Attempts)
+	assert.Equal(t, int64(10), second.Processed)
+
+	_, err = jobs.ExtendJob(ctx, j.ID, first.Attempts, models.JobProgress{}, time.Minute)
+	assert.ErrorIs(t, err, apperr.ErrConflict, "stale worker must lose the lease")
+	err = jobs.FinishJob(ctx, j.ID, first.Attempts, models.JobResult{Status: models.JobSucceeded})
+	assert.ErrorIs(t, err, apperr.ErrConflict)
+}
+
+func testJobFinish(t *testing.T, jobs repository.JobProvider) {
+	ctx := context.Background()
+	j := createJob(t, jobs, models.JobKindExport)
+	claimed, err := jobs.ClaimJob(ctx, time.Minute)
+	require.NoError(t, err)
+
+	err = jobs.FinishJob(ctx, j.ID, claimed.Attempts, models.JobResult{
+		Status:      models.JobSucceeded,
+		JobProgress: models.JobProgress{Processed: 42},
+		Result:      json.RawMessage(`{"rows":42}`),
+	})
+	require.NoError(t, err)
+
+	got, err := jobs.GetJob(ctx, j.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobSucceeded, got.Status)
+	assert.Equal(t, int64(42), got.Processed)
+	assert.JSONEq(t, `{"rows":42}`, string(got.Result))
+	assert.Nil(t, got.LockedUntil)
+	require.NotNil(t, got.FinishedAt)
+
+	err = jobs.FinishJob(ctx, j.ID, claimed.Attempts, models.JobResult{Status: models.JobFailed})
+	assert.ErrorIs(t, err, apperr.ErrConflict, "finished job cannot be finished again")
+	_, err = jobs.ExtendJob(ctx, j.ID, claimed.Attempts, models.JobProgress{}, time.Minute)
+	assert.ErrorIs(t, err, apperr.ErrConflict)
+}
+
+func testJobCancel(t *testing.T, jobs repository.JobProvider) {
+	ctx := context.Background()
+	queued := createJob(t, jobs, models.JobKindExport)
+	canceled, err := jobs.CancelJob(ctx, queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobCanceled, canceled.Status)
+	require.NotNil(t, canceled.FinishedAt)
+
+	none, err := jobs.ClaimJob(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, none, "canceled job is not claimed")
+
+	running := createJob(t, jobs, models.JobKindExport)
+	claimed, err := jobs.ClaimJob(ctx, time.Minute)
+	require.NoError(t, err)
+	got, err := jobs.CancelJob(ctx, running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobRunning, got.Status, "running job stops on the worker's next heartbeat")
+	assert.True(t, got.CancelRequested)
+
+	cancel, err := jobs.ExtendJob(ctx, running.ID, claimed.Attempts, models.JobProgress{}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, cancel)
+	require.NoError(t, jobs.FinishJob(ctx, running.ID, claimed.Attempts, models.JobResult{Status: models.JobCanceled}))
+
+	_, err = jobs.CancelJob(ctx, running.ID)
+	assert.ErrorIs(t, err, apperr.ErrConflict)
+	_, err = jobs.CancelJob(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+}
+
+func testJobDeleteFinished(t *testing.T, jobs repository.JobProvider) {
+	ctx := context.Background()
+	done := createJob(t, jobs, models.JobKindExport)
+	_, err := jobs.CancelJob(ctx, done.ID)
+	require.NoError(t, err)
+	queued := createJob(t, jobs, models.JobKindExport)
+
+	ids, err := jobs.DeleteFinishedJobs(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, ids, "recently finished jobs must survive")
+
+	ids, err = jobs.DeleteFinishedJobs(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{done.ID}, ids)
+
+	_, err = jobs.GetJob(ctx, done.ID)
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+	_, err = jobs.GetJob(ctx, queued.ID)
+	require.NoError(t, err, "unfinished jobs are never deleted")
+}
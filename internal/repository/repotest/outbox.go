@@ -0,0 +1,148 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/reqctx"
+)
+
+// runOutboxSuite проверяет outbox, если реализация поддерживает repository.OutboxProvider.
+func runOutboxSuite(t *testing.T, factory Factory) {
+	outboxRepo := func(t *testing.T) (repository.CarProvider, repository.OutboxProvider) {
+		repo := factory(t)
+		outbox, ok := repo.(repository.OutboxProvider)
+		if !ok {
+			t.Skip("repository does not implement OutboxProvider")
+		}
+		return repo, outbox
+	}
+
+	t.Run("OutboxEventPerMutation", func(t *testing.T) {
+		repo, outbox := outboxRepo(t)
+		testOutboxEventPerMutation(t, repo, outbox)
+	})
+	t.Run("OutboxOrderedPerCar", func(t *testing.T) {
+		repo, outbox := outboxRepo(t)
+		testOutboxOrderedPerCar(t, repo, outbox)
+	})
+	t.Run("OutboxRetryDelay", func(t *testing.T) {
+		repo, outbox := outboxRepo(t)
+		testOutboxRetryDelay(t, repo, outbox)
+	})
+}
+
+// drain помечает опубликованными все доступные события и возвращает их в порядке выдачи.
+func drain(t *testing.T, outbox repository.OutboxProvider) []models.OutboxEvent {
+	t.Helper()
+	ctx := context.Background()
+	var out []models.OutboxEvent
+	for i := 0; i < 100; i++ {
+		batch, err := outbox.PendingEvents(ctx, 10)
+		require.NoError(t, err)
+		if len(batch) == 0 {
+			return out
+		}
+		for _, e := range batch {
+			require.NoError(t, outbox.MarkPublished(ctx, e.ID))
+			out = append(out, e)
+		}
+	}
+	t.Fatal("outbox did not drain")
+	return nil
+}
+
+func testOutboxEventPerMutation(t *testing.T, repo repository.CarProvider, outbox repository.OutboxProvider) {
+	ctx := reqctx.WithRequestID(reqctx.WithActor(context.Background(), "alice"), "req-7")
+
+	c := models.Car{Brand: "Toyota", Model: "Camry", Year: 2020}
+	require.NoError(t, repo.InsertCar(ctx, &c))
+	c.Year = 2021
+	require.NoError(t, repo.UpdateCar(ctx, &c))
+	stale := c
+	stale.Version = 1
+	require.Error(t, repo.UpdateCar(ctx, &stale))
+	require.NoError(t, repo.DeleteByID(ctx, c.ID))
+	_, err := repo.RestoreByID(ctx, c.ID)
+	require.NoError(t, err)
+
+	evts := drain(t, outbox)
+	types := make([]string, len(evts))
+	for i, e := range evts {
+		types[i] = e.Type
+		assert.Equal(t, c.ID, e.CarID)
+		assert.Equal(t, "alice", e.Actor)
+		assert.Equal(t, "req-7", e.RequestID)
+		assert.False(t, e.OccurredAt.IsZero())
+	}
+	assert.Equal(t, []string{
+		models.EventCarCreated,
+		models.EventCarUpdated,
+		models.EventCarDeleted,
+		models.EventCarRestored,
+	}, types, "rejected update must not emit an event")
+
+	var payload models.CarResponse
+	require.NoError(t, json.Unmarshal(evts[1].Payload, &payload))
+	assert.Equal(t, 2021, payload.Year)
+	assert.Equal(t, 2, payload.Version)
+}
+
+func testOutboxOrderedPerCar(t *testing.T, repo repository.CarProvider, outbox repository.OutboxProvider) {
+	ctx := context.Background()
+	a := insert(t, repo, "BMW", "X5", 2019)
+	b := insert(t, repo, "Audi", "A6", 2020)
+	a.Year = 2020
+	require.NoError(t, repo.UpdateCar(ctx, &a))
+
+	batch, err := outbox.PendingEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, batch, 2, "only the head event of each car is pending")
+	assert.Equal(t, a.ID, batch[0].CarID)
+	assert.Equal(t, models.EventCarCreated, batch[0].Type)
+	assert.Equal(t, b.ID, batch[1].CarID)
+
+	require.NoError(t, outbox.MarkPublished(ctx, batch[0].ID))
+	batch, err = outbox.PendingEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, batch, 2)
+	assert.Equal(t, b.ID, batch[0].CarID)
+	assert.Equal(t, a.ID, batch[1].CarID)
+	assert.Equal(t, models.EventCarUpdated, batch[1].Type)
+
+	batch, err = outbox.PendingEvents(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, batch, 1)
+}
+
+func testOutboxRetryDelay(t *testing.T, repo repository.CarProvider, outbox repository.OutboxProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Kia", "Rio", 2015)
+	c.Year = 2016
+	require.NoError(t, repo.UpdateCar(ctx, &c))
+
+	batch, err := outbox.PendingEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	head := batch[0]
+	assert.Zero(t, head.Attempts)
+
+	require.NoError(t, outbox.MarkFailed(ctx, head.ID, time.Now().Add(time.Hour), "broker down"))
+	batch, err = outbox.PendingEvents(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, batch, "delayed head blocks later events of the same car")
+
+	require.NoError(t, outbox.MarkFailed(ctx, head.ID, time.Now().Add(-time.Second), "broker down"))
+	batch, err = outbox.PendingEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, head.ID, batch[0].ID)
+	assert.Equal(t, 2, batch[0].Attempts)
+}
@@ -0,0 +1,93 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// runPriceSuite проверяет историю цен, если реализация поддерживает repository.PriceProvider.
+func runPriceSuite(t *testing.T, factory Factory) {
+	priceRepo := func(t *testing.T) (repository.CarProvider, repository.PriceProvider) {
+		repo := factory(t)
+		prices, ok := repo.(repository.PriceProvider)
+		if !ok {
+			t.Skip("repository does not implement PriceProvider")
+		}
+		return repo, prices
+	}
+
+	t.Run("PriceHistory", func(t *testing.T) {
+		repo, prices := priceRepo(t)
+		testPriceHistory(t, repo, prices)
+	})
+	t.Run("PriceHistoryBatch", func(t *testing.T) {
+		repo, prices := priceRepo(t)
+		testPriceHistoryBatch(t, repo, prices)
+	})
+}
+
+// pricesOf возвращает пары цена/валюта истории, последние первыми; nil цена — пустая строка.
+func pricesOf(t *testing.T, prices repository.PriceProvider, carID string) [][2]any {
+	t.Helper()
+	points, err := prices.ListPrices(context.Background(), carID)
+	require.NoError(t, err)
+	out := [][2]any{}
+	for _, p := range points {
+		assert.Equal(t, carID, p.CarID)
+		assert.False(t, p.ChangedAt.IsZero(), "changed_at must be set")
+		price := ""
+		if p.Price != nil {
+			price = p.Price.String()
+		}
+		out = append(out, [2]any{price, p.Currency})
+	}
+	return out
+}
+
+func testPriceHistory(t *testing.T, repo repository.CarProvider, prices repository.PriceProvider) {
+	ctx := context.Background()
+	c := models.Car{Brand: "Kia", Model: "Rio", Year: 2021,
+		CarAttributes: models.CarAttributes{Price: money("900000"), Currency: "RUB"}}
+	require.NoError(t, repo.InsertCar(ctx, &c))
+	plain := insert(t, repo, "Lada", "Vesta", 2022)
+
+	// изменение без цены не попадает в историю
+	c.Color = "red"
+	require.NoError(t, repo.UpdateCar(ctx, &c))
+	c.Price = money("849999.99")
+	require.NoError(t, repo.UpdateCar(ctx, &c))
+	c.Price, c.Currency = money("9500.5"), "USD"
+	require.NoError(t, repo.UpdateCar(ctx, &c))
+	c.Price, c.Currency = nil, ""
+	require.NoError(t, repo.UpdateCar(ctx, &c))
+	require.NoError(t, repo.DeleteByID(ctx, c.ID))
+
+	assert.Equal(t, [][2]any{{"", ""}, {"9500.50", "USD"}, {"849999.99", "RUB"}, {"900000", "RUB"}}, pricesOf(t, prices, c.ID))
+	assert.Empty(t, pricesOf(t, prices, plain.ID))
+}
+
+func testPriceHistoryBatch(t *testing.T, repo repository.CarProvider, prices repository.PriceProvider) {
+	ctx := context.Background()
+	existing := insert(t, repo, "Kia", "Rio", 2021)
+	priced := models.CarAttributes{Price: money("900000.1"), Currency: "RUB"}
+
+	res, err := repo.ApplyBatch(ctx, []models.CarMutation{
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "BMW", Model: "Xfive", Year: 2020, CarAttributes: priced}},
+		{Op: models.BatchOpUpdate, Car: models.Car{ID: existing.ID, Brand: "Kia", Model: "Rio", Year: 2021, CarAttributes: priced}},
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "Lada", Model: "Vesta", Year: 2022}},
+	}, true)
+	require.NoError(t, err)
+	for _, r := range res {
+		require.NoError(t, r.Err)
+	}
+
+	assert.Equal(t, [][2]any{{"900000.10", "RUB"}}, pricesOf(t, prices, res[0].Car.ID))
+	assert.Equal(t, [][2]any{{"900000.10", "RUB"}}, pricesOf(t, prices, existing.ID))
+	assert.Empty(t, pricesOf(t, prices, res[2].Car.ID))
+}
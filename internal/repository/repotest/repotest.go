@@ -0,0 +1,364 @@
+// Package repotest содержит общий набор тестов поведения, который должна
+// проходить любая реализация repository.CarProvider.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// Factory возвращает пустое хранилище для очередного подтеста.
+type Factory func(t *testing.T) repository.CarProvider
+
+// RunCarProviderSuite прогоняет контрактные тесты против реализации из factory.
+func RunCarProviderSuite(t *testing.T, factory Factory) {
+	t.Run("InsertAssignsIDAndCreatedAt", func(t *testing.T) {
+		testInsertAssignsIDAndCreatedAt(t, factory(t))
+	})
+	t.Run("GetReturnsInserted", func(t *testing.T) {
+		testGetReturnsInserted(t, factory(t))
+	})
+	t.Run("GetUnknownIsNotFound", func(t *testing.T) {
+		testGetUnknownIsNotFound(t, factory(t))
+	})
+	t.Run("ListEmpty", func(t *testing.T) {
+		testListEmpty(t, factory(t))
+	})
+	t.Run("ListNewestFirst", func(t *testing.T) {
+		testListNewestFirst(t, factory(t))
+	})
+	t.Run("Update", func(t *testing.T) {
+		testUpdate(t, factory(t))
+	})
+	t.Run("UpdateUnknownIsNotFound", func(t *testing.T) {
+		testUpdateUnknownIsNotFound(t, factory(t))
+	})
+	t.Run("VersionIncrementsOnUpdate", func(t *testing.T) {
+		testVersionIncrementsOnUpdate(t, factory(t))
+	})
+	t.Run("StaleVersionIsConflict", func(t *testing.T) {
+		testStaleVersionIsConflict(t, factory(t))
+	})
+	t.Run("Delete", func(t *testing.T) {
+		testDelete(t, factory(t))
+	})
+	t.Run("DeleteIsSoft", func(t *testing.T) {
+		testDeleteIsSoft(t, factory(t))
+	})
+	t.Run("Restore", func(t *testing.T) {
+		testRestore(t, factory(t))
+	})
+	t.Run("PurgeDeleted", func(t *testing.T) {
+		testPurgeDeleted(t, factory(t))
+	})
+	t.Run("YearConstraint", func(t *testing.T) {
+		testYearConstraint(t, factory(t))
+	})
+	t.Run("ReturnsCopies", func(t *testing.T) {
+		testReturnsCopies(t, factory(t))
+	})
+	t.Run("GetAsOf", func(t *testing.T) {
+		testGetAsOf(t, factory(t))
+	})
+	t.Run("ListAsOf", func(t *testing.T) {
+		testListAsOf(t, factory(t))
+	})
+	t.Run("BatchMixed", func(t *testing.T) {
+		testBatchMixed(t, factory(t))
+	})
+	t.Run("BatchAtomicRollsBack", func(t *testing.T) {
+		testBatchAtomicRollsBack(t, factory(t))
+	})
+	t.Run("BatchBestEffort", func(t *testing.T) {
+		testBatchBestEffort(t, factory(t))
+	})
+	t.Run("BatchSequential", func(t *testing.T) {
+		testBatchSequential(t, factory(t))
+	})
+	t.Run("VINLookup", func(t *testing.T) {
+		testVINLookup(t, factory(t))
+	})
+	t.Run("VINUnique", func(t *testing.T) {
+		testVINUnique(t, factory(t))
+	})
+	t.Run("BatchVINConflict", func(t *testing.T) {
+		testBatchVINConflict(t, factory(t))
+	})
+	t.Run("AttributesRoundTrip", func(t *testing.T) {
+		testAttributesRoundTrip(t, factory(t))
+	})
+	t.Run("PriceRoundTrip", func(t *testing.T) {
+		testPriceRoundTrip(t, factory(t))
+	})
+	t.Run("BatchAttributes", func(t *testing.T) {
+		testBatchAttributes(t, factory(t))
+	})
+	runAuditSuite(t, factory)
+	runOutboxSuite(t, factory)
+	runWebhookSuite(t, factory)
+	runImportSuite(t, factory)
+	runExportSuite(t, factory)
+	runJobSuite(t, factory)
+	runIdempotencySuite(t, factory)
+	runCatalogSuite(t, factory)
+	runStatsSuite(t, factory)
+	runPriceSuite(t, factory)
+	runReservationSuite(t, factory)
+	runImageSuite(t, factory)
+}
+
+func insert(t *testing.T, repo repository.CarProvider, brand, model string, year int) models.Car {
+	t.Helper()
+	c := models.Car{Brand: brand, Model: model, Year: year}
+	require.NoError(t, repo.InsertCar(context.Background(), &c))
+	return c
+}
+
+func testInsertAssignsIDAndCreatedAt(t *testing.T, repo repository.CarProvider) {
+	before := time.Now().Add(-time.Minute)
+	c := insert(t, repo, "Toyota", "Camry", 2020)
+
+	_, err := uuid.Parse(c.ID)
+	require.NoError(t, err, "id must be a UUID")
+	assert.False(t, c.CreatedAt.IsZero(), "created_at must be set")
+	assert.True(t, c.CreatedAt.After(before), "created_at must be close to now")
+}
+
+func testGetReturnsInserted(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "BMW", "X5", 2022)
+
+	got, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, c.ID, got.ID)
+	assert.Equal(t, "BMW", got.Brand)
+	assert.Equal(t, "X5", got.Model)
+	assert.Equal(t, 2022, got.Year)
+	assert.WithinDuration(t, c.CreatedAt, got.CreatedAt, time.Millisecond)
+}
+
+func testGetUnknownIsNotFound(t *testing.T, repo repository.CarProvider) {
+	_, err := repo.GetCarByID(context.Background(), uuid.NewString())
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "got %v", err)
+}
+
+func testListEmpty(t *testing.T, repo repository.CarProvider) {
+	cars, err := repo.ListCars(context.Background())
+	require.NoError(t, err)
+	assert.NotNil(t, cars, "empty list must not be nil")
+	assert.Empty(t, cars)
+}
+
+func testListNewestFirst(t *testing.T, repo repository.CarProvider) {
+	first := insert(t, repo, "Audi", "A4", 2018)
+	time.Sleep(5 * time.Millisecond)
+	second := insert(t, repo, "Audi", "A6", 2019)
+	time.Sleep(5 * time.Millisecond)
+	third := insert(t, repo, "Audi", "A8", 2020)
+
+	cars, err := repo.ListCars(context.Background())
+	require.NoError(t, err)
+	require.Len(t, cars, 3)
+	assert.Equal(t, third.ID, cars[0].ID)
+	assert.Equal(t, second.ID, cars[1].ID)
+	assert.Equal(t, first.ID, cars[2].ID)
+}
+
+func testUpdate(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Honda", "Civic", 2019)
+
+	c.Brand = "Honda"
+	c.Model = "Accord"
+	c.Year = 2021
+	require.NoError(t, repo.UpdateCar(ctx, &c))
+
+	got, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Accord", got.Model)
+	assert.Equal(t, 2021, got.Year)
+	assert.WithinDuration(t, c.CreatedAt, got.CreatedAt, time.Millisecond, "update must not touch created_at")
+}
+
+func testUpdateUnknownIsNotFound(t *testing.T, repo repository.CarProvider) {
+	c := models.Car{ID: uuid.NewString(), Brand: "Kia", Model: "Rio", Year: 2015}
+	err := repo.UpdateCar(context.Background(), &c)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "got %v", err)
+}
+
+func testVersionIncrementsOnUpdate(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Skoda", "Octavia", 2017)
+	assert.Equal(t, 1, c.Version, "new car starts at version 1")
+
+	c.Model = "Superb"
+	require.NoError(t, repo.UpdateCar(ctx, &c))
+	assert.Equal(t, 2, c.Version, "update must bump version in place")
+
+	got, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Version)
+}
+
+func testStaleVersionIsConflict(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Volvo", "XC60", 2019)
+
+	first := c
+	second := c
+
+	first.Model = "XC90"
+	require.NoError(t, repo.UpdateCar(ctx, &first))
+
+	second.Model = "XC40"
+	err := repo.UpdateCar(ctx, &second)
+	assert.True(t, errors.Is(err, apperr.ErrConflict), "got %v", err)
+
+	got, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "XC90", got.Model, "stale update must not overwrite")
+}
+
+func testDelete(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Lada", "Vesta", 2020)
+
+	require.NoError(t, repo.DeleteByID(ctx, c.ID))
+
+	_, err := repo.GetCarByID(ctx, c.ID)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "got %v", err)
+
+	err = repo.DeleteByID(ctx, c.ID)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "second delete: got %v", err)
+
+	cars, err := repo.ListCars(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, cars)
+}
+
+func testDeleteIsSoft(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	kept := insert(t, repo, "Opel", "Astra", 2016)
+	deleted := insert(t, repo, "Opel", "Corsa", 2015)
+
+	require.NoError(t, repo.DeleteByID(ctx, deleted.ID))
+
+	c := deleted
+	c.Model = "Zafira"
+	err := repo.UpdateCar(ctx, &c)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "update of deleted car: got %v", err)
+
+	cars, err := repo.ListCars(ctx)
+	require.NoError(t, err)
+	require.Len(t, cars, 1)
+	assert.Equal(t, kept.ID, cars[0].ID)
+
+	trash, err := repo.ListDeletedCars(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, deleted.ID, trash[0].ID)
+	assert.Equal(t, "Corsa", trash[0].Model)
+	require.NotNil(t, trash[0].DeletedAt, "deleted_at must be set")
+}
+
+func testRestore(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Seat", "Leon", 2019)
+	require.NoError(t, repo.DeleteByID(ctx, c.ID))
+
+	restored, err := repo.RestoreByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, c.ID, restored.ID)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Greater(t, restored.Version, c.Version, "restore must bump version")
+
+	got, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Leon", got.Model)
+
+	trash, err := repo.ListDeletedCars(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+
+	_, err = repo.RestoreByID(ctx, c.ID)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "restore of live car: got %v", err)
+
+	_, err = repo.RestoreByID(ctx, uuid.NewString())
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "restore of unknown car: got %v", err)
+}
+
+func testPurgeDeleted(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	live := insert(t, repo, "Fiat", "Panda", 2012)
+	old := insert(t, repo, "Fiat", "Punto", 2010)
+	require.NoError(t, repo.DeleteByID(ctx, old.ID))
+
+	n, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n, "recently deleted cars must survive")
+
+	n, err = repo.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	trash, err := repo.ListDeletedCars(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+
+	_, err = repo.RestoreByID(ctx, old.ID)
+	assert.True(t, errors.Is(err, apperr.ErrNotFound), "purged car cannot be restored: got %v", err)
+
+	_, err = repo.GetCarByID(ctx, live.ID)
+	require.NoError(t, err, "live cars are never purged")
+}
+
+func testYearConstraint(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+
+	tooOld := models.Car{Brand: "Benz", Model: "Patent", Year: 1885}
+	err := repo.InsertCar(ctx, &tooOld)
+	assert.True(t, errors.Is(err, apperr.ErrInvalidInput), "year 1885: got %v", err)
+
+	tooNew := models.Car{Brand: "Tesla", Model: "Future", Year: time.Now().Year() + 2}
+	err = repo.InsertCar(ctx, &tooNew)
+	assert.True(t, errors.Is(err, apperr.ErrInvalidInput), "future year: got %v", err)
+
+	c := insert(t, repo, "Ford", "Focus", 2010)
+	c.Year = 1800
+	err = repo.UpdateCar(ctx, &c)
+	assert.True(t, errors.Is(err, apperr.ErrInvalidInput), "update year 1800: got %v", err)
+
+	got, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2010, got.Year, "rejected update must not be applied")
+}
+
+func testReturnsCopies(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Mazda", "CX5", 2021)
+
+	got, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	got.Brand = "MUTATED"
+
+	list, err := repo.ListCars(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	list[0].Model = "MUTATED"
+
+	again, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Mazda", again.Brand)
+	assert.Equal(t, "CX5", again.Model)
+}
@@ -0,0 +1,186 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// runReservationSuite проверяет брони, если реализация поддерживает repository.ReservationProvider.
+func runReservationSuite(t *testing.T, factory Factory) {
+	reservationRepo := func(t *testing.T) (repository.CarProvider, repository.ReservationProvider) {
+		repo := factory(t)
+		reservations, ok := repo.(repository.ReservationProvider)
+		if !ok {
+			t.Skip("repository does not implement ReservationProvider")
+		}
+		return repo, reservations
+	}
+
+	t.Run("ReservationLifecycle", func(t *testing.T) {
+		repo, reservations := reservationRepo(t)
+		testReservationLifecycle(t, repo, reservations)
+	})
+	t.Run("ReservationSingleActive", func(t *testing.T) {
+		repo, reservations := reservationRepo(t)
+		testReservationSingleActive(t, repo, reservations)
+	})
+	t.Run("ReservationConcurrent", func(t *testing.T) {
+		repo, reservations := reservationRepo(t)
+		testReservationConcurrent(t, repo, reservations)
+	})
+	t.Run("ReservationFuncError", func(t *testing.T) {
+		repo, reservations := reservationRepo(t)
+		testReservationFuncError(t, repo, reservations)
+	})
+}
+
+// reserveFn создаёт бронь и переводит машину в reserved, если активной брони нет.
+func reserveFn(expiresAt time.Time) repository.ReservationFunc {
+	return func(car *models.Car, active *models.Reservation) (*models.Reservation, error) {
+		if active != nil {
+			return nil, apperr.ErrConflict
+		}
+		car.Status = models.CarStatusReserved
+		return &models.Reservation{CarID: car.ID, Customer: "Ivan", Status: models.ReservationActive, ExpiresAt: expiresAt}, nil
+	}
+}
+
+func testReservationLifecycle(t *testing.T, repo repository.CarProvider, reservations repository.ReservationProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Kia", "Rio", 2021)
+	expiresAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Millisecond)
+
+	res, car, err := reservations.ChangeReservation(ctx, c.ID, reserveFn(expiresAt))
+	require.NoError(t, err)
+	require.NotEmpty(t, res.ID)
+	assert.False(t, res.CreatedAt.IsZero(), "created_at must be set")
+	assert.Equal(t, models.CarStatusReserved, car.Status)
+	assert.Equal(t, c.Version+1, car.Version)
+
+	stored, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CarStatusReserved, stored.Status)
+	assert.Equal(t, car.Version, stored.Version)
+
+	got, err := reservations.GetReservation(ctx, res.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ivan", got.Customer)
+	assert.True(t, got.ExpiresAt.Equal(expiresAt), "expires_at = %v, want %v", got.ExpiresAt, expiresAt)
+
+	expired, err := reservations.ExpiredReservations(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, res.ID, expired[0].ID)
+
+	closedAt := time.Now().UTC().Truncate(time.Millisecond)
+	closed, car, err := reservations.ChangeReservation(ctx, c.ID, func(car *models.Car, active *models.Reservation) (*models.Reservation, error) {
+		require.NotNil(t, active)
+		assert.Equal(t, res.ID, active.ID)
+		car.Status = models.CarStatusAvailable
+		active.Status, active.ClosedAt = models.ReservationExpired, &closedAt
+		return active, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.ReservationExpired, closed.Status)
+	assert.Equal(t, models.CarStatusAvailable, car.Status)
+
+	expired, err = reservations.ExpiredReservations(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+	list, err := reservations.ListReservations(ctx, c.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, models.ReservationExpired, list[0].Status)
+	require.NotNil(t, list[0].ClosedAt)
+	assert.True(t, list[0].ClosedAt.Equal(closedAt))
+
+	_, err = reservations.GetReservation(ctx, "00000000-0000-4000-8000-000000000000")
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+	_, _, err = reservations.ChangeReservation(ctx, "00000000-0000-4000-8000-000000000000", reserveFn(expiresAt))
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+}
+
+func testReservationSingleActive(t *testing.T, repo repository.CarProvider, reservations repository.ReservationProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Kia", "Rio", 2021)
+	_, _, err := reservations.ChangeReservation(ctx, c.ID, reserveFn(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+
+	// хранилище не даёт завести вторую активную бронь, даже если fn её не проверяет
+	_, _, err = reservations.ChangeReservation(ctx, c.ID, func(car *models.Car, active *models.Reservation) (*models.Reservation, error) {
+		return &models.Reservation{CarID: car.ID, Customer: "Petr", Status: models.ReservationActive, ExpiresAt: time.Now().Add(time.Hour)}, nil
+	})
+	assert.ErrorIs(t, err, apperr.ErrConflict)
+
+	list, err := reservations.ListReservations(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func testReservationConcurrent(t *testing.T, repo repository.CarProvider, reservations repository.ReservationProvider) {
+	c := insert(t, repo, "Kia", "Rio", 2021)
+	const n = 8
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		ok, taken int
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := reservations.ChangeReservation(context.Background(), c.ID, reserveFn(time.Now().Add(time.Hour)))
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				ok++
+			case errors.Is(err, apperr.ErrConflict):
+				taken++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, ok)
+	assert.Equal(t, n-1, taken)
+
+	got, err := repo.GetCarByID(context.Background(), c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, c.Version+1, got.Version)
+}
+
+func testReservationFuncError(t *testing.T, repo repository.CarProvider, reservations repository.ReservationProvider) {
+	ctx := context.Background()
+	c := insert(t, repo, "Kia", "Rio", 2021)
+	boom := errors.New("boom")
+	_, _, err := reservations.ChangeReservation(ctx, c.ID, func(car *models.Car, active *models.Reservation) (*models.Reservation, error) {
+		car.Status = models.CarStatusSold
+		return nil, boom
+	})
+	assert.ErrorIs(t, err, boom)
+
+	got, err := repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CarStatusAvailable, got.Status)
+	assert.Equal(t, c.Version, got.Version)
+
+	// fn без изменений ничего не пишет
+	res, car, err := reservations.ChangeReservation(ctx, c.ID, func(*models.Car, *models.Reservation) (*models.Reservation, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Nil(t, res)
+	assert.Equal(t, c.Version, car.Version)
+}
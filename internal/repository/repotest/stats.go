@@ -0,0 +1,126 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// runStatsSuite проверяет агрегаты, если реализация поддерживает repository.StatsProvider.
+func runStatsSuite(t *testing.T, factory Factory) {
+	statsRepo := func(t *testing.T) (repository.CarProvider, repository.StatsProvider) {
+		repo := factory(t)
+		stats, ok := repo.(repository.StatsProvider)
+		if !ok {
+			t.Skip("repository does not implement StatsProvider")
+		}
+		return repo, stats
+	}
+
+	t.Run("StatsGroups", func(t *testing.T) {
+		repo, stats := statsRepo(t)
+		testStatsGroups(t, repo, stats)
+	})
+	t.Run("StatsFilterAndEmpty", func(t *testing.T) {
+		repo, stats := statsRepo(t)
+		testStatsFilterAndEmpty(t, repo, stats)
+	})
+	t.Run("StatsCreated", func(t *testing.T) {
+		repo, stats := statsRepo(t)
+		testStatsCreated(t, repo, stats)
+	})
+}
+
+// statsQuery — запрос на всё время с шагом в день.
+func statsQuery(groupBy string, f models.CarFilter) models.StatsQuery {
+	return models.StatsQuery{Filter: f, GroupBy: groupBy, Period: models.StatsPeriodDay,
+		From: time.Now().Add(-time.Hour), To: time.Now().Add(time.Hour)}
+}
+
+func carStats(t *testing.T, stats repository.StatsProvider, q models.StatsQuery) *models.CarStats {
+	t.Helper()
+	got, err := stats.CarStats(context.Background(), q)
+	require.NoError(t, err)
+	return got
+}
+
+func testStatsGroups(t *testing.T, repo repository.CarProvider, stats repository.StatsProvider) {
+	insert(t, repo, "Toyota", "Camry", 2018)
+	insert(t, repo, "Toyota", "Camry", 2021)
+	insert(t, repo, "Toyota", "Corolla", 2009)
+	insert(t, repo, "Kia", "Rio", 2021)
+	deleted := insert(t, repo, "Kia", "Ceed", 1999)
+	require.NoError(t, repo.DeleteByID(context.Background(), deleted.ID))
+
+	got := carStats(t, stats, statsQuery(models.StatsGroupBrand, models.CarFilter{}))
+	assert.Equal(t, int64(4), got.Total)
+	require.NotNil(t, got.MinYear)
+	require.NotNil(t, got.MaxYear)
+	require.NotNil(t, got.AvgYear)
+	assert.Equal(t, 2009, *got.MinYear)
+	assert.Equal(t, 2021, *got.MaxYear)
+	assert.InDelta(t, 2017.25, *got.AvgYear, 0.001)
+	assert.Equal(t, []models.StatsGroup{{Key: "Toyota", Count: 3}, {Key: "Kia", Count: 1}}, got.Groups)
+
+	got = carStats(t, stats, statsQuery(models.StatsGroupModel, models.CarFilter{}))
+	assert.Equal(t, []models.StatsGroup{
+		{Brand: "Toyota", Key: "Camry", Count: 2},
+		{Brand: "Toyota", Key: "Corolla", Count: 1},
+		{Brand: "Kia", Key: "Rio", Count: 1},
+	}, got.Groups)
+
+	got = carStats(t, stats, statsQuery(models.StatsGroupYear, models.CarFilter{}))
+	assert.Equal(t, []models.StatsGroup{
+		{Key: "2009", Count: 1}, {Key: "2018", Count: 1}, {Key: "2021", Count: 2},
+	}, got.Groups)
+
+	got = carStats(t, stats, statsQuery(models.StatsGroupDecade, models.CarFilter{}))
+	assert.Equal(t, []models.StatsGroup{{Key: "2000", Count: 1}, {Key: "2010", Count: 1}, {Key: "2020", Count: 2}}, got.Groups)
+}
+
+func testStatsFilterAndEmpty(t *testing.T, repo repository.CarProvider, stats repository.StatsProvider) {
+	got := carStats(t, stats, statsQuery(models.StatsGroupBrand, models.CarFilter{}))
+	assert.Zero(t, got.Total)
+	assert.Nil(t, got.MinYear)
+	assert.Nil(t, got.AvgYear)
+	assert.NotNil(t, got.Groups)
+	assert.NotNil(t, got.Created)
+
+	insert(t, repo, "Toyota", "Camry", 2018)
+	insert(t, repo, "Toyota", "Corolla", 2012)
+	insert(t, repo, "Kia", "Rio", 2021)
+
+	got = carStats(t, stats, statsQuery(models.StatsGroupModel, models.CarFilter{Brand: "toyota", YearFrom: 2015}))
+	assert.Equal(t, int64(1), got.Total)
+	assert.Equal(t, []models.StatsGroup{{Brand: "Toyota", Key: "Camry", Count: 1}}, got.Groups)
+	require.Len(t, got.Created, 1)
+	assert.Equal(t, int64(1), got.Created[0].Count)
+}
+
+func testStatsCreated(t *testing.T, repo repository.CarProvider, stats repository.StatsProvider) {
+	insert(t, repo, "Toyota", "Camry", 2018)
+	insert(t, repo, "Kia", "Rio", 2021)
+	now := time.Now().UTC()
+
+	for _, period := range []string{models.StatsPeriodDay, models.StatsPeriodWeek, models.StatsPeriodMonth} {
+		q := statsQuery(models.StatsGroupBrand, models.CarFilter{})
+		q.Period = period
+		got := carStats(t, stats, q)
+		require.Len(t, got.Created, 1, period)
+		assert.True(t, models.StatsBucketStart(now, period).Equal(got.Created[0].Start),
+			"%s: got %v", period, got.Created[0].Start)
+		assert.Equal(t, int64(2), got.Created[0].Count, period)
+	}
+
+	q := statsQuery(models.StatsGroupBrand, models.CarFilter{})
+	q.From, q.To = now.Add(-48*time.Hour), now.Add(-24*time.Hour)
+	got := carStats(t, stats, q)
+	assert.Empty(t, got.Created)
+	assert.Equal(t, int64(2), got.Total, "the range limits only the created series")
+}
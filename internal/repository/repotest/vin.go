@@ -0,0 +1,98 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+const (
+	testVIN      = "1M8GDM9AXKP042788"
+	otherTestVIN = "1HGCM82633A004352"
+)
+
+func testVINLookup(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	c := models.Car{Brand: "Porsche", Model: "Carrera", Year: 1996, VIN: otherTestVIN}
+	require.NoError(t, repo.InsertCar(ctx, &c))
+	insert(t, repo, "Toyota", "Camry", 2020) // без VIN
+
+	got, err := repo.GetCarByVIN(ctx, otherTestVIN)
+	require.NoError(t, err)
+	assert.Equal(t, c.ID, got.ID)
+	assert.Equal(t, otherTestVIN, got.VIN)
+
+	got, err = repo.GetCarByID(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, otherTestVIN, got.VIN)
+
+	_, err = repo.GetCarByVIN(ctx, testVIN)
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+
+	// VIN можно убрать
+	got.VIN = ""
+	require.NoError(t, repo.UpdateCar(ctx, got))
+	_, err = repo.GetCarByVIN(ctx, otherTestVIN)
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+}
+
+func testVINUnique(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	a := models.Car{Brand: "Toyota", Model: "Camry", Year: 2020, VIN: testVIN}
+	require.NoError(t, repo.InsertCar(ctx, &a))
+
+	dup := models.Car{Brand: "Honda", Model: "Civic", Year: 2019, VIN: testVIN}
+	err := repo.InsertCar(ctx, &dup)
+	assert.ErrorIs(t, err, apperr.ErrConflict)
+	assert.ErrorIs(t, err, repository.ErrVINTaken)
+
+	// машины без VIN уникальностью не ограничены
+	insert(t, repo, "Honda", "Civic", 2019)
+	insert(t, repo, "Honda", "Civic", 2019)
+
+	b := models.Car{Brand: "Honda", Model: "Civic", Year: 2019, VIN: otherTestVIN}
+	require.NoError(t, repo.InsertCar(ctx, &b))
+	b.VIN = testVIN
+	assert.ErrorIs(t, repo.UpdateCar(ctx, &b), repository.ErrVINTaken)
+
+	// удалённая машина освобождает VIN, а восстановить её можно, только пока он свободен
+	require.NoError(t, repo.DeleteByID(ctx, a.ID))
+	c := models.Car{Brand: "Kia", Model: "Rio", Year: 2021, VIN: testVIN}
+	require.NoError(t, repo.InsertCar(ctx, &c))
+	_, err = repo.RestoreByID(ctx, a.ID)
+	assert.ErrorIs(t, err, repository.ErrVINTaken)
+
+	got, err := repo.GetCarByVIN(ctx, testVIN)
+	require.NoError(t, err)
+	assert.Equal(t, c.ID, got.ID)
+}
+
+func testBatchVINConflict(t *testing.T, repo repository.CarProvider) {
+	ctx := context.Background()
+	a := models.Car{Brand: "Toyota", Model: "Camry", Year: 2020, VIN: testVIN}
+	require.NoError(t, repo.InsertCar(ctx, &a))
+
+	results, err := repo.ApplyBatch(ctx, []models.CarMutation{
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "Kia", Model: "Rio", Year: 2021, VIN: testVIN}},
+		// после удаления a её VIN свободен для следующих операций пакета
+		{Op: models.BatchOpDelete, Car: models.Car{ID: a.ID}},
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "Kia", Model: "Ceed", Year: 2021, VIN: testVIN}},
+		{Op: models.BatchOpCreate, Car: models.Car{Brand: "Kia", Model: "Soul", Year: 2021, VIN: testVIN}},
+	}, false)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.ErrorIs(t, results[0].Err, apperr.ErrConflict)
+	require.NoError(t, results[1].Err)
+	require.NoError(t, results[2].Err)
+	assert.ErrorIs(t, results[3].Err, apperr.ErrConflict)
+
+	got, err := repo.GetCarByVIN(ctx, testVIN)
+	require.NoError(t, err)
+	assert.Equal(t, "Ceed", got.Model)
+}
@@ -0,0 +1,190 @@
+package repotest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// runWebhookSuite проверяет подписки и очередь доставок, если реализация поддерживает repository.WebhookProvider.
+func runWebhookSuite(t *testing.T, factory Factory) {
+	webhookRepo := func(t *testing.T) repository.WebhookProvider {
+		hooks, ok := factory(t).(repository.WebhookProvider)
+		if !ok {
+			t.Skip("repository does not implement WebhookProvider")
+		}
+		return hooks
+	}
+
+	t.Run("WebhookCRUD", func(t *testing.T) {
+		testWebhookCRUD(t, webhookRepo(t))
+	})
+	t.Run("WebhookEnqueueMatchesSubscriptions", func(t *testing.T) {
+		testWebhookEnqueue(t, webhookRepo(t))
+	})
+	t.Run("WebhookDeliveryLifecycle", func(t *testing.T) {
+		testWebhookDeliveryLifecycle(t, webhookRepo(t))
+	})
+	t.Run("WebhookDeleteCascades", func(t *testing.T) {
+		testWebhookDeleteCascades(t, webhookRepo(t))
+	})
+}
+
+func createWebhook(t *testing.T, hooks repository.WebhookProvider, active bool, types ...string) models.WebhookSubscription {
+	t.Helper()
+	s := models.WebhookSubscription{
+		URL:        "https://example.com/hook",
+		EventTypes: types,
+		Secret:     "whsec_0123456789abcdef",
+		Active:     active,
+	}
+	require.NoError(t, hooks.CreateWebhook(context.Background(), &s))
+	return s
+}
+
+func event(id int64, typ string) models.OutboxEvent {
+	return models.OutboxEvent{ID: id, Type: typ, CarID: "7b1f4a7e-0000-4000-8000-000000000001"}
+}
+
+func testWebhookCRUD(t *testing.T, hooks repository.WebhookProvider) {
+	ctx := context.Background()
+	s := createWebhook(t, hooks, true, models.EventCarCreated)
+	require.NotEmpty(t, s.ID)
+	assert.False(t, s.CreatedAt.IsZero())
+
+	got, err := hooks.GetWebhook(ctx, s.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.EventCarCreated}, got.EventTypes)
+	assert.Equal(t, s.Secret, got.Secret)
+
+	got.URL = "https://example.com/other"
+	got.EventTypes = nil
+	got.Active = false
+	require.NoError(t, hooks.UpdateWebhook(ctx, got))
+
+	list, err := hooks.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "https://example.com/other", list[0].URL)
+	assert.Empty(t, list[0].EventTypes)
+	assert.False(t, list[0].Active)
+
+	require.NoError(t, hooks.DeleteWebhook(ctx, s.ID))
+	_, err = hooks.GetWebhook(ctx, s.ID)
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+	assert.ErrorIs(t, hooks.DeleteWebhook(ctx, s.ID), apperr.ErrNotFound)
+}
+
+func testWebhookEnqueue(t *testing.T, hooks repository.WebhookProvider) {
+	ctx := context.Background()
+	all := createWebhook(t, hooks, true)
+	onlyDeleted := createWebhook(t, hooks, true, models.EventCarDeleted)
+	createWebhook(t, hooks, false)
+
+	n, err := hooks.EnqueueDeliveries(ctx, event(1, models.EventCarCreated), []byte(`{"id":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "only the catch-all active subscription matches")
+
+	n, err = hooks.EnqueueDeliveries(ctx, event(2, models.EventCarDeleted), []byte(`{"id":2}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = hooks.EnqueueDeliveries(ctx, event(2, models.EventCarDeleted), []byte(`{"id":2}`))
+	require.NoError(t, err)
+	assert.Zero(t, n, "re-enqueue of the same event is a no-op")
+
+	items, total, err := hooks.ListDeliveries(ctx, all.ID, "", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, int64(2), items[0].EventID, "newest first")
+	assert.JSONEq(t, `{"id":2}`, string(items[0].Payload))
+
+	_, total, err = hooks.ListDeliveries(ctx, onlyDeleted.ID, "", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func testWebhookDeliveryLifecycle(t *testing.T, hooks repository.WebhookProvider) {
+	ctx := context.Background()
+	s := createWebhook(t, hooks, true)
+	_, err := hooks.EnqueueDeliveries(ctx, event(1, models.EventCarCreated), []byte(`{}`))
+	require.NoError(t, err)
+
+	pending, err := hooks.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	d := pending[0]
+	assert.Equal(t, models.DeliveryPending, d.Status)
+	assert.Equal(t, s.ID, d.SubscriptionID)
+
+	require.NoError(t, hooks.RecordDeliveryAttempt(ctx, d.ID, models.DeliveryResult{
+		Status: models.DeliveryPending, NextAttemptAt: time.Now().Add(time.Hour), ResponseStatus: 503, Error: "receiver responded 503",
+	}))
+	pending, err = hooks.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending, "retry is scheduled in the future")
+
+	// длинный текст обрезается по границе символа, а не посреди кириллической буквы
+	reason := "receiver responded 500: " + strings.Repeat("ошибка ", 200)
+	require.NoError(t, hooks.RecordDeliveryAttempt(ctx, d.ID, models.DeliveryResult{
+		Status: models.DeliveryDead, NextAttemptAt: time.Now(), ResponseStatus: 500, Error: reason,
+	}))
+	dead, total, err := hooks.ListDeliveries(ctx, s.ID, models.DeliveryDead, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, 2, dead[0].Attempts)
+	assert.Equal(t, 500, dead[0].ResponseStatus)
+	assert.True(t, utf8.ValidString(dead[0].LastError))
+	assert.LessOrEqual(t, len(dead[0].LastError), 1000)
+	assert.True(t, strings.HasPrefix(reason, dead[0].LastError))
+	assert.Greater(t, len(dead[0].LastError), 990)
+
+	redelivered, err := hooks.RedeliverDelivery(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, redelivered.Status)
+	assert.Zero(t, redelivered.Attempts)
+
+	pending, err = hooks.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	require.NoError(t, hooks.RecordDeliveryAttempt(ctx, d.ID, models.DeliveryResult{
+		Status: models.DeliverySucceeded, NextAttemptAt: time.Now(), ResponseStatus: 200,
+	}))
+	got, err := hooks.GetDelivery(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliverySucceeded, got.Status)
+	assert.NotNil(t, got.DeliveredAt)
+
+	_, err = hooks.GetDelivery(ctx, d.ID+100)
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+	_, err = hooks.RedeliverDelivery(ctx, d.ID+100)
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+}
+
+func testWebhookDeleteCascades(t *testing.T, hooks repository.WebhookProvider) {
+	ctx := context.Background()
+	s := createWebhook(t, hooks, true)
+	_, err := hooks.EnqueueDeliveries(ctx, event(1, models.EventCarCreated), []byte(`{}`))
+	require.NoError(t, err)
+	pending, err := hooks.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	deliveryID := pending[0].ID
+
+	require.NoError(t, hooks.DeleteWebhook(ctx, s.ID))
+	pending, err = hooks.PendingDeliveries(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	_, err = hooks.GetDelivery(ctx, deliveryID)
+	assert.ErrorIs(t, err, apperr.ErrNotFound)
+}
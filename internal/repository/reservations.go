@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/reqctx"
+)
+
+var _ ReservationProvider = (*CarRepo)(nil)
+
+const reservationColumns = `id, car_id, customer, note, status, actor, expires_at, created_at, closed_at`
+
+func scanReservation(row rowScanner) (models.Reservation, error) {
+	var res models.Reservation
+	err := row.Scan(&res.ID, &res.CarID, &res.Customer, &res.Note, &res.Status, &res.Actor,
+		&res.ExpiresAt, &res.CreatedAt, &res.ClosedAt)
+	return res, err
+}
+
+func (r *CarRepo) ChangeReservation(ctx context.Context, carID string, fn ReservationFunc) (*models.Reservation, *models.Car, error) {
+	const activeQuery = `
+		SELECT ` + reservationColumns + `
+		FROM car_reservations
+		WHERE car_id = $1 AND status = 'active';
+	`
+	var (
+		saved *models.Reservation
+		car   *models.Car
+	)
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		before, err := lockCar(ctx, tx, carID)
+		if err != nil {
+			return err
+		}
+		var active *models.Reservation
+		res, err := scanReservation(tx.QueryRow(ctx, activeQuery, carID))
+		switch {
+		case err == nil:
+			active = &res
+		case !errors.Is(err, pgx.ErrNoRows):
+			return err
+		}
+
+		after := cloneCar(*before)
+		next, err := fn(&after, active)
+		if err != nil {
+			return err
+		}
+		if next != nil {
+			if err := saveReservation(ctx, tx, next); err != nil {
+				return err
+			}
+		}
+		if after.Status != before.Status {
+			const query = `
+				UPDATE cars
+				SET status = $2, version = version + 1
+				WHERE id = $1
+				RETURNING version;
+			`
+			if err := tx.QueryRow(ctx, query, carID, after.Status).Scan(&after.Version); err != nil {
+				return err
+			}
+			if err := recordChange(ctx, tx, models.AuditActionUpdate, carID, before, &after); err != nil {
+				return err
+			}
+		}
+		saved, car = next, &after
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return saved, car, nil
+}
+
+// saveReservation вставляет новую бронь либо закрывает активную.
+func saveReservation(ctx context.Context, tx pgx.Tx, res *models.Reservation) error {
+	if res.ID == "" {
+		const query = `
+			INSERT INTO car_reservations (car_id, customer, note, status, actor, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at;
+		`
+		res.Actor = reqctx.Actor(ctx)
+		err := tx.QueryRow(ctx, query, res.CarID, res.Customer, res.Note, res.Status, res.Actor, res.ExpiresAt).
+			Scan(&res.ID, &res.CreatedAt)
+		return mapPgErr(err)
+	}
+	const query = `
+		UPDATE car_reservations
+		SET status = $2, closed_at = $3
+		WHERE id = $1 AND status = 'active';
+	`
+	ct, err := tx.Exec(ctx, query, res.ID, res.Status, res.ClosedAt)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return apperr.ErrConflict
+	}
+	return nil
+}
+
+func (r *CarRepo) GetReservation(ctx context.Context, id string) (*models.Reservation, error) {
+	const query = `
+		SELECT ` + reservationColumns + `
+		FROM car_reservations
+		WHERE id = $1;
+	`
+	res, err := scanReservation(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *CarRepo) queryReservations(ctx context.Context, query string, args ...any) ([]models.Reservation, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.Reservation{}
+	for rows.Next() {
+		res, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res)
+	}
+	return out, rows.Err()
+}
+
+func (r *CarRepo) ListReservations(ctx context.Context, carID string) ([]models.Reservation, error) {
+	const query = `
+		SELECT ` + reservationColumns + `
+		FROM car_reservations
+		WHERE car_id = $1
+		ORDER BY created_at DESC, id;
+	`
+	return r.queryReservations(ctx, query, carID)
+}
+
+func (r *CarRepo) ExpiredReservations(ctx context.Context, now time.Time, limit int) ([]models.Reservation, error) {
+	const query = `
+		SELECT ` + reservationColumns + `
+		FROM car_reservations
+		WHERE status = 'active' AND expires_at < $1
+		ORDER BY expires_at
+		LIMIT $2;
+	`
+	return r.queryReservations(ctx, query, now, limit)
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/reqctx"
+)
+
+var _ ReservationProvider = (*MemoryCarRepo)(nil)
+
+func cloneReservation(res models.Reservation) models.Reservation {
+	if res.ClosedAt != nil {
+		t := *res.ClosedAt
+		res.ClosedAt = &t
+	}
+	return res
+}
+
+// activeReservationLocked возвращает индекс активной брони машины или -1; вызывается под r.mu.
+func (r *MemoryCarRepo) activeReservationLocked(carID string) int {
+	return slices.IndexFunc(r.reservations, func(res models.Reservation) bool {
+		return res.CarID == carID && res.Status == models.ReservationActive
+	})
+}
+
+func (r *MemoryCarRepo) ChangeReservation(ctx context.Context, carID string, fn ReservationFunc) (*models.Reservation, *models.Car, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, ok := r.cars[carID]
+	if !ok {
+		return nil, nil, apperr.ErrNotFound
+	}
+	var active *models.Reservation
+	i := r.activeReservationLocked(carID)
+	if i >= 0 {
+		res := cloneReservation(r.reservations[i])
+		active = &res
+	}
+
+	after := cloneCar(item.car)
+	next, err := fn(&after, active)
+	if err != nil {
+		return nil, nil, err
+	}
+	if next != nil {
+		switch {
+		case next.ID == "" && i >= 0:
+			return nil, nil, apperr.ErrConflict
+		case next.ID == "":
+			next.ID = uuid.NewString()
+			next.Actor = reqctx.Actor(ctx)
+			next.CreatedAt = time.Now().UTC()
+			r.reservations = append(r.reservations, cloneReservation(*next))
+		case i < 0 || r.reservations[i].ID != next.ID:
+			return nil, nil, apperr.ErrConflict
+		default:
+			r.reservations[i].Status = next.Status
+			r.reservations[i].ClosedAt = cloneReservation(*next).ClosedAt
+		}
+	}
+	if after.Status != item.car.Status {
+		before := item.car
+		item.car.Status = after.Status
+		item.car.Version++
+		if err := r.recordChange(ctx, models.AuditActionUpdate, &before, &item.car); err != nil {
+			return nil, nil, err
+		}
+		r.cars[carID] = item
+	}
+	car := cloneCar(item.car)
+	return next, &car, nil
+}
+
+func (r *MemoryCarRepo) GetReservation(ctx context.Context, id string) (*models.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i := slices.IndexFunc(r.reservations, func(res models.Reservation) bool { return res.ID == id })
+	if i < 0 {
+		return nil, apperr.ErrNotFound
+	}
+	res := cloneReservation(r.reservations[i])
+	return &res, nil
+}
+
+func (r *MemoryCarRepo) ListReservations(ctx context.Context, carID string) ([]models.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := []models.Reservation{}
+	for i := len(r.reservations) - 1; i >= 0; i-- {
+		if res := r.reservations[i]; res.CarID == carID {
+			out = append(out, cloneReservation(res))
+		}
+	}
+	return out, nil
+}
+
+func (r *MemoryCarRepo) ExpiredReservations(ctx context.Context, now time.Time, limit int) ([]models.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := []models.Reservation{}
+	for _, res := range r.reservations {
+		if res.Status == models.ReservationActive && res.ExpiresAt.Before(now) {
+			out = append(out, cloneReservation(res))
+		}
+	}
+	slices.SortFunc(out, func(a, b models.Reservation) int { return a.ExpiresAt.Compare(b.ExpiresAt) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
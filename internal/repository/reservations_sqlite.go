@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/reqctx"
+)
+
+var _ ReservationProvider = (*SQLiteCarRepo)(nil)
+
+func scanSQLiteReservation(row rowScanner) (models.Reservation, error) {
+	var (
+		res                  models.Reservation
+		expiresAt, createdAt string
+		closedAt             sql.NullString
+	)
+	err := row.Scan(&res.ID, &res.CarID, &res.Customer, &res.Note, &res.Status, &res.Actor,
+		&expiresAt, &createdAt, &closedAt)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+	if res.ExpiresAt, err = time.Parse(sqliteTimeLayout, expiresAt); err != nil {
+		return models.Reservation{}, fmt.Errorf("parse reservation expires_at %q: %w", expiresAt, err)
+	}
+	if res.CreatedAt, err = time.Parse(sqliteTimeLayout, createdAt); err != nil {
+		return models.Reservation{}, fmt.Errorf("parse reservation created_at %q: %w", createdAt, err)
+	}
+	if closedAt.Valid {
+		t, err := time.Parse(sqliteTimeLayout, closedAt.String)
+		if err != nil {
+			return models.Reservation{}, fmt.Errorf("parse reservation closed_at %q: %w", closedAt.String, err)
+		}
+		res.ClosedAt = &t
+	}
+	return res, nil
+}
+
+func (r *SQLiteCarRepo) ChangeReservation(ctx context.Context, carID string, fn ReservationFunc) (*models.Reservation, *models.Car, error) {
+	const activeQuery = `
+		SELECT ` + reservationColumns + `
+		FROM car_reservations
+		WHERE car_id = ? AND status = 'active';
+	`
+	var (
+		saved *models.Reservation
+		car   *models.Car
+	)
+	// единственное соединение SQLite выполняет транзакции по очереди, отдельная блокировка строки не нужна
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := sqliteGetCar(ctx, tx, carID)
+		if err != nil {
+			return err
+		}
+		var active *models.Reservation
+		res, err := scanSQLiteReservation(tx.QueryRowContext(ctx, activeQuery, carID))
+		switch {
+		case err == nil:
+			active = &res
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		after := cloneCar(*before)
+		next, err := fn(&after, active)
+		if err != nil {
+			return err
+		}
+		if next != nil {
+			if err := sqliteSaveReservation(ctx, tx, next); err != nil {
+				return err
+			}
+		}
+		if after.Status != before.Status {
+			const query = `
+				UPDATE cars
+				SET status = ?, version = version + 1
+				WHERE id = ?
+				RETURNING version;
+			`
+			if err := tx.QueryRowContext(ctx, query, after.Status, carID).Scan(&after.Version); err != nil {
+				return err
+			}
+			if err := sqliteRecordChange(ctx, tx, models.AuditActionUpdate, carID, before, &after); err != nil {
+				return err
+			}
+		}
+		saved, car = next, &after
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return saved, car, nil
+}
+
+func sqliteSaveReservation(ctx context.Context, tx *sql.Tx, res *models.Reservation) error {
+	if res.ID == "" {
+		const query = `
+			INSERT INTO car_reservations (id, car_id, customer, note, status, actor, expires_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+		`
+		res.ID = uuid.NewString()
+		res.Actor = reqctx.Actor(ctx)
+		res.CreatedAt = sqliteNow()
+		_, err := tx.ExecContext(ctx, query, res.ID, res.CarID, res.Customer, res.Note, res.Status, res.Actor,
+			formatSQLiteTime(res.ExpiresAt), formatSQLiteTime(res.CreatedAt))
+		return mapSQLiteErr(err)
+	}
+	const query = `
+		UPDATE car_reservations
+		SET status = ?, closed_at = ?
+		WHERE id = ? AND status = 'active';
+	`
+	var closedAt sql.NullString
+	if res.ClosedAt != nil {
+		closedAt = sql.NullString{String: formatSQLiteTime(*res.ClosedAt), Valid: true}
+	}
+	out, err := tx.ExecContext(ctx, query, res.Status, closedAt, res.ID)
+	if err != nil {
+		return err
+	}
+	n, err := out.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperr.ErrConflict
+	}
+	return nil
+}
+
+func (r *SQLiteCarRepo) GetReservation(ctx context.Context, id string) (*models.Reservation, error) {
+	const query = `
+		SELECT ` + reservationColumns + `
+		FROM car_reservations
+		WHERE id = ?;
+	`
+	res, err := scanSQLiteReservation(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *SQLiteCarRepo) querySQLiteReservations(ctx context.Context, query string, args ...any) ([]models.Reservation, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.Reservation{}
+	for rows.Next() {
+		res, err := scanSQLiteReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteCarRepo) ListReservations(ctx context.Context, carID string) ([]models.Reservation, error) {
+	const query = `
+		SELECT ` + reservationColumns + `
+		FROM car_reservations
+		WHERE car_id = ?
+		ORDER BY created_at DESC, id;
+	`
+	return r.querySQLiteReservations(ctx, query, carID)
+}
+
+func (r *SQLiteCarRepo) ExpiredReservations(ctx context.Context, now time.Time, limit int) ([]models.Reservation, error) {
+	const query = `
+		SELECT ` + reservationColumns + `
+		FROM car_reservations
+		WHERE status = 'active' AND expires_at < ?
+		ORDER BY expires_at
+		LIMIT ?;
+	`
+	return r.querySQLiteReservations(ctx, query, formatSQLiteTime(now), limit)
+}
@@ -0,0 +1,669 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// sqliteTimeLayout — фиксированная ширина, чтобы ORDER BY по строке совпадал с порядком по времени.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000Z"
+
+// sqliteCarColumns — порядок колонок, который ожидает scanSQLiteCar.
+const sqliteCarColumns = `id, brand, model, year, version, created_at, deleted_at, vin, ` + carAttrColumns
+
+// sqliteAttrAssignments — SET для атрибутов в порядке attrArgs.
+const sqliteAttrAssignments = `mileage = ?, color = ?, body_type = ?, fuel_type = ?, transmission = ?,
+	engine_volume = ?, engine_power = ?, price = ?, currency = ?, condition = ?, status = ?`
+
+// SQLiteCarRepo — реализация CarProvider поверх SQLite (pure-Go драйвер modernc.org/sqlite).
+// UUID и created_at генерируются в приложении, верхняя граница года проверяется через checkYear.
+type SQLiteCarRepo struct {
+	db *sql.DB
+}
+
+var (
+	_ CarProvider    = (*SQLiteCarRepo)(nil)
+	_ AuditProvider  = (*SQLiteCarRepo)(nil)
+	_ OutboxProvider = (*SQLiteCarRepo)(nil)
+)
+
+func NewSQLiteCarRepo(db *sql.DB) *SQLiteCarRepo {
+	return &SQLiteCarRepo{db: db}
+}
+
+func mapSQLiteErr(err error) error {
+	var sErr *sqlite.Error
+	if errors.As(err, &sErr) {
+		switch sErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_CHECK:
+			return fmt.Errorf("%w: %s", apperr.ErrInvalidInput, sErr.Error())
+		case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+			// SQLite не сообщает имя индекса, только колонки: "UNIQUE constraint failed: cars.vin"
+			if strings.Contains(sErr.Error(), "cars.vin") {
+				return ErrVINTaken
+			}
+			return fmt.Errorf("%w: %s", apperr.ErrConflict, sErr.Error())
+		}
+	}
+	return err
+}
+
+func sqliteNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSQLiteCar(row rowScanner) (models.Car, error) {
+	var (
+		c         models.Car
+		createdAt string
+		deletedAt sql.NullString
+		vin       sql.NullString
+		attrs     attrScan
+	)
+	dest := append([]any{&c.ID, &c.Brand, &c.Model, &c.Year, &c.Version, &createdAt, &deletedAt, &vin},
+		attrs.dest(&c.CarAttributes)...)
+	if err := row.Scan(dest...); err != nil {
+		return models.Car{}, err
+	}
+	c.VIN = vin.String
+	attrs.apply(&c.CarAttributes)
+	t, err := time.Parse(sqliteTimeLayout, createdAt)
+	if err != nil {
+		return models.Car{}, fmt.Errorf("parse created_at %q: %w", createdAt, err)
+	}
+	c.CreatedAt = t
+	if deletedAt.Valid {
+		t, err := time.Parse(sqliteTimeLayout, deletedAt.String)
+		if err != nil {
+			return models.Car{}, fmt.Errorf("parse deleted_at %q: %w", deletedAt.String, err)
+		}
+		c.DeletedAt = &t
+	}
+	return c, nil
+}
+
+func (r *SQLiteCarRepo) queryCars(ctx context.Context, query string, args ...any) ([]models.Car, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cars := []models.Car{}
+	for rows.Next() {
+		c, err := scanSQLiteCar(rows)
+		if err != nil {
+			return nil, err
+		}
+		cars = append(cars, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return cars, nil
+}
+
+func (r *SQLiteCarRepo) ListCars(ctx context.Context) ([]models.Car, error) {
+	const query = `
+		SELECT ` + sqliteCarColumns + `
+		FROM cars
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC, rowid DESC;
+	`
+	return r.queryCars(ctx, query)
+}
+
+func (r *SQLiteCarRepo) GetCarByID(ctx context.Context, id string) (*models.Car, error) {
+	const query = `
+		SELECT ` + sqliteCarColumns + `
+		FROM cars
+		WHERE id = ? AND deleted_at IS NULL;
+	`
+	c, err := scanSQLiteCar(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *SQLiteCarRepo) GetCarByVIN(ctx context.Context, vin string) (*models.Car, error) {
+	const query = `
+		SELECT ` + sqliteCarColumns + `
+		FROM cars
+		WHERE vin = ? AND deleted_at IS NULL;
+	`
+	c, err := scanSQLiteCar(r.db.QueryRowContext(ctx, query, vin))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// inTx выполняет fn в транзакции. Соединение у SQLite одно, поэтому внутри fn
+// можно обращаться только к tx, но не к r.db.
+func (r *SQLiteCarRepo) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func sqliteGetCar(ctx context.Context, tx *sql.Tx, id string) (*models.Car, error) {
+	const query = `
+		SELECT ` + sqliteCarColumns + `
+		FROM cars
+		WHERE id = ?;
+	`
+	c, err := scanSQLiteCar(tx.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func sqliteRecordChange(ctx context.Context, tx *sql.Tx, action, carID string, before, after *models.Car) error {
+	if err := sqliteWriteAudit(ctx, tx, action, carID, before, after); err != nil {
+		return err
+	}
+	if err := sqliteWritePrice(ctx, tx, before, after); err != nil {
+		return err
+	}
+	evt, err := newOutboxEvent(ctx, action, after)
+	if err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO car_outbox (event_type, car_id, actor, request_id, payload, created_at, next_attempt_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?);
+	`
+	now := formatSQLiteTime(sqliteNow())
+	_, err = tx.ExecContext(ctx, query, evt.Type, evt.CarID, evt.Actor, evt.RequestID, string(evt.Payload), now, now)
+	return err
+}
+
+func sqliteWriteAudit(ctx context.Context, tx *sql.Tx, action, carID string, before, after *models.Car) error {
+	entry, err := newAuditEntry(ctx, action, carID, before, after)
+	if err != nil {
+		return err
+	}
+	diff, err := json.Marshal(entry.Diff)
+	if err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO car_audit (car_id, action, actor, request_id, before, after, diff, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+	`
+	_, err = tx.ExecContext(ctx, query, entry.CarID, entry.Action, entry.Actor, entry.RequestID,
+		nullJSON(entry.Before), nullJSON(entry.After), string(diff), formatSQLiteTime(sqliteNow()))
+	return err
+}
+
+func nullJSON(raw json.RawMessage) sql.NullString {
+	return sql.NullString{String: string(raw), Valid: raw != nil}
+}
+
+func (r *SQLiteCarRepo) InsertCar(ctx context.Context, newCar *models.Car) error {
+	if err := checkYear(newCar.Year); err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO cars (id, brand, model, year, vin, created_at, ` + carAttrColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`
+	car := *newCar
+	car.Status = defaultStatus(car.Status)
+	car.ID = uuid.NewString()
+	car.Version = 1
+	car.CreatedAt = sqliteNow()
+	car.DeletedAt = nil
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		args := append([]any{car.ID, car.Brand, car.Model, car.Year, nullVIN(car.VIN), formatSQLiteTime(car.CreatedAt)},
+			attrArgs(car.CarAttributes)...)
+		_, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return mapSQLiteErr(err)
+		}
+		return sqliteRecordChange(ctx, tx, models.AuditActionCreate, car.ID, nil, &car)
+	})
+	if err != nil {
+		return err
+	}
+	*newCar = car
+	return nil
+}
+
+func (r *SQLiteCarRepo) UpdateCar(ctx context.Context, c *models.Car) error {
+	if err := checkYear(c.Year); err != nil {
+		return err
+	}
+	const query = `
+		UPDATE cars
+		SET brand = ?, model = ?, year = ?, vin = ?, ` + sqliteAttrAssignments + `, version = version + 1
+		WHERE id = ?
+		RETURNING ` + sqliteCarColumns + `;
+	`
+	var updated models.Car
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := sqliteGetCar(ctx, tx, c.ID)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt != nil {
+			return apperr.ErrNotFound
+		}
+		if before.Version != c.Version {
+			return fmt.Errorf("%w: version mismatch", apperr.ErrConflict)
+		}
+		args := append(append([]any{c.Brand, c.Model, c.Year, nullVIN(c.VIN)}, attrArgs(c.CarAttributes)...), c.ID)
+		updated, err = scanSQLiteCar(tx.QueryRowContext(ctx, query, args...))
+		if err != nil {
+			return mapSQLiteErr(err)
+		}
+		return sqliteRecordChange(ctx, tx, models.AuditActionUpdate, c.ID, before, &updated)
+	})
+	if err != nil {
+		return err
+	}
+	c.Version = updated.Version
+	slog.Debug("car updated", "id", c.ID, "version", updated.Version)
+	return nil
+}
+
+func (r *SQLiteCarRepo) DeleteByID(ctx context.Context, id string) error {
+	const query = `
+		UPDATE cars
+		SET deleted_at = ?, version = version + 1
+		WHERE id = ?
+		RETURNING ` + sqliteCarColumns + `;
+	`
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := sqliteGetCar(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt != nil {
+			return apperr.ErrNotFound
+		}
+		after, err := scanSQLiteCar(tx.QueryRowContext(ctx, query, formatSQLiteTime(sqliteNow()), id))
+		if err != nil {
+			return err
+		}
+		return sqliteRecordChange(ctx, tx, models.AuditActionDelete, id, before, &after)
+	})
+	if err != nil {
+		return err
+	}
+	slog.Debug("car deleted", "id", id)
+	return nil
+}
+
+func (r *SQLiteCarRepo) ApplyBatch(ctx context.Context, ops []models.CarMutation, atomic bool) ([]models.MutationResult, error) {
+	const (
+		insertQuery = `
+			INSERT INTO cars (id, brand, model, year, vin, version, created_at, ` + carAttrColumns + `)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+		`
+		updateQuery = `
+			UPDATE cars
+			SET brand = ?, model = ?, year = ?, vin = ?, version = ?, deleted_at = ?, ` + sqliteAttrAssignments + `
+			WHERE id = ?;
+		`
+		vinQuery = `SELECT id FROM cars WHERE vin = ? AND deleted_at IS NULL;`
+	)
+	var results []models.MutationResult
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		current := make(map[string]*models.Car)
+		for _, id := range batchCarIDs(ops) {
+			car, err := sqliteGetCar(ctx, tx, id)
+			if errors.Is(err, apperr.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			current[id] = car
+		}
+		vins := make(map[string]string)
+		for _, vin := range batchVINs(ops) {
+			var id string
+			err := tx.QueryRowContext(ctx, vinQuery, vin).Scan(&id)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			vins[vin] = id
+		}
+		plan := planBatch(ops, current, vins, sqliteNow())
+		var applied bool
+		results, applied = batchResults(plan, atomic)
+		if !applied {
+			return nil
+		}
+
+		for _, p := range plan {
+			if p.err != nil {
+				continue
+			}
+			c := p.after
+			var err error
+			if p.before == nil {
+				args := append([]any{c.ID, c.Brand, c.Model, c.Year, nullVIN(c.VIN), c.Version, formatSQLiteTime(c.CreatedAt)},
+					attrArgs(c.CarAttributes)...)
+				_, err = tx.ExecContext(ctx, insertQuery, args...)
+			} else {
+				var deletedAt any
+				if c.DeletedAt != nil {
+					deletedAt = formatSQLiteTime(*c.DeletedAt)
+				}
+				args := append(append([]any{c.Brand, c.Model, c.Year, nullVIN(c.VIN), c.Version, deletedAt},
+					attrArgs(c.CarAttributes)...), c.ID)
+				_, err = tx.ExecContext(ctx, updateQuery, args...)
+			}
+			if err != nil {
+				return mapSQLiteErr(err)
+			}
+			if err := sqliteRecordChange(ctx, tx, p.action, c.ID, p.before, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *SQLiteCarRepo) ListDeletedCars(ctx context.Context) ([]models.Car, error) {
+	const query = `
+		SELECT ` + sqliteCarColumns + `
+		FROM cars
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, rowid DESC;
+	`
+	return r.queryCars(ctx, query)
+}
+
+func (r *SQLiteCarRepo) RestoreByID(ctx context.Context, id string) (*models.Car, error) {
+	const query = `
+		UPDATE cars
+		SET deleted_at = NULL, version = version + 1
+		WHERE id = ?
+		RETURNING ` + sqliteCarColumns + `;
+	`
+	var restored models.Car
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := sqliteGetCar(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt == nil {
+			return apperr.ErrNotFound
+		}
+		restored, err = scanSQLiteCar(tx.QueryRowContext(ctx, query, id))
+		if err != nil {
+			return mapSQLiteErr(err) // VIN уже занят другой машиной
+		}
+		return sqliteRecordChange(ctx, tx, models.AuditActionRestore, id, before, &restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("car restored", "id", id)
+	return &restored, nil
+}
+
+func (r *SQLiteCarRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	const query = `
+		DELETE FROM cars
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?;
+	`
+	res, err := r.db.ExecContext(ctx, query, formatSQLiteTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *SQLiteCarRepo) ListAudit(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, int, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	if f.CarID != "" {
+		conds, args = append(conds, "car_id = ?"), append(args, f.CarID)
+	}
+	if f.Actor != "" {
+		conds, args = append(conds, "actor = ?"), append(args, f.Actor)
+	}
+	if f.Action != "" {
+		conds, args = append(conds, "action = ?"), append(args, f.Action)
+	}
+	if !f.From.IsZero() {
+		conds, args = append(conds, "created_at >= ?"), append(args, formatSQLiteTime(f.From))
+	}
+	if !f.To.IsZero() {
+		conds, args = append(conds, "created_at < ?"), append(args, formatSQLiteTime(f.To))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM car_audit `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, car_id, action, actor, request_id, before, after, diff, created_at
+		FROM car_audit
+		` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?;
+	`
+	rows, err := r.db.QueryContext(ctx, query, append(args, auditLimit(f.Limit), f.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var (
+			e                   models.AuditEntry
+			before, after       sql.NullString
+			diff, createdAtText string
+		)
+		if err := rows.Scan(&e.ID, &e.CarID, &e.Action, &e.Actor, &e.RequestID,
+			&before, &after, &diff, &createdAtText); err != nil {
+			return nil, 0, err
+		}
+		if before.Valid {
+			e.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			e.After = json.RawMessage(after.String)
+		}
+		if err := json.Unmarshal([]byte(diff), &e.Diff); err != nil {
+			return nil, 0, fmt.Errorf("parse audit diff: %w", err)
+		}
+		if e.CreatedAt, err = time.Parse(sqliteTimeLayout, createdAtText); err != nil {
+			return nil, 0, fmt.Errorf("parse audit created_at %q: %w", createdAtText, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// sqliteCarsAsOfQuery — аналог carsAsOfQuery: последняя запись аудита каждой машины не позже момента.
+const sqliteCarsAsOfQuery = `
+	WITH ranked AS (
+		SELECT car_id, after,
+			ROW_NUMBER() OVER (PARTITION BY car_id ORDER BY created_at DESC, id DESC) AS rn
+		FROM car_audit
+		WHERE created_at <= ?1 AND (?2 = '' OR car_id = ?2)
+	), born AS (
+		SELECT car_id, MIN(created_at) AS created_at
+		FROM car_audit
+		WHERE action = 'create' AND created_at <= ?1 AND (?2 = '' OR car_id = ?2)
+		GROUP BY car_id
+	)
+	SELECT r.car_id, r.after, b.created_at
+	FROM ranked r
+	JOIN born b ON b.car_id = r.car_id
+	WHERE r.rn = 1
+	ORDER BY b.created_at DESC;
+`
+
+func (r *SQLiteCarRepo) carsAsOf(ctx context.Context, at time.Time, id string) ([]models.Car, error) {
+	rows, err := r.db.QueryContext(ctx, sqliteCarsAsOfQuery, formatSQLiteTime(at), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cars := []models.Car{}
+	for rows.Next() {
+		var (
+			carID, createdAtText string
+			after                sql.NullString
+		)
+		if err := rows.Scan(&carID, &after, &createdAtText); err != nil {
+			return nil, err
+		}
+		createdAt, err := time.Parse(sqliteTimeLayout, createdAtText)
+		if err != nil {
+			return nil, fmt.Errorf("parse audit created_at %q: %w", createdAtText, err)
+		}
+		var raw []byte
+		if after.Valid {
+			raw = []byte(after.String)
+		}
+		car, ok, err := carAsOf(carID, raw, createdAt)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			cars = append(cars, car)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return cars, nil
+}
+
+func (r *SQLiteCarRepo) GetCarAsOf(ctx context.Context, id string, at time.Time) (*models.Car, error) {
+	cars, err := r.carsAsOf(ctx, at, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(cars) == 0 {
+		return nil, apperr.ErrNotFound
+	}
+	return &cars[0], nil
+}
+
+func (r *SQLiteCarRepo) ListCarsAsOf(ctx context.Context, at time.Time) ([]models.Car, error) {
+	return r.carsAsOf(ctx, at, "")
+}
+
+func (r *SQLiteCarRepo) PendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	const query = `
+		SELECT id, event_type, car_id, actor, request_id, payload, created_at, attempts
+		FROM car_outbox o
+		WHERE published_at IS NULL
+			AND next_attempt_at <= ?
+			AND id = (
+				SELECT MIN(p.id) FROM car_outbox p
+				WHERE p.car_id = o.car_id AND p.published_at IS NULL
+			)
+		ORDER BY id
+		LIMIT ?;
+	`
+	rows, err := r.db.QueryContext(ctx, query, formatSQLiteTime(sqliteNow()), outboxLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.OutboxEvent{}
+	for rows.Next() {
+		var (
+			e                      models.OutboxEvent
+			payload, createdAtText string
+		)
+		if err := rows.Scan(&e.ID, &e.Type, &e.CarID, &e.Actor, &e.RequestID,
+			&payload, &createdAtText, &e.Attempts); err != nil {
+			return nil, err
+		}
+		e.Payload = json.RawMessage(payload)
+		if e.OccurredAt, err = time.Parse(sqliteTimeLayout, createdAtText); err != nil {
+			return nil, fmt.Errorf("parse outbox created_at %q: %w", createdAtText, err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *SQLiteCarRepo) MarkPublished(ctx context.Context, id int64) error {
+	const query = `UPDATE car_outbox SET published_at = ? WHERE id = ?;`
+	_, err := r.db.ExecContext(ctx, query, formatSQLiteTime(sqliteNow()), id)
+	return err
+}
+
+func (r *SQLiteCarRepo) MarkFailed(ctx context.Context, id int64, retryAt time.Time, reason string) error {
+	const query = `
+		UPDATE car_outbox
+		SET attempts = attempts + 1, next_attempt_at = ?, last_error = ?
+		WHERE id = ?;
+	`
+	_, err := r.db.ExecContext(ctx, query, formatSQLiteTime(retryAt), truncateError(reason), id)
+	return err
+}
@@ -0,0 +1,27 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pavel97go/service-cars/database"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/repository/repotest"
+	"github.com/pavel97go/service-cars/internal/storage"
+)
+
+func TestSQLiteCarRepo_Contract(t *testing.T) {
+	repotest.RunCarProviderSuite(t, func(t *testing.T) repository.CarProvider {
+		ctx := context.Background()
+		db, err := storage.OpenSQLite(ctx, storage.SQLiteScheme+":memory:")
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		if err := database.MigrateSQLite(ctx, db); err != nil {
+			t.Fatalf("migrate sqlite: %v", err)
+		}
+		return repository.NewSQLiteCarRepo(db)
+	})
+}
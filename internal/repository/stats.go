@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ StatsProvider = (*CarRepo)(nil)
+
+// statsGroup — выражения группировки; общие для PostgreSQL и SQLite.
+type statsGroup struct {
+	brand, key, groupBy, order string
+}
+
+var statsGroups = map[string]statsGroup{
+	models.StatsGroupBrand:  {"''", "brand", "brand", "COUNT(*) DESC, key"},
+	models.StatsGroupModel:  {"brand", "model", "brand, model", "COUNT(*) DESC, key, brand"},
+	models.StatsGroupYear:   {"''", "CAST(year AS TEXT)", "year", "key"},
+	models.StatsGroupDecade: {"''", "CAST(year / 10 * 10 AS TEXT)", "year / 10 * 10", "key"},
+}
+
+func lookupStatsGroup(groupBy string) (statsGroup, error) {
+	g, ok := statsGroups[groupBy]
+	if !ok {
+		return statsGroup{}, fmt.Errorf("unknown stats group %q", groupBy)
+	}
+	return g, nil
+}
+
+// statsGroupsQuery собирает запрос групп; where — условия carFilterSQL.
+func statsGroupsQuery(g statsGroup, where string) string {
+	return `
+		SELECT ` + g.brand + ` AS brand, ` + g.key + ` AS key, COUNT(*)
+		FROM cars
+		WHERE deleted_at IS NULL` + where + `
+		GROUP BY ` + g.groupBy + `
+		ORDER BY ` + g.order + `;
+	`
+}
+
+// pgStatsPeriods — единицы date_trunc для шага ряда.
+var pgStatsPeriods = map[string]string{
+	models.StatsPeriodDay:   "day",
+	models.StatsPeriodWeek:  "week",
+	models.StatsPeriodMonth: "month",
+}
+
+func (r *CarRepo) CarStats(ctx context.Context, q models.StatsQuery) (*models.CarStats, error) {
+	g, err := lookupStatsGroup(q.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	unit, ok := pgStatsPeriods[q.Period]
+	if !ok {
+		return nil, fmt.Errorf("unknown stats period %q", q.Period)
+	}
+	where, args := carFilterSQL(q.Filter, pgPlaceholder)
+
+	stats := &models.CarStats{Groups: []models.StatsGroup{}, Created: []models.StatsBucket{}}
+	totals := `
+		SELECT COUNT(*), MIN(year), MAX(year), AVG(year)::float8
+		FROM cars
+		WHERE deleted_at IS NULL` + where + `;
+	`
+	err = r.pool.QueryRow(ctx, totals, args...).Scan(&stats.Total, &stats.MinYear, &stats.MaxYear, &stats.AvgYear)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, statsGroupsQuery(g, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var grp models.StatsGroup
+		if err := rows.Scan(&grp.Brand, &grp.Key, &grp.Count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stats.Groups = append(stats.Groups, grp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	n := len(args)
+	created := fmt.Sprintf(`
+		SELECT date_trunc('%s', created_at) AS start, COUNT(*)
+		FROM cars
+		WHERE deleted_at IS NULL%s AND created_at >= $%d AND created_at < $%d
+		GROUP BY start
+		ORDER BY start;
+	`, unit, where, n+1, n+2)
+	rows, err = r.pool.Query(ctx, created, append(args, q.From, q.To)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b models.StatsBucket
+		if err := rows.Scan(&b.Start, &b.Count); err != nil {
+			return nil, err
+		}
+		stats.Created = append(stats.Created, b)
+	}
+	return stats, rows.Err()
+}
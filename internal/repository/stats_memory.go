@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ StatsProvider = (*MemoryCarRepo)(nil)
+
+// memoryStatsKeys повторяет statsGroups: марка (только для моделей) и ключ группы.
+var memoryStatsKeys = map[string]func(c models.Car) (brand, key string){
+	models.StatsGroupBrand: func(c models.Car) (string, string) { return "", c.Brand },
+	models.StatsGroupModel: func(c models.Car) (string, string) { return c.Brand, c.Model },
+	models.StatsGroupYear:  func(c models.Car) (string, string) { return "", strconv.Itoa(c.Year) },
+	models.StatsGroupDecade: func(c models.Car) (string, string) {
+		return "", strconv.Itoa(c.Year / 10 * 10)
+	},
+}
+
+func (r *MemoryCarRepo) CarStats(ctx context.Context, q models.StatsQuery) (*models.CarStats, error) {
+	groupKey, ok := memoryStatsKeys[q.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown stats group %q", q.GroupBy)
+	}
+	switch q.Period {
+	case models.StatsPeriodDay, models.StatsPeriodWeek, models.StatsPeriodMonth:
+	default:
+		return nil, fmt.Errorf("unknown stats period %q", q.Period)
+	}
+	cars, err := r.ListCars(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.CarStats{Groups: []models.StatsGroup{}, Created: []models.StatsBucket{}}
+	groups := map[[2]string]int64{}
+	buckets := map[time.Time]int64{} // начало периода → число машин
+	yearSum := 0
+	for _, c := range cars {
+		if !q.Filter.Match(c) {
+			continue
+		}
+		stats.Total++
+		yearSum += c.Year
+		if stats.MinYear == nil || c.Year < *stats.MinYear {
+			stats.MinYear = clonePtr(&c.Year)
+		}
+		if stats.MaxYear == nil || c.Year > *stats.MaxYear {
+			stats.MaxYear = clonePtr(&c.Year)
+		}
+		brand, key := groupKey(c)
+		groups[[2]string{brand, key}]++
+		if !c.CreatedAt.Before(q.From) && c.CreatedAt.Before(q.To) {
+			buckets[models.StatsBucketStart(c.CreatedAt, q.Period)]++
+		}
+	}
+	if stats.Total > 0 {
+		avg := float64(yearSum) / float64(stats.Total)
+		stats.AvgYear = &avg
+	}
+
+	for k, n := range groups {
+		stats.Groups = append(stats.Groups, models.StatsGroup{Brand: k[0], Key: k[1], Count: n})
+	}
+	byCount := q.GroupBy == models.StatsGroupBrand || q.GroupBy == models.StatsGroupModel
+	slices.SortFunc(stats.Groups, func(a, b models.StatsGroup) int {
+		if byCount && a.Count != b.Count {
+			return cmp.Compare(b.Count, a.Count)
+		}
+		return cmp.Or(cmp.Compare(a.Key, b.Key), cmp.Compare(a.Brand, b.Brand))
+	})
+
+	for start, n := range buckets {
+		stats.Created = append(stats.Created, models.StatsBucket{Start: start, Count: n})
+	}
+	slices.SortFunc(stats.Created, func(a, b models.StatsBucket) int { return a.Start.Compare(b.Start) })
+	return stats, nil
+}
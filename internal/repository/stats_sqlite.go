@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ StatsProvider = (*SQLiteCarRepo)(nil)
+
+// sqliteStatsPeriods — начало периода датой YYYY-MM-DD из created_at (sqliteTimeLayout, UTC).
+var sqliteStatsPeriods = map[string]string{
+	models.StatsPeriodDay:   `substr(created_at, 1, 10)`,
+	models.StatsPeriodWeek:  `date(substr(created_at, 1, 10), '-6 days', 'weekday 1')`,
+	models.StatsPeriodMonth: `substr(created_at, 1, 7) || '-01'`,
+}
+
+func (r *SQLiteCarRepo) CarStats(ctx context.Context, q models.StatsQuery) (*models.CarStats, error) {
+	g, err := lookupStatsGroup(q.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	start, ok := sqliteStatsPeriods[q.Period]
+	if !ok {
+		return nil, fmt.Errorf("unknown stats period %q", q.Period)
+	}
+	where, args := carFilterSQL(q.Filter, func(int) string { return "?" })
+
+	stats := &models.CarStats{Groups: []models.StatsGroup{}, Created: []models.StatsBucket{}}
+	totals := `
+		SELECT COUNT(*), MIN(year), MAX(year), AVG(year)
+		FROM cars
+		WHERE deleted_at IS NULL` + where + `;
+	`
+	err = r.db.QueryRowContext(ctx, totals, args...).Scan(&stats.Total, &stats.MinYear, &stats.MaxYear, &stats.AvgYear)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, statsGroupsQuery(g, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var grp models.StatsGroup
+		if err := rows.Scan(&grp.Brand, &grp.Key, &grp.Count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stats.Groups = append(stats.Groups, grp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	created := `
+		SELECT ` + start + ` AS start, COUNT(*)
+		FROM cars
+		WHERE deleted_at IS NULL` + where + ` AND created_at >= ? AND created_at < ?
+		GROUP BY start
+		ORDER BY start;
+	`
+	rows, err = r.db.QueryContext(ctx, created, append(args, formatSQLiteTime(q.From), formatSQLiteTime(q.To))...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			b   models.StatsBucket
+			day string
+		)
+		if err := rows.Scan(&day, &b.Count); err != nil {
+			return nil, err
+		}
+		if b.Start, err = time.Parse(time.DateOnly, day); err != nil {
+			return nil, fmt.Errorf("parse stats period %q: %w", day, err)
+		}
+		stats.Created = append(stats.Created, b)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ WebhookProvider = (*CarRepo)(nil)
+
+const webhookColumns = `id, url, event_types, secret, active, created_at, updated_at`
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_error, response_status, created_at, delivered_at`
+
+func scanWebhook(row pgx.Row) (models.WebhookSubscription, error) {
+	var s models.WebhookSubscription
+	err := row.Scan(&s.ID, &s.URL, &s.EventTypes, &s.Secret, &s.Active, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+func scanDelivery(row pgx.Row) (models.WebhookDelivery, error) {
+	var (
+		d       models.WebhookDelivery
+		payload []byte
+	)
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastError, &d.ResponseStatus, &d.CreatedAt, &d.DeliveredAt)
+	d.Payload = payload
+	return d, err
+}
+
+func (r *CarRepo) CreateWebhook(ctx context.Context, s *models.WebhookSubscription) error {
+	const query = `
+		INSERT INTO webhook_subscriptions (url, event_types, secret, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + webhookColumns + `;
+	`
+	created, err := scanWebhook(r.pool.QueryRow(ctx, query, s.URL, nonNilTypes(s.EventTypes), s.Secret, s.Active))
+	if err != nil {
+		return err
+	}
+	*s = created
+	return nil
+}
+
+func (r *CarRepo) ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	const query = `
+		SELECT ` + webhookColumns + `
+		FROM webhook_subscriptions
+		ORDER BY created_at;
+	`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		s, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+func (r *CarRepo) GetWebhook(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	const query = `
+		SELECT ` + webhookColumns + `
+		FROM webhook_subscriptions
+		WHERE id = $1;
+	`
+	s, err := scanWebhook(r.pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *CarRepo) UpdateWebhook(ctx context.Context, s *models.WebhookSubscription) error {
+	const query = `
+		UPDATE webhook_subscriptions
+		SET url = $2, event_types = $3, secret = $4, active = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + webhookColumns + `;
+	`
+	updated, err := scanWebhook(r.pool.QueryRow(ctx, query, s.ID, s.URL, nonNilTypes(s.EventTypes), s.Secret, s.Active))
+	if err == pgx.ErrNoRows {
+		return apperr.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	*s = updated
+	return nil
+}
+
+func (r *CarRepo) DeleteWebhook(ctx context.Context, id string) error {
+	ct, err := r.pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1;`, id)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return apperr.ErrNotFound
+	}
+	return nil
+}
+
+func (r *CarRepo) EnqueueDeliveries(ctx context.Context, evt models.OutboxEvent, payload []byte) (int, error) {
+	const query = `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT id, $1, $2::text, $3
+		FROM webhook_subscriptions
+		WHERE active AND (cardinality(event_types) = 0 OR $2::text = ANY(event_types))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING;
+	`
+	ct, err := r.pool.Exec(ctx, query, evt.ID, evt.Type, payload)
+	if err != nil {
+		return 0, err
+	}
+	return int(ct.RowsAffected()), nil
+}
+
+func (r *CarRepo) PendingDeliveries(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	const query = `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= NOW()
+		ORDER BY next_attempt_at, id
+		LIMIT $1;
+	`
+	return r.queryDeliveries(ctx, query, outboxLimit(limit))
+}
+
+func (r *CarRepo) RecordDeliveryAttempt(ctx context.Context, id int64, res models.DeliveryResult) error {
+	const query = `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, status = $2, next_attempt_at = $3, response_status = $4, last_error = $5,
+			delivered_at = CASE WHEN $2 = 'succeeded' THEN NOW() ELSE delivered_at END
+		WHERE id = $1;
+	`
+	_, err := r.pool.Exec(ctx, query, id, res.Status, res.NextAttemptAt, res.ResponseStatus, truncateError(res.Error))
+	return err
+}
+
+func (r *CarRepo) ListDeliveries(ctx context.Context, subscriptionID, status string, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	conds := []string{"subscription_id = $1"}
+	args := []any{subscriptionID}
+	if status != "" {
+		args = append(args, status)
+		conds = append(conds, fmt.Sprintf("status = $%d", len(args)))
+	}
+	where := "WHERE " + strings.Join(conds, " AND ")
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM webhook_deliveries `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	args = append(args, auditLimit(limit), offset)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM webhook_deliveries
+		%s
+		ORDER BY id DESC
+		LIMIT $%d OFFSET $%d;
+	`, deliveryColumns, where, len(args)-1, len(args))
+	items, err := r.queryDeliveries(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *CarRepo) GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	const query = `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE id = $1;
+	`
+	d, err := scanDelivery(r.pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *CarRepo) RedeliverDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), last_error = ''
+		WHERE id = $1
+		RETURNING ` + deliveryColumns + `;
+	`
+	d, err := scanDelivery(r.pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *CarRepo) queryDeliveries(ctx context.Context, query string, args ...any) ([]models.WebhookDelivery, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, d)
+	}
+	return items, rows.Err()
+}
+
+// nonNilTypes не даёт записать NULL вместо пустого массива.
+func nonNilTypes(types []string) []string {
+	if types == nil {
+		return []string{}
+	}
+	return types
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ WebhookProvider = (*MemoryCarRepo)(nil)
+
+// memoryWebhooks — подписки и очередь доставок MemoryCarRepo; у них свой мьютекс,
+// так как с машинами они не изменяются атомарно.
+type memoryWebhooks struct {
+	mu         sync.RWMutex
+	subs       []models.WebhookSubscription
+	deliveries []models.WebhookDelivery
+}
+
+func cloneWebhook(s models.WebhookSubscription) models.WebhookSubscription {
+	s.EventTypes = slices.Clone(nonNilTypes(s.EventTypes))
+	return s
+}
+
+func cloneDelivery(d models.WebhookDelivery) models.WebhookDelivery {
+	if d.DeliveredAt != nil {
+		t := *d.DeliveredAt
+		d.DeliveredAt = &t
+	}
+	return d
+}
+
+func (h *memoryWebhooks) subIndex(id string) int {
+	return slices.IndexFunc(h.subs, func(s models.WebhookSubscription) bool { return s.ID == id })
+}
+
+func (h *memoryWebhooks) delivery(id int64) *models.WebhookDelivery {
+	if id < 1 || id > int64(len(h.deliveries)) || h.deliveries[id-1].ID == 0 {
+		return nil
+	}
+	return &h.deliveries[id-1]
+}
+
+func (r *MemoryCarRepo) CreateWebhook(ctx context.Context, s *models.WebhookSubscription) error {
+	h := &r.hooks
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	created := cloneWebhook(*s)
+	created.ID = uuid.NewString()
+	created.CreatedAt = time.Now().UTC()
+	created.UpdatedAt = created.CreatedAt
+	h.subs = append(h.subs, created)
+	*s = cloneWebhook(created)
+	return nil
+}
+
+func (r *MemoryCarRepo) ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	h := &r.hooks
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	subs := make([]models.WebhookSubscription, len(h.subs))
+	for i, s := range h.subs {
+		subs[i] = cloneWebhook(s)
+	}
+	return subs, nil
+}
+
+func (r *MemoryCarRepo) GetWebhook(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	h := &r.hooks
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	i := h.subIndex(id)
+	if i < 0 {
+		return nil, apperr.ErrNotFound
+	}
+	s := cloneWebhook(h.subs[i])
+	return &s, nil
+}
+
+func (r *MemoryCarRepo) UpdateWebhook(ctx context.Context, s *models.WebhookSubscription) error {
+	h := &r.hooks
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := h.subIndex(s.ID)
+	if i < 0 {
+		return apperr.ErrNotFound
+	}
+	updated := cloneWebhook(*s)
+	updated.CreatedAt = h.subs[i].CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+	h.subs[i] = updated
+	*s = cloneWebhook(updated)
+	return nil
+}
+
+func (r *MemoryCarRepo) DeleteWebhook(ctx context.Context, id string) error {
+	h := &r.hooks
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := h.subIndex(id)
+	if i < 0 {
+		return apperr.ErrNotFound
+	}
+	h.subs = slices.Delete(h.subs, i, i+1)
+	// ID доставки — индекс в срезе, поэтому удалённые записи обнуляются, а не вырезаются.
+	for j := range h.deliveries {
+		if h.deliveries[j].SubscriptionID == id {
+			h.deliveries[j] = models.WebhookDelivery{}
+		}
+	}
+	return nil
+}
+
+func (r *MemoryCarRepo) EnqueueDeliveries(ctx context.Context, evt models.OutboxEvent, payload []byte) (int, error) {
+	h := &r.hooks
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now().UTC()
+	n := 0
+	for _, s := range h.subs {
+		if !s.Active || (len(s.EventTypes) > 0 && !slices.Contains(s.EventTypes, evt.Type)) {
+			continue
+		}
+		dup := slices.ContainsFunc(h.deliveries, func(d models.WebhookDelivery) bool {
+			return d.SubscriptionID == s.ID && d.EventID == evt.ID
+		})
+		if dup {
+			continue
+		}
+		h.deliveries = append(h.deliveries, models.WebhookDelivery{
+			ID:             int64(len(h.deliveries) + 1),
+			SubscriptionID: s.ID,
+			EventID:        evt.ID,
+			EventType:      evt.Type,
+			Payload:        slices.Clone(payload),
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+		n++
+	}
+	return n, nil
+}
+
+func (r *MemoryCarRepo) PendingDeliveries(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	h := &r.hooks
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	now := time.Now().UTC()
+	var items []models.WebhookDelivery
+	for _, d := range h.deliveries {
+		if d.ID != 0 && d.Status == models.DeliveryPending && !d.NextAttemptAt.After(now) {
+			items = append(items, cloneDelivery(d))
+		}
+	}
+	slices.SortStableFunc(items, func(a, b models.WebhookDelivery) int {
+		return a.NextAttemptAt.Compare(b.NextAttemptAt)
+	})
+	if items == nil {
+		items = []models.WebhookDelivery{}
+	}
+	return items[:min(len(items), outboxLimit(limit))], nil
+}
+
+func (r *MemoryCarRepo) RecordDeliveryAttempt(ctx context.Context, id int64, res models.DeliveryResult) error {
+	h := &r.hooks
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	d := h.delivery(id)
+	if d == nil {
+		return nil
+	}
+	d.Attempts++
+	d.Status = res.Status
+	d.NextAttemptAt = res.NextAttemptAt
+	d.ResponseStatus = res.ResponseStatus
+	d.LastError = truncateError(res.Error)
+	if res.Status == models.DeliverySucceeded {
+		now := time.Now().UTC()
+		d.DeliveredAt = &now
+	}
+	return nil
+}
+
+func (r *MemoryCarRepo) ListDeliveries(ctx context.Context, subscriptionID, status string, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	h := &r.hooks
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	matched := []models.WebhookDelivery{}
+	for i := len(h.deliveries) - 1; i >= 0; i-- {
+		d := h.deliveries[i]
+		if d.ID == 0 || d.SubscriptionID != subscriptionID || (status != "" && d.Status != status) {
+			continue
+		}
+		matched = append(matched, cloneDelivery(d))
+	}
+	total := len(matched)
+	start := min(offset, total)
+	end := min(start+auditLimit(limit), total)
+	return matched[start:end], total, nil
+}
+
+func (r *MemoryCarRepo) GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	h := &r.hooks
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	d := h.delivery(id)
+	if d == nil {
+		return nil, apperr.ErrNotFound
+	}
+	c := cloneDelivery(*d)
+	return &c, nil
+}
+
+func (r *MemoryCarRepo) RedeliverDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	h := &r.hooks
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	d := h.delivery(id)
+	if d == nil {
+		return nil, apperr.ErrNotFound
+	}
+	d.Status = models.DeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = time.Now().UTC()
+	d.LastError = ""
+	c := cloneDelivery(*d)
+	return &c, nil
+}
@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+var _ WebhookProvider = (*SQLiteCarRepo)(nil)
+
+func scanSQLiteWebhook(row rowScanner) (models.WebhookSubscription, error) {
+	var (
+		s                    models.WebhookSubscription
+		types                string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&s.ID, &s.URL, &types, &s.Secret, &s.Active, &createdAt, &updatedAt); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	if err := json.Unmarshal([]byte(types), &s.EventTypes); err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("parse event_types: %w", err)
+	}
+	var err error
+	if s.CreatedAt, err = time.Parse(sqliteTimeLayout, createdAt); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	if s.UpdatedAt, err = time.Parse(sqliteTimeLayout, updatedAt); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	return s, nil
+}
+
+func scanSQLiteDelivery(row rowScanner) (models.WebhookDelivery, error) {
+	var (
+		d                          models.WebhookDelivery
+		payload, nextAt, createdAt string
+		deliveredAt                sql.NullString
+	)
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&nextAt, &d.LastError, &d.ResponseStatus, &createdAt, &deliveredAt)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	d.Payload = json.RawMessage(payload)
+	if d.NextAttemptAt, err = time.Parse(sqliteTimeLayout, nextAt); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	if d.CreatedAt, err = time.Parse(sqliteTimeLayout, createdAt); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	if deliveredAt.Valid {
+		t, err := time.Parse(sqliteTimeLayout, deliveredAt.String)
+		if err != nil {
+			return models.WebhookDelivery{}, err
+		}
+		d.DeliveredAt = &t
+	}
+	return d, nil
+}
+
+func (r *SQLiteCarRepo) CreateWebhook(ctx context.Context, s *models.WebhookSubscription) error {
+	types, err := json.Marshal(nonNilTypes(s.EventTypes))
+	if err != nil {
+		return err
+	}
+	created := *s
+	created.ID = uuid.NewString()
+	created.EventTypes = nonNilTypes(s.EventTypes)
+	created.CreatedAt = sqliteNow()
+	created.UpdatedAt = created.CreatedAt
+	const query = `
+		INSERT INTO webhook_subscriptions (id, url, event_types, secret, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?);
+	`
+	_, err = r.db.ExecContext(ctx, query, created.ID, created.URL, string(types), created.Secret, created.Active,
+		formatSQLiteTime(created.CreatedAt), formatSQLiteTime(created.UpdatedAt))
+	if err != nil {
+		return err
+	}
+	*s = created
+	return nil
+}
+
+func (r *SQLiteCarRepo) ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	const query = `
+		SELECT ` + webhookColumns + `
+		FROM webhook_subscriptions
+		ORDER BY created_at, rowid;
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		s, err := scanSQLiteWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+func (r *SQLiteCarRepo) GetWebhook(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	const query = `
+		SELECT ` + webhookColumns + `
+		FROM webhook_subscriptions
+		WHERE id = ?;
+	`
+	s, err := scanSQLiteWebhook(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SQLiteCarRepo) UpdateWebhook(ctx context.Context, s *models.WebhookSubscription) error {
+	types, err := json.Marshal(nonNilTypes(s.EventTypes))
+	if err != nil {
+		return err
+	}
+	const query = `
+		UPDATE webhook_subscriptions
+		SET url = ?, event_types = ?, secret = ?, active = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING ` + webhookColumns + `;
+	`
+	updated, err := scanSQLiteWebhook(r.db.QueryRowContext(ctx, query, s.URL, string(types), s.Secret, s.Active,
+		formatSQLiteTime(sqliteNow()), s.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperr.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	*s = updated
+	return nil
+}
+
+func (r *SQLiteCarRepo) DeleteWebhook(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = ?;`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperr.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteCarRepo) EnqueueDeliveries(ctx context.Context, evt models.OutboxEvent, payload []byte) (int, error) {
+	const query = `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, next_attempt_at, created_at)
+		SELECT id, ?1, ?2, ?3, ?4, ?4
+		FROM webhook_subscriptions
+		WHERE active AND (event_types = '[]' OR EXISTS (SELECT 1 FROM json_each(event_types) WHERE value = ?2))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING;
+	`
+	res, err := r.db.ExecContext(ctx, query, evt.ID, evt.Type, string(payload), formatSQLiteTime(sqliteNow()))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *SQLiteCarRepo) PendingDeliveries(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	const query = `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id
+		LIMIT ?;
+	`
+	return r.queryDeliveries(ctx, query, formatSQLiteTime(sqliteNow()), outboxLimit(limit))
+}
+
+func (r *SQLiteCarRepo) RecordDeliveryAttempt(ctx context.Context, id int64, res models.DeliveryResult) error {
+	const query = `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, status = ?2, next_attempt_at = ?3, response_status = ?4, last_error = ?5,
+			delivered_at = CASE WHEN ?2 = 'succeeded' THEN ?6 ELSE delivered_at END
+		WHERE id = ?1;
+	`
+	_, err := r.db.ExecContext(ctx, query, id, res.Status, formatSQLiteTime(res.NextAttemptAt),
+		res.ResponseStatus, truncateError(res.Error), formatSQLiteTime(sqliteNow()))
+	return err
+}
+
+func (r *SQLiteCarRepo) ListDeliveries(ctx context.Context, subscriptionID, status string, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	where := "WHERE subscription_id = ?"
+	args := []any{subscriptionID}
+	if status != "" {
+		where += " AND status = ?"
+		args = append(args, status)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhook_deliveries `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		` + where + `
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?;
+	`
+	items, err := r.queryDeliveries(ctx, query, append(args, auditLimit(limit), offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *SQLiteCarRepo) GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	const query = `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE id = ?;
+	`
+	d, err := scanSQLiteDelivery(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *SQLiteCarRepo) RedeliverDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = ?, last_error = ''
+		WHERE id = ?
+		RETURNING ` + deliveryColumns + `;
+	`
+	d, err := scanSQLiteDelivery(r.db.QueryRowContext(ctx, query, formatSQLiteTime(sqliteNow()), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *SQLiteCarRepo) queryDeliveries(ctx context.Context, query string, args ...any) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanSQLiteDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, d)
+	}
+	return items, rows.Err()
+}
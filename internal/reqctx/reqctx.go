@@ -0,0 +1,72 @@
+// Package reqctx переносит сведения о запросе (кто и в рамках какого запроса
+// меняет данные) через context.Context от HTTP-слоя до репозитория.
+package reqctx
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderActor     = "X-Actor"
+	HeaderRequestID = fiber.HeaderXRequestID
+
+	// maxHeaderLen ограничивает длину значений, попадающих в аудит.
+	maxHeaderLen = 100
+)
+
+type ctxKey int
+
+const (
+	actorKey ctxKey = iota
+	requestIDKey
+)
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+func Actor(ctx context.Context) string {
+	v, _ := ctx.Value(actorKey).(string)
+	return v
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+func RequestID(ctx context.Context) string {
+	v, _ := ctx.Value(requestIDKey).(string)
+	return v
+}
+
+// Middleware кладёт в UserContext автора изменений (X-Actor) и идентификатор
+// запроса (X-Request-ID, генерируется, если не передан) и возвращает его в ответе.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := truncate(c.Get(HeaderRequestID))
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		c.Set(HeaderRequestID, requestID)
+
+		ctx := WithRequestID(c.UserContext(), requestID)
+		if actor := truncate(c.Get(HeaderActor)); actor != "" {
+			ctx = WithActor(ctx, actor)
+		}
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
+
+// truncate обрезает значение и копирует его: строки из c.Get ссылаются на буфер
+// fasthttp, который переиспользуется после ответа.
+func truncate(s string) string {
+	if len(s) > maxHeaderLen {
+		s = s[:maxHeaderLen]
+	}
+	return strings.Clone(s)
+}
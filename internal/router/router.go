@@ -5,13 +5,90 @@ import (
 	"github.com/pavel97go/service-cars/internal/handler"
 )
 
-func Register(app *fiber.App, h *handler.CarHandler) {
+type Handlers struct {
+	Cars         *handler.CarHandler
+	Audit        *handler.AuditHandler
+	Webhooks     *handler.WebhookHandler
+	Stream       *handler.StreamHandler
+	Live         *handler.LiveHandler
+	Imports      *handler.ImportHandler
+	Export       *handler.ExportHandler
+	Jobs         *handler.JobHandler
+	VIN          *handler.VINHandler
+	Catalog      *handler.CatalogHandler
+	Stats        *handler.StatsHandler
+	Prices       *handler.PriceHandler
+	Reservations *handler.ReservationHandler
+	Images       *handler.ImageHandler
+	// Idempotency — middleware для Idempotency-Key на создании машины.
+	Idempotency fiber.Handler
+}
+
+func Register(app *fiber.App, h Handlers) {
+	app.Use("/ws", h.Live.Upgrade)
+	app.Get("/ws", h.Live.Serve())
+
 	api := app.Group("api/v1")
 	cars := api.Group("/cars")
 
-	cars.Post("/", h.Create)
-	cars.Get("/", h.List)
-	cars.Get("/:id", h.Get)
-	cars.Patch("/:id", h.Update)
-	cars.Delete("/:id", h.Delete)
+	cars.Post("/", h.Idempotency, h.Cars.Create)
+	cars.Get("/", h.Cars.List)
+	cars.Get("/trash", h.Cars.ListTrash)
+	cars.Get("/stats", h.Stats.Stats)
+	cars.Get("/valuation", h.Prices.Valuation)
+	cars.Get("/by-vin/:vin", h.Cars.GetByVIN)
+	cars.Get("/events", h.Stream.Events)
+	cars.Post("/batch", h.Cars.Batch)
+	cars.Get("/export", h.Export.Export)
+	cars.Post("/export", h.Export.Submit)
+	cars.Post("/import", h.Imports.Import)
+	cars.Get("/import/:id", h.Imports.Get)
+	cars.Get("/import/:id/report", h.Imports.Report)
+	cars.Get("/:id", h.Cars.Get)
+	cars.Put("/:id", h.Cars.Replace)
+	cars.Patch("/:id", h.Cars.Update)
+	cars.Delete("/:id", h.Cars.Delete)
+	cars.Post("/:id/restore", h.Cars.Restore)
+	cars.Get("/:id/history", h.Audit.CarHistory)
+	cars.Get("/:id/prices", h.Prices.History)
+	cars.Post("/:id/reservations", h.Reservations.Reserve)
+	cars.Get("/:id/reservations", h.Reservations.List)
+	cars.Post("/:id/reservations/:reservationId/release", h.Reservations.Release)
+	cars.Post("/:id/reservations/:reservationId/sell", h.Reservations.Sell)
+	cars.Post("/:id/images", h.Images.Upload)
+	cars.Get("/:id/images", h.Images.List)
+	cars.Put("/:id/images/order", h.Images.Reorder)
+	cars.Get("/:id/images/:imageId", h.Images.Get)
+	cars.Delete("/:id/images/:imageId", h.Images.Delete)
+
+	api.Get("/audit", h.Audit.Query)
+	api.Get("/vin/:vin/decode", h.VIN.Decode)
+
+	brands := api.Group("/catalog/brands")
+	brands.Post("/", h.Catalog.CreateBrand)
+	brands.Get("/", h.Catalog.ListBrands)
+	brands.Get("/:id", h.Catalog.GetBrand)
+	brands.Put("/:id", h.Catalog.ReplaceBrand)
+	brands.Put("/:id/years", h.Catalog.SetBrandYears)
+	brands.Delete("/:id", h.Catalog.DeleteBrand)
+	brands.Post("/:id/models", h.Catalog.CreateModel)
+	brands.Get("/:id/models", h.Catalog.ListModels)
+	brands.Get("/:id/models/:modelId", h.Catalog.GetModel)
+	brands.Put("/:id/models/:modelId", h.Catalog.ReplaceModel)
+	brands.Put("/:id/models/:modelId/years", h.Catalog.SetModelYears)
+	brands.Delete("/:id/models/:modelId", h.Catalog.DeleteModel)
+
+	jobs := api.Group("/jobs")
+	jobs.Get("/:id", h.Jobs.Get)
+	jobs.Post("/:id/cancel", h.Jobs.Cancel)
+	jobs.Get("/:id/result", h.Jobs.Result)
+
+	hooks := api.Group("/webhooks")
+	hooks.Post("/", h.Webhooks.Create)
+	hooks.Get("/", h.Webhooks.List)
+	hooks.Get("/:id", h.Webhooks.Get)
+	hooks.Put("/:id", h.Webhooks.Replace)
+	hooks.Delete("/:id", h.Webhooks.Delete)
+	hooks.Get("/:id/deliveries", h.Webhooks.Deliveries)
+	hooks.Post("/:id/deliveries/:deliveryId/redeliver", h.Webhooks.Redeliver)
 }
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/go-faster/errors"
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteScheme — префикс DSN, по которому выбирается драйвер SQLite:
+// sqlite:///var/lib/cars.db, sqlite://cars.db, sqlite://:memory:.
+const SQLiteScheme = "sqlite://"
+
+// OpenSQLite открывает базу SQLite по DSN вида sqlite://<path>.
+// Соединение одно: SQLite всё равно сериализует запись, а для :memory:
+// каждое новое соединение означало бы новую пустую базу.
+func OpenSQLite(ctx context.Context, dsn string) (*sql.DB, error) {
+	path := strings.TrimPrefix(dsn, SQLiteScheme)
+	if path == "" {
+		return nil, errors.New("empty sqlite path")
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	path += sep + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, errors.Wrap(err, "sql.Open(sqlite)")
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "ping sqlite")
+	}
+	return db, nil
+}
@@ -0,0 +1,163 @@
+// Package stream раздаёт события об изменении машин подключённым клиентам
+// (SSE, WebSocket) в пределах одного экземпляра сервиса.
+package stream
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// Event — событие с порядковым номером в журнале брокера.
+type Event struct {
+	ID uint64 `json:"id"`
+	models.CarEvent
+}
+
+// Filter отбирает события для подписчика; nil — все события.
+type Filter func(Event) bool
+
+// BrandFilter пропускает события машин указанной марки (без учёта регистра); пустая марка — все.
+func BrandFilter(brand string) Filter {
+	if brand == "" {
+		return nil
+	}
+	return func(e Event) bool { return strings.EqualFold(e.Car.Brand, brand) }
+}
+
+// Broker хранит последние события в кольцевом журнале ограниченного размера
+// и рассылает новые подписчикам. Публикация не блокируется: подписчик,
+// не успевающий читать, отключается и может переподключиться с Last-Event-ID.
+type Broker struct {
+	mu      sync.Mutex
+	log     []Event // кольцевой буфер
+	start   int     // индекс самого старого события в log
+	size    int
+	lastID  uint64
+	bufSize int
+	subs    map[*Subscription]struct{}
+	closed  bool
+	now     func() time.Time
+}
+
+func NewBroker(logSize, bufSize int) *Broker {
+	return &Broker{
+		log:     make([]Event, logSize),
+		bufSize: bufSize,
+		subs:    make(map[*Subscription]struct{}),
+		now:     time.Now,
+	}
+}
+
+// Notify реализует usecase.Notifier.
+func (b *Broker) Notify(evt models.CarEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	if evt.OccurredAt.IsZero() {
+		evt.OccurredAt = b.now().UTC()
+	}
+	b.lastID++
+	e := Event{ID: b.lastID, CarEvent: evt}
+	b.append(e)
+
+	for s := range b.subs {
+		if s.filter != nil && !s.filter(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			b.drop(s)
+		}
+	}
+}
+
+func (b *Broker) append(e Event) {
+	if len(b.log) == 0 {
+		return
+	}
+	if b.size < len(b.log) {
+		b.log[(b.start+b.size)%len(b.log)] = e
+		b.size++
+		return
+	}
+	b.log[b.start] = e
+	b.start = (b.start + 1) % len(b.log)
+}
+
+// Subscription — подписка на события. Backlog содержит пропущенные события
+// после afterID; Gap означает, что часть из них уже вытеснена из журнала
+// (или журнал начат заново после рестарта) и клиенту нужно перечитать состояние.
+// Канал C закрывается при Close, отключении медленного подписчика и остановке брокера.
+type Subscription struct {
+	C       <-chan Event
+	Backlog []Event
+	Gap     bool
+
+	ch     chan Event
+	filter Filter
+	broker *Broker
+	once   sync.Once
+}
+
+// Subscribe регистрирует подписчика. Если resume=true, в Backlog попадают
+// события журнала с ID больше afterID.
+func (b *Broker) Subscribe(afterID uint64, resume bool, filter Filter) *Subscription {
+	ch := make(chan Event, b.bufSize)
+	s := &Subscription{C: ch, ch: ch, filter: filter, broker: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		s.once.Do(func() { close(ch) })
+		return s
+	}
+
+	if resume {
+		oldest := b.lastID - uint64(b.size) + 1
+		s.Gap = afterID > b.lastID || afterID+1 < oldest
+		for i := 0; i < b.size; i++ {
+			e := b.log[(b.start+i)%len(b.log)]
+			if e.ID > afterID && (filter == nil || filter(e)) {
+				s.Backlog = append(s.Backlog, e)
+			}
+		}
+	}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// LastID возвращает номер последнего опубликованного события.
+func (b *Broker) LastID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+// Close отключает всех подписчиков; дальнейшие события игнорируются.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for s := range b.subs {
+		b.drop(s)
+	}
+}
+
+// drop вызывается под b.mu.
+func (b *Broker) drop(s *Subscription) {
+	delete(b.subs, s)
+	s.once.Do(func() { close(s.ch) })
+}
+
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.drop(s)
+}
@@ -0,0 +1,155 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/stream"
+)
+
+func carEvent(typ, brand string) models.CarEvent {
+	return models.CarEvent{Type: typ, Car: models.CarResponse{ID: brand + "-id", Brand: brand}}
+}
+
+func ids(events []stream.Event) []uint64 {
+	out := make([]uint64, 0, len(events))
+	for _, e := range events {
+		out = append(out, e.ID)
+	}
+	return out
+}
+
+func TestBroker_DeliversLiveEvents(t *testing.T) {
+	t.Parallel()
+
+	b := stream.NewBroker(10, 4)
+	sub := b.Subscribe(0, false, nil)
+	defer sub.Close()
+
+	b.Notify(carEvent(models.EventCarCreated, "BMW"))
+	e := <-sub.C
+	assert.Equal(t, uint64(1), e.ID)
+	assert.Equal(t, models.EventCarCreated, e.Type)
+	assert.False(t, e.OccurredAt.IsZero())
+	assert.Empty(t, sub.Backlog)
+}
+
+func TestBroker_ResumeFromLastEventID(t *testing.T) {
+	t.Parallel()
+
+	b := stream.NewBroker(10, 4)
+	for i := 0; i < 5; i++ {
+		b.Notify(carEvent(models.EventCarUpdated, "BMW"))
+	}
+
+	sub := b.Subscribe(3, true, nil)
+	defer sub.Close()
+	assert.False(t, sub.Gap)
+	assert.Equal(t, []uint64{4, 5}, ids(sub.Backlog))
+}
+
+func TestBroker_GapWhenLogOverflowed(t *testing.T) {
+	t.Parallel()
+
+	b := stream.NewBroker(3, 4)
+	for i := 0; i < 6; i++ {
+		b.Notify(carEvent(models.EventCarUpdated, "BMW"))
+	}
+
+	sub := b.Subscribe(1, true, nil)
+	defer sub.Close()
+	assert.True(t, sub.Gap, "events 2-3 were evicted")
+	assert.Equal(t, []uint64{4, 5, 6}, ids(sub.Backlog))
+
+	fresh := b.Subscribe(3, true, nil)
+	defer fresh.Close()
+	assert.False(t, fresh.Gap, "oldest retained event follows last seen")
+
+	restarted := b.Subscribe(100, true, nil)
+	defer restarted.Close()
+	assert.True(t, restarted.Gap, "ID from before restart")
+	assert.Empty(t, restarted.Backlog)
+}
+
+func TestBroker_BrandFilter(t *testing.T) {
+	t.Parallel()
+
+	b := stream.NewBroker(10, 4)
+	b.Notify(carEvent(models.EventCarCreated, "BMW"))
+	b.Notify(carEvent(models.EventCarCreated, "Audi"))
+
+	sub := b.Subscribe(0, true, stream.BrandFilter("bmw"))
+	defer sub.Close()
+	assert.Equal(t, []uint64{1}, ids(sub.Backlog))
+
+	b.Notify(carEvent(models.EventCarDeleted, "Audi"))
+	b.Notify(carEvent(models.EventCarDeleted, "BMW"))
+	e := <-sub.C
+	assert.Equal(t, uint64(4), e.ID)
+	assert.Len(t, sub.C, 0)
+}
+
+func TestBroker_SlowSubscriberDropped(t *testing.T) {
+	t.Parallel()
+
+	b := stream.NewBroker(10, 2)
+	slow := b.Subscribe(0, false, nil)
+	for i := 0; i < 3; i++ {
+		b.Notify(carEvent(models.EventCarUpdated, "BMW"))
+	}
+
+	var got []uint64
+	for e := range slow.C {
+		got = append(got, e.ID)
+	}
+	assert.Equal(t, []uint64{1, 2}, got, "channel closed after buffered events")
+
+	resumed := b.Subscribe(got[len(got)-1], true, nil)
+	defer resumed.Close()
+	require.False(t, resumed.Gap)
+	assert.Equal(t, []uint64{3}, ids(resumed.Backlog))
+	slow.Close() // повторное закрытие безопасно
+}
+
+func TestBroker_CloseStopsSubscribers(t *testing.T) {
+	t.Parallel()
+
+	b := stream.NewBroker(10, 2)
+	sub := b.Subscribe(0, false, nil)
+	b.Close()
+
+	_, ok := <-sub.C
+	assert.False(t, ok)
+	b.Notify(carEvent(models.EventCarCreated, "BMW"))
+	assert.Equal(t, uint64(0), b.LastID())
+}
+
+func TestCriteria_Match(t *testing.T) {
+	t.Parallel()
+
+	e := stream.Event{ID: 1, CarEvent: models.CarEvent{
+		Type: models.EventCarUpdated,
+		Car:  models.CarResponse{ID: "car-1", Brand: "BMW"},
+	}}
+
+	cases := []struct {
+		name string
+		c    stream.Criteria
+		want bool
+	}{
+		{"empty matches all", stream.Criteria{}, true},
+		{"car id", stream.Criteria{CarIDs: []string{"car-2", "car-1"}}, true},
+		{"other car", stream.Criteria{CarIDs: []string{"car-2"}}, false},
+		{"brand case-insensitive", stream.Criteria{Brand: "bmw"}, true},
+		{"other brand", stream.Criteria{Brand: "Audi"}, false},
+		{"type", stream.Criteria{Types: []string{models.EventCarUpdated}}, true},
+		{"other type", stream.Criteria{Types: []string{models.EventCarDeleted}}, false},
+		{"all conditions", stream.Criteria{CarIDs: []string{"car-1"}, Brand: "BMW", Types: []string{models.EventCarCreated}}, false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, tc.c.Match(e), tc.name)
+	}
+}
@@ -0,0 +1,27 @@
+package stream
+
+import (
+	"slices"
+	"strings"
+)
+
+// Criteria — условия подписки на события; пустое поле ничего не ограничивает.
+type Criteria struct {
+	CarIDs []string `json:"car_ids,omitempty"`
+	Brand  string   `json:"brand,omitempty"`
+	Types  []string `json:"types,omitempty"`
+}
+
+// Match сообщает, подходит ли событие под условия.
+func (c Criteria) Match(e Event) bool {
+	if len(c.CarIDs) > 0 && !slices.Contains(c.CarIDs, e.Car.ID) {
+		return false
+	}
+	if c.Brand != "" && !strings.EqualFold(c.Brand, e.Car.Brand) {
+		return false
+	}
+	if len(c.Types) > 0 && !slices.Contains(c.Types, e.Type) {
+		return false
+	}
+	return true
+}
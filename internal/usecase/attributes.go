@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// normalizeAttributes приводит перечисления к нижнему регистру, валюту — к верхнему.
+func normalizeAttributes(a *models.CarAttributes) {
+	a.Color = strings.TrimSpace(a.Color)
+	a.BodyType = strings.ToLower(strings.TrimSpace(a.BodyType))
+	a.FuelType = strings.ToLower(strings.TrimSpace(a.FuelType))
+	a.Transmission = strings.ToLower(strings.TrimSpace(a.Transmission))
+	a.Currency = strings.ToUpper(strings.TrimSpace(a.Currency))
+	a.Condition = strings.ToLower(strings.TrimSpace(a.Condition))
+	a.Status = strings.ToLower(strings.TrimSpace(a.Status))
+}
+
+// validateAttributes проверяет перечисления (числовые диапазоны проверяют теги CarAttributes)
+// и то, что цена указана вместе с валютой.
+func validateAttributes(a models.CarAttributes) error {
+	enums := []struct {
+		field, value string
+		allowed      []string
+	}{
+		{"body_type", a.BodyType, models.BodyTypes},
+		{"fuel_type", a.FuelType, models.FuelTypes},
+		{"transmission", a.Transmission, models.Transmissions},
+		{"currency", a.Currency, models.Currencies},
+		{"condition", a.Condition, models.Conditions},
+		{"status", a.Status, models.CarStatuses},
+	}
+	for _, e := range enums {
+		if e.value != "" && !slices.Contains(e.allowed, e.value) {
+			return fmt.Errorf("%w: %s must be one of %s", apperr.ErrInvalidInput, e.field, strings.Join(e.allowed, ", "))
+		}
+	}
+	if (a.Price == nil) != (a.Currency == "") {
+		return fmt.Errorf("%w: price and currency must be set together", apperr.ErrInvalidInput)
+	}
+	return nil
+}
+
+// replaceAttributes — атрибуты после PUT: все заменяются, пустой status оставляет текущий.
+func replaceAttributes(current, next models.CarAttributes) models.CarAttributes {
+	if next.Status == "" {
+		next.Status = current.Status
+	}
+	return next
+}
+
+// mergeAttributes применяет атрибуты из JSON Merge Patch: null очищает атрибут, status очистить нельзя.
+func mergeAttributes(dst *models.CarAttributes, patch models.UpdateCarAttributes) error {
+	if patch.Status.Null {
+		return fmt.Errorf("%w: status cannot be null", apperr.ErrInvalidInput)
+	}
+	mergeOptional(&dst.Mileage, patch.Mileage)
+	mergeOptional(&dst.EngineVolume, patch.EngineVolume)
+	mergeOptional(&dst.EnginePower, patch.EnginePower)
+	mergeOptional(&dst.Price, patch.Price)
+	for _, f := range []struct {
+		dst   *string
+		patch models.Optional[string]
+	}{
+		{&dst.Color, patch.Color},
+		{&dst.BodyType, patch.BodyType},
+		{&dst.FuelType, patch.FuelType},
+		{&dst.Transmission, patch.Transmission},
+		{&dst.Currency, patch.Currency},
+		{&dst.Condition, patch.Condition},
+		{&dst.Status, patch.Status},
+	} {
+		if f.patch.Set {
+			*f.dst = f.patch.Value // для null Value пустое
+		}
+	}
+	return nil
+}
+
+func mergeOptional[T any](dst **T, v models.Optional[T]) {
+	switch {
+	case v.Null:
+		*dst = nil
+	case v.Set:
+		n := v.Value
+		*dst = &n
+	}
+}
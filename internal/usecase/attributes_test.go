@@ -0,0 +1,152 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository/mocks"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+func intPtr(v int) *int { return &v }
+
+func moneyPtr(v models.Money) *models.Money { return &v }
+
+func TestCreateCar_NormalizesAttributes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	mockRepo.EXPECT().InsertCar(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *models.Car) error {
+		if c.BodyType != "suv" || c.FuelType != "diesel" || c.Currency != "EUR" {
+			t.Fatalf("enums must be normalized, got %+v", c.CarAttributes)
+		}
+		if c.Status != models.CarStatusAvailable {
+			t.Fatalf("status must default to available, got %q", c.Status)
+		}
+		return nil
+	})
+
+	_, err := uc.Create(context.Background(), models.CreateCarRequest{Brand: "BMW", Model: "Xfive", Year: 2020,
+		CarAttributes: models.CarAttributes{BodyType: "SUV", FuelType: " Diesel", Price: moneyPtr(50000), Currency: "eur"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCreateCar_InvalidAttributes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := usecase.NewCarUsecase(mocks.NewMockCarProvider(ctrl))
+	for name, attrs := range map[string]models.CarAttributes{
+		"unknown body type":    {BodyType: "spaceship"},
+		"unknown status":       {Status: "lost"},
+		"unknown currency":     {Price: moneyPtr(1), Currency: "XYZ"},
+		"price without curr":   {Price: moneyPtr(1000)},
+		"currency without sum": {Currency: "RUB"},
+		"negative mileage":     {Mileage: intPtr(-1)},
+		"tiny engine":          {EngineVolume: intPtr(10)},
+	} {
+		_, err := uc.Create(context.Background(),
+			models.CreateCarRequest{Brand: "BMW", Model: "Xfive", Year: 2020, CarAttributes: attrs})
+		if !errors.Is(err, apperr.ErrInvalidInput) {
+			t.Fatalf("%s: expected ErrInvalidInput, got %v", name, err)
+		}
+	}
+}
+
+func TestUpdateCar_MergesAttributes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	id := "4f1c2a9e-8a0b-4c5d-9e6f-0123456789ab"
+	mockRepo.EXPECT().GetCarByID(gomock.Any(), id).Return(&models.Car{ID: id, Brand: "BMW", Model: "Xfive", Year: 2020,
+		Version: 1, CarAttributes: models.CarAttributes{Mileage: intPtr(1000), Color: "red", Price: moneyPtr(5),
+			Currency: "USD", Status: models.CarStatusAvailable}}, nil)
+	mockRepo.EXPECT().UpdateCar(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *models.Car) error {
+		want := models.CarAttributes{Mileage: intPtr(2000), Status: models.CarStatusSold}
+		if *c.Mileage != *want.Mileage || c.Color != "" || c.Price != nil || c.Currency != "" || c.Status != want.Status {
+			t.Fatalf("unexpected attributes %+v", c.CarAttributes)
+		}
+		return nil
+	})
+
+	_, err := uc.Update(context.Background(), models.UpdateCarRequest{ID: id, UpdateCarAttributes: models.UpdateCarAttributes{
+		Mileage: models.Some(2000), Color: models.Null[string](), Price: models.Null[models.Money](),
+		Currency: models.Null[string](), Status: models.Some("SOLD"),
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUpdateCar_NullStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	id := "4f1c2a9e-8a0b-4c5d-9e6f-0123456789ab"
+	mockRepo.EXPECT().GetCarByID(gomock.Any(), id).Return(&models.Car{ID: id, Brand: "BMW", Model: "Xfive", Year: 2020,
+		Version: 1, CarAttributes: models.CarAttributes{Status: models.CarStatusAvailable}}, nil)
+
+	_, err := uc.Update(context.Background(), models.UpdateCarRequest{ID: id,
+		UpdateCarAttributes: models.UpdateCarAttributes{Status: models.Null[string]()}})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestUpdateCar_PriceWithoutCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	id := "4f1c2a9e-8a0b-4c5d-9e6f-0123456789ab"
+	mockRepo.EXPECT().GetCarByID(gomock.Any(), id).Return(&models.Car{ID: id, Brand: "BMW", Model: "Xfive", Year: 2020,
+		Version: 1, CarAttributes: models.CarAttributes{Status: models.CarStatusAvailable}}, nil)
+
+	_, err := uc.Update(context.Background(), models.UpdateCarRequest{ID: id,
+		UpdateCarAttributes: models.UpdateCarAttributes{Price: models.Some[models.Money](10000)}})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestReplaceCar_KeepsStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	id := "4f1c2a9e-8a0b-4c5d-9e6f-0123456789ab"
+	mockRepo.EXPECT().GetCarByID(gomock.Any(), id).Return(&models.Car{ID: id, Brand: "BMW", Model: "Xfive", Year: 2020,
+		Version: 1, CarAttributes: models.CarAttributes{Color: "red", Status: models.CarStatusSold}}, nil)
+	mockRepo.EXPECT().UpdateCar(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *models.Car) error {
+		if c.Status != models.CarStatusSold || c.Color != "" || c.FuelType != "electric" {
+			t.Fatalf("unexpected attributes %+v", c.CarAttributes)
+		}
+		return nil
+	})
+
+	_, err := uc.Replace(context.Background(), models.ReplaceCarRequest{ID: id, Brand: "BMW", Model: "Xfive", Year: 2020,
+		CarAttributes: models.CarAttributes{FuelType: "electric"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
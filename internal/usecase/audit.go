@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+const defaultAuditLimit = 50
+
+type AuditUC struct {
+	repo repository.AuditProvider
+}
+
+func NewAuditUsecase(repo repository.AuditProvider) AuditUsecase {
+	return &AuditUC{repo: repo}
+}
+
+// History возвращает журнал изменений одной машины, включая удалённые и окончательно стёртые.
+func (u *AuditUC) History(ctx context.Context, carID string, limit, offset int) (models.AuditPage, error) {
+	return u.Query(ctx, models.AuditQuery{CarID: carID, Limit: limit, Offset: offset})
+}
+
+// Query ищет записи аудита по автору, действию и полуинтервалу [from, to).
+func (u *AuditUC) Query(ctx context.Context, q models.AuditQuery) (models.AuditPage, error) {
+	if err := models.ValidateStruct(q); err != nil {
+		return models.AuditPage{}, err
+	}
+	filter := models.AuditFilter{
+		CarID:  q.CarID,
+		Actor:  q.Actor,
+		Action: q.Action,
+		Limit:  q.Limit,
+		Offset: q.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	var err error
+	if filter.From, err = parseTime(q.From, "from"); err != nil {
+		return models.AuditPage{}, err
+	}
+	if filter.To, err = parseTime(q.To, "to"); err != nil {
+		return models.AuditPage{}, err
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return models.AuditPage{}, fmt.Errorf("%w: from must be before to", apperr.ErrInvalidInput)
+	}
+
+	items, total, err := u.repo.ListAudit(ctx, filter)
+	if err != nil {
+		return models.AuditPage{}, err
+	}
+	return models.AuditPage{Items: items, Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+func parseTime(s, field string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be RFC 3339", apperr.ErrInvalidInput, field)
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,62 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository/mocks"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+func TestAuditQuery_BuildsFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAuditProvider(ctrl)
+	uc := usecase.NewAuditUsecase(mockRepo)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.EXPECT().
+		ListAudit(gomock.Any(), models.AuditFilter{Actor: "alice", From: from, To: to, Limit: 50}).
+		Return([]models.AuditEntry{{ID: 1, Actor: "alice"}}, 1, nil)
+
+	page, err := uc.Query(context.Background(), models.AuditQuery{
+		Actor: "alice",
+		From:  "2025-01-01T03:00:00+03:00",
+		To:    "2025-02-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Total != 1 || len(page.Items) != 1 || page.Limit != 50 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+}
+
+func TestAuditQuery_InvalidRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAuditProvider(ctrl)
+	uc := usecase.NewAuditUsecase(mockRepo)
+
+	_, err := uc.Query(context.Background(), models.AuditQuery{
+		From: "2025-02-01T00:00:00Z",
+		To:   "2025-01-01T00:00:00Z",
+	})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+
+	_, err = uc.Query(context.Background(), models.AuditQuery{From: "yesterday"})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
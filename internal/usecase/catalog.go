@@ -0,0 +1,349 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+type CatalogUC struct {
+	repo repository.CatalogProvider
+}
+
+func NewCatalogUsecase(repo repository.CatalogProvider) CatalogUsecase {
+	return &CatalogUC{repo: repo}
+}
+
+// CreateBrand сохраняет марку. Название и синонимы не должны совпадать (по models.CatalogKey)
+// с названием или синонимом другой марки — иначе apperr.ErrConflict.
+func (u *CatalogUC) CreateBrand(ctx context.Context, req models.BrandRequest) (models.Brand, error) {
+	req.Aliases = cleanAliases(req.Name, req.Aliases)
+	if err := models.ValidateStruct(req); err != nil {
+		return models.Brand{}, err
+	}
+	if err := u.checkBrandKeys(ctx, "", req.Name, req.Aliases); err != nil {
+		return models.Brand{}, err
+	}
+	b := models.Brand{Name: req.Name, Aliases: req.Aliases}
+	if err := u.repo.CreateBrand(ctx, &b); err != nil {
+		return models.Brand{}, err
+	}
+	return b, nil
+}
+
+func (u *CatalogUC) ListBrands(ctx context.Context) ([]models.Brand, error) {
+	return u.repo.ListBrands(ctx)
+}
+
+func (u *CatalogUC) GetBrand(ctx context.Context, id string) (models.Brand, error) {
+	b, err := u.repo.GetBrand(ctx, id)
+	if err != nil {
+		return models.Brand{}, err
+	}
+	return *b, nil
+}
+
+// ReplaceBrand заменяет название и синонимы. Уже сохранённые машины не переименовываются.
+func (u *CatalogUC) ReplaceBrand(ctx context.Context, req models.BrandRequest) (models.Brand, error) {
+	req.Aliases = cleanAliases(req.Name, req.Aliases)
+	if err := models.ValidateStruct(req); err != nil {
+		return models.Brand{}, err
+	}
+	if err := u.checkBrandKeys(ctx, req.ID, req.Name, req.Aliases); err != nil {
+		return models.Brand{}, err
+	}
+	b := models.Brand{ID: req.ID, Name: req.Name, Aliases: req.Aliases}
+	if err := u.repo.UpdateBrand(ctx, &b); err != nil {
+		return models.Brand{}, err
+	}
+	return b, nil
+}
+
+// SetBrandYears заменяет годы выпуска марки; они ограничивают год новых и изменённых машин.
+func (u *CatalogUC) SetBrandYears(ctx context.Context, req models.YearRangeRequest) (models.Brand, error) {
+	years, err := validateYearRange(req)
+	if err != nil {
+		return models.Brand{}, err
+	}
+	b, err := u.repo.SetBrandYears(ctx, req.ID, years)
+	if err != nil {
+		return models.Brand{}, err
+	}
+	return *b, nil
+}
+
+func (u *CatalogUC) DeleteBrand(ctx context.Context, id string) error {
+	return u.repo.DeleteBrand(ctx, id)
+}
+
+// CreateModel добавляет модель марке req.BrandID; названия моделей уникальны в пределах марки.
+func (u *CatalogUC) CreateModel(ctx context.Context, req models.CarModelRequest) (models.CarModel, error) {
+	req.Aliases = cleanAliases(req.Name, req.Aliases)
+	if err := models.ValidateStruct(req); err != nil {
+		return models.CarModel{}, err
+	}
+	if _, err := u.repo.GetBrand(ctx, req.BrandID); err != nil {
+		return models.CarModel{}, err
+	}
+	if err := u.checkModelKeys(ctx, req.BrandID, "", req.Name, req.Aliases); err != nil {
+		return models.CarModel{}, err
+	}
+	m := models.CarModel{BrandID: req.BrandID, Name: req.Name, Aliases: req.Aliases}
+	if err := u.repo.CreateModel(ctx, &m); err != nil {
+		return models.CarModel{}, err
+	}
+	return m, nil
+}
+
+func (u *CatalogUC) ListModels(ctx context.Context, brandID string) ([]models.CarModel, error) {
+	if _, err := u.repo.GetBrand(ctx, brandID); err != nil {
+		return nil, err
+	}
+	return u.repo.ListModels(ctx, brandID)
+}
+
+func (u *CatalogUC) GetModel(ctx context.Context, brandID, id string) (models.CarModel, error) {
+	m, err := u.repo.GetModel(ctx, id)
+	if err != nil {
+		return models.CarModel{}, err
+	}
+	if m.BrandID != brandID {
+		return models.CarModel{}, apperr.ErrNotFound
+	}
+	return *m, nil
+}
+
+func (u *CatalogUC) ReplaceModel(ctx context.Context, req models.CarModelRequest) (models.CarModel, error) {
+	req.Aliases = cleanAliases(req.Name, req.Aliases)
+	if err := models.ValidateStruct(req); err != nil {
+		return models.CarModel{}, err
+	}
+	if _, err := u.GetModel(ctx, req.BrandID, req.ID); err != nil {
+		return models.CarModel{}, err
+	}
+	if err := u.checkModelKeys(ctx, req.BrandID, req.ID, req.Name, req.Aliases); err != nil {
+		return models.CarModel{}, err
+	}
+	m := models.CarModel{ID: req.ID, BrandID: req.BrandID, Name: req.Name, Aliases: req.Aliases}
+	if err := u.repo.UpdateModel(ctx, &m); err != nil {
+		return models.CarModel{}, err
+	}
+	return m, nil
+}
+
+// SetModelYears заменяет годы выпуска модели req.ID марки req.BrandID.
+func (u *CatalogUC) SetModelYears(ctx context.Context, req models.YearRangeRequest) (models.CarModel, error) {
+	years, err := validateYearRange(req)
+	if err != nil {
+		return models.CarModel{}, err
+	}
+	if _, err := u.GetModel(ctx, req.BrandID, req.ID); err != nil {
+		return models.CarModel{}, err
+	}
+	m, err := u.repo.SetModelYears(ctx, req.ID, years)
+	if err != nil {
+		return models.CarModel{}, err
+	}
+	return *m, nil
+}
+
+func (u *CatalogUC) DeleteModel(ctx context.Context, brandID, id string) error {
+	if _, err := u.GetModel(ctx, brandID, id); err != nil {
+		return err
+	}
+	return u.repo.DeleteModel(ctx, id)
+}
+
+// checkBrandKeys проверяет, что ни одно написание не принадлежит другой марке. Параллельное
+// создание может проскочить проверку синонимов; уникальность названия держит хранилище.
+func (u *CatalogUC) checkBrandKeys(ctx context.Context, id, name string, aliases []string) error {
+	for _, key := range models.CatalogKeys(name, aliases) {
+		b, err := u.repo.FindBrand(ctx, key)
+		if errors.Is(err, apperr.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if b.ID != id {
+			return fmt.Errorf("%w: %q is already used by brand %q", apperr.ErrConflict, key, b.Name)
+		}
+	}
+	return nil
+}
+
+func (u *CatalogUC) checkModelKeys(ctx context.Context, brandID, id, name string, aliases []string) error {
+	for _, key := range models.CatalogKeys(name, aliases) {
+		m, err := u.repo.FindModel(ctx, brandID, key)
+		if errors.Is(err, apperr.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if m.ID != id {
+			return fmt.Errorf("%w: %q is already used by model %q", apperr.ErrConflict, key, m.Name)
+		}
+	}
+	return nil
+}
+
+func validateYearRange(req models.YearRangeRequest) (models.YearRange, error) {
+	if err := models.ValidateStruct(req); err != nil {
+		return models.YearRange{}, err
+	}
+	if req.From != nil && req.To != nil && *req.From > *req.To {
+		return models.YearRange{}, fmt.Errorf("%w: year_from must be <= year_to", apperr.ErrInvalidInput)
+	}
+	return models.YearRange{From: req.From, To: req.To}, nil
+}
+
+// cleanAliases убирает пробелы по краям и синонимы, совпадающие с названием или друг с другом.
+func cleanAliases(name string, aliases []string) []string {
+	seen := []string{models.CatalogKey(name)}
+	out := []string{}
+	for _, a := range aliases {
+		a = strings.TrimSpace(a)
+		key := models.CatalogKey(a)
+		if key != "" && slices.Contains(seen, key) {
+			continue
+		}
+		seen = append(seen, key)
+		out = append(out, a)
+	}
+	return out
+}
+
+// catalogLookup — поиск по справочнику: его реализуют repository.CatalogProvider и catalogSnapshot.
+type catalogLookup interface {
+	FindBrand(ctx context.Context, key string) (*models.Brand, error)
+	FindModel(ctx context.Context, brandID, key string) (*models.CarModel, error)
+}
+
+// carNormalizer приводит марку и модель машины к написанию из справочника и проверяет
+// год правилами yearRules. В строгом режиме марка, которой нет в справочнике, — ошибка;
+// неизвестная модель известной марки сохраняется как передана.
+type carNormalizer struct {
+	catalog catalogLookup
+	strict  bool
+}
+
+// normalize заменяет brand и model каноническими названиями и проверяет year.
+// Без справочника ничего не делает.
+func (n *carNormalizer) normalize(ctx context.Context, brand, model *string, year int) error {
+	if n == nil || *brand == "" {
+		return nil
+	}
+	b, m, err := n.lookup(ctx, *brand, *model)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		if n.strict {
+			return fmt.Errorf("%w: unknown brand %q", apperr.ErrInvalidInput, *brand)
+		}
+		return nil
+	}
+	*brand = b.Name
+	if m != nil {
+		*model = m.Name
+	}
+	return checkYearRules(year, b, m)
+}
+
+// checkYear проверяет только год: марка и модель уже сохранены в машине и не меняются,
+// поэтому строгий режим к ним не применяется.
+func (n *carNormalizer) checkYear(ctx context.Context, brand, model string, year int) error {
+	if n == nil || brand == "" {
+		return nil
+	}
+	b, m, err := n.lookup(ctx, brand, model)
+	if err != nil || b == nil {
+		return err
+	}
+	return checkYearRules(year, b, m)
+}
+
+// lookup ищет марку и её модель; nil — нет в справочнике.
+func (n *carNormalizer) lookup(ctx context.Context, brand, model string) (*models.Brand, *models.CarModel, error) {
+	b, err := n.catalog.FindBrand(ctx, models.CatalogKey(brand))
+	if errors.Is(err, apperr.ErrNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if model == "" {
+		return b, nil, nil
+	}
+	m, err := n.catalog.FindModel(ctx, b.ID, models.CatalogKey(model))
+	if errors.Is(err, apperr.ErrNotFound) {
+		return b, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, m, nil
+}
+
+// catalogSnapshot — справочник, целиком загруженный в память. Импорт проверяет строки внутри
+// транзакции хранилища, где в SQLite нельзя выполнять другие запросы, поэтому читает справочник заранее.
+type catalogSnapshot struct {
+	brands map[string]models.Brand               // ключ написания → марка
+	models map[string]map[string]models.CarModel // ID марки → ключ написания → модель
+}
+
+func loadCatalog(ctx context.Context, repo repository.CatalogProvider) (*catalogSnapshot, error) {
+	brands, err := repo.ListBrands(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := repo.ListModels(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	s := &catalogSnapshot{brands: map[string]models.Brand{}, models: map[string]map[string]models.CarModel{}}
+	for _, b := range brands {
+		for _, key := range models.CatalogKeys(b.Name, b.Aliases) {
+			// название марки важнее совпавшего с ним синонима другой
+			if prev, ok := s.brands[key]; !ok || models.CatalogKey(prev.Name) != key {
+				s.brands[key] = b
+			}
+		}
+	}
+	for _, m := range list {
+		byKey := s.models[m.BrandID]
+		if byKey == nil {
+			byKey = map[string]models.CarModel{}
+			s.models[m.BrandID] = byKey
+		}
+		for _, key := range models.CatalogKeys(m.Name, m.Aliases) {
+			if prev, ok := byKey[key]; !ok || models.CatalogKey(prev.Name) != key {
+				byKey[key] = m
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *catalogSnapshot) FindBrand(_ context.Context, key string) (*models.Brand, error) {
+	b, ok := s.brands[key]
+	if !ok {
+		return nil, apperr.ErrNotFound
+	}
+	return &b, nil
+}
+
+func (s *catalogSnapshot) FindModel(_ context.Context, brandID, key string) (*models.CarModel, error) {
+	m, ok := s.models[brandID][key]
+	if !ok {
+		return nil, apperr.ErrNotFound
+	}
+	return &m, nil
+}
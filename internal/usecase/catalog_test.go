@@ -0,0 +1,244 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/repository/mocks"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+// newCatalog возвращает справочник в памяти с марками Mercedes-Benz (модель E-Class) и Lada.
+func newCatalog(t *testing.T) repository.CatalogProvider {
+	t.Helper()
+	ctx := context.Background()
+	catalog := repository.NewMemoryCarRepo()
+	uc := usecase.NewCatalogUsecase(catalog)
+	mb, err := uc.CreateBrand(ctx, models.BrandRequest{Name: "Mercedes-Benz", Aliases: []string{"Mercedes", "MB"}})
+	if err != nil {
+		t.Fatalf("create brand: %v", err)
+	}
+	if _, err := uc.CreateModel(ctx, models.CarModelRequest{BrandID: mb.ID, Name: "E-Class", Aliases: []string{"W213"}}); err != nil {
+		t.Fatalf("create model: %v", err)
+	}
+	if _, err := uc.CreateBrand(ctx, models.BrandRequest{Name: "Lada", Aliases: []string{"ВАЗ"}}); err != nil {
+		t.Fatalf("create brand: %v", err)
+	}
+	return catalog
+}
+
+func TestCreateCar_NormalizesBrandAndModel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo, usecase.WithCatalog(newCatalog(t), false))
+
+	mockRepo.EXPECT().InsertCar(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *models.Car) error {
+		c.ID = "uuid-1"
+		return nil
+	})
+
+	resp, err := uc.Create(context.Background(), models.CreateCarRequest{Brand: "mercedes benz", Model: "w213", Year: 2020})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Brand != "Mercedes-Benz" || resp.Model != "E-Class" {
+		t.Fatalf("unexpected brand/model: %q %q", resp.Brand, resp.Model)
+	}
+}
+
+func TestCreateCar_UnknownBrand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	catalog := newCatalog(t)
+
+	lenient := usecase.NewCarUsecase(mockRepo, usecase.WithCatalog(catalog, false))
+	mockRepo.EXPECT().InsertCar(gomock.Any(), gomock.Any()).Return(nil)
+	resp, err := lenient.Create(context.Background(), models.CreateCarRequest{Brand: "Tesla", Model: "Model S", Year: 2020})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Brand != "Tesla" {
+		t.Fatalf("unknown brand must be kept, got %q", resp.Brand)
+	}
+
+	strict := usecase.NewCarUsecase(mockRepo, usecase.WithCatalog(catalog, true))
+	_, err = strict.Create(context.Background(), models.CreateCarRequest{Brand: "Tesla", Model: "Model S", Year: 2020})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestUpdateCar_StrictKeepsUnchangedBrand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo, usecase.WithCatalog(newCatalog(t), true))
+
+	id := "8f1b1a2e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	mockRepo.EXPECT().GetCarByID(gomock.Any(), id).
+		Return(&models.Car{ID: id, Brand: "Tesla", Model: "Model S", Year: 2020, Version: 1}, nil)
+	mockRepo.EXPECT().UpdateCar(gomock.Any(), gomock.Any()).Return(nil)
+
+	// машина сохранена до включения строгого режима: правка года не должна упираться в марку
+	if _, err := uc.Update(context.Background(), models.UpdateCarRequest{ID: id, Year: models.Some(2021)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBatch_StrictKeepsUnchangedBrand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo, usecase.WithCatalog(newCatalog(t), true))
+
+	legacy := "8f1b1a2e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	renamed := "9a2c2b3f-4d5e-4f60-9b0c-1d2e3f4a5b6c"
+	for _, id := range []string{legacy, renamed} {
+		mockRepo.EXPECT().GetCarByID(gomock.Any(), id).
+			Return(&models.Car{ID: id, Brand: "Tesla", Model: "Model S", Year: 2020, Version: 1}, nil)
+	}
+	mockRepo.EXPECT().ApplyBatch(gomock.Any(), gomock.Any(), false).
+		DoAndReturn(func(_ context.Context, ops []models.CarMutation, _ bool) ([]models.MutationResult, error) {
+			if len(ops) != 1 || ops[0].Car.ID != legacy || ops[0].Car.Brand != "Tesla" {
+				t.Fatalf("unexpected ops: %+v", ops)
+			}
+			car := ops[0].Car
+			car.Version = 2
+			return []models.MutationResult{{Car: &car}}, nil
+		})
+
+	// машины сохранены до включения строгого режима: правка года проходит, смена модели — нет
+	res, err := uc.Batch(context.Background(), models.BatchRequest{
+		Operations: []models.BatchOperation{
+			{Op: models.BatchOpUpdate, ID: legacy, Brand: "Tesla", Model: "Model S", Year: 2021},
+			{Op: models.BatchOpUpdate, ID: renamed, Brand: "Tesla", Model: "Model X", Year: 2020},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Items[0].Err != nil || res.Items[0].Car.Year != 2021 {
+		t.Fatalf("unexpected first item: %+v", res.Items[0])
+	}
+	if !errors.Is(res.Items[1].Err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", res.Items[1].Err)
+	}
+}
+
+func TestCatalog_AliasConflict(t *testing.T) {
+	catalog := newCatalog(t)
+	uc := usecase.NewCatalogUsecase(catalog)
+
+	_, err := uc.CreateBrand(context.Background(), models.BrandRequest{Name: "Mercedes AMG", Aliases: []string{"mb"}})
+	if !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	b, err := uc.CreateBrand(context.Background(), models.BrandRequest{Name: "Kia", Aliases: []string{" KIA ", "Киа", "киа"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(b.Aliases) != 1 || b.Aliases[0] != "Киа" {
+		t.Fatalf("aliases must be deduplicated, got %q", b.Aliases)
+	}
+}
+
+func TestCreateCar_YearRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	catalog := newCatalog(t)
+	admin := usecase.NewCatalogUsecase(catalog)
+	brands, _ := admin.ListBrands(ctx)
+	mb := brands[1]
+	if _, err := admin.SetBrandYears(ctx, models.YearRangeRequest{ID: mb.ID, From: intPtr(1926)}); err != nil {
+		t.Fatalf("set brand years: %v", err)
+	}
+	list, _ := admin.ListModels(ctx, mb.ID)
+	_, err := admin.SetModelYears(ctx, models.YearRangeRequest{ID: list[0].ID, BrandID: mb.ID, From: intPtr(2016), To: intPtr(2023)})
+	if err != nil {
+		t.Fatalf("set model years: %v", err)
+	}
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo, usecase.WithCatalog(catalog, false))
+
+	cases := []struct {
+		brand, model string
+		year         int
+		reason       string
+	}{
+		{"Mercedes", "Vito", 1920, "year 1920 is before Mercedes-Benz production started in 1926"},
+		{"MB", "W213", 2010, "year 2010 is before Mercedes-Benz E-Class production started in 2016"},
+		{"MB", "W213", 2024, "year 2024 is after Mercedes-Benz E-Class production ended in 2023"},
+	}
+	for _, tc := range cases {
+		_, err := uc.Create(ctx, models.CreateCarRequest{Brand: tc.brand, Model: tc.model, Year: tc.year})
+		if !errors.Is(err, apperr.ErrInvalidInput) || err.Error() != "invalid input: "+tc.reason {
+			t.Fatalf("%s %s %d: unexpected error %v", tc.brand, tc.model, tc.year, err)
+		}
+	}
+
+	mockRepo.EXPECT().InsertCar(gomock.Any(), gomock.Any()).Return(nil)
+	if _, err := uc.Create(ctx, models.CreateCarRequest{Brand: "MB", Model: "W213", Year: 2020}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUpdateCar_YearRuleOnYearChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	catalog := newCatalog(t)
+	lada, err := catalog.FindBrand(ctx, models.CatalogKey("Lada"))
+	if err != nil {
+		t.Fatalf("find brand: %v", err)
+	}
+	if _, err := catalog.SetBrandYears(ctx, lada.ID, models.YearRange{From: intPtr(1970)}); err != nil {
+		t.Fatalf("set brand years: %v", err)
+	}
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo, usecase.WithCatalog(catalog, true))
+
+	id := "8f1b1a2e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	mockRepo.EXPECT().GetCarByID(gomock.Any(), id).
+		Return(&models.Car{ID: id, Brand: "Lada", Model: "Niva", Year: 1980, Version: 1}, nil)
+
+	_, err = uc.Update(ctx, models.UpdateCarRequest{ID: id, Year: models.Some(1965)})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestCatalog_SetYearsValidation(t *testing.T) {
+	ctx := context.Background()
+	catalog := newCatalog(t)
+	uc := usecase.NewCatalogUsecase(catalog)
+	brands, _ := uc.ListBrands(ctx)
+
+	_, err := uc.SetBrandYears(ctx, models.YearRangeRequest{ID: brands[0].ID, From: intPtr(2000), To: intPtr(1990)})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+
+	list, _ := uc.ListModels(ctx, brands[1].ID)
+	_, err = uc.SetModelYears(ctx, models.YearRangeRequest{ID: list[0].ID, BrandID: brands[0].ID})
+	if !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("model of another brand must be not found, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+type ExportUC struct {
+	repo repository.ExportProvider
+}
+
+func NewExportUsecase(repo repository.ExportProvider) ExportUsecase {
+	return &ExportUC{repo: repo}
+}
+
+// Export передаёт машины в fn по мере чтения из хранилища; ошибка fn прерывает выгрузку.
+func (u *ExportUC) Export(ctx context.Context, f models.CarFilter, fn func(models.CarResponse) error) error {
+	return u.repo.StreamCars(ctx, f, func(c models.Car) error {
+		return fn(models.NewCarResponse(c))
+	})
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// idempotencyLockTimeout — через сколько незавершённый запрос считается брошенным
+// (процесс упал, не успев сохранить ответ), и ключ можно занять заново.
+const idempotencyLockTimeout = time.Minute
+
+type IdempotencyUC struct {
+	repo repository.IdempotencyProvider
+	ttl  time.Duration
+}
+
+// NewIdempotencyUsecase хранит ответы ttl с момента первого запроса.
+func NewIdempotencyUsecase(repo repository.IdempotencyProvider, ttl time.Duration) IdempotencyUsecase {
+	return &IdempotencyUC{repo: repo, ttl: ttl}
+}
+
+// Begin занимает ключ за запросом. Возвращает nil, если запрос нужно выполнить,
+// или сохранённый ответ, если такой запрос уже выполнен. ErrMismatch — ключ
+// использован с другим телом или параметрами запроса, ErrConflict — первый запрос ещё выполняется.
+func (u *IdempotencyUC) Begin(ctx context.Context, req models.IdempotentRequest) (*models.StoredResponse, error) {
+	now := time.Now().UTC()
+	k := models.IdempotencyKey{
+		Scope:       req.Scope,
+		Key:         req.Key,
+		RequestHash: requestHash(req.Query, req.Body),
+		ExpiresAt:   now.Add(u.ttl),
+	}
+	existing, err := u.repo.ReserveIdempotencyKey(ctx, &k, now.Add(-idempotencyLockTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, nil
+	}
+	if existing.RequestHash != k.RequestHash {
+		return nil, fmt.Errorf("%w: idempotency key was used with a different request", apperr.ErrMismatch)
+	}
+	if existing.Response == nil {
+		return nil, fmt.Errorf("%w: request with this idempotency key is in progress", apperr.ErrConflict)
+	}
+	return existing.Response, nil
+}
+
+// Complete сохраняет ответ, который будут получать повторы запроса.
+func (u *IdempotencyUC) Complete(ctx context.Context, req models.IdempotentRequest, resp models.StoredResponse) error {
+	return u.repo.CompleteIdempotencyKey(ctx, req.Scope, req.Key, resp)
+}
+
+// Release освобождает ключ, если запрос не дал ответа, который стоит повторять.
+func (u *IdempotencyUC) Release(ctx context.Context, req models.IdempotentRequest) error {
+	return u.repo.ReleaseIdempotencyKey(ctx, req.Scope, req.Key)
+}
+
+// PurgeExpired удаляет ключи, срок хранения которых истёк.
+func (u *IdempotencyUC) PurgeExpired(ctx context.Context) (int64, error) {
+	return u.repo.DeleteExpiredIdempotencyKeys(ctx, time.Now().UTC())
+}
+
+// requestHash без строки запроса совпадает с хешем одного тела — ключи, сохранённые до учёта query, остаются валидными.
+func requestHash(query string, body []byte) string {
+	h := sha256.New()
+	if query != "" {
+		h.Write([]byte(query))
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
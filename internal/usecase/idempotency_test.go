@@ -0,0 +1,136 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository/mocks"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+var idemReq = models.IdempotentRequest{Scope: "POST /api/v1/cars", Key: "k1", Body: []byte(`{"brand":"BMW"}`)}
+
+func TestIdempotencyBegin_Reserves(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyProvider(ctrl)
+	uc := usecase.NewIdempotencyUsecase(mockRepo, time.Hour)
+
+	mockRepo.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, k *models.IdempotencyKey, staleBefore time.Time) (*models.IdempotencyKey, error) {
+			if k.Scope != idemReq.Scope || k.Key != idemReq.Key || len(k.RequestHash) != 64 {
+				t.Fatalf("unexpected key: %+v", k)
+			}
+			if d := time.Until(k.ExpiresAt); d < 59*time.Minute || d > time.Hour {
+				t.Fatalf("unexpected expiry in %v", d)
+			}
+			if !staleBefore.Before(time.Now()) {
+				t.Fatalf("staleBefore must be in the past: %v", staleBefore)
+			}
+			return nil, nil
+		})
+
+	resp, err := uc.Begin(context.Background(), idemReq)
+	if err != nil || resp != nil {
+		t.Fatalf("expected reservation, got %+v, %v", resp, err)
+	}
+}
+
+func TestIdempotencyBegin_Replays(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyProvider(ctrl)
+	uc := usecase.NewIdempotencyUsecase(mockRepo, time.Hour)
+
+	var hash string
+	stored := &models.StoredResponse{Status: 201, Body: []byte(`{"id":"1"}`)}
+	mockRepo.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, k *models.IdempotencyKey, _ time.Time) (*models.IdempotencyKey, error) {
+			hash = k.RequestHash
+			return nil, nil
+		})
+	if _, err := uc.Begin(context.Background(), idemReq); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mockRepo.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&models.IdempotencyKey{RequestHash: hash, Response: stored}, nil)
+	resp, err := uc.Begin(context.Background(), idemReq)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp != stored {
+		t.Fatalf("expected stored response, got %+v", resp)
+	}
+}
+
+func TestIdempotencyBegin_Mismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyProvider(ctrl)
+	uc := usecase.NewIdempotencyUsecase(mockRepo, time.Hour)
+
+	// тело другое: ответ не повторяется, даже если он уже есть
+	mockRepo.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&models.IdempotencyKey{RequestHash: "other", Response: &models.StoredResponse{Status: 201}}, nil)
+
+	_, err := uc.Begin(context.Background(), idemReq)
+	if !errors.Is(err, apperr.ErrMismatch) {
+		t.Fatalf("expected ErrMismatch, got %v", err)
+	}
+}
+
+func TestIdempotencyBegin_InFlight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyProvider(ctrl)
+	uc := usecase.NewIdempotencyUsecase(mockRepo, time.Hour)
+
+	mockRepo.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, k *models.IdempotencyKey, _ time.Time) (*models.IdempotencyKey, error) {
+			return &models.IdempotencyKey{RequestHash: k.RequestHash}, nil
+		})
+
+	_, err := uc.Begin(context.Background(), idemReq)
+	if !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestIdempotencyBegin_QueryIsPartOfRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyProvider(ctrl)
+	uc := usecase.NewIdempotencyUsecase(mockRepo, time.Hour)
+
+	var hash string
+	mockRepo.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, k *models.IdempotencyKey, _ time.Time) (*models.IdempotencyKey, error) {
+			hash = k.RequestHash
+			return nil, nil
+		})
+	if _, err := uc.Begin(context.Background(), idemReq); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// то же тело с другими параметрами — другой запрос
+	withQuery := idemReq
+	withQuery.Query = "prefill_from_vin=true"
+	mockRepo.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&models.IdempotencyKey{RequestHash: hash, Response: &models.StoredResponse{Status: 201}}, nil)
+	_, err := uc.Begin(context.Background(), withQuery)
+	if !errors.Is(err, apperr.ErrMismatch) {
+		t.Fatalf("expected ErrMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/blob"
+	"github.com/pavel97go/service-cars/internal/imaging"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+const (
+	// originalVariant — имя варианта с исходным файлом фотографии.
+	originalVariant = "original"
+	// orphanBatchSize — сколько фотографий удалённых машин очищает один проход PurgeOrphans.
+	orphanBatchSize = 100
+)
+
+// ImageStore — хранилище для ImageUC: машина по ID и описания фотографий.
+type ImageStore interface {
+	GetCarByID(ctx context.Context, id string) (*models.Car, error)
+	repository.ImageProvider
+}
+
+// ImageConfig — ограничения загрузки и размеры миниатюр.
+type ImageConfig struct {
+	MaxBytes  int64 // размер файла
+	MaxPerCar int
+	Sizes     []imaging.Size
+}
+
+// ImageFile — файл фотографии или миниатюры; Body закрывает вызывающий.
+type ImageFile struct {
+	ContentType string
+	Body        io.ReadCloser
+}
+
+type ImageUC struct {
+	repo  ImageStore
+	blobs blob.Store
+	cfg   ImageConfig
+}
+
+func NewImageUsecase(repo ImageStore, blobs blob.Store, cfg ImageConfig) ImageUsecase {
+	return &ImageUC{repo: repo, blobs: blobs, cfg: cfg}
+}
+
+// imageKey — ключ файла варианта фотографии в хранилище объектов.
+func imageKey(carID, id, variant string) string {
+	return "cars/" + carID + "/" + id + "/" + variant
+}
+
+// imageKeys — ключи оригинала и всех миниатюр фотографии.
+func imageKeys(img models.CarImage) []string {
+	keys := []string{imageKey(img.CarID, img.ID, originalVariant)}
+	for _, name := range img.Thumbnails {
+		keys = append(keys, imageKey(img.CarID, img.ID, name))
+	}
+	return keys
+}
+
+// Upload проверяет файл по содержимому (JPEG, PNG или GIF) и размеру, сохраняет оригинал
+// и миниатюры всех размеров и добавляет фотографию в конец галереи машины.
+func (u *ImageUC) Upload(ctx context.Context, carID string, data []byte) (models.CarImageResponse, error) {
+	if len(data) == 0 {
+		return models.CarImageResponse{}, fmt.Errorf("%w: empty image file", apperr.ErrInvalidInput)
+	}
+	if u.cfg.MaxBytes > 0 && int64(len(data)) > u.cfg.MaxBytes {
+		return models.CarImageResponse{}, fmt.Errorf("%w: image is limited to %d bytes", apperr.ErrTooLarge, u.cfg.MaxBytes)
+	}
+	if _, err := u.repo.GetCarByID(ctx, carID); err != nil {
+		return models.CarImageResponse{}, err
+	}
+	src, contentType, err := imaging.Decode(data)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupported):
+		return models.CarImageResponse{}, fmt.Errorf("%w: %v, use %s", apperr.ErrUnsupportedMedia, err,
+			strings.Join(imaging.Types, ", "))
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		return models.CarImageResponse{}, fmt.Errorf("%w: %v", apperr.ErrTooLarge, err)
+	case err != nil:
+		return models.CarImageResponse{}, err
+	}
+
+	b := src.Bounds()
+	img := models.CarImage{
+		ID:          uuid.NewString(),
+		CarID:       carID,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       b.Dx(),
+		Height:      b.Dy(),
+	}
+	var stored []string
+	put := func(variant, contentType string, data []byte) error {
+		key := imageKey(carID, img.ID, variant)
+		if err := u.blobs.Put(ctx, key, contentType, data); err != nil {
+			return fmt.Errorf("store image %s: %w", variant, err)
+		}
+		stored = append(stored, key)
+		return nil
+	}
+	err = put(originalVariant, contentType, data)
+	for _, size := range u.cfg.Sizes {
+		if err != nil {
+			break
+		}
+		var thumb []byte
+		if thumb, err = imaging.EncodeJPEG(imaging.Thumbnail(src, size.Max)); err == nil {
+			err = put(size.Name, imaging.TypeJPEG, thumb)
+		}
+		img.Thumbnails = append(img.Thumbnails, size.Name)
+	}
+	if err == nil {
+		err = u.repo.AddImage(ctx, &img, u.cfg.MaxPerCar)
+	}
+	if err != nil {
+		// файлы без строки в базе никто не найдёт, поэтому убираем их сразу
+		return models.CarImageResponse{}, errors.Join(err, u.deleteBlobs(context.WithoutCancel(ctx), stored))
+	}
+	return models.NewCarImageResponse(img), nil
+}
+
+// List возвращает фотографии машины вне корзины по порядку галереи.
+func (u *ImageUC) List(ctx context.Context, carID string) ([]models.CarImageResponse, error) {
+	if _, err := u.repo.GetCarByID(ctx, carID); err != nil {
+		return nil, err
+	}
+	images, err := u.repo.ListImages(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	return models.ToImageResponse(images), nil
+}
+
+// Open открывает оригинал фотографии (пустой size) или её миниатюру.
+func (u *ImageUC) Open(ctx context.Context, carID, id, size string) (ImageFile, error) {
+	if _, err := u.repo.GetCarByID(ctx, carID); err != nil {
+		return ImageFile{}, err
+	}
+	img, err := u.repo.GetImage(ctx, carID, id)
+	if err != nil {
+		return ImageFile{}, err
+	}
+	variant, contentType := originalVariant, img.ContentType
+	if size != "" {
+		if !slices.Contains(img.Thumbnails, size) {
+			return ImageFile{}, fmt.Errorf("%w: unknown size %q, image has %s", apperr.ErrInvalidInput, size,
+				strings.Join(img.Thumbnails, ", "))
+		}
+		variant, contentType = size, imaging.TypeJPEG
+	}
+	body, err := u.blobs.Get(ctx, imageKey(carID, id, variant))
+	if err != nil {
+		return ImageFile{}, err
+	}
+	return ImageFile{ContentType: contentType, Body: body}, nil
+}
+
+// Delete убирает фотографию из галереи и удаляет её файлы. Если файлы удалить не удалось,
+// фотографии в галерее уже нет, а ошибка сообщает об оставшихся файлах.
+func (u *ImageUC) Delete(ctx context.Context, carID, id string) error {
+	img, err := u.repo.GetImage(ctx, carID, id)
+	if err != nil {
+		return err
+	}
+	if err := u.repo.DeleteImage(ctx, carID, id); err != nil {
+		return err
+	}
+	return u.deleteBlobs(context.WithoutCancel(ctx), imageKeys(*img))
+}
+
+// Reorder задаёт порядок галереи: req.IDs перечисляют все фотографии машины.
+func (u *ImageUC) Reorder(ctx context.Context, req models.ImageOrderRequest) ([]models.CarImageResponse, error) {
+	if err := models.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+	images, err := u.repo.ReorderImages(ctx, req.CarID, req.IDs)
+	if err != nil {
+		return nil, err
+	}
+	return models.ToImageResponse(images), nil
+}
+
+// PurgeOrphans удаляет файлы фотографий окончательно удалённых машин, затем их строки.
+// Фотографии, файлы которых удалить не удалось, останутся до следующего прохода.
+func (u *ImageUC) PurgeOrphans(ctx context.Context) (int64, error) {
+	var total int64
+	for {
+		orphans, err := u.repo.OrphanedImages(ctx, orphanBatchSize)
+		if err != nil || len(orphans) == 0 {
+			return total, err
+		}
+		var (
+			ids  []string
+			errs []error
+		)
+		for _, img := range orphans {
+			if err := u.deleteBlobs(ctx, imageKeys(img)); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			ids = append(ids, img.ID)
+		}
+		if len(ids) > 0 {
+			n, err := u.repo.DeleteOrphanedImages(ctx, ids)
+			total += n
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) > 0 {
+			return total, errors.Join(errs...)
+		}
+		if len(orphans) < orphanBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func (u *ImageUC) deleteBlobs(ctx context.Context, keys []string) error {
+	var errs []error
+	for _, key := range keys {
+		if err := u.blobs.Delete(ctx, key); err != nil {
+			errs = append(errs, fmt.Errorf("delete blob %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,260 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/blob"
+	"github.com/pavel97go/service-cars/internal/imaging"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+type imageFixture struct {
+	repo *repository.MemoryCarRepo
+	uc   usecase.ImageUsecase
+	dir  string
+	car  models.Car
+}
+
+func newImageFixture(t *testing.T, maxPerCar int) imageFixture {
+	t.Helper()
+	repo := repository.NewMemoryCarRepo()
+	c := models.Car{Brand: "Kia", Model: "Rio", Year: 2021}
+	if err := repo.InsertCar(context.Background(), &c); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	dir := t.TempDir()
+	blobs, err := blob.NewFS(dir)
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	uc := usecase.NewImageUsecase(repo, blobs, usecase.ImageConfig{
+		MaxBytes:  1 << 20,
+		MaxPerCar: maxPerCar,
+		Sizes:     []imaging.Size{{Name: "small", Max: 40}, {Name: "large", Max: 400}},
+	})
+	return imageFixture{repo: repo, uc: uc, dir: dir, car: c}
+}
+
+// countFiles возвращает число файлов в хранилище.
+func (f imageFixture) countFiles(t *testing.T) int {
+	t.Helper()
+	n := 0
+	err := filepath.WalkDir(f.dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			n++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("walk: %v", err)
+	}
+	return n
+}
+
+func pngImage(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func readImageFile(t *testing.T, file usecase.ImageFile) []byte {
+	t.Helper()
+	defer file.Body.Close()
+	data, err := io.ReadAll(file.Body)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return data
+}
+
+func TestImage_UploadAndOpen(t *testing.T) {
+	f := newImageFixture(t, 10)
+	ctx := context.Background()
+	data := pngImage(t, 200, 100)
+
+	img, err := f.uc.Upload(ctx, f.car.ID, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if img.ContentType != imaging.TypePNG || img.Width != 200 || img.Height != 100 || img.Size != int64(len(data)) {
+		t.Fatalf("unexpected image: %+v", img)
+	}
+	wantURL := "/api/v1/cars/" + f.car.ID + "/images/" + img.ID
+	if img.URL != wantURL || img.Thumbnails["small"] != wantURL+"?size=small" || len(img.Thumbnails) != 2 {
+		t.Fatalf("unexpected links: %s %v", img.URL, img.Thumbnails)
+	}
+	if n := f.countFiles(t); n != 3 {
+		t.Fatalf("stored %d files, want original and 2 thumbnails", n)
+	}
+
+	original, err := f.uc.Open(ctx, f.car.ID, img.ID, "")
+	if err != nil {
+		t.Fatalf("open original: %v", err)
+	}
+	if original.ContentType != imaging.TypePNG || !bytes.Equal(readImageFile(t, original), data) {
+		t.Fatal("original must be stored as uploaded")
+	}
+
+	small, err := f.uc.Open(ctx, f.car.ID, img.ID, "small")
+	if err != nil {
+		t.Fatalf("open thumbnail: %v", err)
+	}
+	thumb, err := jpeg.Decode(bytes.NewReader(readImageFile(t, small)))
+	if err != nil {
+		t.Fatalf("thumbnail must be JPEG: %v", err)
+	}
+	if b := thumb.Bounds(); small.ContentType != imaging.TypeJPEG || b.Dx() != 40 || b.Dy() != 20 {
+		t.Fatalf("unexpected thumbnail %s %v", small.ContentType, b)
+	}
+	// миниатюра не больше оригинала
+	large, err := f.uc.Open(ctx, f.car.ID, img.ID, "large")
+	if err != nil {
+		t.Fatalf("open thumbnail: %v", err)
+	}
+	if thumb, err = jpeg.Decode(bytes.NewReader(readImageFile(t, large))); err != nil || thumb.Bounds().Dx() != 200 {
+		t.Fatalf("large thumbnail must keep the original size, got %v %v", thumb, err)
+	}
+
+	if _, err := f.uc.Open(ctx, f.car.ID, img.ID, "huge"); !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an unknown size, got %v", err)
+	}
+	if _, err := f.uc.Open(ctx, f.car.ID, uuid.NewString(), ""); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestImage_UploadRejected(t *testing.T) {
+	f := newImageFixture(t, 1)
+	ctx := context.Background()
+
+	for name, tc := range map[string]struct {
+		carID string
+		data  []byte
+		want  error
+	}{
+		"not an image": {f.car.ID, []byte("%PDF-1.4 ..."), apperr.ErrUnsupportedMedia},
+		"broken png":   {f.car.ID, pngImage(t, 10, 10)[:40], apperr.ErrUnsupportedMedia},
+		"empty":        {f.car.ID, nil, apperr.ErrInvalidInput},
+		"too large":    {f.car.ID, make([]byte, 1<<20+1), apperr.ErrTooLarge},
+		"unknown car":  {uuid.NewString(), pngImage(t, 10, 10), apperr.ErrNotFound},
+	} {
+		if _, err := f.uc.Upload(ctx, tc.carID, tc.data); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+
+	if _, err := f.uc.Upload(ctx, f.car.ID, pngImage(t, 10, 10)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// файлы отклонённой загрузки не остаются в хранилище
+	if _, err := f.uc.Upload(ctx, f.car.ID, pngImage(t, 10, 10)); !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("expected ErrConflict over the limit, got %v", err)
+	}
+	if n := f.countFiles(t); n != 3 {
+		t.Fatalf("stored %d files, want 3 of the only image", n)
+	}
+}
+
+func TestImage_GalleryInCarResponse(t *testing.T) {
+	f := newImageFixture(t, 10)
+	ctx := context.Background()
+	cars := usecase.NewCarUsecase(f.repo, usecase.WithImages(f.repo))
+
+	a, err := f.uc.Upload(ctx, f.car.ID, pngImage(t, 10, 10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := f.uc.Upload(ctx, f.car.ID, pngImage(t, 20, 10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := f.uc.Reorder(ctx, models.ImageOrderRequest{CarID: f.car.ID, IDs: []string{b.ID, a.ID}}); err != nil {
+		t.Fatalf("reorder: %v", err)
+	}
+
+	resp, err := cars.Get(ctx, f.car.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(resp.Images) != 2 || resp.Images[0].ID != b.ID || resp.Images[1].Position != 1 {
+		t.Fatalf("unexpected gallery: %+v", resp.Images)
+	}
+	// галерея меняет версию, поэтому ETag ответа с фотографиями тоже меняется
+	if resp.Version != f.car.Version+3 {
+		t.Fatalf("version = %d, want %d", resp.Version, f.car.Version+3)
+	}
+	list, err := cars.List(ctx, models.CarFilter{})
+	if err != nil || len(list) != 1 || len(list[0].Images) != 2 {
+		t.Fatalf("list must include images, got %+v %v", list, err)
+	}
+
+	if _, err := f.uc.Reorder(ctx, models.ImageOrderRequest{CarID: f.car.ID, IDs: []string{b.ID}}); !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for a partial order, got %v", err)
+	}
+
+	if err := f.uc.Delete(ctx, f.car.ID, b.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	images, err := f.uc.List(ctx, f.car.ID)
+	if err != nil || len(images) != 1 || images[0].ID != a.ID || images[0].Position != 0 {
+		t.Fatalf("unexpected gallery after delete: %+v %v", images, err)
+	}
+	if n := f.countFiles(t); n != 3 {
+		t.Fatalf("stored %d files after delete, want 3", n)
+	}
+}
+
+func TestImage_PurgeOrphans(t *testing.T) {
+	f := newImageFixture(t, 10)
+	ctx := context.Background()
+	cars := usecase.NewCarUsecase(f.repo)
+
+	if _, err := f.uc.Upload(ctx, f.car.ID, pngImage(t, 10, 10)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cars.Delete(ctx, f.car.ID); err != nil {
+		t.Fatalf("delete car: %v", err)
+	}
+	// машина в корзине: файлы нужны для восстановления
+	if n, err := f.uc.PurgeOrphans(ctx); err != nil || n != 0 {
+		t.Fatalf("purge before trash purge = %d, %v", n, err)
+	}
+	if _, err := f.uc.List(ctx, f.car.ID); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a car in trash, got %v", err)
+	}
+
+	if _, err := cars.PurgeTrash(ctx, 0); err != nil {
+		t.Fatalf("purge trash: %v", err)
+	}
+	n, err := f.uc.PurgeOrphans(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("purge = %d, %v; want 1 image", n, err)
+	}
+	if files := f.countFiles(t); files != 0 {
+		t.Fatalf("%d files left after purge", files)
+	}
+}
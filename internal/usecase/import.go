@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/vin"
+)
+
+const (
+	defaultImportMaxRows = 100000
+	// importMaxLineBytes — предел строки NDJSON; более длинная строка прерывает импорт.
+	importMaxLineBytes = 64 << 10
+	// defaultImportRowsPage — по сколько созданных машин читать при рассылке событий.
+	defaultImportRowsPage = 500
+)
+
+// importColumns — обязательные колонки CSV; порядок в файле любой. Необязательные — vin
+// и атрибуты под теми же именами, что в выгрузке; лишние колонки (id, version) игнорируются.
+var importColumns = []string{"brand", "model", "year"}
+
+type ImportUC struct {
+	repo     repository.ImportProvider
+	maxRows  int
+	catalog  repository.CatalogProvider
+	strict   bool
+	notifier Notifier
+}
+
+// ImportOption настраивает ImportUC.
+type ImportOption func(*ImportUC)
+
+// WithImportCatalog приводит марку и модель строк к справочнику так же, как WithCatalog для POST /cars.
+func WithImportCatalog(catalog repository.CatalogProvider, strict bool) ImportOption {
+	return func(u *ImportUC) {
+		u.catalog = catalog
+		u.strict = strict
+	}
+}
+
+// WithImportNotifier сообщает о созданных импортом машинах тем же получателям, что и CarUC.
+func WithImportNotifier(n Notifier) ImportOption {
+	return func(u *ImportUC) { u.notifier = n }
+}
+
+func NewImportUsecase(repo repository.ImportProvider, maxRows int, opts ...ImportOption) ImportUsecase {
+	if maxRows <= 0 {
+		maxRows = defaultImportMaxRows
+	}
+	u := &ImportUC{repo: repo, maxRows: maxRows}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// Import разбирает файл потоково и проверяет каждую строку правилами POST /cars.
+// Невалидная строка попадает в отчёт как отклонённая; ошибки чтения, неверный заголовок
+// и превышение лимитов отменяют импорт целиком.
+func (u *ImportUC) Import(ctx context.Context, req models.ImportRequest, body io.Reader) (models.CarImport, error) {
+	var (
+		next func() (models.ImportRow, error)
+		err  error
+	)
+	switch req.Format {
+	case models.ImportFormatCSV:
+		next, err = csvRows(body)
+	case models.ImportFormatNDJSON:
+		next = ndjsonRows(body)
+	default:
+		err = fmt.Errorf("%w: unknown import format %q", apperr.ErrInvalidInput, req.Format)
+	}
+	if err != nil {
+		return models.CarImport{}, err
+	}
+	var normalizer *carNormalizer
+	if u.catalog != nil {
+		snapshot, err := loadCatalog(ctx, u.catalog)
+		if err != nil {
+			return models.CarImport{}, err
+		}
+		normalizer = &carNormalizer{catalog: snapshot, strict: u.strict}
+	}
+
+	count := 0
+	validated := func() (models.ImportRow, error) {
+		row, err := next()
+		if err != nil {
+			return models.ImportRow{}, err
+		}
+		if count++; count > u.maxRows {
+			return models.ImportRow{}, fmt.Errorf("%w: import is limited to %d rows", apperr.ErrTooLarge, u.maxRows)
+		}
+		if row.Reason == "" {
+			row.VIN = vin.Normalize(row.VIN)
+			normalizeAttributes(&row.Attributes)
+			err := validateCreate(models.CreateCarRequest{Brand: row.Brand, Model: row.Model, Year: row.Year,
+				VIN: row.VIN, CarAttributes: row.Attributes})
+			if err == nil {
+				err = normalizer.normalize(ctx, &row.Brand, &row.Model, row.Year)
+			}
+			row.Reason = importReason(err)
+		}
+		return row, nil
+	}
+
+	imp := models.CarImport{ID: req.ID, Format: req.Format, DryRun: req.DryRun}
+	if err := u.repo.ImportCars(ctx, &imp, validated); err != nil {
+		return models.CarImport{}, err
+	}
+	if u.notifier != nil && !imp.DryRun && imp.Accepted > 0 {
+		u.notifyCreated(ctx, imp.ID)
+	}
+	return imp, nil
+}
+
+// notifyCreated отправляет CarCreated по машинам, которые записало хранилище: строку с занятым
+// VIN оно отклоняет уже после валидации, а время создания выбирает само. Машины читаются
+// страницами, чтобы не держать в памяти весь файл. События идут после фиксации транзакции,
+// как у одиночных изменений; на большом импорте брокер отключит отставших подписчиков,
+// и при переподключении они получат resync.
+func (u *ImportUC) notifyCreated(ctx context.Context, importID string) {
+	for afterLine := 0; ; {
+		cars, err := u.repo.ListImportedCars(ctx, importID, afterLine, defaultImportRowsPage)
+		if err != nil || len(cars) == 0 {
+			// машины уже созданы, импорт не откатить: подписчики увидят их при следующем запросе списка
+			return
+		}
+		for _, ic := range cars {
+			u.notifier.Notify(models.CarEvent{Type: models.EventCarCreated, Car: models.NewCarResponse(ic.Car),
+				OccurredAt: time.Now().UTC()})
+		}
+		afterLine = cars[len(cars)-1].Line
+	}
+}
+
+func (u *ImportUC) Get(ctx context.Context, id string) (models.CarImport, error) {
+	imp, err := u.repo.GetImport(ctx, id)
+	if err != nil {
+		return models.CarImport{}, err
+	}
+	return *imp, nil
+}
+
+func (u *ImportUC) Rows(ctx context.Context, id string, afterLine, limit int) ([]models.ImportRow, error) {
+	return u.repo.ListImportRows(ctx, id, afterLine, limit)
+}
+
+// importReason — текст ошибки валидации без префикса apperr.
+func importReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	return strings.TrimPrefix(err.Error(), apperr.ErrInvalidInput.Error()+": ")
+}
+
+// csvRows читает заголовок сразу, чтобы неверный файл отклонялся до начала импорта.
+// Line — номер строки файла, с которой начинается запись (заголовок — строка 1).
+func csvRows(body io.Reader) (func() (models.ImportRow, error), error) {
+	r := csv.NewReader(body)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: empty file, header with %s is required", apperr.ErrInvalidInput,
+			strings.Join(importColumns, ", "))
+	}
+	var perr *csv.ParseError
+	if errors.As(err, &perr) {
+		return nil, fmt.Errorf("%w: header: %s", apperr.ErrInvalidInput, perr.Err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	width := len(header)
+	cols := make(map[string]int, width)
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", apperr.ErrInvalidInput, name)
+		}
+	}
+
+	return func() (models.ImportRow, error) {
+		rec, err := r.Read()
+		if errors.As(err, &perr) {
+			// испорченная строка отклоняется, чтение продолжается со следующей
+			return models.ImportRow{Line: perr.StartLine, Reason: perr.Err.Error()}, nil
+		}
+		if err != nil {
+			return models.ImportRow{}, err
+		}
+		line, _ := r.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		row := models.ImportRow{Line: line, Brand: field("brand"), Model: field("model"), VIN: field("vin")}
+		if len(rec) != width {
+			row.Reason = fmt.Sprintf("expected %d fields, got %d", width, len(rec))
+			return row, nil
+		}
+		if row.Year, err = strconv.Atoi(field("year")); err != nil {
+			row.Reason = "year must be an integer"
+			return row, nil
+		}
+		row.Reason = csvAttributes(field, &row.Attributes)
+		return row, nil
+	}, nil
+}
+
+// csvAttributes заполняет атрибуты из необязательных колонок; пустая ячейка — атрибут не указан.
+// Возвращает причину отклонения, если число записано неверно.
+func csvAttributes(field func(string) string, a *models.CarAttributes) string {
+	a.Color, a.BodyType, a.FuelType = field("color"), field("body_type"), field("fuel_type")
+	a.Transmission, a.Currency, a.Condition, a.Status = field("transmission"), field("currency"),
+		field("condition"), field("status")
+	for _, col := range []struct {
+		name string
+		dst  **int
+	}{{"mileage", &a.Mileage}, {"engine_volume", &a.EngineVolume}, {"engine_power", &a.EnginePower}} {
+		if v := field(col.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return col.name + " must be an integer"
+			}
+			*col.dst = &n
+		}
+	}
+	if v := field("price"); v != "" {
+		price, err := models.ParseMoney(v)
+		if err != nil {
+			return "price " + err.Error()
+		}
+		a.Price = &price
+	}
+	return ""
+}
+
+// importRecord — строка NDJSON в формате выгрузки: марка, модель, год, vin и атрибуты.
+// id и version выгрузки принимаются и игнорируются, остальные поля отклоняются как неизвестные.
+type importRecord struct {
+	ID      string `json:"id"`
+	Brand   string `json:"brand"`
+	Model   string `json:"model"`
+	Year    int    `json:"year"`
+	VIN     string `json:"vin"`
+	Version int    `json:"version"`
+	models.CarAttributes
+}
+
+// ndjsonRows читает по одному объекту importRecord на строку; пустые строки пропускаются.
+func ndjsonRows(body io.Reader) func() (models.ImportRow, error) {
+	sc := bufio.NewScanner(body)
+	sc.Buffer(make([]byte, 0, 4096), importMaxLineBytes)
+	line := 0
+	return func() (models.ImportRow, error) {
+		for sc.Scan() {
+			line++
+			data := bytes.TrimSpace(sc.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+			row := models.ImportRow{Line: line}
+			var req importRecord
+			if err := decodeStrict(data, &req); err != nil {
+				row.Reason = "invalid JSON: " + err.Error()
+				return row, nil
+			}
+			row.Brand, row.Model, row.Year, row.VIN, row.Attributes = req.Brand, req.Model, req.Year, req.VIN,
+				req.CarAttributes
+			return row, nil
+		}
+		if err := sc.Err(); errors.Is(err, bufio.ErrTooLong) {
+			return models.ImportRow{}, fmt.Errorf("%w: line %d is longer than %d bytes", apperr.ErrTooLarge,
+				line+1, importMaxLineBytes)
+		} else if err != nil {
+			return models.ImportRow{}, err
+		}
+		return models.ImportRow{}, io.EOF
+	}
+}
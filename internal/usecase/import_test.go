@@ -0,0 +1,278 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/export"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/repository/mocks"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+// drainImport имитирует хранилище: вычитывает все строки и считает итоги.
+func drainImport(rows *[]models.ImportRow) func(context.Context, *models.CarImport, func() (models.ImportRow, error)) error {
+	return func(_ context.Context, imp *models.CarImport, next func() (models.ImportRow, error)) error {
+		for {
+			row, err := next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			imp.Total++
+			if row.Reason == "" {
+				imp.Accepted++
+			} else {
+				imp.Rejected++
+			}
+			*rows = append(*rows, row)
+		}
+	}
+}
+
+func TestImport_CSV(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockImportProvider(ctrl)
+	uc := usecase.NewImportUsecase(mockRepo, 0)
+
+	var rows []models.ImportRow
+	mockRepo.EXPECT().ImportCars(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(drainImport(&rows))
+
+	body := "\ufeffYear,Brand,Model\n" +
+		"2020,BMW,Xfive\n" +
+		"abc,Toyota,Camry\n" +
+		"\n" +
+		"2019,Audi\n" +
+		"1800,Lada,Niva\n" +
+		"2018,\"Kia\",Rio\n"
+	imp, err := uc.Import(context.Background(), models.ImportRequest{Format: models.ImportFormatCSV}, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imp.Total != 5 || imp.Accepted != 2 || imp.Rejected != 3 {
+		t.Fatalf("unexpected counts: %+v", imp)
+	}
+	wantLines := []int{2, 3, 5, 6, 7}
+	for i, row := range rows {
+		if row.Line != wantLines[i] {
+			t.Fatalf("row %d: got line %d, want %d", i, row.Line, wantLines[i])
+		}
+	}
+	if rows[0].Brand != "BMW" || rows[0].Year != 2020 || rows[0].Reason != "" {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].Reason != "year must be an integer" {
+		t.Fatalf("unexpected reason: %q", rows[1].Reason)
+	}
+	if !strings.Contains(rows[2].Reason, "expected 3 fields") {
+		t.Fatalf("unexpected reason: %q", rows[2].Reason)
+	}
+	if !strings.Contains(rows[3].Reason, "Year") {
+		t.Fatalf("unexpected reason: %q", rows[3].Reason)
+	}
+}
+
+func TestImport_CSVMissingColumn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := usecase.NewImportUsecase(mocks.NewMockImportProvider(ctrl), 0)
+	_, err := uc.Import(context.Background(), models.ImportRequest{Format: models.ImportFormatCSV},
+		strings.NewReader("brand,model\nBMW,Xfive\n"))
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestImport_NDJSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockImportProvider(ctrl)
+	uc := usecase.NewImportUsecase(mockRepo, 0)
+
+	var rows []models.ImportRow
+	mockRepo.EXPECT().ImportCars(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(drainImport(&rows))
+
+	body := `{"brand":"BMW","model":"Xfive","year":2020}` + "\n" +
+		"\n" +
+		`{"brand":"BMW","model":"Xfive","year":2020,"owner":"Ivan"}` + "\n" +
+		`{"brand":"BMW",` + "\n" +
+		`{"brand":"","model":"Camry","year":2019}`
+	imp, err := uc.Import(context.Background(), models.ImportRequest{Format: models.ImportFormatNDJSON, DryRun: true},
+		strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !imp.DryRun || imp.Accepted != 1 || imp.Rejected != 3 {
+		t.Fatalf("unexpected result: %+v", imp)
+	}
+	if rows[1].Line != 3 || !strings.HasPrefix(rows[1].Reason, "invalid JSON") {
+		t.Fatalf("unknown field must be rejected: %+v", rows[1])
+	}
+	if rows[3].Line != 5 || rows[3].Model != "Camry" || rows[3].Reason == "" {
+		t.Fatalf("unexpected last row: %+v", rows[3])
+	}
+}
+
+func TestImport_RowLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockImportProvider(ctrl)
+	uc := usecase.NewImportUsecase(mockRepo, 2)
+
+	var rows []models.ImportRow
+	mockRepo.EXPECT().ImportCars(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(drainImport(&rows))
+
+	body := "brand,model,year\nBMW,Xfive,2020\nBMW,Xsix,2020\nBMW,Xseven,2020\n"
+	_, err := uc.Import(context.Background(), models.ImportRequest{Format: models.ImportFormatCSV}, strings.NewReader(body))
+	if !errors.Is(err, apperr.ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestImport_NotifiesCreatedCars(t *testing.T) {
+	repo := repository.NewMemoryCarRepo()
+	n := &recordingNotifier{}
+	uc := usecase.NewImportUsecase(repo, 0, usecase.WithImportNotifier(n))
+	body := "brand,model,year\nBMW,Xfive,2020\nLada,Niva,1800\nKia,Rio,2021\n"
+
+	if _, err := uc.Import(context.Background(), models.ImportRequest{Format: models.ImportFormatCSV, DryRun: true},
+		strings.NewReader(body)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(n.events) != 0 {
+		t.Fatalf("dry run must not notify, got %+v", n.events)
+	}
+
+	imp, err := uc.Import(context.Background(), models.ImportRequest{Format: models.ImportFormatCSV}, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imp.Accepted != 2 || len(n.events) != 2 {
+		t.Fatalf("expected 2 CarCreated events, got %d for %+v", len(n.events), imp)
+	}
+	for i, model := range []string{"Xfive", "Rio"} {
+		evt := n.events[i]
+		stored, err := repo.GetCarByID(context.Background(), evt.Car.ID)
+		if err != nil {
+			t.Fatalf("event %d must point to the created car: %v", i, err)
+		}
+		if evt.Type != models.EventCarCreated || evt.Car.Model != model || !reflect.DeepEqual(evt.Car, models.NewCarResponse(*stored)) {
+			t.Fatalf("event %d = %+v, stored %+v", i, evt, *stored)
+		}
+	}
+}
+
+func TestImport_Attributes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockImportProvider(ctrl)
+	uc := usecase.NewImportUsecase(mockRepo, 0)
+
+	var rows []models.ImportRow
+	mockRepo.EXPECT().ImportCars(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(drainImport(&rows))
+
+	body := "brand,model,year,vin,mileage,price,currency,body_type,status\n" +
+		"BMW,Xfive,2020,1m8gdm9axkp042788,42000,19999.99,rub,SUV,\n" +
+		"BMW,Xfive,2020,,many,,,,\n" +
+		"BMW,Xfive,2020,,,1.999,RUB,,\n" +
+		"BMW,Xfive,2020,,,100,,,\n" +
+		"BMW,Xfive,2020,,,,,boat,\n" +
+		"BMW,Xfive,2020,not-a-vin,,,,,\n" +
+		"BMW,Xfive,2020,,,,,,reserved\n"
+	imp, err := uc.Import(context.Background(), models.ImportRequest{Format: models.ImportFormatCSV}, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imp.Accepted != 1 || imp.Rejected != 6 {
+		t.Fatalf("unexpected counts: %+v", imp)
+	}
+	got := rows[0]
+	if got.VIN != "1M8GDM9AXKP042788" || *got.Attributes.Mileage != 42000 || *got.Attributes.Price != 1999999 ||
+		got.Attributes.Currency != "RUB" || got.Attributes.BodyType != "suv" {
+		t.Fatalf("unexpected first row: %+v", got)
+	}
+	for i, want := range []string{"mileage must be an integer", "price must be a decimal", "currency", "body_type",
+		"vin", "status"} {
+		if reason := rows[i+1].Reason; !strings.Contains(reason, want) {
+			t.Fatalf("row %d: reason %q must mention %q", i+1, reason, want)
+		}
+	}
+}
+
+// TestImport_ExportRoundTrip: файл выгрузки загружается обратно без правок и даёт те же машины.
+func TestImport_ExportRoundTrip(t *testing.T) {
+	mileage, power := 42000, 249
+	price := models.Money(1999999)
+	src := models.Car{ID: "7b1f4a7e-0000-4000-8000-000000000001", Brand: "BMW", Model: "Xfive", Year: 2020,
+		VIN: "1M8GDM9AXKP042788", Version: 3, CarAttributes: models.CarAttributes{Mileage: &mileage, Color: "black",
+			BodyType: "suv", EnginePower: &power, Price: &price, Currency: "RUB", Status: models.CarStatusSold}}
+
+	for _, format := range []string{export.FormatCSV, export.FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := export.NewWriter(format, &buf)
+			if err != nil {
+				t.Fatalf("new writer: %v", err)
+			}
+			if err := w.Write(models.NewCarResponse(src)); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			repo := repository.NewMemoryCarRepo()
+			uc := usecase.NewImportUsecase(repo, 0)
+			imp, err := uc.Import(context.Background(), models.ImportRequest{Format: format}, &buf)
+			if err != nil || imp.Accepted != 1 {
+				rows, _ := uc.Rows(context.Background(), imp.ID, 0, 0)
+				t.Fatalf("import: %+v, %v, rows %+v", imp, err, rows)
+			}
+			cars, err := repo.ListCars(context.Background())
+			if err != nil || len(cars) != 1 {
+				t.Fatalf("list: %v, %v", cars, err)
+			}
+			got := cars[0]
+			if got.VIN != src.VIN || !reflect.DeepEqual(got.CarAttributes, src.CarAttributes) {
+				t.Fatalf("imported %+v, want attributes of %+v", got, src)
+			}
+		})
+	}
+}
+
+// TestImport_NotifiesOnlyStoredRows: строку с занятым VIN отклоняет хранилище, события по ней нет.
+func TestImport_NotifiesOnlyStoredRows(t *testing.T) {
+	repo := repository.NewMemoryCarRepo()
+	if err := repo.InsertCar(context.Background(), &models.Car{Brand: "Porsche", Model: "Carrera", Year: 1996,
+		VIN: "1M8GDM9AXKP042788"}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	n := &recordingNotifier{}
+	uc := usecase.NewImportUsecase(repo, 0, usecase.WithImportNotifier(n))
+	body := "brand,model,year,vin\nBMW,Xfive,2020,1M8GDM9AXKP042788\nKia,Rio,2021,\n"
+
+	imp, err := uc.Import(context.Background(), models.ImportRequest{Format: models.ImportFormatCSV}, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imp.Accepted != 1 || len(n.events) != 1 || n.events[0].Car.Model != "Rio" {
+		t.Fatalf("expected one event for Rio, got %+v for %+v", n.events, imp)
+	}
+}
@@ -2,14 +2,119 @@ package usecase
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/pavel97go/service-cars/internal/models"
 )
 
 type CarUsecase interface {
 	Create(ctx context.Context, req models.CreateCarRequest) (models.CarResponse, error)
-	List(ctx context.Context) ([]models.CarResponse, error)
+	List(ctx context.Context, f models.CarFilter) ([]models.CarResponse, error)
 	Get(ctx context.Context, id string) (models.CarResponse, error)
+	GetByVIN(ctx context.Context, vin string) (models.CarResponse, error)
+	ListAsOf(ctx context.Context, at time.Time, f models.CarFilter) ([]models.CarResponse, error)
+	GetAsOf(ctx context.Context, id string, at time.Time) (models.CarResponse, error)
 	Update(ctx context.Context, req models.UpdateCarRequest) (models.CarResponse, error)
+	Replace(ctx context.Context, req models.ReplaceCarRequest) (models.CarResponse, error)
+	Patch(ctx context.Context, req models.JSONPatchRequest) (models.CarResponse, error)
 	Delete(ctx context.Context, id string) error
+	ListTrash(ctx context.Context) ([]models.CarResponse, error)
+	Restore(ctx context.Context, id string) (models.CarResponse, error)
+	PurgeTrash(ctx context.Context, retention time.Duration) (int64, error)
+	Batch(ctx context.Context, req models.BatchRequest) (models.BatchResult, error)
+}
+
+type AuditUsecase interface {
+	History(ctx context.Context, carID string, limit, offset int) (models.AuditPage, error)
+	Query(ctx context.Context, q models.AuditQuery) (models.AuditPage, error)
+}
+
+type WebhookUsecase interface {
+	Create(ctx context.Context, req models.CreateWebhookRequest) (models.WebhookResponse, error)
+	List(ctx context.Context) ([]models.WebhookResponse, error)
+	Get(ctx context.Context, id string) (models.WebhookResponse, error)
+	Replace(ctx context.Context, req models.ReplaceWebhookRequest) (models.WebhookResponse, error)
+	Delete(ctx context.Context, id string) error
+	Deliveries(ctx context.Context, subscriptionID string, q models.DeliveryQuery) (models.DeliveryPage, error)
+	Redeliver(ctx context.Context, subscriptionID string, deliveryID int64) (models.WebhookDelivery, error)
+}
+
+// Notifier получает события об успешных изменениях машин. Вызывается синхронно
+// после записи, поэтому реализация не должна блокироваться.
+type Notifier interface {
+	Notify(evt models.CarEvent)
+}
+
+type ImportUsecase interface {
+	Import(ctx context.Context, req models.ImportRequest, body io.Reader) (models.CarImport, error)
+	Get(ctx context.Context, id string) (models.CarImport, error)
+	Rows(ctx context.Context, id string, afterLine, limit int) ([]models.ImportRow, error)
+}
+
+type ExportUsecase interface {
+	Export(ctx context.Context, f models.CarFilter, fn func(models.CarResponse) error) error
+}
+
+type StatsUsecase interface {
+	Stats(ctx context.Context, q models.StatsQuery) (models.CarStats, error)
+}
+
+// PriceUsecase — история цен машины и оценка рыночной цены по похожим машинам.
+type PriceUsecase interface {
+	History(ctx context.Context, carID string) ([]models.PricePoint, error)
+	Valuate(ctx context.Context, q models.ValuationQuery) (models.Valuation, error)
+}
+
+// ReservationUsecase — брони машин: машина в продаже переходит в reserved, затем бронь продаётся
+// (sold), снимается или истекает (машина снова available).
+type ReservationUsecase interface {
+	Reserve(ctx context.Context, req models.ReservationRequest) (models.Reservation, error)
+	List(ctx context.Context, carID string) ([]models.Reservation, error)
+	Release(ctx context.Context, carID, id string) (models.Reservation, error)
+	Sell(ctx context.Context, carID, id string) (models.Reservation, error)
+	ExpireReservations(ctx context.Context) (int64, error)
+}
+
+// ImageUsecase — фотографии машин: загрузка с миниатюрами, порядок галереи и очистка файлов
+// окончательно удалённых машин.
+type ImageUsecase interface {
+	Upload(ctx context.Context, carID string, data []byte) (models.CarImageResponse, error)
+	List(ctx context.Context, carID string) ([]models.CarImageResponse, error)
+	Open(ctx context.Context, carID, id, size string) (ImageFile, error)
+	Delete(ctx context.Context, carID, id string) error
+	Reorder(ctx context.Context, req models.ImageOrderRequest) ([]models.CarImageResponse, error)
+	PurgeOrphans(ctx context.Context) (int64, error)
+}
+
+type JobUsecase interface {
+	SubmitImport(ctx context.Context, req models.ImportRequest, body io.Reader) (models.JobResponse, error)
+	SubmitExport(ctx context.Context, req models.ExportJobParams) (models.JobResponse, error)
+	Get(ctx context.Context, id string) (models.JobResponse, error)
+	Cancel(ctx context.Context, id string) (models.JobResponse, error)
+	Result(ctx context.Context, id string) (JobFile, error)
+}
+
+type IdempotencyUsecase interface {
+	Begin(ctx context.Context, req models.IdempotentRequest) (*models.StoredResponse, error)
+	Complete(ctx context.Context, req models.IdempotentRequest, resp models.StoredResponse) error
+	Release(ctx context.Context, req models.IdempotentRequest) error
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// CatalogUsecase — администрирование справочника марок и моделей. Модели адресуются
+// внутри марки: модель другой марки — apperr.ErrNotFound.
+type CatalogUsecase interface {
+	CreateBrand(ctx context.Context, req models.BrandRequest) (models.Brand, error)
+	ListBrands(ctx context.Context) ([]models.Brand, error)
+	GetBrand(ctx context.Context, id string) (models.Brand, error)
+	ReplaceBrand(ctx context.Context, req models.BrandRequest) (models.Brand, error)
+	SetBrandYears(ctx context.Context, req models.YearRangeRequest) (models.Brand, error)
+	DeleteBrand(ctx context.Context, id string) error
+	CreateModel(ctx context.Context, req models.CarModelRequest) (models.CarModel, error)
+	ListModels(ctx context.Context, brandID string) ([]models.CarModel, error)
+	GetModel(ctx context.Context, brandID, id string) (models.CarModel, error)
+	ReplaceModel(ctx context.Context, req models.CarModelRequest) (models.CarModel, error)
+	SetModelYears(ctx context.Context, req models.YearRangeRequest) (models.CarModel, error)
+	DeleteModel(ctx context.Context, brandID, id string) error
 }
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/google/uuid"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/export"
+	"github.com/pavel97go/service-cars/internal/jobs"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// JobFile — файл результата задачи; Body закрывает вызывающий.
+type JobFile struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Body        io.ReadCloser
+}
+
+type JobUC struct {
+	repo  repository.JobProvider
+	files *jobs.Files
+}
+
+func NewJobUsecase(repo repository.JobProvider, files *jobs.Files) JobUsecase {
+	return &JobUC{repo: repo, files: files}
+}
+
+// SubmitImport сохраняет загружаемый файл и ставит импорт в очередь. Файл читается
+// целиком до ответа клиенту, разбор и проверка строк выполняются уже в фоне.
+func (u *JobUC) SubmitImport(ctx context.Context, req models.ImportRequest, body io.Reader) (models.JobResponse, error) {
+	if req.Format != models.ImportFormatCSV && req.Format != models.ImportFormatNDJSON {
+		return models.JobResponse{}, fmt.Errorf("%w: unknown import format %q", apperr.ErrInvalidInput, req.Format)
+	}
+	params, err := json.Marshal(models.ImportJobParams{Format: req.Format, DryRun: req.DryRun})
+	if err != nil {
+		return models.JobResponse{}, err
+	}
+	job := models.Job{ID: uuid.NewString(), Kind: models.JobKindImport, Params: params}
+	if _, err := u.files.SaveInput(job.ID, body); err != nil {
+		return models.JobResponse{}, err
+	}
+	if err := u.repo.CreateJob(ctx, &job); err != nil {
+		_ = u.files.RemoveInput(job.ID)
+		return models.JobResponse{}, err
+	}
+	return models.NewJobResponse(job), nil
+}
+
+func (u *JobUC) SubmitExport(ctx context.Context, req models.ExportJobParams) (models.JobResponse, error) {
+	if _, ok := export.ContentType(req.Format); !ok {
+		return models.JobResponse{}, fmt.Errorf("%w: unknown export format %q", apperr.ErrInvalidInput, req.Format)
+	}
+	params, err := json.Marshal(req)
+	if err != nil {
+		return models.JobResponse{}, err
+	}
+	job := models.Job{Kind: models.JobKindExport, Params: params}
+	if err := u.repo.CreateJob(ctx, &job); err != nil {
+		return models.JobResponse{}, err
+	}
+	return models.NewJobResponse(job), nil
+}
+
+func (u *JobUC) Get(ctx context.Context, id string) (models.JobResponse, error) {
+	job, err := u.repo.GetJob(ctx, id)
+	if err != nil {
+		return models.JobResponse{}, err
+	}
+	return models.NewJobResponse(*job), nil
+}
+
+// Cancel отменяет задачу из очереди сразу; выполняемая остановится при следующем
+// продлении аренды воркером.
+func (u *JobUC) Cancel(ctx context.Context, id string) (models.JobResponse, error) {
+	job, err := u.repo.CancelJob(ctx, id)
+	if errors.Is(err, apperr.ErrConflict) {
+		return models.JobResponse{}, fmt.Errorf("%w: job is already finished", apperr.ErrConflict)
+	}
+	if err != nil {
+		return models.JobResponse{}, err
+	}
+	return models.NewJobResponse(*job), nil
+}
+
+// Result открывает файл успешно завершённой выгрузки. apperr.ErrConflict — задача
+// ещё не завершена или завершилась неуспешно.
+func (u *JobUC) Result(ctx context.Context, id string) (JobFile, error) {
+	job, err := u.repo.GetJob(ctx, id)
+	if err != nil {
+		return JobFile{}, err
+	}
+	if job.Kind != models.JobKindExport {
+		return JobFile{}, fmt.Errorf("%w: %s job has no result file", apperr.ErrNotFound, job.Kind)
+	}
+	if job.Status != models.JobSucceeded {
+		return JobFile{}, fmt.Errorf("%w: job is %s", apperr.ErrConflict, job.Status)
+	}
+	var res models.ExportJobResult
+	if err := json.Unmarshal(job.Result, &res); err != nil {
+		return JobFile{}, err
+	}
+	contentType, _ := export.ContentType(res.Format)
+	f, err := u.files.OpenOutput(id)
+	if errors.Is(err, fs.ErrNotExist) {
+		return JobFile{}, fmt.Errorf("%w: result file is gone", apperr.ErrNotFound)
+	}
+	if err != nil {
+		return JobFile{}, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return JobFile{}, err
+	}
+	return JobFile{Name: res.Filename, ContentType: contentType, Size: info.Size(), Body: f}, nil
+}
+
+// ImportJob выполняет задачи импорта. ID импорта совпадает с ID задачи: если воркер упал
+// после фиксации импорта, повторный запуск находит готовый импорт и не создаёт машины дважды.
+// Прогресс — прочитанные байты файла из total.
+func ImportJob(imports ImportUsecase, files *jobs.Files) jobs.Handler {
+	return func(ctx context.Context, job models.Job, progress *jobs.Progress) (json.RawMessage, error) {
+		imp, err := imports.Get(ctx, job.ID)
+		if errors.Is(err, apperr.ErrNotFound) {
+			imp, err = runImportJob(ctx, imports, files, job, progress)
+		}
+		if errors.Is(err, apperr.ErrConflict) {
+			imp, err = imports.Get(ctx, job.ID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(imp)
+	}
+}
+
+func runImportJob(ctx context.Context, imports ImportUsecase, files *jobs.Files, job models.Job,
+	progress *jobs.Progress) (models.CarImport, error) {
+	var params models.ImportJobParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return models.CarImport{}, fmt.Errorf("job params: %w", err)
+	}
+	f, err := files.OpenInput(job.ID)
+	if err != nil {
+		return models.CarImport{}, err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil {
+		progress.SetTotal(info.Size())
+	}
+	progress.SetProcessed(0)
+
+	req := models.ImportRequest{ID: job.ID, Format: params.Format, DryRun: params.DryRun}
+	return imports.Import(ctx, req, &progressReader{r: f, progress: progress})
+}
+
+// ExportJob пишет выгрузку в файл задачи; перезапуск переписывает файл с начала.
+// Прогресс — число выгруженных машин, total неизвестен.
+func ExportJob(exports ExportUsecase, files *jobs.Files) jobs.Handler {
+	return func(ctx context.Context, job models.Job, progress *jobs.Progress) (json.RawMessage, error) {
+		var params models.ExportJobParams
+		if err := json.Unmarshal(job.Params, &params); err != nil {
+			return nil, fmt.Errorf("job params: %w", err)
+		}
+		f, err := files.CreateOutput(job.ID)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		w := bufio.NewWriterSize(f, 64<<10)
+		out, err := export.NewWriter(params.Format, w)
+		if err != nil {
+			return nil, err
+		}
+
+		progress.SetProcessed(0)
+		var rows int64
+		err = exports.Export(ctx, params.Filter, func(car models.CarResponse) error {
+			if err := out.Write(car); err != nil {
+				return err
+			}
+			rows++
+			progress.Add(1)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := out.Close(); err != nil {
+			return nil, err
+		}
+		if err := w.Flush(); err != nil {
+			return nil, err
+		}
+		if err := f.Sync(); err != nil {
+			return nil, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(models.ExportJobResult{
+			Format:   params.Format,
+			Filename: fmt.Sprintf("cars-%s.%s", job.CreatedAt.UTC().Format("20060102-150405"), params.Format),
+			Rows:     rows,
+			Bytes:    info.Size(),
+		})
+	}
+}
+
+type progressReader struct {
+	r        io.Reader
+	progress *jobs.Progress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.progress.Add(int64(n))
+	return n, err
+}
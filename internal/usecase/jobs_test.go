@@ -0,0 +1,213 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/jobs"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository/mocks"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+func newFiles(t *testing.T) *jobs.Files {
+	t.Helper()
+	files, err := jobs.NewFiles(t.TempDir())
+	if err != nil {
+		t.Fatalf("files: %v", err)
+	}
+	return files
+}
+
+func TestSubmitImport_SavesFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockJobProvider(ctrl)
+	files := newFiles(t)
+	uc := usecase.NewJobUsecase(mockRepo, files)
+
+	mockRepo.EXPECT().CreateJob(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, j *models.Job) error {
+		f, err := files.OpenInput(j.ID)
+		if err != nil {
+			t.Fatalf("file must be saved before the job is queued: %v", err)
+		}
+		defer f.Close()
+		j.Status = models.JobQueued
+		return nil
+	})
+
+	resp, err := uc.SubmitImport(context.Background(),
+		models.ImportRequest{Format: models.ImportFormatCSV, DryRun: true}, strings.NewReader("brand,model,year\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Kind != models.JobKindImport || resp.Status != models.JobQueued {
+		t.Fatalf("unexpected job: %+v", resp)
+	}
+}
+
+func TestSubmitImport_QueueErrorRemovesFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockJobProvider(ctrl)
+	files := newFiles(t)
+	uc := usecase.NewJobUsecase(mockRepo, files)
+
+	var id string
+	mockRepo.EXPECT().CreateJob(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, j *models.Job) error {
+		id = j.ID
+		return errors.New("db down")
+	})
+
+	_, err := uc.SubmitImport(context.Background(), models.ImportRequest{Format: models.ImportFormatNDJSON},
+		strings.NewReader("{}\n"))
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, err := files.OpenInput(id); err == nil {
+		t.Fatalf("input file must be removed")
+	}
+}
+
+func TestSubmitExport_UnknownFormat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := usecase.NewJobUsecase(mocks.NewMockJobProvider(ctrl), newFiles(t))
+	_, err := uc.SubmitExport(context.Background(), models.ExportJobParams{Format: "pdf"})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestImportJob_AlreadyImported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockImportProvider(ctrl)
+	job := models.Job{ID: "1b9d6bcd-bbfd-4b2d-9b5d-ab8dfbbd4bed", Kind: models.JobKindImport}
+	// воркер упал после фиксации импорта: повторный запуск не читает файл и не импортирует заново
+	mockRepo.EXPECT().GetImport(gomock.Any(), job.ID).Return(&models.CarImport{ID: job.ID, Total: 5, Accepted: 5}, nil)
+
+	run := usecase.ImportJob(usecase.NewImportUsecase(mockRepo, 0), newFiles(t))
+	result, err := run(context.Background(), job, &jobs.Progress{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var imp models.CarImport
+	if err := json.Unmarshal(result, &imp); err != nil || imp.Accepted != 5 {
+		t.Fatalf("unexpected result %s: %v", result, err)
+	}
+}
+
+func TestImportJob_RunsWithJobID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockImportProvider(ctrl)
+	files := newFiles(t)
+	job := models.Job{ID: "1b9d6bcd-bbfd-4b2d-9b5d-ab8dfbbd4bee", Kind: models.JobKindImport,
+		Params: json.RawMessage(`{"format":"csv","dry_run":true}`)}
+	if _, err := files.SaveInput(job.ID, strings.NewReader("brand,model,year\nBMW,Xfive,2020\n")); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	var rows []models.ImportRow
+	mockRepo.EXPECT().GetImport(gomock.Any(), job.ID).Return(nil, apperr.ErrNotFound)
+	mockRepo.EXPECT().ImportCars(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, imp *models.CarImport, next func() (models.ImportRow, error)) error {
+			if imp.ID != job.ID || !imp.DryRun {
+				t.Fatalf("unexpected import: %+v", imp)
+			}
+			return drainImport(&rows)(ctx, imp, next)
+		})
+
+	run := usecase.ImportJob(usecase.NewImportUsecase(mockRepo, 0), files)
+	if _, err := run(context.Background(), job, &jobs.Progress{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+}
+
+func TestExportJob_WritesFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExport := mocks.NewMockExportProvider(ctrl)
+	mockJobs := mocks.NewMockJobProvider(ctrl)
+	files := newFiles(t)
+	job := models.Job{ID: "1b9d6bcd-bbfd-4b2d-9b5d-ab8dfbbd4bef", Kind: models.JobKindExport,
+		Params: json.RawMessage(`{"format":"ndjson","filter":{"brand":"BMW"}}`)}
+
+	mockExport.EXPECT().StreamCars(gomock.Any(), models.CarFilter{Brand: "BMW"}, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ models.CarFilter, fn func(models.Car) error) error {
+			for _, m := range []string{"Xfive", "Xsix"} {
+				if err := fn(models.Car{ID: "id-" + m, Brand: "BMW", Model: m, Year: 2020, Version: 1}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	progress := &jobs.Progress{}
+	result, err := usecase.ExportJob(usecase.NewExportUsecase(mockExport), files)(context.Background(), job, progress)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var res models.ExportJobResult
+	if err := json.Unmarshal(result, &res); err != nil {
+		t.Fatalf("result: %v", err)
+	}
+	if res.Rows != 2 || res.Format != "ndjson" || res.Bytes == 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	// файл отдаётся через Result, когда задача завершена
+	done := job
+	done.Status = models.JobSucceeded
+	done.Result = result
+	mockJobs.EXPECT().GetJob(gomock.Any(), job.ID).Return(&done, nil)
+	file, err := usecase.NewJobUsecase(mockJobs, files).Result(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("result file: %v", err)
+	}
+	defer file.Body.Close()
+	data, _ := io.ReadAll(file.Body)
+	if n := strings.Count(string(data), "\n"); n != 2 || int64(len(data)) != file.Size {
+		t.Fatalf("unexpected file (%d lines): %s", n, data)
+	}
+	if file.ContentType != "application/x-ndjson" {
+		t.Fatalf("unexpected content type %q", file.ContentType)
+	}
+}
+
+func TestJobResult_NotReady(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockJobProvider(ctrl)
+	uc := usecase.NewJobUsecase(mockRepo, newFiles(t))
+
+	running := models.Job{ID: "1b9d6bcd-bbfd-4b2d-9b5d-ab8dfbbd4bf0", Kind: models.JobKindExport, Status: models.JobRunning}
+	mockRepo.EXPECT().GetJob(gomock.Any(), running.ID).Return(&running, nil)
+	if _, err := uc.Result(context.Background(), running.ID); !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	imp := models.Job{ID: "1b9d6bcd-bbfd-4b2d-9b5d-ab8dfbbd4bf1", Kind: models.JobKindImport, Status: models.JobSucceeded}
+	mockRepo.EXPECT().GetJob(gomock.Any(), imp.ID).Return(&imp, nil)
+	if _, err := uc.Result(context.Background(), imp.ID); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
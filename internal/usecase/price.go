@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/valuation"
+)
+
+// valuationYearSpan — похожими считаются машины той же марки и модели не дальше стольких лет выпуска.
+const valuationYearSpan = 3
+
+// PriceStore — хранилище для PriceUC: машина по ID, история цен и выборка похожих машин.
+type PriceStore interface {
+	GetCarByID(ctx context.Context, id string) (*models.Car, error)
+	repository.PriceProvider
+	repository.ExportProvider
+}
+
+type PriceUC struct {
+	repo PriceStore
+}
+
+func NewPriceUsecase(repo PriceStore) PriceUsecase {
+	return &PriceUC{repo: repo}
+}
+
+// History возвращает историю цены машины вне корзины, последние изменения первыми.
+func (u *PriceUC) History(ctx context.Context, carID string) ([]models.PricePoint, error) {
+	if _, err := u.repo.GetCarByID(ctx, carID); err != nil {
+		return nil, err
+	}
+	return u.repo.ListPrices(ctx, carID)
+}
+
+// Valuate оценивает цену по машинам той же марки и модели с ценой в одной валюте и годом
+// выпуска в пределах valuationYearSpan; чем ближе год и пробег, тем больше вес машины.
+// Без q.Currency берётся валюта, в которой таких машин больше всего. Если похожих машин
+// меньше valuation.MinComparables, возвращает apperr.ErrNotFound.
+func (u *PriceUC) Valuate(ctx context.Context, q models.ValuationQuery) (models.Valuation, error) {
+	q.Currency = strings.ToUpper(q.Currency)
+	if err := models.ValidateStruct(q); err != nil {
+		return models.Valuation{}, err
+	}
+	if q.Currency != "" && !slices.Contains(models.Currencies, q.Currency) {
+		return models.Valuation{}, fmt.Errorf("%w: currency must be one of %s", apperr.ErrInvalidInput, strings.Join(models.Currencies, ", "))
+	}
+
+	filter := models.CarFilter{
+		Brand:    q.Brand,
+		Model:    q.Model,
+		YearFrom: q.Year - valuationYearSpan,
+		YearTo:   q.Year + valuationYearSpan,
+		Currency: q.Currency,
+	}
+	byCurrency := map[string][]valuation.Car{}
+	err := u.repo.StreamCars(ctx, filter, func(c models.Car) error {
+		if c.Price != nil {
+			byCurrency[c.Currency] = append(byCurrency[c.Currency], valuation.Car{Year: c.Year, Mileage: c.Mileage, Price: *c.Price})
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Valuation{}, err
+	}
+	currency := q.Currency
+	if currency == "" {
+		for cur, comps := range byCurrency {
+			best := len(byCurrency[currency])
+			if len(comps) > best || len(comps) == best && cur < currency {
+				currency = cur
+			}
+		}
+	}
+
+	est, err := valuation.Compute(valuation.Car{Year: q.Year, Mileage: q.Mileage}, byCurrency[currency])
+	if errors.Is(err, valuation.ErrTooFewComparables) {
+		return models.Valuation{}, fmt.Errorf("%w: fewer than %d comparable cars with a price", apperr.ErrNotFound, valuation.MinComparables)
+	}
+	if err != nil {
+		return models.Valuation{}, err
+	}
+	return models.Valuation{
+		Brand:       q.Brand,
+		Model:       q.Model,
+		Year:        q.Year,
+		Mileage:     q.Mileage,
+		Currency:    currency,
+		Comparables: est.Comparables,
+		Median:      est.Median,
+		P10:         est.P10,
+		P25:         est.P25,
+		P75:         est.P75,
+		P90:         est.P90,
+		Min:         est.Min,
+		Max:         est.Max,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+func insertPriced(t *testing.T, repo *repository.MemoryCarRepo, model string, year int, price models.Money, currency string) models.Car {
+	t.Helper()
+	c := models.Car{Brand: "Toyota", Model: model, Year: year,
+		CarAttributes: models.CarAttributes{Price: &price, Currency: currency}}
+	if err := repo.InsertCar(context.Background(), &c); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	return c
+}
+
+func TestPriceHistory(t *testing.T) {
+	repo := repository.NewMemoryCarRepo()
+	ctx := context.Background()
+	c := insertPriced(t, repo, "Camry", 2018, 2000000, "RUB")
+	c.Price = moneyPtr(1900000)
+	if err := repo.UpdateCar(ctx, &c); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	uc := usecase.NewPriceUsecase(repo)
+
+	points, err := uc.History(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(points) != 2 || *points[0].Price != 1900000 || *points[1].Price != 2000000 {
+		t.Fatalf("unexpected history: %+v", points)
+	}
+
+	if err := repo.DeleteByID(ctx, c.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := uc.History(ctx, c.ID); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a car in trash, got %v", err)
+	}
+}
+
+func TestValuate(t *testing.T) {
+	repo := repository.NewMemoryCarRepo()
+	for i, price := range []models.Money{1800000, 2000000, 2200000} {
+		insertPriced(t, repo, "Camry", 2017+i, price, "RUB")
+	}
+	insertPriced(t, repo, "Camry", 2018, 25000, "USD")
+	insertPriced(t, repo, "Camry", 2010, 100000, "RUB")  // слишком старая
+	insertPriced(t, repo, "Corolla", 2018, 50000, "RUB") // другая модель
+	uc := usecase.NewPriceUsecase(repo)
+
+	got, err := uc.Valuate(context.Background(), models.ValuationQuery{Brand: "toyota", Model: "camry", Year: 2018})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Currency != "RUB" || got.Comparables != 3 || got.Median != 2000000 || got.Min != 1800000 || got.Max != 2200000 {
+		t.Fatalf("unexpected valuation: %+v", got)
+	}
+
+	_, err = uc.Valuate(context.Background(), models.ValuationQuery{Brand: "Toyota", Model: "Camry", Year: 2018, Currency: "usd"})
+	if !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound with one comparable, got %v", err)
+	}
+	_, err = uc.Valuate(context.Background(), models.ValuationQuery{Brand: "Toyota", Model: "Camry", Year: 2018, Currency: "XXX"})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for unknown currency, got %v", err)
+	}
+	_, err = uc.Valuate(context.Background(), models.ValuationQuery{Brand: "Toyota", Year: 2018})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput without model, got %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// expireBatchSize — сколько истёкших броней снимает один проход ExpireReservations.
+const expireBatchSize = 100
+
+// statusTransitions — допустимые смены статуса машины: available → reserved → sold или
+// обратно в available; проданную машину можно вернуть в продажу.
+var statusTransitions = map[string][]string{
+	models.CarStatusAvailable: {models.CarStatusReserved, models.CarStatusSold},
+	models.CarStatusReserved:  {models.CarStatusAvailable, models.CarStatusSold},
+	models.CarStatusSold:      {models.CarStatusAvailable},
+}
+
+// setStatus переводит машину в статус to, если переход допустим, иначе apperr.ErrConflict.
+func setStatus(car *models.Car, to string) error {
+	if car.Status != to && !slices.Contains(statusTransitions[car.Status], to) {
+		return fmt.Errorf("%w: car status cannot change from %s to %s", apperr.ErrConflict, car.Status, to)
+	}
+	car.Status = to
+	return nil
+}
+
+// errNotActive — бронь, которую закрывают, не активная бронь машины; причину уточняет closeReservation.
+var errNotActive = errors.New("reservation is not active")
+
+// ReservationStore — хранилище для ReservationUC: машина по ID и брони.
+type ReservationStore interface {
+	GetCarByID(ctx context.Context, id string) (*models.Car, error)
+	repository.ReservationProvider
+}
+
+type ReservationUC struct {
+	repo     ReservationStore
+	ttl      time.Duration
+	maxTTL   time.Duration
+	notifier Notifier
+}
+
+// ReservationOption настраивает ReservationUC.
+type ReservationOption func(*ReservationUC)
+
+// WithReservationNotifier сообщает о смене статуса машины тем же получателям, что и CarUC.
+func WithReservationNotifier(n Notifier) ReservationOption {
+	return func(u *ReservationUC) { u.notifier = n }
+}
+
+// NewReservationUsecase: ttl — срок брони по умолчанию, maxTTL — наибольший срок, который можно запросить.
+func NewReservationUsecase(repo ReservationStore, ttl, maxTTL time.Duration, opts ...ReservationOption) ReservationUsecase {
+	u := &ReservationUC{repo: repo, ttl: ttl, maxTTL: max(maxTTL, ttl)}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// Reserve бронирует машину в продаже на req.TTLSeconds (0 — срок по умолчанию). Машина переходит
+// в reserved; apperr.ErrConflict, если она уже забронирована или продана.
+func (u *ReservationUC) Reserve(ctx context.Context, req models.ReservationRequest) (models.Reservation, error) {
+	if err := models.ValidateStruct(req); err != nil {
+		return models.Reservation{}, err
+	}
+	ttl := u.ttl
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl > u.maxTTL {
+		return models.Reservation{}, fmt.Errorf("%w: ttl_seconds must be <= %d", apperr.ErrInvalidInput, int(u.maxTTL.Seconds()))
+	}
+
+	expiresAt := time.Now().UTC().Add(ttl)
+	res, car, err := u.repo.ChangeReservation(ctx, req.CarID, func(car *models.Car, active *models.Reservation) (*models.Reservation, error) {
+		if car.DeletedAt != nil {
+			return nil, apperr.ErrNotFound
+		}
+		if active != nil {
+			return nil, fmt.Errorf("%w: car is already reserved until %s", apperr.ErrConflict, active.ExpiresAt.Format(time.RFC3339))
+		}
+		if err := setStatus(car, models.CarStatusReserved); err != nil {
+			return nil, err
+		}
+		return &models.Reservation{CarID: car.ID, Customer: req.Customer, Note: req.Note,
+			Status: models.ReservationActive, ExpiresAt: expiresAt}, nil
+	})
+	if err != nil {
+		return models.Reservation{}, err
+	}
+	u.notify(*car)
+	return *res, nil
+}
+
+// List возвращает брони машины вне корзины, новые первыми.
+func (u *ReservationUC) List(ctx context.Context, carID string) ([]models.Reservation, error) {
+	if _, err := u.repo.GetCarByID(ctx, carID); err != nil {
+		return nil, err
+	}
+	return u.repo.ListReservations(ctx, carID)
+}
+
+// Release снимает активную бронь, машина возвращается в продажу.
+func (u *ReservationUC) Release(ctx context.Context, carID, id string) (models.Reservation, error) {
+	return u.closeReservation(ctx, carID, id, models.ReservationReleased)
+}
+
+// Sell закрывает активную бронь продажей, машина переходит в sold. Истёкшую бронь продать нельзя,
+// даже если её ещё не снял фоновый процесс.
+func (u *ReservationUC) Sell(ctx context.Context, carID, id string) (models.Reservation, error) {
+	return u.closeReservation(ctx, carID, id, models.ReservationSold)
+}
+
+func (u *ReservationUC) closeReservation(ctx context.Context, carID, id, status string) (models.Reservation, error) {
+	now := time.Now().UTC()
+	res, car, err := u.repo.ChangeReservation(ctx, carID, func(car *models.Car, active *models.Reservation) (*models.Reservation, error) {
+		if car.DeletedAt != nil {
+			return nil, apperr.ErrNotFound
+		}
+		if active == nil || active.ID != id {
+			return nil, errNotActive
+		}
+		if status == models.ReservationSold && active.ExpiresAt.Before(now) {
+			return nil, fmt.Errorf("%w: reservation expired at %s", apperr.ErrConflict, active.ExpiresAt.Format(time.RFC3339))
+		}
+		if err := closeActive(car, active, status, now); err != nil {
+			return nil, err
+		}
+		return active, nil
+	})
+	if errors.Is(err, errNotActive) {
+		return models.Reservation{}, u.notActiveError(ctx, carID, id)
+	}
+	if err != nil {
+		return models.Reservation{}, err
+	}
+	u.notify(*car)
+	return *res, nil
+}
+
+// closeActive переводит активную бронь в status и машину — в соответствующий статус.
+// Снятая или истёкшая бронь возвращает в продажу, только если машина всё ещё reserved.
+func closeActive(car *models.Car, active *models.Reservation, status string, now time.Time) error {
+	switch {
+	case status == models.ReservationSold:
+		if err := setStatus(car, models.CarStatusSold); err != nil {
+			return err
+		}
+	case car.Status == models.CarStatusReserved:
+		if err := setStatus(car, models.CarStatusAvailable); err != nil {
+			return err
+		}
+	}
+	active.Status = status
+	active.ClosedAt = &now
+	return nil
+}
+
+// notActiveError — apperr.ErrNotFound, если у машины нет такой брони, иначе apperr.ErrConflict.
+func (u *ReservationUC) notActiveError(ctx context.Context, carID, id string) error {
+	res, err := u.repo.GetReservation(ctx, id)
+	if errors.Is(err, apperr.ErrNotFound) || err == nil && res.CarID != carID {
+		return apperr.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: reservation is already %s", apperr.ErrConflict, res.Status)
+}
+
+// ExpireReservations снимает брони, срок которых истёк, и возвращает их число. Бронь,
+// которую за это время закрыли или продлили, пропускается.
+func (u *ReservationUC) ExpireReservations(ctx context.Context) (int64, error) {
+	var n int64
+	for {
+		now := time.Now().UTC()
+		expired, err := u.repo.ExpiredReservations(ctx, now, expireBatchSize)
+		if err != nil {
+			return n, err
+		}
+		for _, res := range expired {
+			closed, car, err := u.repo.ChangeReservation(ctx, res.CarID, func(car *models.Car, active *models.Reservation) (*models.Reservation, error) {
+				if active == nil || active.ID != res.ID || !active.ExpiresAt.Before(now) {
+					return nil, nil
+				}
+				if err := closeActive(car, active, models.ReservationExpired, now); err != nil {
+					return nil, err
+				}
+				return active, nil
+			})
+			if err != nil {
+				return n, err
+			}
+			if closed != nil {
+				n++
+				u.notify(*car)
+			}
+		}
+		if len(expired) < expireBatchSize {
+			return n, nil
+		}
+	}
+}
+
+func (u *ReservationUC) notify(car models.Car) {
+	if u.notifier == nil {
+		return
+	}
+	u.notifier.Notify(models.CarEvent{Type: models.EventCarUpdated, Car: models.NewCarResponse(car), OccurredAt: time.Now().UTC()})
+}
@@ -0,0 +1,201 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+func newReservationFixture(t *testing.T, ttl time.Duration) (*repository.MemoryCarRepo, usecase.ReservationUsecase, models.Car) {
+	t.Helper()
+	repo := repository.NewMemoryCarRepo()
+	c := models.Car{Brand: "Kia", Model: "Rio", Year: 2021}
+	if err := repo.InsertCar(context.Background(), &c); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	return repo, usecase.NewReservationUsecase(repo, ttl, time.Hour), c
+}
+
+func carStatus(t *testing.T, repo *repository.MemoryCarRepo, id string) string {
+	t.Helper()
+	c, err := repo.GetCarByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("get car: %v", err)
+	}
+	return c.Status
+}
+
+func TestReservation_ReserveAndSell(t *testing.T) {
+	repo, uc, c := newReservationFixture(t, time.Minute)
+	ctx := context.Background()
+
+	res, err := uc.Reserve(ctx, models.ReservationRequest{CarID: c.ID, Customer: "Ivan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != models.ReservationActive || time.Until(res.ExpiresAt) > time.Minute {
+		t.Fatalf("unexpected reservation: %+v", res)
+	}
+	if got := carStatus(t, repo, c.ID); got != models.CarStatusReserved {
+		t.Fatalf("car status = %q, want reserved", got)
+	}
+
+	_, err = uc.Reserve(ctx, models.ReservationRequest{CarID: c.ID, Customer: "Petr"})
+	if !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a second reservation, got %v", err)
+	}
+
+	sold, err := uc.Sell(ctx, c.ID, res.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sold.Status != models.ReservationSold || sold.ClosedAt == nil {
+		t.Fatalf("unexpected reservation: %+v", sold)
+	}
+	if got := carStatus(t, repo, c.ID); got != models.CarStatusSold {
+		t.Fatalf("car status = %q, want sold", got)
+	}
+
+	if _, err := uc.Release(ctx, c.ID, res.ID); !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a closed reservation, got %v", err)
+	}
+	if _, err := uc.Reserve(ctx, models.ReservationRequest{CarID: c.ID, Customer: "Petr"}); !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a sold car, got %v", err)
+	}
+}
+
+func TestReservation_Release(t *testing.T) {
+	repo, uc, c := newReservationFixture(t, time.Minute)
+	ctx := context.Background()
+	res, err := uc.Reserve(ctx, models.ReservationRequest{CarID: c.ID, Customer: "Ivan"})
+	if err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+
+	if _, err := uc.Release(ctx, c.ID, "4f1c2a9e-8a0b-4c5d-9e6f-0123456789ab"); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown reservation, got %v", err)
+	}
+	released, err := uc.Release(ctx, c.ID, res.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if released.Status != models.ReservationReleased {
+		t.Fatalf("unexpected reservation: %+v", released)
+	}
+	if got := carStatus(t, repo, c.ID); got != models.CarStatusAvailable {
+		t.Fatalf("car status = %q, want available", got)
+	}
+
+	list, err := uc.List(ctx, c.ID)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("unexpected list %+v, err %v", list, err)
+	}
+}
+
+func TestReservation_TTL(t *testing.T) {
+	_, uc, c := newReservationFixture(t, time.Minute)
+	_, err := uc.Reserve(context.Background(), models.ReservationRequest{CarID: c.ID, Customer: "Ivan", TTLSeconds: 7200})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput above max ttl, got %v", err)
+	}
+	_, err = uc.Reserve(context.Background(), models.ReservationRequest{CarID: c.ID})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput without customer, got %v", err)
+	}
+}
+
+func TestReservation_Expire(t *testing.T) {
+	repo, uc, c := newReservationFixture(t, time.Millisecond)
+	ctx := context.Background()
+	res, err := uc.Reserve(ctx, models.ReservationRequest{CarID: c.ID, Customer: "Ivan"})
+	if err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := uc.Sell(ctx, c.ID, res.ID); !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("expected ErrConflict for an expired reservation, got %v", err)
+	}
+	n, err := uc.ExpireReservations(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("expired %d, err %v", n, err)
+	}
+	if got := carStatus(t, repo, c.ID); got != models.CarStatusAvailable {
+		t.Fatalf("car status = %q, want available", got)
+	}
+	if n, _ := uc.ExpireReservations(ctx); n != 0 {
+		t.Fatalf("second pass expired %d", n)
+	}
+}
+
+func TestUpdateCar_ReservedStatusIsManagedByReservations(t *testing.T) {
+	repo, reservations, c := newReservationFixture(t, time.Minute)
+	ctx := context.Background()
+	uc := usecase.NewCarUsecase(repo)
+
+	_, err := uc.Update(ctx, models.UpdateCarRequest{ID: c.ID,
+		UpdateCarAttributes: models.UpdateCarAttributes{Status: models.Some(models.CarStatusReserved)}})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+
+	if _, err := reservations.Reserve(ctx, models.ReservationRequest{CarID: c.ID, Customer: "Ivan"}); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	_, err = uc.Update(ctx, models.UpdateCarRequest{ID: c.ID,
+		UpdateCarAttributes: models.UpdateCarAttributes{Status: models.Some(models.CarStatusSold)}})
+	if !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	// правка других полей забронированной машины разрешена
+	if _, err := uc.Update(ctx, models.UpdateCarRequest{ID: c.ID,
+		UpdateCarAttributes: models.UpdateCarAttributes{Color: models.Some("red")}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBatch_ReservedStatusIsManagedByReservations(t *testing.T) {
+	repo, reservations, c := newReservationFixture(t, time.Minute)
+	ctx := context.Background()
+	uc := usecase.NewCarUsecase(repo)
+
+	if _, err := reservations.Reserve(ctx, models.ReservationRequest{CarID: c.ID, Customer: "Ivan"}); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	attrs := func(status string) models.CarAttributes { return models.CarAttributes{Status: status} }
+	res, err := uc.Batch(ctx, models.BatchRequest{Operations: []models.BatchOperation{
+		{Op: models.BatchOpUpdate, ID: c.ID, Brand: "Kia", Model: "Rio", Year: 2021, CarAttributes: attrs(models.CarStatusSold)},
+		{Op: models.BatchOpCreate, Brand: "Kia", Model: "Ceed", Year: 2022},
+		{Op: models.BatchOpUpdate, ID: c.ID, Brand: "Kia", Model: "Rio", Year: 2021, CarAttributes: attrs(models.CarStatusAvailable)},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// продажа и снятие брони пакетом отклоняются только для своей операции
+	if !errors.Is(res.Items[0].Err, apperr.ErrConflict) || !errors.Is(res.Items[2].Err, apperr.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v and %v", res.Items[0].Err, res.Items[2].Err)
+	}
+	if res.Items[1].Err != nil {
+		t.Fatalf("unexpected error: %v", res.Items[1].Err)
+	}
+	if status := carStatus(t, repo, c.ID); status != models.CarStatusReserved {
+		t.Fatalf("status = %s, want reserved", status)
+	}
+
+	// правка других полей без статуса сохраняет бронь
+	res, err = uc.Batch(ctx, models.BatchRequest{Operations: []models.BatchOperation{
+		{Op: models.BatchOpUpdate, ID: c.ID, Brand: "Kia", Model: "Rio", Year: 2021, CarAttributes: models.CarAttributes{Color: "red"}},
+	}})
+	if err != nil || res.Items[0].Err != nil {
+		t.Fatalf("unexpected error: %v %v", err, res.Items[0].Err)
+	}
+	if status := carStatus(t, repo, c.ID); status != models.CarStatusReserved {
+		t.Fatalf("status = %s, want reserved", status)
+	}
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+// maxStatsBuckets ограничивает длину ряда Created: не больше года по дням.
+const maxStatsBuckets = 366
+
+type StatsUC struct {
+	repo repository.StatsProvider
+}
+
+func NewStatsUsecase(repo repository.StatsProvider) StatsUsecase {
+	return &StatsUC{repo: repo}
+}
+
+// Stats считает статистику по машинам под q.Filter. Пустой GroupBy — по марке, пустой Period —
+// по дням. Без To ряд заканчивается сегодняшним днём (UTC), без From — начинается за 30 дней,
+// 12 недель или 12 месяцев до To. Границы не меняются в течение дня, поэтому совпадающие
+// запросы попадают в кеш хранилища. Периоды без новых машин возвращаются с нулём.
+func (u *StatsUC) Stats(ctx context.Context, q models.StatsQuery) (models.CarStats, error) {
+	if q.GroupBy == "" {
+		q.GroupBy = models.StatsGroupBrand
+	}
+	if q.Period == "" {
+		q.Period = models.StatsPeriodDay
+	}
+	switch q.GroupBy {
+	case models.StatsGroupBrand, models.StatsGroupModel, models.StatsGroupYear, models.StatsGroupDecade:
+	default:
+		return models.CarStats{}, fmt.Errorf("%w: group_by must be one of brand, model, year, decade", apperr.ErrInvalidInput)
+	}
+	switch q.Period {
+	case models.StatsPeriodDay, models.StatsPeriodWeek, models.StatsPeriodMonth:
+	default:
+		return models.CarStats{}, fmt.Errorf("%w: period must be one of day, week, month", apperr.ErrInvalidInput)
+	}
+	if q.To.IsZero() {
+		q.To = models.StatsBucketStart(time.Now(), models.StatsPeriodDay).AddDate(0, 0, 1)
+	}
+	if q.From.IsZero() {
+		q.From = defaultStatsFrom(q.To, q.Period)
+	}
+	q.From, q.To = q.From.UTC(), q.To.UTC()
+	if !q.From.Before(q.To) {
+		return models.CarStats{}, fmt.Errorf("%w: from must be before to", apperr.ErrInvalidInput)
+	}
+	starts := statsBuckets(q.From, q.To, q.Period)
+	if starts == nil {
+		return models.CarStats{}, fmt.Errorf("%w: range is limited to %d %ss", apperr.ErrInvalidInput, maxStatsBuckets, q.Period)
+	}
+
+	stats, err := u.repo.CarStats(ctx, q)
+	if err != nil {
+		return models.CarStats{}, err
+	}
+	counts := make(map[time.Time]int64, len(stats.Created))
+	for _, b := range stats.Created {
+		counts[b.Start.UTC()] = b.Count
+	}
+	created := make([]models.StatsBucket, len(starts))
+	for i, start := range starts {
+		created[i] = models.StatsBucket{Start: start, Count: counts[start]}
+	}
+
+	resp := *stats
+	resp.GroupBy, resp.Period = q.GroupBy, q.Period
+	resp.From, resp.To = q.From, q.To
+	resp.Created = created
+	return resp, nil
+}
+
+func defaultStatsFrom(to time.Time, period string) time.Time {
+	switch period {
+	case models.StatsPeriodWeek:
+		return to.AddDate(0, 0, -7*12)
+	case models.StatsPeriodMonth:
+		return to.AddDate(0, -12, 0)
+	default:
+		return to.AddDate(0, 0, -30)
+	}
+}
+
+// statsBuckets возвращает начала периодов, пересекающихся с [from, to); nil — периодов
+// больше maxStatsBuckets.
+func statsBuckets(from, to time.Time, period string) []time.Time {
+	starts := []time.Time{}
+	for start := models.StatsBucketStart(from, period); start.Before(to); start = models.NextStatsBucket(start, period) {
+		if len(starts) == maxStatsBuckets {
+			return nil
+		}
+		starts = append(starts, start)
+	}
+	return starts
+}
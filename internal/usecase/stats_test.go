@@ -0,0 +1,82 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+func TestStats_FillsEmptyPeriods(t *testing.T) {
+	repo := repository.NewMemoryCarRepo()
+	ctx := context.Background()
+	for _, c := range []models.Car{{Brand: "Kia", Model: "Rio", Year: 2020}, {Brand: "Lada", Model: "Niva", Year: 2010}} {
+		if err := repo.InsertCar(ctx, &c); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	uc := usecase.NewStatsUsecase(repo)
+
+	stats, err := uc.Stats(ctx, models.StatsQuery{Filter: models.CarFilter{YearFrom: 2015}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.GroupBy != models.StatsGroupBrand || stats.Period != models.StatsPeriodDay {
+		t.Fatalf("unexpected defaults: %q %q", stats.GroupBy, stats.Period)
+	}
+	if stats.Total != 1 || len(stats.Groups) != 1 || stats.Groups[0].Key != "Kia" {
+		t.Fatalf("filter must apply: %+v", stats)
+	}
+	if len(stats.Created) != 30 {
+		t.Fatalf("expected 30 days, got %d", len(stats.Created))
+	}
+	today := models.StatsBucketStart(time.Now(), models.StatsPeriodDay)
+	last := stats.Created[len(stats.Created)-1]
+	if !last.Start.Equal(today) || last.Count != 1 || stats.Created[0].Count != 0 {
+		t.Fatalf("unexpected series: first %+v, last %+v", stats.Created[0], last)
+	}
+	if !stats.To.Equal(today.AddDate(0, 0, 1)) {
+		t.Fatalf("unexpected to: %v", stats.To)
+	}
+}
+
+func TestStats_WeeksStartOnMonday(t *testing.T) {
+	uc := usecase.NewStatsUsecase(repository.NewMemoryCarRepo())
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC) // среда
+	stats, err := uc.Stats(context.Background(), models.StatsQuery{
+		Period: models.StatsPeriodWeek, From: from, To: from.AddDate(0, 0, 14),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"2024-04-29", "2024-05-06", "2024-05-13"}
+	if len(stats.Created) != len(want) {
+		t.Fatalf("unexpected series: %+v", stats.Created)
+	}
+	for i, b := range stats.Created {
+		if b.Start.Format(time.DateOnly) != want[i] {
+			t.Fatalf("bucket %d: got %s, want %s", i, b.Start.Format(time.DateOnly), want[i])
+		}
+	}
+}
+
+func TestStats_InvalidQuery(t *testing.T) {
+	uc := usecase.NewStatsUsecase(repository.NewMemoryCarRepo())
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for name, q := range map[string]models.StatsQuery{
+		"group_by":   {GroupBy: "color"},
+		"period":     {Period: "hour"},
+		"reversed":   {From: day, To: day},
+		"too long":   {From: day.AddDate(-2, 0, 0), To: day},
+		"from > now": {From: time.Now().AddDate(0, 0, 2)},
+	} {
+		if _, err := uc.Stats(context.Background(), q); !errors.Is(err, apperr.ErrInvalidInput) {
+			t.Fatalf("%s: expected ErrInvalidInput, got %v", name, err)
+		}
+	}
+}
@@ -1,51 +1,106 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/jsonpatch"
 	"github.com/pavel97go/service-cars/internal/models"
 	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/vin"
 )
 
+// defaultBatchLimit — максимум операций в POST /cars/batch, если не задан WithBatchLimit.
+const defaultBatchLimit = 1000
+
 type CarUC struct {
-	repo repository.CarProvider
+	repo       repository.CarProvider
+	notifier   Notifier
+	batchLimit int
+	catalog    *carNormalizer
+	images     repository.ImageProvider
+}
+
+// Option настраивает CarUC.
+type Option func(*CarUC)
+
+// WithNotifier подключает получателя событий об изменениях (SSE, WebSocket).
+func WithNotifier(n Notifier) Option {
+	return func(u *CarUC) { u.notifier = n }
+}
+
+// WithBatchLimit ограничивает число операций в одном пакете.
+func WithBatchLimit(n int) Option {
+	return func(u *CarUC) {
+		if n > 0 {
+			u.batchLimit = n
+		}
+	}
+}
+
+// WithCatalog приводит марку и модель машины к названиям из справочника;
+// strict отклоняет марки, которых в справочнике нет.
+func WithCatalog(catalog repository.CatalogProvider, strict bool) Option {
+	return func(u *CarUC) { u.catalog = &carNormalizer{catalog: catalog, strict: strict} }
 }
 
-func NewCarUsecase(repo repository.CarProvider) CarUsecase {
-	return &CarUC{repo: repo}
+// WithImages добавляет в ответы с машинами их фотографии по порядку галереи.
+func WithImages(images repository.ImageProvider) Option {
+	return func(u *CarUC) { u.images = images }
+}
+
+func NewCarUsecase(repo repository.CarProvider, opts ...Option) CarUsecase {
+	u := &CarUC{repo: repo, batchLimit: defaultBatchLimit}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
 }
 func (u *CarUC) Create(ctx context.Context, req models.CreateCarRequest) (models.CarResponse, error) {
-	if err := models.ValidateStruct(req); err != nil {
+	req.VIN = vin.Normalize(req.VIN)
+	normalizeAttributes(&req.CarAttributes)
+	if req.Status == "" {
+		req.Status = models.CarStatusAvailable
+	}
+	if req.PrefillFromVIN {
+		if err := prefillFromVIN(&req); err != nil {
+			return models.CarResponse{}, err
+		}
+	}
+	if err := validateCreate(req); err != nil {
 		return models.CarResponse{}, err
 	}
-	currentYear := time.Now().Year()
-	if req.Year > currentYear+1 {
-		return models.CarResponse{}, fmt.Errorf("%w: invalid year", apperr.ErrInvalidInput)
+	if err := u.catalog.normalize(ctx, &req.Brand, &req.Model, req.Year); err != nil {
+		return models.CarResponse{}, err
 	}
 	car := models.Car{
-		Brand: req.Brand,
-		Model: req.Model,
-		Year:  req.Year,
+		Brand:         req.Brand,
+		Model:         req.Model,
+		Year:          req.Year,
+		VIN:           req.VIN,
+		CarAttributes: req.CarAttributes,
 	}
 	if err := u.repo.InsertCar(ctx, &car); err != nil {
 		return models.CarResponse{}, err
 	}
-	return models.CarResponse{
-		ID:    car.ID,
-		Brand: car.Brand,
-		Model: car.Model,
-		Year:  car.Year,
-	}, nil
+	resp := models.NewCarResponse(car)
+	u.notify(models.EventCarCreated, resp)
+	return resp, nil
 }
-func (u *CarUC) List(ctx context.Context) ([]models.CarResponse, error) {
+func (u *CarUC) List(ctx context.Context, f models.CarFilter) ([]models.CarResponse, error) {
 	cars, err := u.repo.ListCars(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return models.ToResponse(cars), nil
+	return u.withImages(ctx, models.ToResponse(filterCars(cars, f)))
 }
 func (u *CarUC) Get(ctx context.Context, id string) (models.CarResponse, error) {
 	car, err := u.repo.GetCarByID(ctx, id)
@@ -55,56 +110,493 @@ func (u *CarUC) Get(ctx context.Context, id string) (models.CarResponse, error)
 	if err != nil {
 		return models.CarResponse{}, err
 	}
-	response := models.CarResponse{
-		ID:    car.ID,
-		Brand: car.Brand,
-		Model: car.Model,
-		Year:  car.Year,
+	return u.withCarImages(ctx, models.NewCarResponse(*car))
+}
+
+// prefillFromVIN подставляет марку и модельный год из VIN, если они не переданы.
+// Марку удаётся определить только для WMI из встроенной таблицы.
+func prefillFromVIN(req *models.CreateCarRequest) error {
+	if req.VIN == "" || req.Brand != "" && req.Year != 0 {
+		return nil
 	}
-	return response, nil
+	info, err := vin.Decode(req.VIN)
+	if err != nil {
+		return err
+	}
+	if req.Brand == "" {
+		req.Brand = info.Brand
+	}
+	if req.Year == 0 {
+		req.Year = info.ModelYear
+	}
+	return nil
 }
+
+// GetByVIN ищет машину по VIN без учёта регистра.
+func (u *CarUC) GetByVIN(ctx context.Context, number string) (models.CarResponse, error) {
+	number = vin.Normalize(number)
+	if !vin.Valid(number) {
+		return models.CarResponse{}, fmt.Errorf("%w: invalid vin", apperr.ErrInvalidInput)
+	}
+	car, err := u.repo.GetCarByVIN(ctx, number)
+	if err != nil {
+		return models.CarResponse{}, err
+	}
+	return u.withCarImages(ctx, models.NewCarResponse(*car))
+}
+
+// ListAsOf и GetAsOf возвращают состояние на момент at; кэш при этом не используется.
+func (u *CarUC) ListAsOf(ctx context.Context, at time.Time, f models.CarFilter) ([]models.CarResponse, error) {
+	cars, err := u.repo.ListCarsAsOf(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+	return models.ToResponse(filterCars(cars, f)), nil
+}
+
+func (u *CarUC) GetAsOf(ctx context.Context, id string, at time.Time) (models.CarResponse, error) {
+	car, err := u.repo.GetCarAsOf(ctx, id, at)
+	if err != nil {
+		return models.CarResponse{}, err
+	}
+	return models.NewCarResponse(*car), nil
+}
+
+// Update применяет JSON Merge Patch: отсутствующие поля не меняются,
+// null для обязательного поля — ошибка валидации.
 func (u *CarUC) Update(ctx context.Context, req models.UpdateCarRequest) (models.CarResponse, error) {
+	req.VIN.Value = vin.Normalize(req.VIN.Value)
+	if err := models.ValidateStruct(req); err != nil {
+		return models.CarResponse{}, err
+	}
+	car, err := u.load(ctx, req.ID, req.IfMatch)
+	if err != nil {
+		return models.CarResponse{}, err
+	}
+	before := *car
+	if err := mergeString(&car.Brand, req.Brand, "brand"); err != nil {
+		return models.CarResponse{}, err
+	}
+	if err := mergeString(&car.Model, req.Model, "model"); err != nil {
+		return models.CarResponse{}, err
+	}
+	if req.Year.Null {
+		return models.CarResponse{}, fmt.Errorf("%w: year cannot be null", apperr.ErrInvalidInput)
+	}
+	if req.Year.Set {
+		car.Year = req.Year.Value
+	}
+	if req.VIN.Set {
+		car.VIN = req.VIN.Value // null очищает VIN
+	}
+	if err := mergeAttributes(&car.CarAttributes, req.UpdateCarAttributes); err != nil {
+		return models.CarResponse{}, err
+	}
+	normalizeAttributes(&car.CarAttributes)
+	if err := u.normalizeChanged(ctx, &before, &car.Brand, &car.Model, car.Year); err != nil {
+		return models.CarResponse{}, err
+	}
+	return u.save(ctx, car, before.Status, req.IfMatch)
+}
+
+// Replace полностью заменяет изменяемые поля записи (PUT); не переданный status не меняется.
+func (u *CarUC) Replace(ctx context.Context, req models.ReplaceCarRequest) (models.CarResponse, error) {
+	req.VIN = vin.Normalize(req.VIN)
+	normalizeAttributes(&req.CarAttributes)
 	if err := models.ValidateStruct(req); err != nil {
 		return models.CarResponse{}, err
 	}
-	car, err := u.repo.GetCarByID(ctx, req.ID)
+	car, err := u.load(ctx, req.ID, req.IfMatch)
+	if err != nil {
+		return models.CarResponse{}, err
+	}
+	if err := u.normalizeChanged(ctx, car, &req.Brand, &req.Model, req.Year); err != nil {
+		return models.CarResponse{}, err
+	}
+	car.Brand = req.Brand
+	car.Model = req.Model
+	car.Year = req.Year
+	car.VIN = req.VIN
+	status := car.Status
+	car.CarAttributes = replaceAttributes(car.CarAttributes, req.CarAttributes)
+	return u.save(ctx, car, status, req.IfMatch)
+}
+
+// Patch применяет JSON Patch (RFC 6902) к текущему состоянию записи;
+// результат проходит ту же валидацию, что и PUT.
+func (u *CarUC) Patch(ctx context.Context, req models.JSONPatchRequest) (models.CarResponse, error) {
+	if err := models.ValidateStruct(req); err != nil {
+		return models.CarResponse{}, err
+	}
+	car, err := u.load(ctx, req.ID, req.IfMatch)
+	if err != nil {
+		return models.CarResponse{}, err
+	}
+	doc, err := json.Marshal(replaceRequest(car))
+	if err != nil {
+		return models.CarResponse{}, err
+	}
+	patched, err := jsonpatch.Apply(doc, req.Operations)
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return models.CarResponse{}, fmt.Errorf("%w: %s", apperr.ErrConflict, err.Error())
+	case err != nil:
+		return models.CarResponse{}, fmt.Errorf("%w: %s", apperr.ErrInvalidInput, err.Error())
+	}
+
+	var replace models.ReplaceCarRequest
+	if err := decodeStrict(patched, &replace); err != nil {
+		return models.CarResponse{}, fmt.Errorf("%w: %s", apperr.ErrInvalidInput, err.Error())
+	}
+	replace.ID = car.ID
+	replace.VIN = vin.Normalize(replace.VIN)
+	normalizeAttributes(&replace.CarAttributes)
+	if err := models.ValidateStruct(replace); err != nil {
+		return models.CarResponse{}, err
+	}
+	if err := u.normalizeChanged(ctx, car, &replace.Brand, &replace.Model, replace.Year); err != nil {
+		return models.CarResponse{}, err
+	}
+	car.Brand = replace.Brand
+	car.Model = replace.Model
+	car.Year = replace.Year
+	car.VIN = replace.VIN
+	status := car.Status
+	car.CarAttributes = replaceAttributes(car.CarAttributes, replace.CarAttributes)
+	return u.save(ctx, car, status, req.IfMatch)
+}
+
+func (u *CarUC) Delete(ctx context.Context, id string) error {
+	// снимок нужен подписчикам, чтобы фильтровать удаление по марке
+	var before *models.Car
+	if u.notifier != nil {
+		car, err := u.repo.GetCarByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		before = car
+	}
+	err := u.repo.DeleteByID(ctx, id)
 	if err == apperr.ErrNotFound {
-		return models.CarResponse{}, apperr.ErrNotFound
+		return apperr.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if before != nil {
+		u.notify(models.EventCarDeleted, models.NewCarResponse(*before))
+	}
+	return nil
+}
+
+func (u *CarUC) ListTrash(ctx context.Context) ([]models.CarResponse, error) {
+	cars, err := u.repo.ListDeletedCars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return u.withImages(ctx, models.ToResponse(cars))
+}
+
+func (u *CarUC) Restore(ctx context.Context, id string) (models.CarResponse, error) {
+	car, err := u.repo.RestoreByID(ctx, id)
+	if err != nil {
+		return models.CarResponse{}, err
+	}
+	resp, err := u.withCarImages(ctx, models.NewCarResponse(*car))
+	if err != nil {
+		return models.CarResponse{}, err
+	}
+	u.notify(models.EventCarRestored, resp)
+	return resp, nil
+}
+
+// PurgeTrash окончательно удаляет машины, пролежавшие в корзине дольше retention.
+func (u *CarUC) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	if retention < 0 {
+		return 0, fmt.Errorf("%w: negative retention", apperr.ErrInvalidInput)
+	}
+	return u.repo.PurgeDeleted(ctx, time.Now().Add(-retention))
+}
+
+// Batch проверяет операции пакета и применяет прошедшие проверку одной транзакцией.
+// В атомарном режиме ошибка любой операции отменяет весь пакет.
+func (u *CarUC) Batch(ctx context.Context, req models.BatchRequest) (models.BatchResult, error) {
+	if len(req.Operations) == 0 {
+		return models.BatchResult{}, fmt.Errorf("%w: operations are required", apperr.ErrInvalidInput)
+	}
+	if len(req.Operations) > u.batchLimit {
+		return models.BatchResult{}, fmt.Errorf("%w: too many operations, max %d", apperr.ErrInvalidInput, u.batchLimit)
+	}
+
+	result := models.BatchResult{Atomic: req.Atomic, Items: make([]models.BatchItem, len(req.Operations))}
+	muts := make([]models.CarMutation, 0, len(req.Operations))
+	index := make([]int, 0, len(req.Operations)) // позиция мутации в запросе
+	invalid := false
+	for i, op := range req.Operations {
+		result.Items[i] = models.BatchItem{Index: i, Op: op.Op}
+		mut, err := batchMutation(op)
+		if err == nil {
+			err = u.normalizeMutation(ctx, &mut)
+		}
+		if err != nil {
+			result.Items[i].Err = err
+			invalid = true
+			continue
+		}
+		muts = append(muts, mut)
+		index = append(index, i)
+	}
+	if req.Atomic && invalid {
+		for _, i := range index {
+			result.Items[i].Err = apperr.ErrAborted
+		}
+		return result, nil
+	}
+
+	applied, err := u.repo.ApplyBatch(ctx, muts, req.Atomic)
+	if err != nil {
+		return models.BatchResult{}, err
+	}
+	for j, res := range applied {
+		item := &result.Items[index[j]]
+		if res.Err != nil {
+			item.Err = res.Err
+			continue
+		}
+		resp := models.NewCarResponse(*res.Car)
+		item.Car = &resp
+	}
+	for _, item := range result.Items {
+		if item.Err == nil {
+			u.notify(batchEventTypes[item.Op], *item.Car)
+		}
+	}
+	return result, nil
+}
+
+var batchEventTypes = map[string]string{
+	models.BatchOpCreate: models.EventCarCreated,
+	models.BatchOpUpdate: models.EventCarUpdated,
+	models.BatchOpDelete: models.EventCarDeleted,
+}
+
+// batchMutation проверяет операцию пакета теми же правилами, что и одиночные POST, PUT и DELETE.
+func batchMutation(op models.BatchOperation) (models.CarMutation, error) {
+	op.VIN = vin.Normalize(op.VIN)
+	normalizeAttributes(&op.CarAttributes)
+	car := models.Car{ID: op.ID, Brand: op.Brand, Model: op.Model, Year: op.Year, VIN: op.VIN,
+		CarAttributes: op.CarAttributes, Version: op.Version}
+	if op.Version < 0 {
+		return models.CarMutation{}, fmt.Errorf("%w: version must be >= 0", apperr.ErrInvalidInput)
+	}
+	switch op.Op {
+	case models.BatchOpCreate:
+		if op.ID != "" || op.Version != 0 {
+			return models.CarMutation{}, fmt.Errorf("%w: id and version are not allowed for create", apperr.ErrInvalidInput)
+		}
+		if car.Status == "" {
+			car.Status = models.CarStatusAvailable
+		}
+		create := models.CreateCarRequest{Brand: op.Brand, Model: op.Model, Year: op.Year, VIN: op.VIN,
+			CarAttributes: car.CarAttributes}
+		if err := validateCreate(create); err != nil {
+			return models.CarMutation{}, err
+		}
+	case models.BatchOpUpdate:
+		req := models.ReplaceCarRequest{ID: op.ID, Brand: op.Brand, Model: op.Model, Year: op.Year, VIN: op.VIN,
+			CarAttributes: op.CarAttributes}
+		if err := models.ValidateStruct(req); err != nil {
+			return models.CarMutation{}, err
+		}
+		if err := validateAttributes(op.CarAttributes); err != nil {
+			return models.CarMutation{}, err
+		}
+		// текущий статус машины проверяет репозиторий под блокировкой строки
+		if err := models.CheckManualStatus("", op.Status); err != nil {
+			return models.CarMutation{}, err
+		}
+	case models.BatchOpDelete:
+		if _, err := uuid.Parse(op.ID); err != nil {
+			return models.CarMutation{}, fmt.Errorf("%w: invalid id format, must be UUID", apperr.ErrInvalidInput)
+		}
+		car = models.Car{ID: op.ID, Version: op.Version}
+	default:
+		return models.CarMutation{}, fmt.Errorf("%w: unknown op %q", apperr.ErrInvalidInput, op.Op)
+	}
+	if yearLimit := time.Now().Year() + 1; car.Year > yearLimit {
+		return models.CarMutation{}, fmt.Errorf("%w: year must be <= %d", apperr.ErrInvalidInput, yearLimit)
+	}
+	return models.CarMutation{Op: op.Op, Car: car}, nil
+}
+
+// filterCars оставляет машины под фильтром; список целиком уже в памяти (и в кеше),
+// поэтому фильтр применяется здесь, а не в запросе.
+func filterCars(cars []models.Car, f models.CarFilter) []models.Car {
+	if f == (models.CarFilter{}) {
+		return cars
+	}
+	out := cars[:0:0]
+	for _, c := range cars {
+		if f.Match(c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// validateCreate — правила для новой машины: теги CreateCarRequest, атрибуты и год не позже следующего.
+func validateCreate(req models.CreateCarRequest) error {
+	if err := models.ValidateStruct(req); err != nil {
+		return err
+	}
+	if err := validateAttributes(req.CarAttributes); err != nil {
+		return err
+	}
+	if err := models.CheckManualStatus(models.CarStatusAvailable, req.Status); err != nil {
+		return err
+	}
+	if yearLimit := time.Now().Year() + 1; req.Year > yearLimit {
+		return fmt.Errorf("%w: year must be <= %d", apperr.ErrInvalidInput, yearLimit)
+	}
+	return nil
+}
+
+// load читает запись для изменения и проверяет If-Match.
+func (u *CarUC) load(ctx context.Context, id string, ifMatch []int) (*models.Car, error) {
+	car, err := u.repo.GetCarByID(ctx, id)
+	if err == apperr.ErrNotFound {
+		return nil, apperr.ErrNotFound
 	}
-	if req.Brand != "" {
-		car.Brand = req.Brand
+	if err != nil {
+		return nil, err
 	}
-	if req.Model != "" {
-		car.Model = req.Model
+	if len(ifMatch) > 0 && !slices.Contains(ifMatch, car.Version) {
+		return nil, apperr.ErrPreconditionFailed
 	}
-	if req.Year != 0 {
-		yearLimit := time.Now().Year() + 1
-		if req.Year > yearLimit {
-			return models.CarResponse{}, fmt.Errorf("%w: year must be <= %d", apperr.ErrInvalidInput, yearLimit)
+	return car, nil
+}
+
+// normalizeChanged сверяет со справочником новые марку и модель, только если они отличаются
+// от текущих: машину с маркой не из справочника можно править, не меняя марку.
+// Новый год при прежних марке и модели проверяется правилами без строгого режима.
+func (u *CarUC) normalizeChanged(ctx context.Context, car *models.Car, brand, model *string, year int) error {
+	switch {
+	case *brand != car.Brand || *model != car.Model:
+		return u.catalog.normalize(ctx, brand, model, year)
+	case year != car.Year:
+		return u.catalog.checkYear(ctx, *brand, *model, year)
+	}
+	return nil
+}
+
+// normalizeMutation сверяет операцию пакета со справочником: create — целиком,
+// update — как одиночное обновление, только изменённые марку и модель.
+func (u *CarUC) normalizeMutation(ctx context.Context, mut *models.CarMutation) error {
+	switch {
+	case u.catalog == nil || mut.Op == models.BatchOpDelete:
+		return nil
+	case mut.Op == models.BatchOpUpdate:
+		car, err := u.repo.GetCarByID(ctx, mut.Car.ID)
+		if err != nil {
+			return err
 		}
-		car.Year = req.Year
+		return u.normalizeChanged(ctx, car, &mut.Car.Brand, &mut.Car.Model, mut.Car.Year)
 	}
+	return u.catalog.normalize(ctx, &mut.Car.Brand, &mut.Car.Model, mut.Car.Year)
+}
 
+// save проверяет бизнес-ограничения и записывает изменения с проверкой версии;
+// status — статус машины до изменения.
+func (u *CarUC) save(ctx context.Context, car *models.Car, status string, ifMatch []int) (models.CarResponse, error) {
+	yearLimit := time.Now().Year() + 1
+	if car.Year > yearLimit {
+		return models.CarResponse{}, fmt.Errorf("%w: year must be <= %d", apperr.ErrInvalidInput, yearLimit)
+	}
+	if err := validateAttributes(car.CarAttributes); err != nil {
+		return models.CarResponse{}, err
+	}
+	if err := models.CheckManualStatus(status, car.Status); err != nil {
+		return models.CarResponse{}, err
+	}
 	if err := u.repo.UpdateCar(ctx, car); err != nil {
 		if err == apperr.ErrNotFound { // если запись удалили между Read и Update
 			return models.CarResponse{}, apperr.ErrNotFound
 		}
+		// версия изменилась между Read и Update; занятый VIN остаётся 409
+		if errors.Is(err, apperr.ErrConflict) && len(ifMatch) > 0 && !errors.Is(err, repository.ErrVINTaken) {
+			return models.CarResponse{}, apperr.ErrPreconditionFailed
+		}
+		return models.CarResponse{}, err
+	}
+	resp, err := u.withCarImages(ctx, models.NewCarResponse(*car))
+	if err != nil {
 		return models.CarResponse{}, err
 	}
-	return models.CarResponse{
-		ID:    car.ID,
-		Brand: car.Brand,
-		Model: car.Model,
-		Year:  car.Year,
-	}, nil
+	u.notify(models.EventCarUpdated, resp)
+	return resp, nil
 }
-func (u *CarUC) Delete(ctx context.Context, id string) error {
-	err := u.repo.DeleteByID(ctx, id)
-	if err == apperr.ErrNotFound {
-		return apperr.ErrNotFound
+
+// withImages заполняет фотографии машин одним запросом; без WithImages ничего не делает.
+func (u *CarUC) withImages(ctx context.Context, cars []models.CarResponse) ([]models.CarResponse, error) {
+	if u.images == nil || len(cars) == 0 {
+		return cars, nil
 	}
+	ids := make([]string, len(cars))
+	for i, c := range cars {
+		ids[i] = c.ID
+	}
+	images, err := u.images.ListImages(ctx, ids...)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	byCar := make(map[string][]models.CarImageResponse)
+	for _, img := range images {
+		byCar[img.CarID] = append(byCar[img.CarID], models.NewCarImageResponse(img))
+	}
+	for i := range cars {
+		cars[i].Images = byCar[cars[i].ID]
+	}
+	return cars, nil
+}
+
+func (u *CarUC) withCarImages(ctx context.Context, car models.CarResponse) (models.CarResponse, error) {
+	cars, err := u.withImages(ctx, []models.CarResponse{car})
+	if err != nil {
+		return models.CarResponse{}, err
+	}
+	return cars[0], nil
+}
+
+// notify сообщает об успешном изменении; без notifier ничего не делает.
+func (u *CarUC) notify(eventType string, car models.CarResponse) {
+	if u.notifier == nil {
+		return
+	}
+	u.notifier.Notify(models.CarEvent{Type: eventType, Car: car, OccurredAt: time.Now().UTC()})
+}
+
+func mergeString(dst *string, v models.Optional[string], field string) error {
+	if v.Null {
+		return fmt.Errorf("%w: %s cannot be null", apperr.ErrInvalidInput, field)
+	}
+	if v.Set {
+		*dst = v.Value
 	}
 	return nil
 }
+
+func replaceRequest(car *models.Car) models.ReplaceCarRequest {
+	return models.ReplaceCarRequest{
+		Brand:         car.Brand,
+		Model:         car.Model,
+		Year:          car.Year,
+		VIN:           car.VIN,
+		CarAttributes: car.CarAttributes,
+	}
+}
+
+func decodeStrict(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
@@ -2,12 +2,14 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 
+	"github.com/pavel97go/service-cars/internal/apperr"
 	"github.com/pavel97go/service-cars/internal/models"
 	"github.com/pavel97go/service-cars/internal/repository/mocks"
 	"github.com/pavel97go/service-cars/internal/usecase"
@@ -52,3 +54,277 @@ func TestCreateCar(t *testing.T) {
 		t.Fatalf("unexpected model: got %q, want %q", resp.Model, "Camry")
 	}
 }
+
+func TestUpdateCar_IfMatchMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	id := "8f1b1a2e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	mockRepo.
+		EXPECT().
+		GetCarByID(gomock.Any(), id).
+		Return(&models.Car{ID: id, Brand: "Toyota", Model: "Camry", Year: 2020, Version: 3}, nil).
+		Times(1)
+
+	_, err := uc.Update(context.Background(), models.UpdateCarRequest{ID: id, Model: models.Some("Corolla"), IfMatch: []int{2}})
+	if !errors.Is(err, apperr.ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+}
+
+func TestUpdateCar_ConcurrentChangeWithIfMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	id := "8f1b1a2e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	mockRepo.
+		EXPECT().
+		GetCarByID(gomock.Any(), id).
+		Return(&models.Car{ID: id, Brand: "Toyota", Model: "Camry", Year: 2020, Version: 3}, nil).
+		Times(1)
+	mockRepo.
+		EXPECT().
+		UpdateCar(gomock.Any(), gomock.Any()).
+		Return(apperr.ErrConflict).
+		Times(1)
+
+	_, err := uc.Update(context.Background(), models.UpdateCarRequest{ID: id, Model: models.Some("Corolla"), IfMatch: []int{3}})
+	if !errors.Is(err, apperr.ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+}
+
+func TestUpdateCar_MergePatchNullRequiredField(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	id := "8f1b1a2e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	mockRepo.
+		EXPECT().
+		GetCarByID(gomock.Any(), id).
+		Return(&models.Car{ID: id, Brand: "Toyota", Model: "Camry", Year: 2020, Version: 1}, nil).
+		Times(1)
+
+	_, err := uc.Update(context.Background(), models.UpdateCarRequest{ID: id, Model: models.Null[string]()})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestPatchCar_JSONPatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	id := "8f1b1a2e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	mockRepo.
+		EXPECT().
+		GetCarByID(gomock.Any(), id).
+		Return(&models.Car{ID: id, Brand: "Toyota", Model: "Camry", Year: 2020, Version: 1}, nil).
+		Times(1)
+	mockRepo.
+		EXPECT().
+		UpdateCar(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, c *models.Car) error {
+			c.Version++
+			return nil
+		}).
+		Times(1)
+
+	resp, err := uc.Patch(context.Background(), models.JSONPatchRequest{
+		ID: id,
+		Operations: []models.PatchOperation{
+			{Op: "test", Path: "/model", Value: []byte(`"Camry"`)},
+			{Op: "replace", Path: "/model", Value: []byte(`"Corolla"`)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Model != "Corolla" || resp.Version != 2 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+type recordingNotifier struct {
+	events []models.CarEvent
+}
+
+func (n *recordingNotifier) Notify(evt models.CarEvent) {
+	n.events = append(n.events, evt)
+}
+
+func TestNotifier_CreateAndDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	n := &recordingNotifier{}
+	uc := usecase.NewCarUsecase(mockRepo, usecase.WithNotifier(n))
+
+	id := "8f1b1a2e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	mockRepo.EXPECT().InsertCar(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, c *models.Car) error {
+			c.ID = id
+			return nil
+		})
+	mockRepo.EXPECT().GetCarByID(gomock.Any(), id).
+		Return(&models.Car{ID: id, Brand: "Toyota", Model: "Camry", Year: 2020, Version: 1}, nil)
+	mockRepo.EXPECT().DeleteByID(gomock.Any(), id).Return(nil)
+
+	if _, err := uc.Create(context.Background(), models.CreateCarRequest{Brand: "Toyota", Model: "Camry", Year: 2020}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := uc.Delete(context.Background(), id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(n.events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(n.events))
+	}
+	if n.events[0].Type != models.EventCarCreated || n.events[1].Type != models.EventCarDeleted {
+		t.Fatalf("unexpected event types: %q, %q", n.events[0].Type, n.events[1].Type)
+	}
+	if n.events[1].Car.Brand != "Toyota" {
+		t.Fatalf("delete event must carry brand, got %q", n.events[1].Car.Brand)
+	}
+}
+
+func TestNotifier_NotCalledOnFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	n := &recordingNotifier{}
+	uc := usecase.NewCarUsecase(mockRepo, usecase.WithNotifier(n))
+
+	mockRepo.EXPECT().InsertCar(gomock.Any(), gomock.Any()).Return(errors.New("db down"))
+
+	if _, err := uc.Create(context.Background(), models.CreateCarRequest{Brand: "Toyota", Model: "Camry", Year: 2020}); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(n.events) != 0 {
+		t.Fatalf("expected no events, got %d", len(n.events))
+	}
+}
+
+func TestBatch_AtomicInvalidSkipsRepo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	res, err := uc.Batch(context.Background(), models.BatchRequest{
+		Atomic: true,
+		Operations: []models.BatchOperation{
+			{Op: models.BatchOpCreate, Brand: "Toyota", Model: "Camry", Year: 2020},
+			{Op: models.BatchOpDelete, ID: "not-a-uuid"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !errors.Is(res.Items[0].Err, apperr.ErrAborted) {
+		t.Fatalf("expected ErrAborted, got %v", res.Items[0].Err)
+	}
+	if !errors.Is(res.Items[1].Err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", res.Items[1].Err)
+	}
+}
+
+func TestBatch_BestEffortMergesResults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	n := &recordingNotifier{}
+	uc := usecase.NewCarUsecase(mockRepo, usecase.WithNotifier(n))
+
+	id := "8f1b1a2e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	mockRepo.EXPECT().ApplyBatch(gomock.Any(), gomock.Any(), false).
+		DoAndReturn(func(_ context.Context, ops []models.CarMutation, _ bool) ([]models.MutationResult, error) {
+			if len(ops) != 2 || ops[0].Op != models.BatchOpCreate || ops[1].Car.ID != id {
+				t.Fatalf("unexpected ops: %+v", ops)
+			}
+			return []models.MutationResult{
+				{Car: &models.Car{ID: "new-id", Brand: "Toyota", Model: "Camry", Year: 2020, Version: 1}},
+				{Err: apperr.ErrNotFound},
+			}, nil
+		})
+
+	res, err := uc.Batch(context.Background(), models.BatchRequest{
+		Operations: []models.BatchOperation{
+			{Op: models.BatchOpCreate, Brand: "Toyota", Model: "Camry", Year: 2020},
+			{Op: "upsert"},
+			{Op: models.BatchOpDelete, ID: id},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Items[0].Err != nil || res.Items[0].Car.ID != "new-id" {
+		t.Fatalf("unexpected first item: %+v", res.Items[0])
+	}
+	if !errors.Is(res.Items[1].Err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", res.Items[1].Err)
+	}
+	if !errors.Is(res.Items[2].Err, apperr.ErrNotFound) || res.Items[2].Index != 2 {
+		t.Fatalf("unexpected third item: %+v", res.Items[2])
+	}
+	if len(n.events) != 1 || n.events[0].Type != models.EventCarCreated {
+		t.Fatalf("expected one CarCreated event, got %+v", n.events)
+	}
+}
+
+func TestBatch_Limit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := usecase.NewCarUsecase(mocks.NewMockCarProvider(ctrl), usecase.WithBatchLimit(1))
+	_, err := uc.Batch(context.Background(), models.BatchRequest{Operations: make([]models.BatchOperation, 2)})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestList_Filter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	mockRepo.EXPECT().ListCars(gomock.Any()).Return([]models.Car{
+		{ID: "1", Brand: "Toyota", Model: "Camry", Year: 2015},
+		{ID: "2", Brand: "toyota", Model: "Corolla", Year: 2020},
+		{ID: "3", Brand: "Honda", Model: "Civic", Year: 2020},
+	}, nil).Times(2)
+
+	resp, err := uc.List(context.Background(), models.CarFilter{Brand: "TOYOTA", YearFrom: 2016})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp) != 1 || resp[0].ID != "2" {
+		t.Fatalf("unexpected result: %+v", resp)
+	}
+
+	resp, err = uc.List(context.Background(), models.CarFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp) != 3 {
+		t.Fatalf("empty filter must keep all cars, got %d", len(resp))
+	}
+}
@@ -0,0 +1,159 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/repository/mocks"
+	"github.com/pavel97go/service-cars/internal/usecase"
+)
+
+func TestCreateCar_NormalizesVIN(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	mockRepo.EXPECT().InsertCar(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *models.Car) error {
+		if c.VIN != "1M8GDM9AXKP042788" {
+			t.Fatalf("vin must be normalized, got %q", c.VIN)
+		}
+		c.ID = "uuid-1"
+		return nil
+	})
+
+	resp, err := uc.Create(context.Background(),
+		models.CreateCarRequest{Brand: "Toyota", Model: "Camry", Year: 2020, VIN: " 1m8gdm9axkp042788 "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.VIN != "1M8GDM9AXKP042788" {
+		t.Fatalf("unexpected vin %q", resp.VIN)
+	}
+}
+
+func TestCreateCar_InvalidVIN(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := usecase.NewCarUsecase(mocks.NewMockCarProvider(ctrl))
+	_, err := uc.Create(context.Background(),
+		models.CreateCarRequest{Brand: "Toyota", Model: "Camry", Year: 2020, VIN: "1M8GDM9A1KP042788"})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestUpdateCar_VINTakenIsConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	id := "8f1b1a2e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	mockRepo.EXPECT().GetCarByID(gomock.Any(), id).
+		Return(&models.Car{ID: id, Brand: "Toyota", Model: "Camry", Year: 2020, Version: 3}, nil)
+	mockRepo.EXPECT().UpdateCar(gomock.Any(), gomock.Any()).Return(repository.ErrVINTaken)
+
+	// с If-Match занятый VIN — всё равно 409, а не 412
+	_, err := uc.Update(context.Background(),
+		models.UpdateCarRequest{ID: id, VIN: models.Some("1HGCM82633A004352"), IfMatch: []int{3}})
+	if !errors.Is(err, apperr.ErrConflict) || errors.Is(err, apperr.ErrPreconditionFailed) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestUpdateCar_NullVINClears(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	id := "8f1b1a2e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	mockRepo.EXPECT().GetCarByID(gomock.Any(), id).
+		Return(&models.Car{ID: id, Brand: "Toyota", Model: "Camry", Year: 2020, VIN: "1HGCM82633A004352", Version: 1}, nil)
+	mockRepo.EXPECT().UpdateCar(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *models.Car) error {
+		if c.VIN != "" {
+			t.Fatalf("vin must be cleared, got %q", c.VIN)
+		}
+		c.Version++
+		return nil
+	})
+
+	if _, err := uc.Update(context.Background(), models.UpdateCarRequest{ID: id, VIN: models.Null[string]()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetByVIN_Invalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := usecase.NewCarUsecase(mocks.NewMockCarProvider(ctrl))
+	if _, err := uc.GetByVIN(context.Background(), "not-a-vin"); !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestCreateCar_PrefillFromVIN(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	mockRepo.EXPECT().InsertCar(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *models.Car) error {
+		if c.Brand != "Honda" || c.Year != 2003 {
+			t.Fatalf("brand and year must come from vin, got %q %d", c.Brand, c.Year)
+		}
+		return nil
+	})
+
+	_, err := uc.Create(context.Background(),
+		models.CreateCarRequest{Model: "Accord", VIN: "1HGCM82633A004352", PrefillFromVIN: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCreateCar_PrefillKeepsExplicitFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCarProvider(ctrl)
+	uc := usecase.NewCarUsecase(mockRepo)
+
+	mockRepo.EXPECT().InsertCar(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *models.Car) error {
+		if c.Brand != "Acura" || c.Year != 2003 {
+			t.Fatalf("explicit brand must be kept, got %q %d", c.Brand, c.Year)
+		}
+		return nil
+	})
+
+	_, err := uc.Create(context.Background(),
+		models.CreateCarRequest{Brand: "Acura", Model: "Accord", VIN: "1HGCM82633A004352", PrefillFromVIN: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCreateCar_WithoutPrefillBrandRequired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := usecase.NewCarUsecase(mocks.NewMockCarProvider(ctrl))
+	_, err := uc.Create(context.Background(),
+		models.CreateCarRequest{Model: "Accord", VIN: "1HGCM82633A004352"})
+	if !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
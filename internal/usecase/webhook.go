@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/webhook"
+)
+
+type WebhookUC struct {
+	repo repository.WebhookProvider
+}
+
+func NewWebhookUsecase(repo repository.WebhookProvider) WebhookUsecase {
+	return &WebhookUC{repo: repo}
+}
+
+// Create сохраняет подписку; если секрет не передан, он генерируется.
+// Секрет возвращается только в ответе на создание.
+func (u *WebhookUC) Create(ctx context.Context, req models.CreateWebhookRequest) (models.WebhookResponse, error) {
+	if err := models.ValidateStruct(req); err != nil {
+		return models.WebhookResponse{}, err
+	}
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = webhook.NewSecret(); err != nil {
+			return models.WebhookResponse{}, err
+		}
+	}
+	sub := models.WebhookSubscription{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     secret,
+		Active:     req.Active == nil || *req.Active,
+	}
+	if err := u.repo.CreateWebhook(ctx, &sub); err != nil {
+		return models.WebhookResponse{}, err
+	}
+	resp := models.NewWebhookResponse(sub)
+	resp.Secret = sub.Secret
+	return resp, nil
+}
+
+func (u *WebhookUC) List(ctx context.Context) ([]models.WebhookResponse, error) {
+	subs, err := u.repo.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]models.WebhookResponse, len(subs))
+	for i, s := range subs {
+		resp[i] = models.NewWebhookResponse(s)
+	}
+	return resp, nil
+}
+
+func (u *WebhookUC) Get(ctx context.Context, id string) (models.WebhookResponse, error) {
+	sub, err := u.repo.GetWebhook(ctx, id)
+	if err != nil {
+		return models.WebhookResponse{}, err
+	}
+	return models.NewWebhookResponse(*sub), nil
+}
+
+func (u *WebhookUC) Replace(ctx context.Context, req models.ReplaceWebhookRequest) (models.WebhookResponse, error) {
+	if err := models.ValidateStruct(req); err != nil {
+		return models.WebhookResponse{}, err
+	}
+	sub, err := u.repo.GetWebhook(ctx, req.ID)
+	if err != nil {
+		return models.WebhookResponse{}, err
+	}
+	sub.URL = req.URL
+	sub.EventTypes = req.EventTypes
+	sub.Active = req.Active
+	if req.Secret != "" {
+		sub.Secret = req.Secret
+	}
+	if err := u.repo.UpdateWebhook(ctx, sub); err != nil {
+		return models.WebhookResponse{}, err
+	}
+	return models.NewWebhookResponse(*sub), nil
+}
+
+func (u *WebhookUC) Delete(ctx context.Context, id string) error {
+	return u.repo.DeleteWebhook(ctx, id)
+}
+
+// Deliveries — журнал доставок подписки, новые первыми.
+func (u *WebhookUC) Deliveries(ctx context.Context, subscriptionID string, q models.DeliveryQuery) (models.DeliveryPage, error) {
+	if err := models.ValidateStruct(q); err != nil {
+		return models.DeliveryPage{}, err
+	}
+	if _, err := u.repo.GetWebhook(ctx, subscriptionID); err != nil {
+		return models.DeliveryPage{}, err
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultAuditLimit
+	}
+	items, total, err := u.repo.ListDeliveries(ctx, subscriptionID, q.Status, q.Limit, q.Offset)
+	if err != nil {
+		return models.DeliveryPage{}, err
+	}
+	return models.DeliveryPage{Items: items, Total: total, Limit: q.Limit, Offset: q.Offset}, nil
+}
+
+// Redeliver ставит доставку (в том числе успешную или dead) в очередь заново.
+func (u *WebhookUC) Redeliver(ctx context.Context, subscriptionID string, deliveryID int64) (models.WebhookDelivery, error) {
+	d, err := u.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	if d.SubscriptionID != subscriptionID {
+		return models.WebhookDelivery{}, apperr.ErrNotFound
+	}
+	d, err = u.repo.RedeliverDelivery(ctx, deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return *d, nil
+}
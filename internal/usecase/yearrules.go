@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// yearRule проверяет год машины по найденной в справочнике марке; model — nil, если
+// модели в справочнике нет. Нарушение — apperr.ErrInvalidInput с причиной для клиента.
+type yearRule func(year int, brand *models.Brand, model *models.CarModel) error
+
+// yearRules применяются по порядку до первого нарушения. Границы задаются для каждой
+// марки и модели: PUT /catalog/brands/:id/years и /catalog/brands/:id/models/:modelId/years.
+var yearRules = []yearRule{brandYearRule, modelYearRule}
+
+func checkYearRules(year int, brand *models.Brand, model *models.CarModel) error {
+	for _, rule := range yearRules {
+		if err := rule(year, brand, model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// brandYearRule — год в пределах лет, когда марка выпускала машины.
+func brandYearRule(year int, brand *models.Brand, _ *models.CarModel) error {
+	return checkYearRange(year, brand.Name, brand.YearRange)
+}
+
+// modelYearRule — год в пределах лет выпуска модели.
+func modelYearRule(year int, brand *models.Brand, model *models.CarModel) error {
+	if model == nil {
+		return nil
+	}
+	return checkYearRange(year, brand.Name+" "+model.Name, model.YearRange)
+}
+
+func checkYearRange(year int, name string, r models.YearRange) error {
+	if r.From != nil && year < *r.From {
+		return fmt.Errorf("%w: year %d is before %s production started in %d", apperr.ErrInvalidInput, year, name, *r.From)
+	}
+	if r.To != nil && year > *r.To {
+		return fmt.Errorf("%w: year %d is after %s production ended in %d", apperr.ErrInvalidInput, year, name, *r.To)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package valuation оценивает рыночную цену машины по ценам похожих машин.
+package valuation
+
+import (
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// MinComparables — меньше похожих машин оценка не строится.
+const MinComparables = 3
+
+// mileageScale — разница в пробеге (км), которая снижает вес так же, как разница в один год.
+const mileageScale = 20000
+
+// ErrTooFewComparables — похожих машин меньше MinComparables.
+var ErrTooFewComparables = errors.New("too few comparable cars")
+
+// Car — оцениваемая или похожая машина. Mileage nil — пробег неизвестен, Price у оцениваемой не используется.
+type Car struct {
+	Year    int
+	Mileage *int
+	Price   models.Money
+}
+
+// Estimate — оценка цены: взвешенные перцентили и диапазон цен похожих машин.
+type Estimate struct {
+	Comparables int
+	Median      models.Money
+	P10         models.Money
+	P25         models.Money
+	P75         models.Money
+	P90         models.Money
+	Min         models.Money
+	Max         models.Money
+}
+
+// Weight — сходство похожей машины c с оцениваемой t, от 0 до 1: каждый год разницы и каждые
+// mileageScale км разницы в пробеге уменьшают вес. Если пробег одной из машин неизвестен,
+// он не учитывается.
+func Weight(t, c Car) float64 {
+	w := 1 / (1 + math.Abs(float64(t.Year-c.Year)))
+	if t.Mileage != nil && c.Mileage != nil {
+		w /= 1 + math.Abs(float64(*t.Mileage-*c.Mileage))/mileageScale
+	}
+	return w
+}
+
+type weighted struct {
+	price  float64
+	weight float64
+}
+
+// Compute оценивает цену t по comps. Перцентили считаются по ценам comps с весами Weight
+// и линейной интерполяцией между соседними ценами; при равных весах медиана совпадает
+// с обычной. Результат округляется до сотых.
+func Compute(t Car, comps []Car) (Estimate, error) {
+	if len(comps) < MinComparables {
+		return Estimate{}, ErrTooFewComparables
+	}
+	ws := make([]weighted, len(comps))
+	total := 0.0
+	for i, c := range comps {
+		ws[i] = weighted{price: float64(c.Price), weight: Weight(t, c)}
+		total += ws[i].weight
+	}
+	slices.SortFunc(ws, func(a, b weighted) int {
+		switch {
+		case a.price < b.price:
+			return -1
+		case a.price > b.price:
+			return 1
+		}
+		return 0
+	})
+
+	// pos[i] — доля суммарного веса до середины i-й цены
+	pos := make([]float64, len(ws))
+	acc := 0.0
+	for i, w := range ws {
+		pos[i] = (acc + w.weight/2) / total
+		acc += w.weight
+	}
+	quantile := func(q float64) models.Money {
+		i, _ := slices.BinarySearch(pos, q)
+		switch {
+		case i == 0:
+			return round(ws[0].price)
+		case i == len(ws):
+			return round(ws[len(ws)-1].price)
+		}
+		lo, hi := ws[i-1].price, ws[i].price
+		return round(lo + (hi-lo)*(q-pos[i-1])/(pos[i]-pos[i-1]))
+	}
+
+	return Estimate{
+		Comparables: len(comps),
+		Median:      quantile(0.5),
+		P10:         quantile(0.1),
+		P25:         quantile(0.25),
+		P75:         quantile(0.75),
+		P90:         quantile(0.9),
+		Min:         round(ws[0].price),
+		Max:         round(ws[len(ws)-1].price),
+	}, nil
+}
+
+func round(f float64) models.Money {
+	return models.Money(math.Round(f))
+}
@@ -0,0 +1,80 @@
+package valuation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+func intPtr(n int) *int { return &n }
+
+func prices(year int, ps ...models.Money) []Car {
+	cars := make([]Car, len(ps))
+	for i, p := range ps {
+		cars[i] = Car{Year: year, Price: p}
+	}
+	return cars
+}
+
+func TestCompute_EqualWeights(t *testing.T) {
+	got, err := Compute(Car{Year: 2018}, prices(2018, 400, 100, 300, 200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Estimate{Comparables: 4, Median: 250, P10: 100, P25: 150, P75: 350, P90: 400, Min: 100, Max: 400}
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	got, err = Compute(Car{Year: 2018}, prices(2018, 100, 200, 300))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Median != 200 {
+		t.Fatalf("median of odd count = %d, want 200", got.Median)
+	}
+}
+
+func TestCompute_CloserCarsWeighMore(t *testing.T) {
+	target := Car{Year: 2018, Mileage: intPtr(50000)}
+	comps := []Car{
+		{Year: 2018, Mileage: intPtr(50000), Price: 1000},
+		{Year: 2018, Mileage: intPtr(55000), Price: 1100},
+		{Year: 2014, Mileage: intPtr(200000), Price: 300},
+		{Year: 2013, Mileage: intPtr(250000), Price: 250},
+	}
+	got, err := Compute(target, comps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// без весов медиана была бы (300+1000)/2 = 650
+	if got.Median < 900 || got.Median > 1100 {
+		t.Fatalf("median = %d, want close to the similar cars", got.Median)
+	}
+	if got.Min != 250 || got.Max != 1100 {
+		t.Fatalf("range = %d..%d, want 250..1100", got.Min, got.Max)
+	}
+	if !(got.P10 <= got.P25 && got.P25 <= got.Median && got.Median <= got.P75 && got.P75 <= got.P90) {
+		t.Fatalf("percentiles are not ordered: %+v", got)
+	}
+}
+
+func TestWeight(t *testing.T) {
+	target := Car{Year: 2018, Mileage: intPtr(60000)}
+	if w := Weight(target, Car{Year: 2018, Mileage: intPtr(60000)}); w != 1 {
+		t.Fatalf("same car weight = %v, want 1", w)
+	}
+	if w := Weight(target, Car{Year: 2016}); w != 1.0/3 {
+		t.Fatalf("unknown mileage weight = %v, want 1/3", w)
+	}
+	if w := Weight(target, Car{Year: 2018, Mileage: intPtr(80000)}); w != 0.5 {
+		t.Fatalf("20000 km apart weight = %v, want 0.5", w)
+	}
+}
+
+func TestCompute_TooFew(t *testing.T) {
+	if _, err := Compute(Car{Year: 2018}, prices(2018, 100, 200)); !errors.Is(err, ErrTooFewComparables) {
+		t.Fatalf("err = %v, want ErrTooFewComparables", err)
+	}
+}
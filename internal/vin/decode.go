@@ -0,0 +1,108 @@
+package vin
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+)
+
+// Info — расшифровка VIN. Производитель, марка и страна известны, только если WMI есть во встроенной таблице.
+type Info struct {
+	VIN          string `json:"vin"`
+	WMI          string `json:"wmi"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Brand        string `json:"brand,omitempty"`
+	Country      string `json:"country,omitempty"`
+	Region       string `json:"region,omitempty"`
+	ModelYear    int    `json:"model_year"`
+	PlantCode    string `json:"plant_code"`
+	SerialNumber string `json:"serial_number"`
+}
+
+type manufacturer struct {
+	name, brand, country string
+}
+
+//go:embed wmi.csv
+var wmiCSV []byte
+
+// wmiTable — встроенная таблица WMI; ключ из двух символов покрывает все WMI с этим префиксом.
+var wmiTable = sync.OnceValue(func() map[string]manufacturer {
+	rows, err := csv.NewReader(bytes.NewReader(wmiCSV)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("vin: wmi.csv: %v", err))
+	}
+	table := make(map[string]manufacturer, len(rows))
+	for _, row := range rows[1:] {
+		table[row[0]] = manufacturer{name: row[1], brand: row[2], country: row[3]}
+	}
+	return table
+})
+
+// yearCodes — символы 10-й позиции по порядку: A — 1980 (и 2010), Y — 2000, 1..9 — 2001..2009. Цикл 30 лет.
+const yearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// Decode расшифровывает VIN без внешних сервисов. Неверный VIN — apperr.ErrInvalidInput.
+func Decode(v string) (Info, error) {
+	v = Normalize(v)
+	if !Valid(v) {
+		return Info{}, fmt.Errorf("%w: invalid vin", apperr.ErrInvalidInput)
+	}
+	info := Info{
+		VIN:          v,
+		WMI:          v[:3],
+		Region:       region(v[0]),
+		ModelYear:    modelYear(v, time.Now().Year()),
+		PlantCode:    v[10:11],
+		SerialNumber: v[11:],
+	}
+	m, ok := wmiTable()[v[:3]]
+	if !ok {
+		m, ok = wmiTable()[v[:2]]
+	}
+	if ok {
+		info.Manufacturer, info.Brand, info.Country = m.name, m.brand, m.country
+	}
+	return info, nil
+}
+
+// modelYear выбирает 30-летний цикл по 7-й позиции, как принято в Северной Америке:
+// буква — 2010 год и позже, цифра — до 2010. Год позже следующего за текущим сдвигается на цикл назад.
+func modelYear(v string, currentYear int) int {
+	i := strings.IndexByte(yearCodes, v[9])
+	if i < 0 {
+		return 0
+	}
+	year := 1980 + i
+	if v[6] >= 'A' && v[6] <= 'Z' {
+		year += len(yearCodes)
+	}
+	if year > currentYear+1 {
+		year -= len(yearCodes)
+	}
+	return year
+}
+
+func region(c byte) string {
+	switch {
+	case c >= 'A' && c <= 'H':
+		return "Africa"
+	case c >= 'J' && c <= 'R':
+		return "Asia"
+	case c >= 'S' && c <= 'Z':
+		return "Europe"
+	case c >= '1' && c <= '5':
+		return "North America"
+	case c == '6' || c == '7':
+		return "Oceania"
+	case c == '8' || c == '9':
+		return "South America"
+	}
+	return ""
+}
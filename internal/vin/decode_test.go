@@ -0,0 +1,98 @@
+package vin
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+)
+
+// withCheckDigit подставляет верную контрольную цифру на 9-ю позицию.
+func withCheckDigit(v string) string {
+	sum := 0
+	for i := 0; i < Length; i++ {
+		n, _ := charValue(v[i])
+		sum += n * weights[i]
+	}
+	check := byte('0' + sum%11)
+	if sum%11 == 10 {
+		check = 'X'
+	}
+	return v[:8] + string(check) + v[9:]
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		vin  string
+		want Info
+	}{
+		{"1hgcm82633a004352", Info{
+			VIN: "1HGCM82633A004352", WMI: "1HG", Manufacturer: "Honda of America", Brand: "Honda",
+			Country: "United States", Region: "North America", ModelYear: 2003, PlantCode: "A", SerialNumber: "004352",
+		}},
+		{"1M8GDM9AXKP042788", Info{
+			VIN: "1M8GDM9AXKP042788", WMI: "1M8", Manufacturer: "Motor Coach Industries", Brand: "MCI",
+			Country: "United States", Region: "North America", ModelYear: 1989, PlantCode: "P", SerialNumber: "042788",
+		}},
+		// JTD нет в таблице — срабатывает префикс JT; буква в 7-й позиции — цикл с 2010 года
+		{withCheckDigit("JTDKBRF00C0123456"), Info{
+			WMI: "JTD", Manufacturer: "Toyota", Brand: "Toyota", Country: "Japan", Region: "Asia",
+			ModelYear: 2012, PlantCode: "0", SerialNumber: "123456",
+		}},
+		// неизвестный WMI: только регион и год
+		{withCheckDigit("ZZZ11111011111111"), Info{
+			WMI: "ZZZ", Region: "Europe", ModelYear: 2001, PlantCode: "1", SerialNumber: "111111",
+		}},
+	}
+	for _, tt := range tests {
+		got, err := Decode(tt.vin)
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", tt.vin, err)
+		}
+		tt.want.VIN = strings.ToUpper(tt.vin)
+		if got != tt.want {
+			t.Errorf("Decode(%q) = %+v, want %+v", tt.vin, got, tt.want)
+		}
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	if _, err := Decode("1M8GDM9A1KP042788"); !errors.Is(err, apperr.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestModelYear(t *testing.T) {
+	tests := []struct {
+		vin  string
+		year int
+	}{
+		{"1HGCM82633A004352", 2003},
+		{"WBA00000?A0000000", 1980},
+		{"WBAZZZZ0?A0000000", 2010},
+		{"WBAZZZZ0?90000000", 2009}, // 2039 ещё не наступил — предыдущий цикл
+		{"WBAZZZZ0?S0000000", 2025},
+	}
+	for _, tt := range tests {
+		if got := modelYear(tt.vin, 2026); got != tt.year {
+			t.Errorf("modelYear(%q) = %d, want %d", tt.vin, got, tt.year)
+		}
+	}
+}
+
+// brandRe повторяет проверку carname из models.
+var brandRe = regexp.MustCompile(`^[\p{L}\p{N}]+(?:[ '-][\p{L}\p{N}]+)*$`)
+
+func TestWMITable(t *testing.T) {
+	for wmi, m := range wmiTable() {
+		if len(wmi) != 2 && len(wmi) != 3 || m.name == "" || m.country == "" {
+			t.Errorf("bad wmi.csv row %q: %+v", wmi, m)
+		}
+		// марка подставляется в CreateCarRequest.Brand и должна пройти carname
+		if m.brand != "" && !brandRe.MatchString(m.brand) {
+			t.Errorf("wmi %q: brand %q is not a valid car name", wmi, m.brand)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package vin
+
+import "strings"
+
+// Length — длина VIN по ISO 3779.
+const Length = 17
+
+// weights — веса позиций для контрольной цифры; сама контрольная цифра (9-я позиция) имеет вес 0.
+var weights = [Length]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// charValue переводит символ VIN в число для контрольной суммы.
+// I, O и Q в VIN запрещены, чтобы их не путали с 1 и 0.
+func charValue(r byte) (int, bool) {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0'), true
+	case r >= 'A' && r <= 'H':
+		return int(r-'A') + 1, true
+	case r >= 'J' && r <= 'N':
+		return int(r-'J') + 1, true
+	case r == 'P':
+		return 7, true
+	case r == 'R':
+		return 9, true
+	case r >= 'S' && r <= 'Z':
+		return int(r-'S') + 2, true
+	}
+	return 0, false
+}
+
+// Normalize убирает пробелы по краям и приводит VIN к верхнему регистру.
+func Normalize(vin string) string {
+	return strings.ToUpper(strings.TrimSpace(vin))
+}
+
+// Valid проверяет VIN в верхнем регистре: 17 допустимых символов, 10-я позиция
+// (модельный год) не U, Z и 0, контрольная цифра на 9-й позиции совпадает
+// с вычисленной (остаток 10 записывается как X).
+func Valid(vin string) bool {
+	if len(vin) != Length {
+		return false
+	}
+	sum := 0
+	for i := 0; i < Length; i++ {
+		v, ok := charValue(vin[i])
+		if !ok {
+			return false
+		}
+		sum += v * weights[i]
+	}
+	switch vin[9] {
+	case 'U', 'Z', '0':
+		return false
+	}
+	check := byte('0' + sum%11)
+	if sum%11 == 10 {
+		check = 'X'
+	}
+	return vin[8] == check
+}
@@ -0,0 +1,28 @@
+package vin
+
+import "testing"
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		vin  string
+		want bool
+	}{
+		{"1M8GDM9AXKP042788", true}, // остаток 10 — контрольная цифра X
+		{"1HGCM82633A004352", true},
+		{"11111111111111111", true},
+		{"1M8GDM9A1KP042788", false}, // неверная контрольная цифра
+		{"1M8GDM9AXKP04278", false},  // 16 символов
+		{"1M8GDM9AXKP0427880", false},
+		{"1m8gdm9axkp042788", false}, // ожидается верхний регистр (Normalize)
+		{"1M8GDM9AXKP04278O", false}, // O запрещена
+		{"IM8GDM9AXKP042788", false}, // I запрещена
+		{"1M8GDM9AXQP042788", false}, // Q запрещена
+		{"1HGCM8261UA004352", false}, // контрольная цифра верна, но U не может обозначать модельный год
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.vin); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.vin, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// DeliveryQueue — куда Enqueuer ставит доставки (repository.WebhookProvider).
+type DeliveryQueue interface {
+	EnqueueDeliveries(ctx context.Context, evt models.OutboxEvent, payload []byte) (int, error)
+}
+
+// Enqueuer — events.EventPublisher, который раскладывает событие из outbox
+// по подпискам. Сама отправка выполняется worker.DeliverWebhooks.
+type Enqueuer struct {
+	queue DeliveryQueue
+}
+
+func NewEnqueuer(queue DeliveryQueue) *Enqueuer {
+	return &Enqueuer{queue: queue}
+}
+
+func (e *Enqueuer) Publish(ctx context.Context, evt models.OutboxEvent) error {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, err = e.queue.EnqueueDeliveries(ctx, evt, payload)
+	return err
+}
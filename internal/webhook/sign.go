@@ -0,0 +1,76 @@
+// Package webhook доставляет доменные события подписчикам по HTTP
+// с подписью HMAC-SHA256.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderSignature  = "X-Webhook-Signature"
+	HeaderDeliveryID = "X-Webhook-Delivery"
+	HeaderEventID    = "X-Event-ID"
+	HeaderEventType  = "X-Event-Type"
+
+	secretPrefix = "whsec_"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign возвращает значение заголовка X-Webhook-Signature: "t=<unix>,v1=<hex>",
+// где v1 = HMAC-SHA256(secret, "<unix>.<body>"). Метка времени в подписи
+// не даёт переиграть старый запрос.
+func Sign(secret string, ts time.Time, body []byte) string {
+	unix := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + unix + ",v1=" + mac(secret, unix, body)
+}
+
+// Verify проверяет заголовок подписи; подпись старше tolerance отклоняется.
+// Предназначена для получателей и тестов.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var unix, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			unix = v
+		case "v1":
+			sig = v
+		}
+	}
+	ts, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || sig == "" {
+		return ErrInvalidSignature
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(sig), []byte(mac(secret, unix, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func mac(secret, unix string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(unix))
+	h.Write([]byte{'.'})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NewSecret генерирует случайный секрет подписи.
+func NewSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	now := time.Unix(1_700_000_000, 0)
+	header := Sign("secret", now, body)
+	assert.True(t, strings.HasPrefix(header, "t=1700000000,v1="))
+
+	require.NoError(t, Verify("secret", header, body, now, time.Minute))
+	assert.ErrorIs(t, Verify("other", header, body, now, time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, []byte(`{"id":2}`), now, time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, body, now.Add(time.Hour), time.Minute), ErrInvalidSignature, "replayed")
+	assert.ErrorIs(t, Verify("secret", "garbage", body, now, time.Minute), ErrInvalidSignature)
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	require.NoError(t, err)
+	b, err := NewSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(a, secretPrefix))
+	assert.NotEqual(t, a, b)
+}
+
+func TestSender_SignsRequest(t *testing.T) {
+	const secret = "whsec_test"
+	sub := models.WebhookSubscription{ID: "sub-1", Secret: secret}
+	d := models.WebhookDelivery{ID: 7, EventID: 42, EventType: models.EventCarUpdated, Payload: []byte(`{"id":42}`)}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify(secret, r.Header.Get(HeaderSignature), body, time.Now(), time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "7", r.Header.Get(HeaderDeliveryID))
+		assert.Equal(t, "42", r.Header.Get(HeaderEventID))
+		assert.Equal(t, models.EventCarUpdated, r.Header.Get(HeaderEventType))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	sub.URL = srv.URL
+
+	code, err := NewSender(time.Second).Send(context.Background(), sub, d)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	sub.Secret = "whsec_wrong"
+	code, err = NewSender(time.Second).Send(context.Background(), sub, d)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
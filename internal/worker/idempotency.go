@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// IdempotencyPurger — то, что удаляет истёкшие ключи идемпотентности (usecase.IdempotencyUsecase).
+type IdempotencyPurger interface {
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// PurgeIdempotencyKeys раз в interval удаляет истёкшие ключи идемпотентности.
+// Блокируется до отмены ctx.
+func PurgeIdempotencyKeys(ctx context.Context, p IdempotencyPurger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeKeysOnce(ctx, p)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeKeysOnce(ctx context.Context, p IdempotencyPurger) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	n, err := p.PurgeExpired(ctx)
+	if err != nil {
+		slog.Error("idempotency keys purge failed", "err", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("idempotency keys purged", "keys", n)
+	}
+}
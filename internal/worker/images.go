@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ImagePurger — то, что удаляет файлы фотографий окончательно удалённых машин (usecase.ImageUsecase).
+type ImagePurger interface {
+	PurgeOrphans(ctx context.Context) (int64, error)
+}
+
+// PurgeImages раз в interval удаляет из хранилища объектов фотографии машин, удалённых
+// из корзины. Блокируется до отмены ctx.
+func PurgeImages(ctx context.Context, p ImagePurger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeImagesOnce(ctx, p)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeImagesOnce(ctx context.Context, p ImagePurger) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	n, err := p.PurgeOrphans(ctx)
+	if err != nil {
+		slog.Error("image purge failed", "err", err, "images", n)
+		return
+	}
+	if n > 0 {
+		slog.Info("images purged", "images", n)
+	}
+}
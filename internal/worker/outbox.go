@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/events"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// OutboxSource — то, откуда relay забирает события (repository.OutboxProvider).
+type OutboxSource interface {
+	PendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, retryAt time.Time, reason string) error
+}
+
+type RelayConfig struct {
+	Interval     time.Duration // пауза между опросами, когда событий нет
+	BatchSize    int
+	BaseBackoff  time.Duration // задержка перед первым повтором, дальше удваивается
+	MaxBackoff   time.Duration
+	PublishLimit time.Duration // таймаут одной публикации
+}
+
+// RelayOutbox публикует события из outbox, пока не отменён ctx.
+// Доставка at-least-once: событие помечается опубликованным только после
+// успешного Publish, поэтому при сбое между ними оно уйдёт повторно.
+// Порядок по машине сохраняется, так как источник отдаёт только первое
+// неопубликованное событие каждой машины. Рассчитан на один активный relay.
+func RelayOutbox(ctx context.Context, src OutboxSource, pub events.EventPublisher, cfg RelayConfig) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		wait := cfg.Interval
+		if n := relayOnce(ctx, src, pub, cfg); n > 0 {
+			wait = 0 // есть события — сразу забираем следующую пачку
+		}
+		timer.Reset(wait)
+	}
+}
+
+// relayOnce обрабатывает одну пачку и возвращает число успешно опубликованных событий.
+func relayOnce(ctx context.Context, src OutboxSource, pub events.EventPublisher, cfg RelayConfig) int {
+	batch, err := src.PendingEvents(ctx, cfg.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("outbox fetch failed", "err", err)
+		}
+		return 0
+	}
+
+	published := 0
+	for _, evt := range batch {
+		if ctx.Err() != nil {
+			return published
+		}
+		pctx, cancel := context.WithTimeout(ctx, cfg.PublishLimit)
+		err := pub.Publish(pctx, evt)
+		cancel()
+		if err != nil {
+			retryAt := time.Now().Add(backoff(evt.Attempts+1, cfg.BaseBackoff, cfg.MaxBackoff))
+			slog.Warn("event publish failed", "id", evt.ID, "type", evt.Type,
+				"attempt", evt.Attempts+1, "retry_at", retryAt, "err", err)
+			if err := src.MarkFailed(ctx, evt.ID, retryAt, err.Error()); err != nil {
+				slog.Error("outbox mark failed", "id", evt.ID, "err", err)
+			}
+			continue
+		}
+		if err := src.MarkPublished(ctx, evt.ID); err != nil {
+			slog.Error("outbox mark published failed", "id", evt.ID, "err", err)
+			continue
+		}
+		published++
+	}
+	return published
+}
+
+// backoff возвращает base * 2^(attempt-1), но не больше maxDelay.
+func backoff(attempt int, base, maxDelay time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < maxDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxDelay)
+}
@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+)
+
+type recordingPublisher struct {
+	mu       sync.Mutex
+	failures map[string]int // сколько раз подряд отказывать по машине
+	got      []models.OutboxEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, evt models.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failures[evt.CarID] > 0 {
+		p.failures[evt.CarID]--
+		return errors.New("broker unavailable")
+	}
+	p.got = append(p.got, evt)
+	return nil
+}
+
+func (p *recordingPublisher) events() []models.OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]models.OutboxEvent(nil), p.got...)
+}
+
+var testRelayConfig = RelayConfig{
+	Interval:     5 * time.Millisecond,
+	BatchSize:    10,
+	BaseBackoff:  time.Millisecond,
+	MaxBackoff:   10 * time.Millisecond,
+	PublishLimit: time.Second,
+}
+
+func TestRelayOutbox_RetriesAndKeepsOrderPerCar(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryCarRepo()
+
+	a := models.Car{Brand: "BMW", Model: "X5", Year: 2019}
+	require.NoError(t, repo.InsertCar(ctx, &a))
+	b := models.Car{Brand: "Audi", Model: "A6", Year: 2020}
+	require.NoError(t, repo.InsertCar(ctx, &b))
+	a.Year = 2020
+	require.NoError(t, repo.UpdateCar(ctx, &a))
+	require.NoError(t, repo.DeleteByID(ctx, a.ID))
+
+	pub := &recordingPublisher{failures: map[string]int{a.ID: 2}}
+	rctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		RelayOutbox(rctx, repo, pub, testRelayConfig)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return len(pub.events()) == 4 }, 2*time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	var forA []string
+	for _, e := range pub.events() {
+		if e.CarID == a.ID {
+			forA = append(forA, e.Type)
+		}
+	}
+	assert.Equal(t, []string{models.EventCarCreated, models.EventCarUpdated, models.EventCarDeleted}, forA)
+	assert.Equal(t, b.ID, pub.events()[0].CarID, "failing car must not block others")
+
+	pending, err := repo.PendingEvents(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestRelayOnce_MarksFailedWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryCarRepo()
+	c := models.Car{Brand: "Kia", Model: "Rio", Year: 2015}
+	require.NoError(t, repo.InsertCar(ctx, &c))
+
+	cfg := testRelayConfig
+	cfg.BaseBackoff = time.Hour
+	cfg.MaxBackoff = time.Hour
+	pub := &recordingPublisher{failures: map[string]int{c.ID: 1}}
+
+	assert.Zero(t, relayOnce(ctx, repo, pub, cfg))
+	pending, err := repo.PendingEvents(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending, "failed event waits for its retry time")
+}
+
+func TestBackoff(t *testing.T) {
+	base, maxDelay := time.Second, 10*time.Second
+	assert.Equal(t, time.Second, backoff(1, base, maxDelay))
+	assert.Equal(t, 2*time.Second, backoff(2, base, maxDelay))
+	assert.Equal(t, 8*time.Second, backoff(4, base, maxDelay))
+	assert.Equal(t, maxDelay, backoff(5, base, maxDelay))
+	assert.Equal(t, maxDelay, backoff(100, base, maxDelay))
+}
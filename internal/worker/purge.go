@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// TrashPurger — то, что умеет окончательно удалять машины из корзины (usecase.CarUsecase).
+type TrashPurger interface {
+	PurgeTrash(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// PurgeTrash раз в interval удаляет машины, пролежавшие в корзине дольше retention.
+// Блокируется до отмены ctx.
+func PurgeTrash(ctx context.Context, p TrashPurger, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeOnce(ctx, p, retention)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeOnce(ctx context.Context, p TrashPurger, retention time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	n, err := p.PurgeTrash(ctx, retention)
+	if err != nil {
+		slog.Error("trash purge failed", "err", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("trash purged", "cars", n, "retention", retention)
+	}
+}
@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ReservationExpirer — то, что снимает истёкшие брони (usecase.ReservationUsecase).
+type ReservationExpirer interface {
+	ExpireReservations(ctx context.Context) (int64, error)
+}
+
+// ExpireReservations раз в interval снимает брони, срок которых истёк, и возвращает машины
+// в продажу. Блокируется до отмены ctx.
+func ExpireReservations(ctx context.Context, e ReservationExpirer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expireOnce(ctx, e)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func expireOnce(ctx context.Context, e ReservationExpirer) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	n, err := e.ExpireReservations(ctx)
+	if err != nil {
+		slog.Error("reservation expiry failed", "err", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("reservations expired", "reservations", n)
+	}
+}
@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/pavel97go/service-cars/internal/apperr"
+	"github.com/pavel97go/service-cars/internal/models"
+)
+
+// WebhookStore — очередь доставок и подписки (repository.WebhookProvider).
+type WebhookStore interface {
+	PendingDeliveries(ctx context.Context, limit int) ([]models.WebhookDelivery, error)
+	GetWebhook(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	RecordDeliveryAttempt(ctx context.Context, id int64, res models.DeliveryResult) error
+}
+
+// WebhookSender отправляет доставку; возвращает HTTP-код ответа (0 — ответа не было).
+type WebhookSender interface {
+	Send(ctx context.Context, sub models.WebhookSubscription, d models.WebhookDelivery) (int, error)
+}
+
+type DeliveryConfig struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int // после стольких неудач доставка уходит в dead
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DeliverWebhooks отправляет доставки из очереди, пока не отменён ctx.
+// Неудачные попытки повторяются с экспоненциальной задержкой; после
+// MaxAttempts доставка получает статус dead и ждёт ручного redeliver.
+func DeliverWebhooks(ctx context.Context, store WebhookStore, sender WebhookSender, cfg DeliveryConfig) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		wait := cfg.Interval
+		if n := deliverOnce(ctx, store, sender, cfg); n > 0 {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// deliverOnce обрабатывает одну пачку и возвращает число выполненных попыток.
+func deliverOnce(ctx context.Context, store WebhookStore, sender WebhookSender, cfg DeliveryConfig) int {
+	batch, err := store.PendingDeliveries(ctx, cfg.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("webhook fetch failed", "err", err)
+		}
+		return 0
+	}
+
+	subs := map[string]*models.WebhookSubscription{}
+	for i, d := range batch {
+		if ctx.Err() != nil {
+			return i
+		}
+		sub, ok := subs[d.SubscriptionID]
+		if !ok {
+			sub, err = store.GetWebhook(ctx, d.SubscriptionID)
+			if err != nil && !errors.Is(err, apperr.ErrNotFound) {
+				slog.Error("webhook subscription lookup failed", "id", d.SubscriptionID, "err", err)
+				continue
+			}
+			subs[d.SubscriptionID] = sub
+		}
+		res := attempt(ctx, sender, sub, d, cfg)
+		if err := store.RecordDeliveryAttempt(ctx, d.ID, res); err != nil {
+			slog.Error("webhook record attempt failed", "delivery", d.ID, "err", err)
+		}
+	}
+	return len(batch)
+}
+
+func attempt(ctx context.Context, sender WebhookSender, sub *models.WebhookSubscription,
+	d models.WebhookDelivery, cfg DeliveryConfig) models.DeliveryResult {
+	now := time.Now()
+	if sub == nil || !sub.Active {
+		return models.DeliveryResult{Status: models.DeliveryDead, NextAttemptAt: now, Error: "subscription is inactive"}
+	}
+
+	code, err := sender.Send(ctx, *sub, d)
+	if err == nil {
+		return models.DeliveryResult{Status: models.DeliverySucceeded, NextAttemptAt: now, ResponseStatus: code}
+	}
+
+	res := models.DeliveryResult{ResponseStatus: code, Error: err.Error()}
+	if d.Attempts+1 >= cfg.MaxAttempts {
+		res.Status = models.DeliveryDead
+		res.NextAttemptAt = now
+		slog.Warn("webhook delivery dead-lettered", "delivery", d.ID, "subscription", sub.ID,
+			"attempts", d.Attempts+1, "err", err)
+		return res
+	}
+	res.Status = models.DeliveryPending
+	res.NextAttemptAt = now.Add(backoff(d.Attempts+1, cfg.BaseBackoff, cfg.MaxBackoff))
+	return res
+}
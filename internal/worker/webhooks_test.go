@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavel97go/service-cars/internal/models"
+	"github.com/pavel97go/service-cars/internal/repository"
+	"github.com/pavel97go/service-cars/internal/webhook"
+)
+
+var testDeliveryConfig = DeliveryConfig{
+	Interval:    5 * time.Millisecond,
+	BatchSize:   10,
+	MaxAttempts: 3,
+	BaseBackoff: time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+// receiver — httptest-получатель, который проверяет подпись и отвечает 500 первые failFirst раз.
+func receiver(t *testing.T, secret string, failFirst int32) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, webhook.Verify(secret, r.Header.Get(webhook.HeaderSignature), body, time.Now(), time.Minute))
+		if calls.Add(1) <= failFirst {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func setupDelivery(t *testing.T, url string) (*repository.MemoryCarRepo, models.WebhookSubscription) {
+	t.Helper()
+	ctx := context.Background()
+	repo := repository.NewMemoryCarRepo()
+	sub := models.WebhookSubscription{URL: url, Secret: "whsec_test", Active: true}
+	require.NoError(t, repo.CreateWebhook(ctx, &sub))
+
+	car := models.Car{Brand: "Toyota", Model: "Camry", Year: 2020}
+	require.NoError(t, repo.InsertCar(ctx, &car))
+	evts, err := repo.PendingEvents(ctx, 10)
+	require.NoError(t, err)
+	require.NoError(t, webhook.NewEnqueuer(repo).Publish(ctx, evts[0]))
+	return repo, sub
+}
+
+func runDeliveries(t *testing.T, repo *repository.MemoryCarRepo, until func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		DeliverWebhooks(ctx, repo, webhook.NewSender(time.Second), testDeliveryConfig)
+		close(done)
+	}()
+	require.Eventually(t, until, 2*time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+}
+
+func TestDeliverWebhooks_RetriesUntilSuccess(t *testing.T) {
+	srv, calls := receiver(t, "whsec_test", 2)
+	repo, sub := setupDelivery(t, srv.URL)
+
+	runDeliveries(t, repo, func() bool {
+		items, _, _ := repo.ListDeliveries(context.Background(), sub.ID, models.DeliverySucceeded, 0, 0)
+		return len(items) == 1
+	})
+
+	items, _, err := repo.ListDeliveries(context.Background(), sub.ID, "", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, 3, items[0].Attempts)
+	assert.Equal(t, http.StatusOK, items[0].ResponseStatus)
+	assert.EqualValues(t, 3, calls.Load())
+}
+
+func TestDeliverWebhooks_DeadLetterAndRedeliver(t *testing.T) {
+	srv, calls := receiver(t, "whsec_test", 3)
+	repo, sub := setupDelivery(t, srv.URL)
+	ctx := context.Background()
+
+	runDeliveries(t, repo, func() bool {
+		items, _, _ := repo.ListDeliveries(ctx, sub.ID, models.DeliveryDead, 0, 0)
+		return len(items) == 1
+	})
+	dead, _, err := repo.ListDeliveries(ctx, sub.ID, models.DeliveryDead, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, testDeliveryConfig.MaxAttempts, dead[0].Attempts)
+	assert.Equal(t, "receiver responded 500", dead[0].LastError)
+	assert.EqualValues(t, 3, calls.Load())
+
+	_, err = repo.RedeliverDelivery(ctx, dead[0].ID)
+	require.NoError(t, err)
+	runDeliveries(t, repo, func() bool {
+		items, _, _ := repo.ListDeliveries(ctx, sub.ID, models.DeliverySucceeded, 0, 0)
+		return len(items) == 1
+	})
+	assert.EqualValues(t, 4, calls.Load())
+}
+
+func TestDeliverWebhooks_InactiveSubscriptionIsDeadLettered(t *testing.T) {
+	srv, calls := receiver(t, "whsec_test", 0)
+	repo, sub := setupDelivery(t, srv.URL)
+	ctx := context.Background()
+
+	sub.Active = false
+	require.NoError(t, repo.UpdateWebhook(ctx, &sub))
+	assert.Equal(t, 1, deliverOnce(ctx, repo, webhook.NewSender(time.Second), testDeliveryConfig))
+
+	items, _, err := repo.ListDeliveries(ctx, sub.ID, models.DeliveryDead, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Zero(t, calls.Load())
+}